@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/manager"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/plugin"
 )
@@ -34,6 +35,9 @@ func (r *Resolver) Query() models.QueryResolver {
 func (r *Resolver) Scene() models.SceneResolver {
 	return &sceneResolver{r}
 }
+func (r *Resolver) SceneFile() models.SceneFileResolver {
+	return &sceneFileResolver{r}
+}
 func (r *Resolver) Image() models.ImageResolver {
 	return &imageResolver{r}
 }
@@ -52,6 +56,9 @@ func (r *Resolver) Subscription() models.SubscriptionResolver {
 func (r *Resolver) Tag() models.TagResolver {
 	return &tagResolver{r}
 }
+func (r *Resolver) Job() models.JobResolver {
+	return &jobResolver{r}
+}
 
 func (r *Resolver) ScrapedSceneTag() models.ScrapedSceneTagResolver {
 	return &scrapedSceneTagResolver{r}
@@ -76,11 +83,13 @@ type subscriptionResolver struct{ *Resolver }
 type galleryResolver struct{ *Resolver }
 type performerResolver struct{ *Resolver }
 type sceneResolver struct{ *Resolver }
+type sceneFileResolver struct{ *Resolver }
 type sceneMarkerResolver struct{ *Resolver }
 type imageResolver struct{ *Resolver }
 type studioResolver struct{ *Resolver }
 type movieResolver struct{ *Resolver }
 type tagResolver struct{ *Resolver }
+type jobResolver struct{ *Resolver }
 type scrapedSceneTagResolver struct{ *Resolver }
 type scrapedSceneMovieResolver struct{ *Resolver }
 type scrapedScenePerformerResolver struct{ *Resolver }
@@ -166,6 +175,10 @@ func (r *queryResolver) Stats(ctx context.Context) (*models.StatsResultType, err
 	return &ret, nil
 }
 
+func (r *queryResolver) GeneratedUsage(ctx context.Context) ([]*models.GeneratedCategoryUsage, error) {
+	return generatedUsageToModel(manager.GetInstance().GetGeneratedUsage()), nil
+}
+
 func (r *queryResolver) Version(ctx context.Context) (*models.Version, error) {
 	version, hash, buildtime := GetVersion()
 
@@ -119,6 +119,34 @@ func (r *galleryResolver) Studio(ctx context.Context, obj *models.Gallery) (ret
 	return ret, nil
 }
 
+func (r *galleryResolver) Parent(ctx context.Context, obj *models.Gallery) (ret *models.Gallery, err error) {
+	if !obj.ParentID.Valid {
+		return nil, nil
+	}
+
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		var err error
+		ret, err = repo.Gallery().Find(int(obj.ParentID.Int64))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *galleryResolver) Children(ctx context.Context, obj *models.Gallery) (ret []*models.Gallery, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		var err error
+		ret, err = repo.Gallery().FindChildren(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
 func (r *galleryResolver) Tags(ctx context.Context, obj *models.Gallery) (ret []*models.Tag, err error) {
 	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
 		var err error
@@ -2,11 +2,13 @@ package api
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/stashapp/stash/pkg/api/urlbuilders"
 	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
 )
 
 func (r *imageResolver) Title(ctx context.Context, obj *models.Image) (*string, error) {
@@ -14,6 +16,14 @@ func (r *imageResolver) Title(ctx context.Context, obj *models.Image) (*string,
 	return &ret, nil
 }
 
+func (r *imageResolver) Date(ctx context.Context, obj *models.Image) (*string, error) {
+	if obj.Date.Valid {
+		result := utils.GetYMDFromDatabaseDate(obj.Date.String)
+		return &result, nil
+	}
+	return nil, nil
+}
+
 func (r *imageResolver) Rating(ctx context.Context, obj *models.Image) (*int, error) {
 	if obj.Rating.Valid {
 		rating := int(obj.Rating.Int64)
@@ -22,26 +32,78 @@ func (r *imageResolver) Rating(ctx context.Context, obj *models.Image) (*int, er
 	return nil, nil
 }
 
+func (r *imageResolver) Phash(ctx context.Context, obj *models.Image) (*string, error) {
+	if obj.Phash.Valid {
+		hexval := utils.PhashToString(obj.Phash.Int64)
+		return &hexval, nil
+	}
+	return nil, nil
+}
+
 func (r *imageResolver) File(ctx context.Context, obj *models.Image) (*models.ImageFileType, error) {
 	width := int(obj.Width.Int64)
 	height := int(obj.Height.Int64)
 	size := int(obj.Size.Int64)
 	return &models.ImageFileType{
-		Size:   &size,
-		Width:  &width,
-		Height: &height,
+		Size:       &size,
+		Width:      &width,
+		Height:     &height,
+		Duration:   nullFloat64Ptr(obj.Duration),
+		FrameCount: nullInt64Ptr(obj.FrameCount),
 	}, nil
 }
 
+func (r *imageResolver) Metadata(ctx context.Context, obj *models.Image) (*models.ImageMetadataType, error) {
+	ret := &models.ImageMetadataType{
+		CameraMake:   nullStringPtr(obj.CameraMake),
+		CameraModel:  nullStringPtr(obj.CameraModel),
+		Lens:         nullStringPtr(obj.Lens),
+		ExposureTime: nullFloat64Ptr(obj.ExposureTime),
+		FNumber:      nullFloat64Ptr(obj.FNumber),
+		Iso:          nullInt64Ptr(obj.ISO),
+		FocalLength:  nullFloat64Ptr(obj.FocalLength),
+		GpsLatitude:  nullFloat64Ptr(obj.GPSLatitude),
+		GpsLongitude: nullFloat64Ptr(obj.GPSLongitude),
+		Orientation:  nullInt64Ptr(obj.Orientation),
+		Rating:       nullInt64Ptr(obj.MetadataRating),
+		Creator:      nullStringPtr(obj.Creator),
+	}
+
+	if obj.CapturedAt.Valid {
+		ret.CapturedAt = &obj.CapturedAt.Timestamp
+	}
+
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		var err error
+		ret.Keywords, err = repo.Image().GetKeywords(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	if ret.Keywords == nil {
+		ret.Keywords = []string{}
+	}
+
+	return ret, nil
+}
+
 func (r *imageResolver) Paths(ctx context.Context, obj *models.Image) (*models.ImagePathsType, error) {
 	baseURL, _ := ctx.Value(BaseURLCtxKey).(string)
 	builder := urlbuilders.NewImageURLBuilder(baseURL, obj)
 	thumbnailPath := builder.GetThumbnailURL()
 	imagePath := builder.GetImageURL()
-	return &models.ImagePathsType{
+	ret := &models.ImagePathsType{
 		Image:     &imagePath,
 		Thumbnail: &thumbnailPath,
-	}, nil
+	}
+
+	if obj.Clip {
+		previewPath := builder.GetPreviewURL()
+		ret.Preview = &previewPath
+	}
+
+	return ret, nil
 }
 
 func (r *imageResolver) Galleries(ctx context.Context, obj *models.Image) (ret []*models.Gallery, err error) {
@@ -104,3 +166,37 @@ func (r *imageResolver) UpdatedAt(ctx context.Context, obj *models.Image) (*time
 func (r *imageResolver) FileModTime(ctx context.Context, obj *models.Image) (*time.Time, error) {
 	return &obj.FileModTime.Timestamp, nil
 }
+
+func (r *imageResolver) OHistory(ctx context.Context, obj *models.Image) (ret []*time.Time, err error) {
+	var dates []time.Time
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		dates, err = repo.Image().GetODates(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return timesToPointers(dates), nil
+}
+
+func nullStringPtr(v sql.NullString) *string {
+	if !v.Valid {
+		return nil
+	}
+	return &v.String
+}
+
+func nullInt64Ptr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	ret := int(v.Int64)
+	return &ret
+}
+
+func nullFloat64Ptr(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Float64
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/stashapp/stash/pkg/manager"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *jobResolver) Result(ctx context.Context, obj *models.Job) (models.JobResult, error) {
+	jobID, err := strconv.Atoi(obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	j := manager.GetInstance().JobManager.GetJob(jobID)
+	if j == nil {
+		return nil, nil
+	}
+
+	switch result := j.Result.(type) {
+	case *manager.ScanReport:
+		return scanReportToModel(ctx, result), nil
+	case *manager.GeneratedReport:
+		return generatedReportToModel(ctx, result), nil
+	}
+
+	return nil, nil
+}
+
+func scanReportToModel(ctx context.Context, r *manager.ScanReport) *models.ScanReport {
+	ret := &models.ScanReport{
+		New:        r.New,
+		Modified:   r.Modified,
+		Moved:      scanReportMatchesToModel(r.Moved),
+		Duplicates: scanReportMatchesToModel(r.Duplicates),
+		Excluded:   r.Excluded,
+	}
+
+	// ensure empty lists are returned for non-nullable fields
+	if ret.New == nil {
+		ret.New = []string{}
+	}
+	if ret.Modified == nil {
+		ret.Modified = []string{}
+	}
+	if ret.Excluded == nil {
+		ret.Excluded = []string{}
+	}
+
+	if r.DownloadHash != "" {
+		baseURL, _ := ctx.Value(BaseURLCtxKey).(string)
+		url := baseURL + "/downloads/" + r.DownloadHash + "/scan_report.json"
+		ret.DownloadURL = &url
+	}
+
+	return ret
+}
+
+func scanReportMatchesToModel(matches []manager.ScanReportMatch) []*models.ScanReportMatch {
+	ret := make([]*models.ScanReportMatch, len(matches))
+	for i, m := range matches {
+		ret[i] = &models.ScanReportMatch{
+			Path:         m.Path,
+			ExistingPath: m.ExistingPath,
+		}
+	}
+
+	return ret
+}
+
+func generatedReportToModel(ctx context.Context, r *manager.GeneratedReport) *models.GeneratedReport {
+	ret := &models.GeneratedReport{
+		Categories: generatedUsageToModel(r.Categories),
+		Deleted:    r.Deleted,
+	}
+
+	if r.DownloadHash != "" {
+		baseURL, _ := ctx.Value(BaseURLCtxKey).(string)
+		url := baseURL + "/downloads/" + r.DownloadHash + "/generated_report.json"
+		ret.DownloadURL = &url
+	}
+
+	return ret
+}
+
+func generatedUsageToModel(usage []*manager.GeneratedUsage) []*models.GeneratedCategoryUsage {
+	ret := make([]*models.GeneratedCategoryUsage, len(usage))
+	for i, u := range usage {
+		ret[i] = &models.GeneratedCategoryUsage{
+			Category:    u.Category,
+			Files:       u.Files,
+			Size:        float64(u.Size),
+			OrphanFiles: u.OrphanFiles,
+			OrphanSize:  float64(u.OrphanSize),
+		}
+	}
+
+	return ret
+}
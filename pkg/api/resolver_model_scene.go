@@ -7,6 +7,7 @@ import (
 	"github.com/stashapp/stash/pkg/api/urlbuilders"
 	"github.com/stashapp/stash/pkg/manager/config"
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/scene"
 	"github.com/stashapp/stash/pkg/utils"
 )
 
@@ -62,18 +63,38 @@ func (r *sceneResolver) Rating(ctx context.Context, obj *models.Scene) (*int, er
 }
 
 func (r *sceneResolver) File(ctx context.Context, obj *models.Scene) (*models.SceneFileType, error) {
+	var tracks []*models.SceneAudioTrack
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		var err error
+		tracks, err = repo.Scene().GetAudioTracks(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	audioTracks := []*models.AudioTrack{}
+	for _, t := range tracks {
+		audioTracks = append(audioTracks, &models.AudioTrack{
+			Index:        t.StreamIndex,
+			LanguageCode: t.LanguageCode,
+			Codec:        t.Codec,
+			Channels:     t.Channels,
+		})
+	}
+
 	width := int(obj.Width.Int64)
 	height := int(obj.Height.Int64)
 	bitrate := int(obj.Bitrate.Int64)
 	return &models.SceneFileType{
-		Size:       &obj.Size.String,
-		Duration:   &obj.Duration.Float64,
-		VideoCodec: &obj.VideoCodec.String,
-		AudioCodec: &obj.AudioCodec.String,
-		Width:      &width,
-		Height:     &height,
-		Framerate:  &obj.Framerate.Float64,
-		Bitrate:    &bitrate,
+		Size:        &obj.Size.String,
+		Duration:    &obj.Duration.Float64,
+		VideoCodec:  &obj.VideoCodec.String,
+		AudioCodec:  &obj.AudioCodec.String,
+		AudioTracks: audioTracks,
+		Width:       &width,
+		Height:      &height,
+		Framerate:   &obj.Framerate.Float64,
+		Bitrate:     &bitrate,
 	}, nil
 }
 
@@ -90,6 +111,25 @@ func (r *sceneResolver) Paths(ctx context.Context, obj *models.Scene) (*models.S
 	chaptersVttPath := builder.GetChaptersVTTURL()
 	funscriptPath := builder.GetFunscriptURL()
 
+	var captions []*models.SceneCaption
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		var err error
+		captions, err = repo.Scene().GetCaptions(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	var videoCaptions []*models.VideoCaption
+	for _, c := range captions {
+		videoCaptions = append(videoCaptions, &models.VideoCaption{
+			LanguageCode: c.LanguageCode,
+			CaptionType:  c.CaptionType,
+			Embedded:     c.IsEmbedded(),
+			URL:          builder.GetCaptionURL(*c),
+		})
+	}
+
 	return &models.ScenePathsType{
 		Screenshot:  &screenshotPath,
 		Preview:     &previewPath,
@@ -99,9 +139,21 @@ func (r *sceneResolver) Paths(ctx context.Context, obj *models.Scene) (*models.S
 		ChaptersVtt: &chaptersVttPath,
 		Sprite:      &spritePath,
 		Funscript:   &funscriptPath,
+		Captions:    videoCaptions,
 	}, nil
 }
 
+func (r *sceneResolver) Files(ctx context.Context, obj *models.Scene) (ret []*models.SceneFile, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.Scene().GetFiles(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
 func (r *sceneResolver) SceneMarkers(ctx context.Context, obj *models.Scene) (ret []*models.SceneMarker, err error) {
 	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
 		ret, err = repo.SceneMarker().FindBySceneID(obj.ID)
@@ -227,3 +279,90 @@ func (r *sceneResolver) UpdatedAt(ctx context.Context, obj *models.Scene) (*time
 func (r *sceneResolver) FileModTime(ctx context.Context, obj *models.Scene) (*time.Time, error) {
 	return &obj.FileModTime.Timestamp, nil
 }
+
+func (r *sceneResolver) LastPlayedAt(ctx context.Context, obj *models.Scene) (*time.Time, error) {
+	if obj.LastPlayedAt.Valid {
+		return &obj.LastPlayedAt.Timestamp, nil
+	}
+	return nil, nil
+}
+
+func (r *sceneResolver) OHistory(ctx context.Context, obj *models.Scene) (ret []*time.Time, err error) {
+	var dates []time.Time
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		dates, err = repo.Scene().GetODates(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return timesToPointers(dates), nil
+}
+
+func (r *sceneResolver) PlayHistory(ctx context.Context, obj *models.Scene) (ret []*time.Time, err error) {
+	var dates []time.Time
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		dates, err = repo.Scene().GetPlayDates(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return timesToPointers(dates), nil
+}
+
+func (r *sceneResolver) IntroEnd(ctx context.Context, obj *models.Scene) (*float64, error) {
+	return nullFloat64Ptr(obj.IntroEnd), nil
+}
+
+func (r *sceneResolver) OutroStart(ctx context.Context, obj *models.Scene) (*float64, error) {
+	return nullFloat64Ptr(obj.OutroStart), nil
+}
+
+func (r *sceneResolver) Cuts(ctx context.Context, obj *models.Scene) (ret []float64, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.Scene().GetCuts(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *sceneResolver) MarkerSuggestions(ctx context.Context, obj *models.Scene, limit *int) ([]*models.SceneMarkerSuggestion, error) {
+	var cuts []float64
+	var markers []*models.SceneMarker
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		var err error
+		cuts, err = repo.Scene().GetCuts(obj.ID)
+		if err != nil {
+			return err
+		}
+
+		markers, err = repo.SceneMarker().FindBySceneID(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	maxCuts := scene.DefaultSuggestedCuts
+	if limit != nil {
+		maxCuts = *limit
+	}
+
+	ret := scene.SuggestMarkers(obj, cuts, markers, maxCuts)
+	if ret == nil {
+		ret = []*models.SceneMarkerSuggestion{}
+	}
+
+	return ret, nil
+}
+
+func timesToPointers(dates []time.Time) []*time.Time {
+	ret := make([]*time.Time, len(dates))
+	for i := range dates {
+		ret[i] = &dates[i]
+	}
+	return ret
+}
@@ -65,3 +65,15 @@ func (r *sceneMarkerResolver) CreatedAt(ctx context.Context, obj *models.SceneMa
 func (r *sceneMarkerResolver) UpdatedAt(ctx context.Context, obj *models.SceneMarker) (*time.Time, error) {
 	return &obj.UpdatedAt.Timestamp, nil
 }
+
+func (r *sceneMarkerResolver) EndSeconds(ctx context.Context, obj *models.SceneMarker) (*float64, error) {
+	if !obj.EndSeconds.Valid {
+		return nil, nil
+	}
+
+	return &obj.EndSeconds.Float64, nil
+}
+
+func (r *sceneMarkerResolver) Duration(ctx context.Context, obj *models.SceneMarker) (*float64, error) {
+	return obj.GetDuration(), nil
+}
@@ -115,6 +115,14 @@ func (r *mutationResolver) ConfigureGeneral(ctx context.Context, input models.Co
 		c.Set(config.MaxStreamingTranscodeSize, input.MaxStreamingTranscodeSize.String())
 	}
 
+	if input.MaxConcurrentTranscodes != nil {
+		c.Set(config.MaxConcurrentTranscodes, *input.MaxConcurrentTranscodes)
+	}
+
+	if input.TranscodeQueueTimeout != nil {
+		c.Set(config.TranscodeQueueTimeout, *input.TranscodeQueueTimeout)
+	}
+
 	if input.Username != nil {
 		c.Set(config.Username, input.Username)
 	}
@@ -161,12 +169,47 @@ func (r *mutationResolver) ConfigureGeneral(ctx context.Context, input models.Co
 		c.Set(config.ImageExtensions, input.ImageExtensions)
 	}
 
+	if input.ImageClipExtensions != nil {
+		c.Set(config.ImageClipExtensions, input.ImageClipExtensions)
+	}
+
 	if input.GalleryExtensions != nil {
 		c.Set(config.GalleryExtensions, input.GalleryExtensions)
 	}
 
 	c.Set(config.CreateGalleriesFromFolders, input.CreateGalleriesFromFolders)
 
+	if input.AutoRotateImages != nil {
+		c.Set(config.AutoRotateImages, *input.AutoRotateImages)
+	}
+
+	if input.ChapterTagMappings != nil {
+		c.Set(config.ChapterTagMappings, input.ChapterTagMappings)
+	}
+
+	if input.ChapterDefaultTag != nil {
+		if *input.ChapterDefaultTag == "" {
+			return makeConfigGeneralResult(), errors.New("chapter default tag cannot be empty")
+		}
+		c.Set(config.ChapterDefaultTag, *input.ChapterDefaultTag)
+	}
+
+	if input.WatchStashPaths != nil {
+		c.Set(config.WatchStashPaths, *input.WatchStashPaths)
+	}
+
+	if input.WatchDebounceDelay != nil {
+		c.Set(config.WatchDebounceDelay, *input.WatchDebounceDelay)
+	}
+
+	if input.WatchUsePolling != nil {
+		c.Set(config.WatchUsePolling, *input.WatchUsePolling)
+	}
+
+	if input.WatchPollInterval != nil {
+		c.Set(config.WatchPollInterval, *input.WatchPollInterval)
+	}
+
 	refreshScraperCache := false
 	if input.ScraperUserAgent != nil {
 		c.Set(config.ScraperUserAgent, input.ScraperUserAgent)
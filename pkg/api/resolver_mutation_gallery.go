@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/stashapp/stash/pkg/gallery"
 	"github.com/stashapp/stash/pkg/manager"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/plugin"
@@ -72,6 +73,11 @@ func (r *mutationResolver) GalleryCreate(ctx context.Context, input models.Galle
 		newGallery.StudioID = sql.NullInt64{Valid: false}
 	}
 
+	if input.ParentID != nil {
+		parentID, _ := strconv.ParseInt(*input.ParentID, 10, 64)
+		newGallery.ParentID = sql.NullInt64{Int64: parentID, Valid: true}
+	}
+
 	// Start the transaction and save the gallery
 	var gallery *models.Gallery
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
@@ -220,7 +226,7 @@ func (r *mutationResolver) galleryUpdate(input models.GalleryUpdateInput, transl
 			return nil, errors.New("title must not be empty")
 		}
 
-		// if gallery is not zip-based, then generate the checksum from the title
+		// if gallery is not archive-based, then generate the checksum from the title
 		if !originalGallery.Path.Valid {
 			checksum := utils.MD5FromString(*input.Title)
 			updatedGallery.Checksum = &checksum
@@ -234,8 +240,15 @@ func (r *mutationResolver) galleryUpdate(input models.GalleryUpdateInput, transl
 	updatedGallery.Date = translator.sqliteDate(input.Date, "date")
 	updatedGallery.Rating = translator.nullInt64(input.Rating, "rating")
 	updatedGallery.StudioID = translator.nullInt64FromString(input.StudioID, "studio_id")
+	updatedGallery.ParentID = translator.nullInt64FromString(input.ParentID, "parent_id")
 	updatedGallery.Organized = input.Organized
 
+	if updatedGallery.ParentID != nil {
+		if err := gallery.ValidateParent(qb, galleryID, *updatedGallery.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
 	// gallery scene is set from the scene only
 
 	gallery, err := qb.UpdatePartial(updatedGallery)
@@ -284,6 +297,7 @@ func (r *mutationResolver) BulkGalleryUpdate(ctx context.Context, input models.B
 	updatedGallery.Date = translator.sqliteDate(input.Date, "date")
 	updatedGallery.Rating = translator.nullInt64(input.Rating, "rating")
 	updatedGallery.StudioID = translator.nullInt64FromString(input.StudioID, "studio_id")
+	updatedGallery.ParentID = translator.nullInt64FromString(input.ParentID, "parent_id")
 	updatedGallery.Organized = input.Organized
 
 	ret := []*models.Gallery{}
@@ -296,6 +310,12 @@ func (r *mutationResolver) BulkGalleryUpdate(ctx context.Context, input models.B
 			galleryID, _ := strconv.Atoi(galleryIDStr)
 			updatedGallery.ID = galleryID
 
+			if updatedGallery.ParentID != nil {
+				if err := gallery.ValidateParent(qb, galleryID, *updatedGallery.ParentID); err != nil {
+					return err
+				}
+			}
+
 			gallery, err := qb.UpdatePartial(updatedGallery)
 			if err != nil {
 				return err
@@ -414,7 +434,7 @@ func (r *mutationResolver) GalleryDestroy(ctx context.Context, input models.Gall
 
 			galleries = append(galleries, gallery)
 
-			// if this is a zip-based gallery, delete the images as well first
+			// if this is an archive-based gallery, delete the images as well first
 			if gallery.Zip {
 				imgs, err := iqb.FindByGalleryID(id)
 				if err != nil {
@@ -518,7 +538,7 @@ func (r *mutationResolver) AddGalleryImages(ctx context.Context, input models.Ga
 		}
 
 		if gallery.Zip {
-			return errors.New("cannot modify zip gallery images")
+			return errors.New("cannot modify archive gallery images")
 		}
 
 		newIDs, err := qb.GetImageIDs(galleryID)
@@ -558,7 +578,7 @@ func (r *mutationResolver) RemoveGalleryImages(ctx context.Context, input models
 		}
 
 		if gallery.Zip {
-			return errors.New("cannot modify zip gallery images")
+			return errors.New("cannot modify archive gallery images")
 		}
 
 		newIDs, err := qb.GetImageIDs(galleryID)
@@ -55,6 +55,11 @@ func (r *mutationResolver) MetadataExport(ctx context.Context) (string, error) {
 	return strconv.Itoa(jobID), nil
 }
 
+func (r *mutationResolver) MetadataExportNfo(ctx context.Context, input models.ExportNfoInput) (string, error) {
+	jobID := manager.GetInstance().ExportNFO(ctx, input)
+	return strconv.Itoa(jobID), nil
+}
+
 func (r *mutationResolver) ExportObjects(ctx context.Context, input models.ExportObjectsInput) (*string, error) {
 	t := manager.CreateExportTask(config.GetInstance().GetVideoFileNamingAlgorithm(), input)
 
@@ -84,6 +89,11 @@ func (r *mutationResolver) MetadataGenerate(ctx context.Context, input models.Ge
 	return strconv.Itoa(jobID), nil
 }
 
+func (r *mutationResolver) MetadataRegenerateImageThumbnails(ctx context.Context, input models.RegenerateImageThumbnailsInput) (string, error) {
+	jobID := manager.GetInstance().RegenerateImageThumbnails(ctx, input)
+	return strconv.Itoa(jobID), nil
+}
+
 func (r *mutationResolver) MetadataAutoTag(ctx context.Context, input models.AutoTagMetadataInput) (string, error) {
 	jobID := manager.GetInstance().AutoTag(ctx, input)
 	return strconv.Itoa(jobID), nil
@@ -94,6 +104,11 @@ func (r *mutationResolver) MetadataClean(ctx context.Context, input models.Clean
 	return strconv.Itoa(jobID), nil
 }
 
+func (r *mutationResolver) MetadataCleanGenerated(ctx context.Context, input models.CleanGeneratedInput) (string, error) {
+	jobID := manager.GetInstance().CleanGenerated(ctx, input)
+	return strconv.Itoa(jobID), nil
+}
+
 func (r *mutationResolver) MigrateHashNaming(ctx context.Context) (string, error) {
 	jobID := manager.GetInstance().MigrateHash(ctx)
 	return strconv.Itoa(jobID), nil
@@ -11,6 +11,7 @@ import (
 	"github.com/stashapp/stash/pkg/manager/config"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/plugin"
+	"github.com/stashapp/stash/pkg/scene"
 	"github.com/stashapp/stash/pkg/utils"
 )
 
@@ -402,6 +403,7 @@ func (r *mutationResolver) SceneDestroy(ctx context.Context, input models.SceneD
 	}
 
 	var scene *models.Scene
+	var files []*models.SceneFile
 	var postCommitFunc func()
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
 		qb := repo.Scene()
@@ -415,6 +417,11 @@ func (r *mutationResolver) SceneDestroy(ctx context.Context, input models.SceneD
 			return fmt.Errorf("scene with id %d not found", sceneID)
 		}
 
+		files, err = qb.GetFiles(sceneID)
+		if err != nil {
+			return err
+		}
+
 		postCommitFunc, err = manager.DestroyScene(scene, repo)
 		return err
 	}); err != nil {
@@ -427,13 +434,15 @@ func (r *mutationResolver) SceneDestroy(ctx context.Context, input models.SceneD
 	// if delete generated is true, then delete the generated files
 	// for the scene
 	if input.DeleteGenerated != nil && *input.DeleteGenerated {
-		manager.DeleteGeneratedSceneFiles(scene, config.GetInstance().GetVideoFileNamingAlgorithm())
+		fileNamingAlgo := config.GetInstance().GetVideoFileNamingAlgorithm()
+		manager.DeleteGeneratedSceneFiles(scene, fileNamingAlgo)
+		manager.DeleteGeneratedSceneFileFiles(scene, files, fileNamingAlgo)
 	}
 
 	// if delete file is true, then delete the file as well
 	// if it fails, just log a message
 	if input.DeleteFile != nil && *input.DeleteFile {
-		manager.DeleteSceneFile(scene)
+		manager.DeleteSceneFile(scene, files)
 	}
 
 	// call post hook after performing the other actions
@@ -444,6 +453,7 @@ func (r *mutationResolver) SceneDestroy(ctx context.Context, input models.SceneD
 
 func (r *mutationResolver) ScenesDestroy(ctx context.Context, input models.ScenesDestroyInput) (bool, error) {
 	var scenes []*models.Scene
+	sceneFiles := make(map[int][]*models.SceneFile)
 	var postCommitFuncs []func()
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
 		qb := repo.Scene()
@@ -458,6 +468,12 @@ func (r *mutationResolver) ScenesDestroy(ctx context.Context, input models.Scene
 			if scene != nil {
 				scenes = append(scenes, scene)
 			}
+
+			sceneFiles[sceneID], err = qb.GetFiles(sceneID)
+			if err != nil {
+				return err
+			}
+
 			f, err := manager.DestroyScene(scene, repo)
 			if err != nil {
 				return err
@@ -481,12 +497,13 @@ func (r *mutationResolver) ScenesDestroy(ctx context.Context, input models.Scene
 		// for the scene
 		if input.DeleteGenerated != nil && *input.DeleteGenerated {
 			manager.DeleteGeneratedSceneFiles(scene, fileNamingAlgo)
+			manager.DeleteGeneratedSceneFileFiles(scene, sceneFiles[scene.ID], fileNamingAlgo)
 		}
 
 		// if delete file is true, then delete the file as well
 		// if it fails, just log a message
 		if input.DeleteFile != nil && *input.DeleteFile {
-			manager.DeleteSceneFile(scene)
+			manager.DeleteSceneFile(scene, sceneFiles[scene.ID])
 		}
 
 		// call post hook after performing the other actions
@@ -496,6 +513,107 @@ func (r *mutationResolver) ScenesDestroy(ctx context.Context, input models.Scene
 	return true, nil
 }
 
+func (r *mutationResolver) ScenesMerge(ctx context.Context, input models.ScenesMergeInput) (*models.Scene, error) {
+	source, err := utils.StringSliceToIntSlice(input.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	destination, err := strconv.Atoi(input.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(source) == 0 {
+		return nil, nil
+	}
+
+	deleteFiles := input.DeleteFiles != nil && *input.DeleteFiles
+	fileNamingAlgo := config.GetInstance().GetVideoFileNamingAlgorithm()
+
+	var destHash string
+	var sourceScenes []*models.Scene
+	sceneFiles := make(map[int][]*models.SceneFile)
+	var postCommitFuncs []func()
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Scene()
+
+		dest, err := qb.Find(destination)
+		if err != nil {
+			return err
+		}
+
+		if dest == nil {
+			return fmt.Errorf("scene with id %d not found", destination)
+		}
+
+		destHash = dest.GetHash(fileNamingAlgo)
+
+		for _, id := range source {
+			s, err := qb.Find(id)
+			if err != nil {
+				return err
+			}
+
+			if s == nil {
+				return fmt.Errorf("scene with id %d not found", id)
+			}
+
+			sourceScenes = append(sourceScenes, s)
+
+			if deleteFiles {
+				sceneFiles[id], err = qb.GetFiles(id)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := scene.Merge(qb, repo.SceneMarker(), sourceScenes, dest, !deleteFiles); err != nil {
+			return err
+		}
+
+		for _, s := range sourceScenes {
+			f, err := manager.DestroyScene(s, repo)
+			if err != nil {
+				return err
+			}
+
+			postCommitFuncs = append(postCommitFuncs, f)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, f := range postCommitFuncs {
+		f()
+	}
+
+	for _, s := range sourceScenes {
+		// if delete files is true, then delete the source files
+		// if it fails, just log a message
+		if deleteFiles {
+			manager.DeleteSceneFile(s, sceneFiles[s.ID])
+
+			// the generated files are no longer needed, unless they are
+			// shared with the destination scene
+			if s.GetHash(fileNamingAlgo) != destHash {
+				manager.DeleteGeneratedSceneFiles(s, fileNamingAlgo)
+			}
+			manager.DeleteGeneratedSceneFileFiles(s, sceneFiles[s.ID], fileNamingAlgo)
+		}
+
+		r.hookExecutor.ExecutePostHooks(ctx, s.ID, plugin.SceneDestroyPost, input, nil)
+	}
+
+	// call post hook after performing the other actions
+	r.hookExecutor.ExecutePostHooks(ctx, destination, plugin.SceneUpdatePost, input, nil)
+
+	return r.getScene(ctx, destination)
+}
+
 func (r *mutationResolver) getSceneMarker(ctx context.Context, id int) (ret *models.SceneMarker, err error) {
 	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
 		ret, err = repo.SceneMarker().Find(id)
@@ -518,10 +636,16 @@ func (r *mutationResolver) SceneMarkerCreate(ctx context.Context, input models.S
 		return nil, err
 	}
 
+	endSeconds, err := getMarkerEndSeconds(input.Seconds, input.EndSeconds)
+	if err != nil {
+		return nil, err
+	}
+
 	currentTime := time.Now()
 	newSceneMarker := models.SceneMarker{
 		Title:        input.Title,
 		Seconds:      input.Seconds,
+		EndSeconds:   endSeconds,
 		PrimaryTagID: primaryTagID,
 		SceneID:      sql.NullInt64{Int64: int64(sceneID), Valid: sceneID != 0},
 		CreatedAt:    models.SQLiteTimestamp{Timestamp: currentTime},
@@ -559,10 +683,16 @@ func (r *mutationResolver) SceneMarkerUpdate(ctx context.Context, input models.S
 		return nil, err
 	}
 
+	endSeconds, err := getMarkerEndSeconds(input.Seconds, input.EndSeconds)
+	if err != nil {
+		return nil, err
+	}
+
 	updatedSceneMarker := models.SceneMarker{
 		ID:           sceneMarkerID,
 		Title:        input.Title,
 		Seconds:      input.Seconds,
+		EndSeconds:   endSeconds,
 		SceneID:      sql.NullInt64{Int64: int64(sceneID), Valid: sceneID != 0},
 		PrimaryTagID: primaryTagID,
 		UpdatedAt:    models.SQLiteTimestamp{Timestamp: time.Now()},
@@ -585,6 +715,20 @@ func (r *mutationResolver) SceneMarkerUpdate(ctx context.Context, input models.S
 	return r.getSceneMarker(ctx, ret.ID)
 }
 
+// getMarkerEndSeconds returns the end time of a marker from the input. The
+// end time must be after the start of the marker.
+func getMarkerEndSeconds(seconds float64, endSeconds *float64) (sql.NullFloat64, error) {
+	if endSeconds == nil {
+		return sql.NullFloat64{}, nil
+	}
+
+	if *endSeconds <= seconds {
+		return sql.NullFloat64{}, fmt.Errorf("end_seconds (%v) must be greater than seconds (%v)", *endSeconds, seconds)
+	}
+
+	return sql.NullFloat64{Float64: *endSeconds, Valid: true}, nil
+}
+
 func (r *mutationResolver) SceneMarkerDestroy(ctx context.Context, id string) (bool, error) {
 	markerID, err := strconv.Atoi(id)
 	if err != nil {
@@ -663,10 +807,9 @@ func (r *mutationResolver) changeMarker(ctx context.Context, changeType int, cha
 		return nil, err
 	}
 
-	// remove the marker preview if the timestamp was changed
-	if scene != nil && existingMarker != nil && existingMarker.Seconds != changedMarker.Seconds {
-		seconds := int(existingMarker.Seconds)
-		manager.DeleteSceneMarkerFiles(scene, seconds, config.GetInstance().GetVideoFileNamingAlgorithm())
+	// remove the marker preview if the timestamp or end time was changed
+	if scene != nil && existingMarker != nil && (existingMarker.Seconds != changedMarker.Seconds || existingMarker.EndSeconds != changedMarker.EndSeconds) {
+		manager.DeleteSceneMarkerFiles(scene, existingMarker, config.GetInstance().GetVideoFileNamingAlgorithm())
 	}
 
 	return sceneMarker, nil
@@ -726,6 +869,136 @@ func (r *mutationResolver) SceneResetO(ctx context.Context, id string) (ret int,
 	return ret, nil
 }
 
+func (r *mutationResolver) SceneSaveActivity(ctx context.Context, id string, resumeTime *float64, playDuration *float64) (ret bool, err error) {
+	sceneID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Scene()
+
+		return qb.SaveActivity(sceneID, resumeTime, playDuration)
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) SceneIncrementPlayCount(ctx context.Context, id string) (ret int, err error) {
+	sceneID, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Scene()
+
+		ret, err = qb.IncrementPlayCount(sceneID)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) SceneSetPrimaryFile(ctx context.Context, id string, fileID string) (*models.Scene, error) {
+	sceneID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+
+	fileIDInt, err := strconv.Atoi(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	var oldScene *models.Scene
+	var newScene *models.Scene
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Scene()
+
+		oldScene, err = qb.Find(sceneID)
+		if err != nil {
+			return err
+		}
+
+		if oldScene == nil {
+			return fmt.Errorf("scene with id %d not found", sceneID)
+		}
+
+		if err := qb.SetPrimaryFile(sceneID, fileIDInt); err != nil {
+			return err
+		}
+
+		newScene, err = qb.Find(sceneID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	// keep the generated files for the scene
+	fileNamingAlgo := config.GetInstance().GetVideoFileNamingAlgorithm()
+	oldHash := oldScene.GetHash(fileNamingAlgo)
+	newHash := newScene.GetHash(fileNamingAlgo)
+	if oldHash != "" && newHash != "" && oldHash != newHash {
+		manager.MigrateHash(oldHash, newHash)
+	}
+
+	r.hookExecutor.ExecutePostHooks(ctx, sceneID, plugin.SceneUpdatePost, nil, nil)
+	return r.getScene(ctx, sceneID)
+}
+
+func (r *mutationResolver) SceneAssignFile(ctx context.Context, fileID string, sceneID string) (bool, error) {
+	fileIDInt, err := strconv.Atoi(fileID)
+	if err != nil {
+		return false, err
+	}
+
+	sceneIDInt, err := strconv.Atoi(sceneID)
+	if err != nil {
+		return false, err
+	}
+
+	var oldSceneID int
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Scene()
+
+		file, err := qb.FindFile(fileIDInt)
+		if err != nil {
+			return err
+		}
+
+		if file == nil {
+			return fmt.Errorf("file with id %d not found", fileIDInt)
+		}
+
+		scene, err := qb.Find(sceneIDInt)
+		if err != nil {
+			return err
+		}
+
+		if scene == nil {
+			return fmt.Errorf("scene with id %d not found", sceneIDInt)
+		}
+
+		oldSceneID = file.SceneID
+		file.SceneID = sceneIDInt
+		file.UpdatedAt = models.SQLiteTimestamp{Timestamp: time.Now()}
+		_, err = qb.UpdateFile(*file)
+		return err
+	}); err != nil {
+		return false, err
+	}
+
+	r.hookExecutor.ExecutePostHooks(ctx, oldSceneID, plugin.SceneUpdatePost, nil, nil)
+	r.hookExecutor.ExecutePostHooks(ctx, sceneIDInt, plugin.SceneUpdatePost, nil, nil)
+
+	return true, nil
+}
+
 func (r *mutationResolver) SceneGenerateScreenshot(ctx context.Context, id string, at *float64) (string, error) {
 	if at != nil {
 		manager.GetInstance().GenerateScreenshot(ctx, id, *at)
@@ -70,6 +70,8 @@ func makeConfigGeneralResult() *models.ConfigGeneralResult {
 		PreviewPreset:              config.GetPreviewPreset(),
 		MaxTranscodeSize:           &maxTranscodeSize,
 		MaxStreamingTranscodeSize:  &maxStreamingTranscodeSize,
+		MaxConcurrentTranscodes:    config.GetMaxConcurrentTranscodes(),
+		TranscodeQueueTimeout:      config.GetTranscodeQueueTimeout(),
 		APIKey:                     config.GetAPIKey(),
 		Username:                   config.GetUsername(),
 		Password:                   config.GetPasswordHash(),
@@ -80,8 +82,16 @@ func makeConfigGeneralResult() *models.ConfigGeneralResult {
 		LogAccess:                  config.GetLogAccess(),
 		VideoExtensions:            config.GetVideoExtensions(),
 		ImageExtensions:            config.GetImageExtensions(),
+		ImageClipExtensions:        config.GetImageClipExtensions(),
 		GalleryExtensions:          config.GetGalleryExtensions(),
 		CreateGalleriesFromFolders: config.GetCreateGalleriesFromFolders(),
+		AutoRotateImages:           config.GetAutoRotateImages(),
+		ChapterTagMappings:         config.GetChapterTagMappings(),
+		ChapterDefaultTag:          config.GetChapterDefaultTag(),
+		WatchStashPaths:            config.GetWatchStashPaths(),
+		WatchDebounceDelay:         config.GetWatchDebounceDelay(),
+		WatchUsePolling:            config.GetWatchUsePolling(),
+		WatchPollInterval:          config.GetWatchPollInterval(),
 		Excludes:                   config.GetExcludes(),
 		ImageExcludes:              config.GetImageExcludes(),
 		ScraperUserAgent:           &scraperUserAgent,
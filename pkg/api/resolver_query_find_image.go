@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
 )
 
 func (r *queryResolver) FindImage(ctx context.Context, id *string, checksum *string) (*models.Image, error) {
@@ -56,3 +57,44 @@ func (r *queryResolver) FindImages(ctx context.Context, imageFilter *models.Imag
 
 	return ret, nil
 }
+
+func (r *queryResolver) FindDuplicateImages(ctx context.Context, distance *int) (ret []*models.DuplicateImageGroup, err error) {
+	dist := 0
+	if distance != nil {
+		dist = *distance
+	}
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		qb := repo.Image()
+		duplicates, err := qb.FindDuplicates(dist)
+		if err != nil {
+			return err
+		}
+
+		for _, images := range duplicates {
+			var galleryIDs []int
+			for _, i := range images {
+				ids, err := qb.GetGalleryIDs(i.ID)
+				if err != nil {
+					return err
+				}
+				galleryIDs = utils.IntAppendUniques(galleryIDs, ids)
+			}
+
+			galleries, err := repo.Gallery().FindMany(galleryIDs)
+			if err != nil {
+				return err
+			}
+
+			ret = append(ret, &models.DuplicateImageGroup{
+				Images:    images,
+				Galleries: galleries,
+			})
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -169,3 +169,27 @@ func (r *queryResolver) FindDuplicateScenes(ctx context.Context, distance *int)
 
 	return ret, nil
 }
+
+const (
+	defaultPartialDuplicateDistance = 4
+	defaultPartialDuplicateOverlap  = 0.5
+)
+
+func (r *queryResolver) FindPartialDuplicateScenes(ctx context.Context, distance *int, minOverlap *float64) (ret []*models.SceneOverlap, err error) {
+	dist := defaultPartialDuplicateDistance
+	if distance != nil {
+		dist = *distance
+	}
+	overlap := defaultPartialDuplicateOverlap
+	if minOverlap != nil {
+		overlap = *minOverlap
+	}
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.Scene().FindOverlaps(dist, overlap)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
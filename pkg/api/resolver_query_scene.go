@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
 
 	"github.com/stashapp/stash/pkg/api/urlbuilders"
@@ -11,13 +12,24 @@ import (
 	"github.com/stashapp/stash/pkg/models"
 )
 
-func (r *queryResolver) SceneStreams(ctx context.Context, id *string) ([]*models.SceneStreamEndpoint, error) {
+func (r *queryResolver) SceneStreams(ctx context.Context, id *string, fileID *string) ([]*models.SceneStreamEndpoint, error) {
 	// find the scene
 	var scene *models.Scene
+	var file *models.SceneFile
 	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
 		idInt, _ := strconv.Atoi(*id)
 		var err error
 		scene, err = repo.Scene().Find(idInt)
+		if err != nil || fileID == nil {
+			return err
+		}
+
+		fileIDInt, err := strconv.Atoi(*fileID)
+		if err != nil {
+			return err
+		}
+
+		file, err = repo.Scene().FindFile(fileIDInt)
 		return err
 	}); err != nil {
 		return nil, err
@@ -30,5 +42,13 @@ func (r *queryResolver) SceneStreams(ctx context.Context, id *string) ([]*models
 	baseURL, _ := ctx.Value(BaseURLCtxKey).(string)
 	builder := urlbuilders.NewSceneURLBuilder(baseURL, scene.ID)
 
+	if fileID != nil {
+		if file == nil || file.SceneID != scene.ID {
+			return nil, fmt.Errorf("file with id %s does not belong to scene %d", *fileID, scene.ID)
+		}
+
+		return manager.GetSceneStreamPaths(scene.WithFile(*file), builder.GetFileStreamURL(file.ID), config.GetInstance().GetMaxStreamingTranscodeSize())
+	}
+
 	return manager.GetSceneStreamPaths(scene, builder.GetStreamURL(), config.GetInstance().GetMaxStreamingTranscodeSize())
 }
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/stashapp/stash/pkg/manager"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *queryResolver) StreamSessions(ctx context.Context) ([]*models.StreamSession, error) {
+	sessions := manager.GetInstance().StreamSessions.Sessions()
+
+	ret := []*models.StreamSession{}
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		qb := repo.Scene()
+		for _, s := range sessions {
+			scene, err := qb.Find(s.SceneID)
+			if err != nil {
+				return err
+			}
+
+			// scene may have been deleted while streaming
+			if scene == nil {
+				continue
+			}
+
+			ret = append(ret, &models.StreamSession{
+				ID:         strconv.Itoa(s.ID),
+				Scene:      scene,
+				ClientIP:   s.ClientIP,
+				Codec:      s.Codec,
+				Resolution: s.Resolution,
+				StartTime:  s.StartTime,
+				Uptime:     s.Uptime().Seconds(),
+			})
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/manager"
+	"github.com/stashapp/stash/pkg/manager/config"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/utils"
 )
@@ -24,6 +25,7 @@ func (rs imageRoutes) Routes() chi.Router {
 
 		r.Get("/image", rs.Image)
 		r.Get("/thumbnail", rs.Thumbnail)
+		r.Get("/preview", rs.Preview)
 	})
 
 	return r
@@ -44,10 +46,29 @@ func (rs imageRoutes) Thumbnail(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (rs imageRoutes) Preview(w http.ResponseWriter, r *http.Request) {
+	image := r.Context().Value(imageKey).(*models.Image)
+	filepath := manager.GetInstance().Paths.Generated.GetImageClipPreviewPath(image.Checksum, models.DefaultGthumbWidth)
+
+	// previews are only generated for image clips with more than one frame
+	exists, _ := utils.FileExists(filepath)
+	if !exists {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	http.ServeFile(w, r, filepath)
+}
+
 func (rs imageRoutes) Image(w http.ResponseWriter, r *http.Request) {
 	i := r.Context().Value(imageKey).(*models.Image)
 
-	// if image is in a zip file, we need to serve it specifically
+	if config.GetInstance().GetAutoRotateImages() {
+		image.ServeOriented(w, r, i)
+		return
+	}
+
+	// if image is in an archive file, we need to serve it specifically
 	image.Serve(w, r, i.Path)
 }
 
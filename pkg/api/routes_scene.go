@@ -1,8 +1,12 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"net"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
 
@@ -12,6 +16,7 @@ import (
 	"github.com/stashapp/stash/pkg/manager"
 	"github.com/stashapp/stash/pkg/manager/config"
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/scene"
 	"github.com/stashapp/stash/pkg/utils"
 )
 
@@ -27,18 +32,20 @@ func (rs sceneRoutes) Routes() chi.Router {
 		r.Use(SceneCtx)
 
 		// streaming endpoints
-		r.Get("/stream", rs.StreamDirect)
-		r.Get("/stream.mkv", rs.StreamMKV)
-		r.Get("/stream.webm", rs.StreamWebM)
-		r.Get("/stream.m3u8", rs.StreamHLS)
-		r.Get("/stream.ts", rs.StreamTS)
-		r.Get("/stream.mp4", rs.StreamMp4)
+		rs.streamRoutes(r)
+
+		// streaming endpoints for additional files of the scene
+		r.Route("/file/{fileId}", func(r chi.Router) {
+			r.Use(SceneFileCtx)
+			rs.streamRoutes(r)
+		})
 
 		r.Get("/screenshot", rs.Screenshot)
 		r.Get("/preview", rs.Preview)
 		r.Get("/webp", rs.Webp)
 		r.Get("/vtt/chapter", rs.ChapterVtt)
 		r.Get("/funscript", rs.Funscript)
+		r.Get("/caption", rs.Caption)
 
 		r.Get("/scene_marker/{sceneMarkerId}/stream", rs.SceneMarkerStream)
 		r.Get("/scene_marker/{sceneMarkerId}/preview", rs.SceneMarkerPreview)
@@ -49,6 +56,19 @@ func (rs sceneRoutes) Routes() chi.Router {
 	return r
 }
 
+func (rs sceneRoutes) streamRoutes(r chi.Router) {
+	r.Get("/stream", rs.StreamDirect)
+	r.Get("/stream.mkv", rs.StreamMKV)
+	r.Get("/stream.webm", rs.StreamWebM)
+	r.Get("/stream.m3u8", rs.StreamHLS)
+	r.Get("/stream.ts", rs.StreamTS)
+	r.Get("/hls/{segment}.ts", rs.StreamHLSSegment)
+	r.Get("/stream.mpd", rs.StreamDASH)
+	r.Get("/dash/init.mp4", rs.StreamDASHInit)
+	r.Get("/dash/{segment}.m4s", rs.StreamDASHSegment)
+	r.Get("/stream.mp4", rs.StreamMp4)
+}
+
 // region Handlers
 
 func getSceneFileContainer(scene *models.Scene) ffmpeg.Container {
@@ -109,13 +129,32 @@ func (rs sceneRoutes) StreamHLS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logger.Debug("Returning HLS playlist")
-
 	// getting the playlist manifest only
 	w.Header().Set("Content-Type", ffmpeg.MimeHLS)
 	var str strings.Builder
 
-	ffmpeg.WriteHLSPlaylist(*videoFile, r.URL.String(), &str)
+	query := r.URL.Query()
+	if query.Get("resolution") == "" {
+		// no resolution requested - return the master playlist of the
+		// allowed renditions
+		logger.Debug("Returning HLS master playlist")
+
+		var renditions []ffmpeg.HLSRendition
+		for _, res := range manager.GetSceneStreamResolutions(scene, config.GetInstance().GetMaxStreamingTranscodeSize()) {
+			query.Set("resolution", res.String())
+			renditions = append(renditions, ffmpeg.GetHLSRendition(*videoFile, res, "stream.m3u8?"+query.Encode()))
+		}
+
+		ffmpeg.WriteHLSMasterPlaylist(renditions, &str)
+	} else {
+		logger.Debug("Returning HLS playlist")
+
+		// segment URLs are relative to the playlist, and keep the query
+		// parameters of the playlist request
+		ffmpeg.WriteHLSPlaylist(*videoFile, func(segment int) string {
+			return "hls/" + strconv.Itoa(segment) + ".ts?" + r.URL.RawQuery
+		}, &str)
+	}
 
 	requestByteRange := utils.CreateByteRange(r.Header.Get("Range"))
 	if requestByteRange.RawString != "" {
@@ -129,6 +168,202 @@ func (rs sceneRoutes) StreamHLS(w http.ResponseWriter, r *http.Request) {
 	w.Write(ret)
 }
 
+func (rs sceneRoutes) StreamHLSSegment(w http.ResponseWriter, r *http.Request) {
+	segment, err := strconv.Atoi(chi.URLParam(r, "segment"))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	streamManager, encoder, options := rs.getSegmentOptions(w, r, ffmpeg.CodecHLS, ffmpeg.SegmentFormatMpegts)
+	if streamManager == nil {
+		return
+	}
+
+	streamManager.ServeSegment(w, r, encoder, *options, segment)
+}
+
+// getDASHCodec returns the DASH segment codec with the provided name, which
+// defaults to H264. Returns nil if the codec is not supported.
+func getDASHCodec(name string) *ffmpeg.Codec {
+	switch name {
+	case "", ffmpeg.H264:
+		return &ffmpeg.CodecH264
+	case ffmpeg.Vp9:
+		return &ffmpeg.CodecVP9
+	}
+
+	return nil
+}
+
+func (rs sceneRoutes) StreamDASH(w http.ResponseWriter, r *http.Request) {
+	scene := r.Context().Value(sceneKey).(*models.Scene)
+
+	videoFile, err := ffmpeg.NewVideoFile(manager.GetInstance().FFProbePath, scene.Path, false)
+	if err != nil {
+		logger.Errorf("[stream] error reading video file: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Debug("Returning DASH manifest")
+
+	resolutions := manager.GetSceneStreamResolutions(scene, config.GetInstance().GetMaxStreamingTranscodeSize())
+	// audio is removed if the audio codec is not supported
+	hasAudio := scene.AudioCodec.Valid && ffmpeg.AudioCodec(scene.AudioCodec.String) != ffmpeg.MissingUnsupported
+
+	// an adaptation set per codec, so that the client can choose the codec it
+	// supports. Segment URLs are relative to the manifest, and keep the
+	// query parameters of the manifest request
+	var adaptationSets []ffmpeg.DASHAdaptationSet
+	for _, codecName := range []string{ffmpeg.H264, ffmpeg.Vp9} {
+		query := r.URL.Query()
+		query.Set("codec", codecName)
+		codec := getDASHCodec(codecName)
+
+		var as ffmpeg.DASHAdaptationSet
+		for _, res := range resolutions {
+			query.Set("resolution", res.String())
+			encoded := query.Encode()
+
+			as.Representations = append(as.Representations, ffmpeg.GetDASHRepresentation(*videoFile, *codec, res, hasAudio, "dash/init.mp4?"+encoded, "dash/$Number$.m4s?"+encoded))
+		}
+
+		adaptationSets = append(adaptationSets, as)
+	}
+
+	var buf bytes.Buffer
+	if err := ffmpeg.WriteDASHManifest(*videoFile, adaptationSets, &buf); err != nil {
+		logger.Errorf("[stream] error writing DASH manifest: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ffmpeg.MimeDASH)
+	w.Write(buf.Bytes())
+}
+
+func (rs sceneRoutes) StreamDASHInit(w http.ResponseWriter, r *http.Request) {
+	codec := getDASHCodec(r.URL.Query().Get("codec"))
+	if codec == nil {
+		http.Error(w, "unsupported codec", http.StatusBadRequest)
+		return
+	}
+
+	streamManager, encoder, options := rs.getSegmentOptions(w, r, *codec, ffmpeg.SegmentFormatFMP4)
+	if streamManager == nil {
+		return
+	}
+
+	streamManager.ServeInitSegment(w, r, encoder, *options)
+}
+
+func (rs sceneRoutes) StreamDASHSegment(w http.ResponseWriter, r *http.Request) {
+	segment, err := strconv.Atoi(chi.URLParam(r, "segment"))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	codec := getDASHCodec(r.URL.Query().Get("codec"))
+	if codec == nil {
+		http.Error(w, "unsupported codec", http.StatusBadRequest)
+		return
+	}
+
+	streamManager, encoder, options := rs.getSegmentOptions(w, r, *codec, ffmpeg.SegmentFormatFMP4)
+	if streamManager == nil {
+		return
+	}
+
+	streamManager.ServeSegment(w, r, encoder, *options, segment)
+}
+
+// getSegmentOptions returns the stream manager, encoder and options of a
+// segmented transcode of the scene in the request context. Writes an error
+// to the response and returns a nil stream manager if the options cannot be
+// determined.
+func (rs sceneRoutes) getSegmentOptions(w http.ResponseWriter, r *http.Request, codec ffmpeg.Codec, format ffmpeg.SegmentFormat) (*ffmpeg.SegmentManager, ffmpeg.Encoder, *ffmpeg.SegmentOptions) {
+	scene := r.Context().Value(sceneKey).(*models.Scene)
+
+	streamManager := manager.GetInstance().StreamManager
+	if streamManager == nil {
+		http.Error(w, "stream manager not initialized", http.StatusServiceUnavailable)
+		return nil, ffmpeg.Encoder{}, nil
+	}
+
+	videoFile, err := ffmpeg.NewVideoFile(manager.GetInstance().FFProbePath, scene.Path, false)
+	if err != nil {
+		logger.Errorf("[stream] error reading video file: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, ffmpeg.Encoder{}, nil
+	}
+
+	audioCodec := ffmpeg.MissingUnsupported
+	if scene.AudioCodec.Valid {
+		audioCodec = ffmpeg.AudioCodec(scene.AudioCodec.String)
+	}
+
+	audioStreamIndex, err := getAudioStreamIndex(r, videoFile)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, ffmpeg.Encoder{}, nil
+	}
+
+	options := ffmpeg.SegmentOptions{
+		ProbeResult:      *videoFile,
+		Codec:            codec,
+		Format:           format,
+		MaxTranscodeSize: config.GetInstance().GetMaxStreamingTranscodeSize(),
+		// ffmpeg fails if it tries to transcode a non supported audio codec
+		VideoOnly:        audioCodec == ffmpeg.MissingUnsupported && audioStreamIndex == nil,
+		AudioStreamIndex: audioStreamIndex,
+	}
+	if requestedSize := r.URL.Query().Get("resolution"); requestedSize != "" {
+		options.MaxTranscodeSize = models.StreamingResolutionEnum(requestedSize)
+	}
+	options.Acquire = getAcquireTranscodeFunc(r, scene, codec, options.MaxTranscodeSize)
+
+	encoder := ffmpeg.NewEncoder(manager.GetInstance().FFMPEGPath)
+	return streamManager, encoder, &options
+}
+
+// getAudioStreamIndex returns the index of the audio stream requested by the
+// audioTrack query parameter. Returns nil if no audio stream was requested.
+func getAudioStreamIndex(r *http.Request, videoFile *ffmpeg.VideoFile) (*int, error) {
+	audioTrack := r.URL.Query().Get("audioTrack")
+	if audioTrack == "" {
+		return nil, nil
+	}
+
+	index, err := strconv.Atoi(audioTrack)
+	if err != nil || videoFile.GetAudioStreamByIndex(index) == nil {
+		return nil, fmt.Errorf("invalid audio track: %s", audioTrack)
+	}
+
+	return &index, nil
+}
+
+// getAcquireTranscodeFunc returns a function that registers a transcode of
+// the scene for the request with the stream session manager.
+func getAcquireTranscodeFunc(r *http.Request, scene *models.Scene, codec ffmpeg.Codec, resolution models.StreamingResolutionEnum) ffmpeg.AcquireTranscodeFunc {
+	clientIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		clientIP = r.RemoteAddr
+	}
+
+	session := manager.StreamSession{
+		SceneID:    scene.ID,
+		ClientIP:   clientIP,
+		Codec:      codec.Codec,
+		Resolution: resolution,
+	}
+
+	return func(ctx context.Context, kill func(), stop func()) (func(), error) {
+		return manager.GetInstance().StreamSessions.Start(ctx, session, kill, stop)
+	}
+}
+
 func (rs sceneRoutes) StreamTS(w http.ResponseWriter, r *http.Request) {
 	rs.streamTranscode(w, r, ffmpeg.CodecHLS)
 }
@@ -157,13 +392,37 @@ func (rs sceneRoutes) streamTranscode(w http.ResponseWriter, r *http.Request, vi
 		audioCodec = ffmpeg.AudioCodec(scene.AudioCodec.String)
 	}
 
+	audioStreamIndex, err := getAudioStreamIndex(r, videoFile)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	options := ffmpeg.GetTranscodeStreamOptions(*videoFile, videoCodec, audioCodec)
+	if audioStreamIndex != nil {
+		options.AudioStreamIndex = audioStreamIndex
+		options.VideoOnly = false
+	}
 	options.StartTime = startTime
 	options.MaxTranscodeSize = config.GetInstance().GetMaxStreamingTranscodeSize()
 	if requestedSize != "" {
 		options.MaxTranscodeSize = models.StreamingResolutionEnum(requestedSize)
 	}
 
+	// killing or replacing the session cancels the request context, which
+	// stops the stream
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	r = r.WithContext(ctx)
+
+	release, err := getAcquireTranscodeFunc(r, scene, videoCodec, options.MaxTranscodeSize)(ctx, cancel, cancel)
+	if err != nil {
+		logger.Warnf("[stream] could not start transcode: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer release()
+
 	encoder := ffmpeg.NewEncoder(manager.GetInstance().FFMPEGPath)
 	stream, err = encoder.GetTranscodeStream(options)
 
@@ -230,6 +489,29 @@ func (rs sceneRoutes) getChapterVttTitle(ctx context.Context, marker *models.Sce
 	return ret
 }
 
+// getChapterVttEnd returns the end time of the chapter cue of the marker at
+// the provided index. Markers without an end time end at the start of the
+// next marker, or at the end of the scene if it is the last marker. Markers
+// are ordered by start time.
+func getChapterVttEnd(scene *models.Scene, sceneMarkers []*models.SceneMarker, index int) float64 {
+	marker := sceneMarkers[index]
+	if marker.EndSeconds.Valid {
+		return marker.EndSeconds.Float64
+	}
+
+	for _, next := range sceneMarkers[index+1:] {
+		if next.Seconds > marker.Seconds {
+			return next.Seconds
+		}
+	}
+
+	if scene.Duration.Valid && scene.Duration.Float64 > marker.Seconds {
+		return scene.Duration.Float64
+	}
+
+	return marker.Seconds
+}
+
 func (rs sceneRoutes) ChapterVtt(w http.ResponseWriter, r *http.Request) {
 	scene := r.Context().Value(sceneKey).(*models.Scene)
 	var sceneMarkers []*models.SceneMarker
@@ -245,8 +527,9 @@ func (rs sceneRoutes) ChapterVtt(w http.ResponseWriter, r *http.Request) {
 	vttLines := []string{"WEBVTT", ""}
 	for i, marker := range sceneMarkers {
 		vttLines = append(vttLines, strconv.Itoa(i+1))
-		time := utils.GetVTTTime(marker.Seconds)
-		vttLines = append(vttLines, time+" --> "+time)
+		start := utils.GetVTTTime(marker.Seconds)
+		end := utils.GetVTTTime(getChapterVttEnd(scene, sceneMarkers, i))
+		vttLines = append(vttLines, start+" --> "+end)
 		vttLines = append(vttLines, rs.getChapterVttTitle(r.Context(), marker))
 		vttLines = append(vttLines, "")
 	}
@@ -262,6 +545,85 @@ func (rs sceneRoutes) Funscript(w http.ResponseWriter, r *http.Request) {
 	utils.ServeFileNoCache(w, r, funscript)
 }
 
+// Caption serves the requested caption of the scene as WebVTT. Embedded
+// captions are requested by stream index, sidecar captions by language and
+// type.
+func (rs sceneRoutes) Caption(w http.ResponseWriter, r *http.Request) {
+	s := r.Context().Value(sceneKey).(*models.Scene)
+
+	var captions []*models.SceneCaption
+	if err := rs.txnManager.WithReadTxn(r.Context(), func(repo models.ReaderRepository) error {
+		var err error
+		captions, err = repo.Scene().GetCaptions(s.ID)
+		return err
+	}); err != nil {
+		logger.Warnf("Error when getting captions for scene: %s", err.Error())
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	query := r.URL.Query()
+	stream := query.Get("stream")
+	lang := query.Get("lang")
+	captionType := query.Get("type")
+
+	var caption *models.SceneCaption
+	for _, c := range captions {
+		if stream != "" {
+			if c.IsEmbedded() && strconv.FormatInt(c.StreamIndex.Int64, 10) == stream {
+				caption = c
+				break
+			}
+		} else if !c.IsEmbedded() && c.LanguageCode == lang && c.CaptionType == captionType {
+			caption = c
+			break
+		}
+	}
+
+	if caption == nil {
+		http.Error(w, http.StatusText(404), 404)
+		return
+	}
+
+	var vtt []byte
+	if caption.IsEmbedded() {
+		videoFile, err := ffmpeg.NewVideoFile(manager.GetInstance().FFProbePath, s.Path, false)
+		if err != nil {
+			logger.Errorf("[caption] error reading video file: %s", err.Error())
+			http.Error(w, http.StatusText(500), 500)
+			return
+		}
+
+		encoder := ffmpeg.NewEncoder(manager.GetInstance().FFMPEGPath)
+		out, err := encoder.ExtractCaption(*videoFile, int(caption.StreamIndex.Int64))
+		if err != nil {
+			logger.Errorf("[caption] error extracting caption: %s", err.Error())
+			http.Error(w, http.StatusText(500), 500)
+			return
+		}
+		vtt = []byte(out)
+	} else {
+		f, err := os.Open(caption.Path(s.Path))
+		if err != nil {
+			logger.Warnf("[caption] error opening caption file: %s", err.Error())
+			http.Error(w, http.StatusText(404), 404)
+			return
+		}
+		defer f.Close()
+
+		vtt, err = scene.CaptionToVTT(f, caption.CaptionType)
+		if err != nil {
+			logger.Errorf("[caption] error converting caption: %s", err.Error())
+			http.Error(w, http.StatusText(500), 500)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/vtt")
+	w.Header().Add("Cache-Control", "no-cache")
+	_, _ = w.Write(vtt)
+}
+
 func (rs sceneRoutes) VttThumbs(w http.ResponseWriter, r *http.Request) {
 	scene := r.Context().Value(sceneKey).(*models.Scene)
 	w.Header().Set("Content-Type", "text/vtt")
@@ -289,7 +651,7 @@ func (rs sceneRoutes) SceneMarkerStream(w http.ResponseWriter, r *http.Request)
 		http.Error(w, http.StatusText(500), 500)
 		return
 	}
-	filepath := manager.GetInstance().Paths.SceneMarkers.GetStreamPath(scene.GetHash(config.GetInstance().GetVideoFileNamingAlgorithm()), int(sceneMarker.Seconds))
+	filepath := manager.GetInstance().Paths.SceneMarkers.GetStreamPath(scene.GetHash(config.GetInstance().GetVideoFileNamingAlgorithm()), int(sceneMarker.Seconds), sceneMarker.GetEndSeconds())
 	http.ServeFile(w, r, filepath)
 }
 
@@ -306,7 +668,7 @@ func (rs sceneRoutes) SceneMarkerPreview(w http.ResponseWriter, r *http.Request)
 		http.Error(w, http.StatusText(500), 500)
 		return
 	}
-	filepath := manager.GetInstance().Paths.SceneMarkers.GetStreamPreviewImagePath(scene.GetHash(config.GetInstance().GetVideoFileNamingAlgorithm()), int(sceneMarker.Seconds))
+	filepath := manager.GetInstance().Paths.SceneMarkers.GetStreamPreviewImagePath(scene.GetHash(config.GetInstance().GetVideoFileNamingAlgorithm()), int(sceneMarker.Seconds), sceneMarker.GetEndSeconds())
 
 	// If the image doesn't exist, send the placeholder
 	exists, _ := utils.FileExists(filepath)
@@ -353,3 +715,26 @@ func SceneCtx(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// SceneFileCtx replaces the primary file details of the scene in the context
+// with those of the additional file in the URL.
+func SceneFileCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scene := r.Context().Value(sceneKey).(*models.Scene)
+		fileID, _ := strconv.Atoi(chi.URLParam(r, "fileId"))
+
+		var file *models.SceneFile
+		manager.GetInstance().TxnManager.WithReadTxn(r.Context(), func(repo models.ReaderRepository) error {
+			file, _ = repo.Scene().FindFile(fileID)
+			return nil
+		})
+
+		if file == nil || file.SceneID != scene.ID {
+			http.Error(w, http.StatusText(404), 404)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), sceneKey, scene.WithFile(*file))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetChapterVttEnd(t *testing.T) {
+	duration := func(v float64) sql.NullFloat64 {
+		return sql.NullFloat64{Float64: v, Valid: true}
+	}
+
+	marker := func(seconds float64, end sql.NullFloat64) *models.SceneMarker {
+		return &models.SceneMarker{Seconds: seconds, EndSeconds: end}
+	}
+
+	tests := []struct {
+		name     string
+		duration sql.NullFloat64
+		markers  []*models.SceneMarker
+		index    int
+		want     float64
+	}{
+		{
+			"end time",
+			duration(100),
+			[]*models.SceneMarker{marker(10, duration(15)), marker(20, sql.NullFloat64{})},
+			0,
+			15,
+		},
+		{
+			"next marker",
+			duration(100),
+			[]*models.SceneMarker{marker(10, sql.NullFloat64{}), marker(20, sql.NullFloat64{})},
+			0,
+			20,
+		},
+		{
+			"skips markers at the same time",
+			duration(100),
+			[]*models.SceneMarker{marker(10, sql.NullFloat64{}), marker(10, sql.NullFloat64{}), marker(30, sql.NullFloat64{})},
+			0,
+			30,
+		},
+		{
+			"last marker ends at scene end",
+			duration(100),
+			[]*models.SceneMarker{marker(10, sql.NullFloat64{}), marker(20, sql.NullFloat64{})},
+			1,
+			100,
+		},
+		{
+			"same time as last marker ends at scene end",
+			duration(100),
+			[]*models.SceneMarker{marker(10, sql.NullFloat64{}), marker(10, sql.NullFloat64{})},
+			0,
+			100,
+		},
+		{
+			"unknown duration",
+			sql.NullFloat64{},
+			[]*models.SceneMarker{marker(10, sql.NullFloat64{}), marker(20, sql.NullFloat64{})},
+			1,
+			20,
+		},
+		{
+			"marker after scene end",
+			duration(15),
+			[]*models.SceneMarker{marker(20, sql.NullFloat64{})},
+			0,
+			20,
+		},
+	}
+
+	for _, tt := range tests {
+		scene := &models.Scene{Duration: tt.duration}
+		assert.Equal(t, tt.want, getChapterVttEnd(scene, tt.markers, tt.index), tt.name)
+	}
+}
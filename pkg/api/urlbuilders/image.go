@@ -26,3 +26,7 @@ func (b ImageURLBuilder) GetImageURL() string {
 func (b ImageURLBuilder) GetThumbnailURL() string {
 	return b.BaseURL + "/image/" + b.ImageID + "/thumbnail?" + b.UpdatedAt
 }
+
+func (b ImageURLBuilder) GetPreviewURL() string {
+	return b.BaseURL + "/image/" + b.ImageID + "/preview?" + b.UpdatedAt
+}
@@ -2,8 +2,11 @@ package urlbuilders
 
 import (
 	"fmt"
+	"net/url"
 	"strconv"
 	"time"
+
+	"github.com/stashapp/stash/pkg/models"
 )
 
 type SceneURLBuilder struct {
@@ -27,6 +30,11 @@ func (b SceneURLBuilder) GetStreamURL() string {
 	return fmt.Sprintf("%s/scene/%s/stream%s", b.BaseURL, b.SceneID, apiKeyParam)
 }
 
+// GetFileStreamURL returns the stream URL of an additional file of the scene.
+func (b SceneURLBuilder) GetFileStreamURL(fileID int) string {
+	return fmt.Sprintf("%s/scene/%s/file/%d/stream", b.BaseURL, b.SceneID, fileID)
+}
+
 func (b SceneURLBuilder) GetStreamPreviewURL() string {
 	return b.BaseURL + "/scene/" + b.SceneID + "/preview"
 }
@@ -59,6 +67,15 @@ func (b SceneURLBuilder) GetSceneMarkerStreamPreviewURL(sceneMarkerID int) strin
 	return b.BaseURL + "/scene/" + b.SceneID + "/scene_marker/" + strconv.Itoa(sceneMarkerID) + "/preview"
 }
 
+// GetCaptionURL returns the URL of the caption, converted to WebVTT.
+func (b SceneURLBuilder) GetCaptionURL(caption models.SceneCaption) string {
+	if caption.IsEmbedded() {
+		return fmt.Sprintf("%s/scene/%s/caption?stream=%d", b.BaseURL, b.SceneID, caption.StreamIndex.Int64)
+	}
+
+	return fmt.Sprintf("%s/scene/%s/caption?lang=%s&type=%s", b.BaseURL, b.SceneID, url.QueryEscape(caption.LanguageCode), url.QueryEscape(caption.CaptionType))
+}
+
 func (b SceneURLBuilder) GetFunscriptURL() string {
 	return b.BaseURL + "/scene/" + b.SceneID + "/funscript"
 }
@@ -0,0 +1,177 @@
+// Package archive provides read access to the files within archive files,
+// such as zip, RAR, 7z and tar files. Formats are registered with Register,
+// and the format of an archive is detected from the contents of the file
+// rather than the file extension, since comic book archives in particular
+// are frequently named with the wrong extension.
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// headerSize is the number of bytes read from the start of a file to detect
+// its format. Tar files are identified by a magic string at offset 257.
+const headerSize = 512
+
+// ErrUnsupported is returned when the format of a file is not supported.
+var ErrUnsupported = errors.New("unsupported archive format")
+
+// File is a file within an archive.
+type File interface {
+	// Name returns the path of the file within the archive.
+	Name() string
+	FileInfo() os.FileInfo
+	// Open returns a reader of the contents of the file. The reader must be
+	// closed before the archive is closed.
+	Open() (io.ReadCloser, error)
+}
+
+// Reader provides access to the files within an archive.
+type Reader interface {
+	// Files returns the files and directories in the archive, in the order
+	// that they are stored.
+	Files() []File
+	Close() error
+}
+
+// Format is a supported archive format.
+type Format struct {
+	Name string
+	// Match returns true if the header is the start of an archive of this
+	// format. The header may be shorter than headerSize for small files.
+	Match func(header []byte) bool
+	// Open opens the archive at the path.
+	Open func(path string) (Reader, error)
+}
+
+var (
+	formatsMutex sync.RWMutex
+	formats      []Format
+)
+
+// Register adds an archive format. Formats are matched in the order that they
+// are registered.
+func Register(f Format) {
+	formatsMutex.Lock()
+	defer formatsMutex.Unlock()
+	formats = append(formats, f)
+}
+
+// Detect returns the format of the archive at the path. Returns
+// ErrUnsupported if the file is not an archive of a registered format.
+func Detect(path string) (*Format, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header := make([]byte, headerSize)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	header = header[:n]
+
+	formatsMutex.RLock()
+	defer formatsMutex.RUnlock()
+
+	for _, format := range formats {
+		if format.Match(header) {
+			ret := format
+			return &ret, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupported, path)
+}
+
+// Open opens the archive at the path, detecting its format. If the archive
+// is held, then the held reader is returned.
+func Open(path string) (Reader, error) {
+	if r := getHeld(path); r != nil {
+		return r, nil
+	}
+
+	format, err := Detect(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return format.Open(path)
+}
+
+// Find returns the file with the name in the archive. Returns nil if the
+// file is not found.
+func Find(r Reader, name string) File {
+	for _, f := range r.Files() {
+		if f.Name() == name {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// fileReadCloser reads a file within an archive, and closes the archive when
+// it is closed.
+type fileReadCloser struct {
+	io.ReadCloser
+	archive Reader
+}
+
+func (f *fileReadCloser) Close() error {
+	err := f.ReadCloser.Close()
+	if err2 := f.archive.Close(); err == nil {
+		err = err2
+	}
+
+	return err
+}
+
+// OpenFile opens the file with the name in the archive at the path. Closing
+// the returned reader closes the archive.
+func OpenFile(path string, name string) (io.ReadCloser, error) {
+	r, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f := Find(r, name)
+	if f == nil {
+		r.Close()
+		return nil, fmt.Errorf("file with name '%s' not found in archive file '%s'", name, path)
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+
+	return &fileReadCloser{
+		ReadCloser: src,
+		archive:    r,
+	}, nil
+}
+
+// Stat returns the file info of the file with the name in the archive at the
+// path.
+func Stat(path string, name string) (os.FileInfo, error) {
+	r, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	f := Find(r, name)
+	if f == nil {
+		return nil, fmt.Errorf("file with name '%s' not found in archive file '%s'", name, path)
+	}
+
+	return f.FileInfo(), nil
+}
@@ -0,0 +1,320 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testFile struct {
+	name     string
+	contents string
+}
+
+var testFiles = []testFile{
+	{"01.jpg", "first"},
+	{"sub/02.jpg", "second"},
+	{"03.png", "third"},
+}
+
+func writeZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, f := range testFiles {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.contents); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func writeTar(w io.Writer) error {
+	tw := tar.NewWriter(w)
+	for _, f := range testFiles {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     f.name,
+			Mode:     0644,
+			Size:     int64(len(f.contents)),
+			Typeflag: tar.TypeReg,
+			Format:   tar.FormatUSTAR,
+		}); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(tw, f.contents); err != nil {
+			return err
+		}
+	}
+
+	return tw.Close()
+}
+
+func writeTarGz(w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	if err := writeTar(gw); err != nil {
+		return err
+	}
+
+	return gw.Close()
+}
+
+func createFile(t *testing.T, dir string, name string, write func(w io.Writer) error) string {
+	fn := filepath.Join(dir, name)
+	f, err := os.Create(fn)
+	if err != nil {
+		t.Fatalf("error creating %s: %s", name, err.Error())
+	}
+	defer f.Close()
+
+	if err := write(f); err != nil {
+		t.Fatalf("error writing %s: %s", name, err.Error())
+	}
+
+	return fn
+}
+
+func writeString(s string) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.WriteString(w, s)
+		return err
+	}
+}
+
+func TestArchives(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-archive-test")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name   string
+		write  func(w io.Writer) error
+		format string
+	}{
+		{"test.cbz", writeZip, ZipFormat},
+		{"test.cbt", writeTar, TarFormat},
+		{"test.tar.gz", writeTarGz, TarGzFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			fn := createFile(t, dir, tt.name, tt.write)
+
+			format, err := Detect(fn)
+			if !assert.Nil(err) {
+				return
+			}
+			assert.Equal(tt.format, format.Name)
+
+			r, err := Open(fn)
+			if !assert.Nil(err) {
+				return
+			}
+			defer r.Close()
+
+			files := r.Files()
+			if !assert.Len(files, len(testFiles)) {
+				return
+			}
+
+			// read the files out of order to ensure that files are found
+			// independently of each other
+			for i := len(files) - 1; i >= 0; i-- {
+				assert.Equal(testFiles[i].name, files[i].Name())
+				assert.Equal(filepath.Base(testFiles[i].name), files[i].FileInfo().Name())
+
+				src, err := files[i].Open()
+				if !assert.Nil(err) {
+					continue
+				}
+				data, err := ioutil.ReadAll(src)
+				src.Close()
+				assert.Nil(err)
+				assert.Equal(testFiles[i].contents, string(data))
+			}
+
+			assert.NotNil(Find(r, "sub/02.jpg"))
+			assert.Nil(Find(r, "missing.jpg"))
+
+			src, err := OpenFile(fn, "03.png")
+			if assert.Nil(err) {
+				data, err := ioutil.ReadAll(src)
+				assert.Nil(err)
+				assert.Equal("third", string(data))
+				assert.Nil(src.Close())
+			}
+
+			_, err = OpenFile(fn, "missing.jpg")
+			assert.NotNil(err)
+
+			info, err := Stat(fn, "sub/02.jpg")
+			if assert.Nil(err) {
+				assert.Equal(int64(len("second")), info.Size())
+			}
+
+			_, err = Stat(fn, "missing.jpg")
+			assert.NotNil(err)
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-archive-test")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name     string
+		contents string
+		format   string
+	}{
+		{"test.cb7", "7z\xbc\xaf\x27\x1c\x00\x04", SevenZipFormat},
+		{"test.cbr", "Rar!\x1a\x07\x00", RARFormat},
+		{"test5.cbr", "Rar!\x1a\x07\x01\x00", RARFormat},
+		// detection ignores the extension
+		{"misnamed.cbz", "Rar!\x1a\x07\x00", RARFormat},
+		{"empty.zip", "PK\x05\x06", ZipFormat},
+	}
+
+	for _, tt := range tests {
+		fn := createFile(t, dir, tt.name, writeString(tt.contents))
+		format, err := Detect(fn)
+		if assert.Nil(t, err, tt.name) {
+			assert.Equal(t, tt.format, format.Name, tt.name)
+		}
+	}
+
+	unsupported := []struct {
+		name     string
+		contents string
+	}{
+		{"empty.cbz", ""},
+		{"text.cbr", "not an archive"},
+		{"rar.cbr", "Rar!"},
+	}
+
+	for _, tt := range unsupported {
+		fn := createFile(t, dir, tt.name, writeString(tt.contents))
+		_, err := Detect(fn)
+		assert.True(t, errors.Is(err, ErrUnsupported), tt.name)
+	}
+
+	_, err = Detect(filepath.Join(dir, "missing.zip"))
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, ErrUnsupported))
+}
+
+func readArchiveFile(f File) (string, error) {
+	src, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	data, err := ioutil.ReadAll(src)
+	return string(data), err
+}
+
+func TestStreamReaderInOrder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-archive-test")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	fn := createFile(t, dir, "test.cbt", writeTar)
+	r, err := Open(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	files := r.Files()
+	data, err := readArchiveFile(files[0])
+	assert.Nil(t, err)
+	assert.Equal(t, testFiles[0].contents, data)
+
+	// files read in order continue from the open stream, so the archive
+	// file is not opened again
+	if err := os.Remove(fn); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i < len(files); i++ {
+		data, err := readArchiveFile(files[i])
+		assert.Nil(t, err)
+		assert.Equal(t, testFiles[i].contents, data)
+	}
+
+	// reading an earlier file restarts the stream
+	_, err = readArchiveFile(files[0])
+	assert.NotNil(t, err)
+}
+
+func TestHold(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-archive-test")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	fn := createFile(t, dir, "test.cbz", writeZip)
+	release, err := Hold(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nestedRelease, err := Hold(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the held archive is read without opening the archive file again
+	if err := os.Remove(fn); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := Stat(fn, "sub/02.jpg")
+	if assert.Nil(t, err) {
+		assert.Equal(t, int64(len("second")), info.Size())
+	}
+
+	src, err := OpenFile(fn, "03.png")
+	if assert.Nil(t, err) {
+		data, err := ioutil.ReadAll(src)
+		assert.Nil(t, err)
+		assert.Equal(t, "third", string(data))
+		assert.Nil(t, src.Close())
+	}
+
+	// closing the reader of a held archive does not close the archive
+	_, err = Stat(fn, "01.jpg")
+	assert.Nil(t, err)
+
+	nestedRelease()
+	// releasing more than once has no effect
+	nestedRelease()
+
+	_, err = Stat(fn, "01.jpg")
+	assert.Nil(t, err)
+
+	release()
+
+	_, err = Stat(fn, "01.jpg")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,85 @@
+package archive
+
+import (
+	"sync"
+)
+
+var (
+	heldMutex sync.Mutex
+	held      = make(map[string]*heldReader)
+)
+
+// heldReader is an archive kept open by Hold.
+type heldReader struct {
+	Reader
+	refs int
+}
+
+// sharedReader is a held archive returned by Open. Closing it does not close
+// the archive, which is closed when it is no longer held.
+type sharedReader struct {
+	Reader
+}
+
+func (r sharedReader) Close() error {
+	return nil
+}
+
+// Hold keeps the archive at the path open until the returned function is
+// called. While the archive is held, Open, OpenFile and Stat share the same
+// reader, so that the files of the archive are not listed again for each
+// file that is read. Holds may be nested.
+func Hold(path string) (release func(), err error) {
+	heldMutex.Lock()
+	defer heldMutex.Unlock()
+
+	r := held[path]
+	if r == nil {
+		format, err := Detect(path)
+		if err != nil {
+			return nil, err
+		}
+
+		reader, err := format.Open(path)
+		if err != nil {
+			return nil, err
+		}
+
+		r = &heldReader{Reader: reader}
+		held[path] = r
+	}
+
+	r.refs++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			unhold(path, r)
+		})
+	}, nil
+}
+
+func unhold(path string, r *heldReader) {
+	heldMutex.Lock()
+	defer heldMutex.Unlock()
+
+	r.refs--
+	if r.refs > 0 {
+		return
+	}
+
+	delete(held, path)
+	r.Reader.Close()
+}
+
+// getHeld returns the reader of the archive at the path if it is held.
+func getHeld(path string) Reader {
+	heldMutex.Lock()
+	defer heldMutex.Unlock()
+
+	if r := held[path]; r != nil {
+		return sharedReader{r.Reader}
+	}
+
+	return nil
+}
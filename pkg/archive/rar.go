@@ -0,0 +1,77 @@
+package archive
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"time"
+
+	"github.com/nwaples/rardecode"
+)
+
+// RARFormat is the name of the RAR format, which includes cbr files. RAR
+// files can only be read.
+const RARFormat = "rar"
+
+func init() {
+	Register(Format{
+		Name: RARFormat,
+		Match: func(header []byte) bool {
+			// RAR 1.5 to 4.x and RAR 5 signatures
+			return bytes.HasPrefix(header, []byte("Rar!\x1a\x07\x00")) || bytes.HasPrefix(header, []byte("Rar!\x1a\x07\x01\x00"))
+		},
+		Open: func(path string) (Reader, error) {
+			return openStreamReader(path, openRARStream)
+		},
+	})
+}
+
+type rarStream struct {
+	*rardecode.ReadCloser
+}
+
+func openRARStream(path string) (stream, error) {
+	r, err := rardecode.OpenReader(path, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return rarStream{r}, nil
+}
+
+func (s rarStream) Next() (string, os.FileInfo, error) {
+	h, err := s.ReadCloser.Next()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return h.Name, rarFileInfo{h}, nil
+}
+
+type rarFileInfo struct {
+	h *rardecode.FileHeader
+}
+
+func (fi rarFileInfo) Name() string {
+	return path.Base(fi.h.Name)
+}
+
+func (fi rarFileInfo) Size() int64 {
+	return fi.h.UnPackedSize
+}
+
+func (fi rarFileInfo) Mode() os.FileMode {
+	return fi.h.Mode()
+}
+
+func (fi rarFileInfo) ModTime() time.Time {
+	return fi.h.ModificationTime
+}
+
+func (fi rarFileInfo) IsDir() bool {
+	return fi.h.IsDir
+}
+
+func (fi rarFileInfo) Sys() interface{} {
+	return fi.h
+}
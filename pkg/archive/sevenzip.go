@@ -0,0 +1,68 @@
+package archive
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/bodgit/sevenzip"
+)
+
+// SevenZipFormat is the name of the 7z format, which includes cb7 files.
+const SevenZipFormat = "7z"
+
+func init() {
+	Register(Format{
+		Name: SevenZipFormat,
+		Match: func(header []byte) bool {
+			return bytes.HasPrefix(header, []byte("7z\xbc\xaf\x27\x1c"))
+		},
+		Open: openSevenZip,
+	})
+}
+
+type sevenZipReader struct {
+	r     *sevenzip.ReadCloser
+	files []File
+}
+
+func openSevenZip(path string) (Reader, error) {
+	r, err := sevenzip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &sevenZipReader{r: r}
+	for _, f := range r.File {
+		ret.files = append(ret.files, sevenZipFile{f})
+	}
+
+	return ret, nil
+}
+
+func (r *sevenZipReader) Files() []File {
+	return r.files
+}
+
+func (r *sevenZipReader) Close() error {
+	return r.r.Close()
+}
+
+type sevenZipFile struct {
+	f *sevenzip.File
+}
+
+func (f sevenZipFile) Name() string {
+	return f.f.Name
+}
+
+func (f sevenZipFile) FileInfo() os.FileInfo {
+	return f.f.FileInfo()
+}
+
+// Open returns a reader of the file. Files in solid archives are compressed
+// together, so the files preceding the file in its block are decompressed as
+// well.
+func (f sevenZipFile) Open() (io.ReadCloser, error) {
+	return f.f.Open()
+}
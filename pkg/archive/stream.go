@@ -0,0 +1,186 @@
+package archive
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// stream reads the files of an archive that can only be read in order, such
+// as tar and RAR files.
+type stream interface {
+	// Next advances to the next file in the archive. Returns io.EOF at the
+	// end of the archive.
+	Next() (name string, info os.FileInfo, err error)
+	// Read reads the contents of the current file.
+	Read(p []byte) (int, error)
+	Close() error
+}
+
+// streamReader provides access to the files of an archive that can only be
+// read in order. Each file is read by skipping the files that precede it. The
+// stream of the last file read is kept open, so that reading the files in
+// order does not restart the stream for each file.
+type streamReader struct {
+	path  string
+	open  func(path string) (stream, error)
+	files []File
+
+	mutex sync.Mutex
+	// idle is the open stream positioned at the file at idleIndex, or nil
+	idle      stream
+	idleIndex int
+	closed    bool
+}
+
+func openStreamReader(path string, open func(path string) (stream, error)) (Reader, error) {
+	s, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	ret := &streamReader{
+		path: path,
+		open: open,
+	}
+
+	for {
+		name, info, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		ret.files = append(ret.files, &streamFile{
+			r:     ret,
+			index: len(ret.files),
+			name:  name,
+			info:  info,
+		})
+	}
+
+	return ret, nil
+}
+
+func (r *streamReader) Files() []File {
+	return r.files
+}
+
+func (r *streamReader) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.closed = true
+	return r.closeIdle()
+}
+
+// closeIdle closes the idle stream. Must be called with the mutex held.
+func (r *streamReader) closeIdle() error {
+	if r.idle == nil {
+		return nil
+	}
+
+	err := r.idle.Close()
+	r.idle = nil
+	return err
+}
+
+// takeStream returns a stream positioned before the file at the index,
+// reusing the idle stream if it has not passed the file.
+func (r *streamReader) takeStream(index int) (s stream, position int, err error) {
+	r.mutex.Lock()
+	if r.idle != nil && r.idleIndex < index {
+		s, position = r.idle, r.idleIndex
+		r.idle = nil
+	} else {
+		_ = r.closeIdle()
+	}
+	r.mutex.Unlock()
+
+	if s != nil {
+		return s, position, nil
+	}
+
+	s, err = r.open(r.path)
+	return s, -1, err
+}
+
+// putStream keeps the stream positioned at the file at the index as the
+// idle stream, closing the previous idle stream.
+func (r *streamReader) putStream(s stream, index int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return s.Close()
+	}
+
+	_ = r.closeIdle()
+	r.idle = s
+	r.idleIndex = index
+	return nil
+}
+
+type streamFile struct {
+	r     *streamReader
+	index int
+	name  string
+	info  os.FileInfo
+}
+
+func (f *streamFile) Name() string {
+	return f.name
+}
+
+func (f *streamFile) FileInfo() os.FileInfo {
+	return f.info
+}
+
+func (f *streamFile) Open() (io.ReadCloser, error) {
+	s, position, err := f.r.takeStream(f.index)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := position + 1; i <= f.index; i++ {
+		if _, _, err := s.Next(); err != nil {
+			s.Close()
+			if err == io.EOF {
+				return nil, fmt.Errorf("file with name '%s' not found in archive file '%s'", f.name, f.r.path)
+			}
+			return nil, err
+		}
+	}
+
+	return &streamFileReader{
+		s:     s,
+		r:     f.r,
+		index: f.index,
+	}, nil
+}
+
+// streamFileReader reads a file from a stream. Closing it returns the stream
+// to the reader.
+type streamFileReader struct {
+	s     stream
+	r     *streamReader
+	index int
+}
+
+func (f *streamFileReader) Read(p []byte) (int, error) {
+	return f.s.Read(p)
+}
+
+func (f *streamFileReader) Close() error {
+	if f.s == nil {
+		return nil
+	}
+
+	s := f.s
+	f.s = nil
+	return f.r.putStream(s, f.index)
+}
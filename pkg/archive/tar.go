@@ -0,0 +1,105 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+)
+
+// Names of the tar formats, which include cbt files.
+const (
+	TarFormat   = "tar"
+	TarGzFormat = "tar.gz"
+)
+
+var gzipMagic = []byte("\x1f\x8b")
+
+func init() {
+	Register(Format{
+		Name:  TarFormat,
+		Match: isTarHeader,
+		Open: func(path string) (Reader, error) {
+			return openStreamReader(path, openTarStream)
+		},
+	})
+
+	Register(Format{
+		Name: TarGzFormat,
+		Match: func(header []byte) bool {
+			// the header of the gzip file does not include the tar header,
+			// so gzip files are assumed to be compressed tar files
+			return bytes.HasPrefix(header, gzipMagic)
+		},
+		Open: func(path string) (Reader, error) {
+			return openStreamReader(path, openTarGzStream)
+		},
+	})
+}
+
+func isTarHeader(header []byte) bool {
+	// ustar and GNU tar files
+	const magicOffset = 257
+	return len(header) >= magicOffset+5 && bytes.Equal(header[magicOffset:magicOffset+5], []byte("ustar"))
+}
+
+type tarStream struct {
+	tr      *tar.Reader
+	closers []io.Closer
+}
+
+func openTarStream(path string) (stream, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// the tar reader seeks over the contents of skipped files
+	return &tarStream{
+		tr:      tar.NewReader(f),
+		closers: []io.Closer{f},
+	}, nil
+}
+
+func openTarGzStream(path string) (stream, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &tarStream{
+		tr:      tar.NewReader(gz),
+		closers: []io.Closer{gz, f},
+	}, nil
+}
+
+func (s *tarStream) Next() (string, os.FileInfo, error) {
+	h, err := s.tr.Next()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return h.Name, h.FileInfo(), nil
+}
+
+func (s *tarStream) Read(p []byte) (int, error) {
+	return s.tr.Read(p)
+}
+
+func (s *tarStream) Close() error {
+	var ret error
+	for _, c := range s.closers {
+		if err := c.Close(); err != nil && ret == nil {
+			ret = err
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,65 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+)
+
+// ZipFormat is the name of the zip format, which includes cbz files.
+const ZipFormat = "zip"
+
+func init() {
+	Register(Format{
+		Name: ZipFormat,
+		Match: func(header []byte) bool {
+			// local file header, or end of central directory of an empty file
+			return bytes.HasPrefix(header, []byte("PK\x03\x04")) || bytes.HasPrefix(header, []byte("PK\x05\x06"))
+		},
+		Open: openZip,
+	})
+}
+
+type zipReader struct {
+	r     *zip.ReadCloser
+	files []File
+}
+
+func openZip(path string) (Reader, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &zipReader{r: r}
+	for _, f := range r.File {
+		ret.files = append(ret.files, zipFile{f})
+	}
+
+	return ret, nil
+}
+
+func (r *zipReader) Files() []File {
+	return r.files
+}
+
+func (r *zipReader) Close() error {
+	return r.r.Close()
+}
+
+type zipFile struct {
+	f *zip.File
+}
+
+func (f zipFile) Name() string {
+	return f.f.Name
+}
+
+func (f zipFile) FileInfo() os.FileInfo {
+	return f.f.FileInfo()
+}
+
+func (f zipFile) Open() (io.ReadCloser, error) {
+	return f.f.Open()
+}
@@ -0,0 +1,4 @@
+ALTER TABLE `scenes` ADD COLUMN `resume_time` float not null default 0;
+ALTER TABLE `scenes` ADD COLUMN `play_duration` float not null default 0;
+ALTER TABLE `scenes` ADD COLUMN `play_count` integer not null default 0;
+ALTER TABLE `scenes` ADD COLUMN `last_played_at` datetime;
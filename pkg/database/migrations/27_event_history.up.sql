@@ -0,0 +1,23 @@
+CREATE TABLE `scenes_o_dates` (
+  `scene_id` integer not null,
+  `o_date` datetime not null,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete CASCADE
+);
+
+CREATE INDEX `index_scenes_o_dates_on_scene_id` on `scenes_o_dates` (`scene_id`);
+
+CREATE TABLE `scenes_play_dates` (
+  `scene_id` integer not null,
+  `play_date` datetime not null,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete CASCADE
+);
+
+CREATE INDEX `index_scenes_play_dates_on_scene_id` on `scenes_play_dates` (`scene_id`);
+
+CREATE TABLE `images_o_dates` (
+  `image_id` integer not null,
+  `o_date` datetime not null,
+  foreign key(`image_id`) references `images`(`id`) on delete CASCADE
+);
+
+CREATE INDEX `index_images_o_dates_on_image_id` on `images_o_dates` (`image_id`);
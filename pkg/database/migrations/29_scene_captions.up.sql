@@ -0,0 +1,10 @@
+CREATE TABLE `scene_captions` (
+  `scene_id` integer not null,
+  `language_code` varchar(255) not null,
+  `filename` varchar(255) not null,
+  `caption_type` varchar(255) not null,
+  `stream_index` integer,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete CASCADE
+);
+
+CREATE INDEX `index_scene_captions_on_scene_id` on `scene_captions` (`scene_id`);
@@ -0,0 +1,10 @@
+CREATE TABLE `scene_audio_tracks` (
+  `scene_id` integer not null,
+  `stream_index` integer not null,
+  `language_code` varchar(255) not null,
+  `codec` varchar(255) not null,
+  `channels` integer not null,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete CASCADE
+);
+
+CREATE INDEX `index_scene_audio_tracks_on_scene_id` on `scene_audio_tracks` (`scene_id`);
@@ -0,0 +1,23 @@
+ALTER TABLE `images` ADD COLUMN `date` date;
+ALTER TABLE `images` ADD COLUMN `captured_at` datetime;
+ALTER TABLE `images` ADD COLUMN `camera_make` varchar(255);
+ALTER TABLE `images` ADD COLUMN `camera_model` varchar(255);
+ALTER TABLE `images` ADD COLUMN `lens` varchar(255);
+ALTER TABLE `images` ADD COLUMN `exposure_time` float;
+ALTER TABLE `images` ADD COLUMN `f_number` float;
+ALTER TABLE `images` ADD COLUMN `iso` integer;
+ALTER TABLE `images` ADD COLUMN `focal_length` float;
+ALTER TABLE `images` ADD COLUMN `gps_latitude` float;
+ALTER TABLE `images` ADD COLUMN `gps_longitude` float;
+ALTER TABLE `images` ADD COLUMN `orientation` tinyint;
+ALTER TABLE `images` ADD COLUMN `creator` varchar(255);
+ALTER TABLE `images` ADD COLUMN `metadata_rating` tinyint;
+
+CREATE TABLE `images_keywords` (
+  `image_id` integer not null,
+  `keyword` varchar(255) not null,
+  foreign key(`image_id`) references `images`(`id`) on delete CASCADE
+);
+
+CREATE INDEX `index_images_keywords_on_image_id` on `images_keywords` (`image_id`);
+CREATE INDEX `index_images_keywords_on_keyword` on `images_keywords` (`keyword`);
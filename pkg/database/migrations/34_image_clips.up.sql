@@ -0,0 +1,3 @@
+ALTER TABLE `images` ADD COLUMN `clip` boolean not null default '0';
+ALTER TABLE `images` ADD COLUMN `duration` float;
+ALTER TABLE `images` ADD COLUMN `frame_count` integer;
@@ -0,0 +1,10 @@
+ALTER TABLE `scenes` ADD COLUMN `intro_end` float;
+ALTER TABLE `scenes` ADD COLUMN `outro_start` float;
+
+CREATE TABLE `scene_cuts` (
+  `scene_id` integer not null,
+  `seconds` float not null,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete CASCADE
+);
+
+CREATE INDEX `index_scene_cuts_on_scene_id` on `scene_cuts` (`scene_id`);
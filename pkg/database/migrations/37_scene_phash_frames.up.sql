@@ -0,0 +1,8 @@
+CREATE TABLE `scene_phash_frames` (
+  `scene_id` integer not null,
+  `seconds` float not null,
+  `phash` integer not null,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete CASCADE
+);
+
+CREATE INDEX `index_scene_phash_frames_on_scene_id` on `scene_phash_frames` (`scene_id`);
@@ -0,0 +1 @@
+ALTER TABLE `scenes` ADD COLUMN `captions_mod_time` datetime;
@@ -0,0 +1,4 @@
+ALTER TABLE `scenes` ADD COLUMN `audio_tracks_probed` boolean not null default '0';
+
+-- scenes with audio tracks were probed when they were scanned
+UPDATE `scenes` SET `audio_tracks_probed` = 1 WHERE `id` IN (SELECT DISTINCT `scene_id` FROM `scene_audio_tracks`);
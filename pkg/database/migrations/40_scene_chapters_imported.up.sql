@@ -0,0 +1 @@
+ALTER TABLE `scenes` ADD COLUMN `chapters_imported` boolean not null default '0';
@@ -76,7 +76,11 @@ func (me *contentDirectoryService) updateIDString() string {
 	return fmt.Sprintf("%d", uint32(os.Getpid()))
 }
 
-func sceneToContainer(scene *models.Scene, parent string, host string) interface{} {
+// audioTrackMimeType is the mime type of scenes streamed with a selected
+// audio track, which are served as MPEG-TS.
+const audioTrackMimeType = "video/mpeg"
+
+func sceneToContainer(scene *models.Scene, audioTracks []*models.SceneAudioTrack, parent string, host string) interface{} {
 	// make stash server URL
 	// TODO - fix this
 	iconURI := (&url.URL{
@@ -130,6 +134,28 @@ func sceneToContainer(scene *models.Scene, parent string, host string) interface
 		// Resolution: resolution,
 	})
 
+	// add a resource for each audio track, in stream order, if there is a
+	// choice of tracks
+	if len(audioTracks) > 1 {
+		for _, t := range audioTracks {
+			item.Res = append(item.Res, upnpav.Resource{
+				URL: (&url.URL{
+					Scheme: "http",
+					Host:   host,
+					Path:   resPath,
+					RawQuery: url.Values{
+						"scene":      {strconv.Itoa(scene.ID)},
+						"audioTrack": {strconv.Itoa(t.StreamIndex)},
+					}.Encode(),
+				}).String(),
+				ProtocolInfo: fmt.Sprintf("http-get:*:%s:%s", audioTrackMimeType, dlna.ContentFeatures{
+					Transcoded: true,
+				}.String()),
+				Duration: formatDurationSexagesimal(time.Duration(duration) * time.Second),
+			})
+		}
+	}
+
 	item.Res = append(item.Res, upnpav.Resource{
 		URL:          iconURI,
 		ProtocolInfo: "http-get:*:image/jpeg:DLNA.ORG_PN=JPEG_MED",
@@ -138,6 +164,20 @@ func sceneToContainer(scene *models.Scene, parent string, host string) interface
 	return item
 }
 
+func scenesToContainers(r models.ReaderRepository, scenes []*models.Scene, parent string, host string) ([]interface{}, error) {
+	var ret []interface{}
+	for _, s := range scenes {
+		audioTracks, err := r.Scene().GetAudioTracks(s.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, sceneToContainer(s, audioTracks, parent, host))
+	}
+
+	return ret, nil
+}
+
 // ContentDirectory object from ObjectID.
 func (me *contentDirectoryService) objectFromID(id string) (o object, err error) {
 	o.Path, err = url.QueryUnescape(id)
@@ -350,6 +390,7 @@ func (me *contentDirectoryService) handleBrowseMetadata(obj object, host string)
 		updateID = me.updateIDString()
 	} else {
 		var scene *models.Scene
+		var audioTracks []*models.SceneAudioTrack
 
 		if err := me.txnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
 			scene, err = r.Scene().Find(sceneID)
@@ -357,13 +398,20 @@ func (me *contentDirectoryService) handleBrowseMetadata(obj object, host string)
 				return err
 			}
 
+			if scene != nil {
+				audioTracks, err = r.Scene().GetAudioTracks(sceneID)
+				if err != nil {
+					return err
+				}
+			}
+
 			return nil
 		}); err != nil {
 			logger.Error(err.Error())
 		}
 
 		if scene != nil {
-			upnpObject := sceneToContainer(scene, "-1", host)
+			upnpObject := sceneToContainer(scene, audioTracks, "-1", host)
 			objs = []interface{}{upnpObject}
 
 			// http://upnp.org/specs/av/UPnP-av-ContentDirectory-v1-Service.pdf
@@ -453,8 +501,9 @@ func (me *contentDirectoryService) getVideos(sceneFilter *models.SceneFilterType
 				return err
 			}
 		} else {
-			for _, s := range scenes {
-				objs = append(objs, sceneToContainer(s, parentID, host))
+			objs, err = scenesToContainers(r, scenes, parentID, host)
+			if err != nil {
+				return err
 			}
 		}
 
@@ -566,6 +566,17 @@ func (me *Server) initMux(mux *http.ServeMux) {
 			return
 		}
 
+		if audioTrack := r.URL.Query().Get("audioTrack"); audioTrack != "" {
+			index, err := strconv.Atoi(audioTrack)
+			if err != nil {
+				http.Error(w, "invalid audio track", http.StatusBadRequest)
+				return
+			}
+
+			me.sceneServer.StreamSceneAudioTrack(scene, index, w, r)
+			return
+		}
+
 		me.sceneServer.StreamSceneDirect(scene, w, r)
 	})
 	mux.HandleFunc(rootDescPath, func(w http.ResponseWriter, r *http.Request) {
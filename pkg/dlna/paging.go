@@ -58,8 +58,6 @@ func (p *scenePager) getPages(r models.ReaderRepository, total int) ([]interface
 }
 
 func (p *scenePager) getPageVideos(r models.ReaderRepository, page int, host string) ([]interface{}, error) {
-	var objs []interface{}
-
 	sort := "title"
 	findFilter := &models.FindFilterType{
 		PerPage: &pageSize,
@@ -72,9 +70,5 @@ func (p *scenePager) getPageVideos(r models.ReaderRepository, page int, host str
 		return nil, err
 	}
 
-	for _, s := range scenes {
-		objs = append(objs, sceneToContainer(s, p.parentID, host))
-	}
-
-	return objs, nil
+	return scenesToContainers(r, scenes, p.parentID, host)
 }
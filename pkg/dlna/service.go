@@ -25,6 +25,7 @@ type dmsConfig struct {
 
 type sceneServer interface {
 	StreamSceneDirect(scene *models.Scene, w http.ResponseWriter, r *http.Request)
+	StreamSceneAudioTrack(scene *models.Scene, audioStreamIndex int, w http.ResponseWriter, r *http.Request)
 	ServeScreenshot(scene *models.Scene, w http.ResponseWriter, r *http.Request)
 }
 
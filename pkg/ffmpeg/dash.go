@@ -0,0 +1,205 @@
+package ffmpeg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const (
+	dashNamespace   = "urn:mpeg:dash:schema:mpd:2011"
+	dashProfile     = "urn:mpeg:dash:profile:isoff-live:2011"
+	dashAudioCodecs = "mp4a.40.2"
+)
+
+// DASHRepresentation is a representation of a DASH manifest.
+type DASHRepresentation struct {
+	ID                string
+	Codecs            string
+	Width             int
+	Height            int
+	Bandwidth         int64
+	InitializationURL string
+	MediaURL          string
+}
+
+// DASHAdaptationSet is a set of interchangeable representations of a DASH
+// manifest.
+type DASHAdaptationSet struct {
+	Representations []DASHRepresentation
+}
+
+// dashVideoProfile is the fixed video output profile of a DASH transcode.
+// The arguments are added to the transcode and the codecs string is
+// advertised in the manifest, so that the advertised codecs always match the
+// segments, regardless of the source video.
+type dashVideoProfile struct {
+	// codecs is the RFC 6381 codecs string of the video stream
+	codecs string
+	args   []string
+}
+
+// getDASHVideoProfile returns the video output profile of a DASH transcode
+// with the codec and output height. Level 4.0 is used up to 1080p and level
+// 5.1 above.
+func getDASHVideoProfile(codec Codec, height int) dashVideoProfile {
+	hd := height <= 1080
+
+	switch codec.Codec {
+	case CodecVP9.Codec:
+		// profile 0 is 8-bit 4:2:0, so higher bit depths are converted
+		ret := dashVideoProfile{
+			codecs: "vp09.00.51.08",
+			args:   []string{"-profile:v", "0", "-pix_fmt", "yuv420p"},
+		}
+		if hd {
+			ret.codecs = "vp09.00.40.08"
+		}
+		return ret
+	default:
+		if hd {
+			return dashVideoProfile{
+				codecs: "avc1.640028",
+				args:   []string{"-profile:v", "high", "-level:v", "4.0"},
+			}
+		}
+
+		return dashVideoProfile{
+			codecs: "avc1.640033",
+			args:   []string{"-profile:v", "high", "-level:v", "5.1"},
+		}
+	}
+}
+
+// getDASHCodecs returns the RFC 6381 codecs string of the fragmented MP4
+// segments of the video file transcoded with the provided codec and
+// resolution. The audio is always transcoded to AAC-LC.
+func getDASHCodecs(probeResult VideoFile, codec Codec, maxTranscodeSize models.StreamingResolutionEnum, audio bool) string {
+	_, height := calculateTranscodeDimensions(probeResult, maxTranscodeSize)
+	ret := getDASHVideoProfile(codec, height).codecs
+
+	if audio {
+		ret += "," + dashAudioCodecs
+	}
+
+	return ret
+}
+
+// GetDASHRepresentation returns the representation of the video file
+// transcoded with the provided codec and resolution. audio should be false if
+// the audio is removed by the transcode. initURL is the URL of the
+// initialisation segment, and mediaURL is the URL template of the media
+// segments, where $Number$ is replaced with the segment index.
+func GetDASHRepresentation(probeResult VideoFile, codec Codec, maxTranscodeSize models.StreamingResolutionEnum, audio bool, initURL string, mediaURL string) DASHRepresentation {
+	rendition := GetHLSRendition(probeResult, maxTranscodeSize, "")
+
+	return DASHRepresentation{
+		ID:                codec.Codec + "_" + maxTranscodeSize.String(),
+		Codecs:            getDASHCodecs(probeResult, codec, maxTranscodeSize, audio),
+		Width:             rendition.Width,
+		Height:            rendition.Height,
+		Bandwidth:         rendition.Bandwidth,
+		InitializationURL: initURL,
+		MediaURL:          mediaURL,
+	}
+}
+
+type mpd struct {
+	XMLName                   xml.Name `xml:"MPD"`
+	Xmlns                     string   `xml:"xmlns,attr"`
+	Profiles                  string   `xml:"profiles,attr"`
+	Type                      string   `xml:"type,attr"`
+	MediaPresentationDuration string   `xml:"mediaPresentationDuration,attr"`
+	MinBufferTime             string   `xml:"minBufferTime,attr"`
+	Period                    mpdPeriod
+}
+
+type mpdPeriod struct {
+	XMLName        xml.Name `xml:"Period"`
+	Start          string   `xml:"start,attr"`
+	AdaptationSets []mpdAdaptationSet
+}
+
+type mpdAdaptationSet struct {
+	XMLName          xml.Name `xml:"AdaptationSet"`
+	MimeType         string   `xml:"mimeType,attr"`
+	SegmentAlignment bool     `xml:"segmentAlignment,attr"`
+	StartWithSAP     int      `xml:"startWithSAP,attr"`
+	Representations  []mpdRepresentation
+}
+
+type mpdRepresentation struct {
+	XMLName         xml.Name `xml:"Representation"`
+	ID              string   `xml:"id,attr"`
+	Codecs          string   `xml:"codecs,attr"`
+	Width           int      `xml:"width,attr"`
+	Height          int      `xml:"height,attr"`
+	Bandwidth       int64    `xml:"bandwidth,attr"`
+	SegmentTemplate mpdSegmentTemplate
+}
+
+type mpdSegmentTemplate struct {
+	XMLName        xml.Name `xml:"SegmentTemplate"`
+	Timescale      int      `xml:"timescale,attr"`
+	Duration       int      `xml:"duration,attr"`
+	StartNumber    int      `xml:"startNumber,attr"`
+	Initialization string   `xml:"initialization,attr"`
+	Media          string   `xml:"media,attr"`
+}
+
+func getDASHDuration(seconds float64) string {
+	return fmt.Sprintf("PT%.3fS", seconds)
+}
+
+// WriteDASHManifest writes a static DASH manifest of the video file with the
+// provided adaptation sets. Each representation is split into fragmented MP4
+// segments of hlsSegmentLength seconds, with video and audio muxed together.
+func WriteDASHManifest(probeResult VideoFile, adaptationSets []DASHAdaptationSet, w io.Writer) error {
+	m := mpd{
+		Xmlns:                     dashNamespace,
+		Profiles:                  dashProfile,
+		Type:                      "static",
+		MediaPresentationDuration: getDASHDuration(probeResult.Duration),
+		MinBufferTime:             getDASHDuration(hlsSegmentLength),
+		Period: mpdPeriod{
+			Start: getDASHDuration(0),
+		},
+	}
+
+	for _, as := range adaptationSets {
+		mas := mpdAdaptationSet{
+			MimeType:         MimeMp4,
+			SegmentAlignment: true,
+			StartWithSAP:     1,
+		}
+
+		for _, r := range as.Representations {
+			mas.Representations = append(mas.Representations, mpdRepresentation{
+				ID:        r.ID,
+				Codecs:    r.Codecs,
+				Width:     r.Width,
+				Height:    r.Height,
+				Bandwidth: r.Bandwidth,
+				SegmentTemplate: mpdSegmentTemplate{
+					Timescale:      1,
+					Duration:       int(hlsSegmentLength),
+					StartNumber:    0,
+					Initialization: r.InitializationURL,
+					Media:          r.MediaURL,
+				},
+			})
+		}
+
+		m.Period.AdaptationSets = append(m.Period.AdaptationSets, mas)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(m)
+}
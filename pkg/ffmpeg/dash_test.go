@@ -0,0 +1,103 @@
+package ffmpeg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func TestGetDASHCodecs(t *testing.T) {
+	fhd := VideoFile{Width: 1920, Height: 1080}
+	uhd := VideoFile{Width: 3840, Height: 2160}
+
+	tests := []struct {
+		name       string
+		video      VideoFile
+		codec      Codec
+		resolution models.StreamingResolutionEnum
+		audio      bool
+		want       string
+	}{
+		{"h264 1080p", fhd, CodecH264, models.StreamingResolutionEnumOriginal, true, "avc1.640028,mp4a.40.2"},
+		{"h264 no audio", fhd, CodecH264, models.StreamingResolutionEnumOriginal, false, "avc1.640028"},
+		{"h264 4k", uhd, CodecH264, models.StreamingResolutionEnumOriginal, true, "avc1.640033,mp4a.40.2"},
+		{"h264 4k downscaled", uhd, CodecH264, models.StreamingResolutionEnumStandardHd, true, "avc1.640028,mp4a.40.2"},
+		{"vp9 1080p", fhd, CodecVP9, models.StreamingResolutionEnumOriginal, true, "vp09.00.40.08,mp4a.40.2"},
+		{"vp9 4k", uhd, CodecVP9, models.StreamingResolutionEnumOriginal, false, "vp09.00.51.08"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, getDASHCodecs(tt.video, tt.codec, tt.resolution, tt.audio), tt.name)
+	}
+}
+
+func TestSegmentOptionsDASHProfile(t *testing.T) {
+	options := SegmentOptions{
+		ProbeResult:      VideoFile{Path: "video.mp4", Width: 3840, Height: 2160},
+		Codec:            CodecH264,
+		Format:           SegmentFormatFMP4,
+		MaxTranscodeSize: models.StreamingResolutionEnumStandardHd,
+	}
+
+	// the transcode matches the profile advertised in the manifest
+	args := strings.Join(options.getArgs("segments", 0), " ")
+	assert.Contains(t, args, "-profile:v high -level:v 4.0")
+
+	options.Codec = CodecVP9
+	args = strings.Join(options.getArgs("segments", 0), " ")
+	assert.Contains(t, args, "-profile:v 0 -pix_fmt yuv420p")
+
+	// HLS segments are not advertised with a codecs string
+	options.Codec = CodecHLS
+	options.Format = SegmentFormatMpegts
+	args = strings.Join(options.getArgs("segments", 0), " ")
+	assert.NotContains(t, args, "-level:v")
+}
+
+func TestWriteDASHManifest(t *testing.T) {
+	probeResult := VideoFile{Width: 1920, Height: 1080, Duration: 125.5}
+
+	adaptationSets := []DASHAdaptationSet{
+		{
+			Representations: []DASHRepresentation{
+				GetDASHRepresentation(probeResult, CodecH264, models.StreamingResolutionEnumOriginal, true, "h264/init.mp4", "h264/$Number$.m4s"),
+				GetDASHRepresentation(probeResult, CodecH264, models.StreamingResolutionEnumStandard, true, "h264_480/init.mp4", "h264_480/$Number$.m4s"),
+			},
+		},
+		{
+			Representations: []DASHRepresentation{
+				GetDASHRepresentation(probeResult, CodecVP9, models.StreamingResolutionEnumOriginal, false, "vp9/init.mp4", "vp9/$Number$.m4s"),
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDASHManifest(probeResult, adaptationSets, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := xml.Header + `<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-live:2011" type="static" mediaPresentationDuration="PT125.500S" minBufferTime="PT10.000S">
+  <Period start="PT0.000S">
+    <AdaptationSet mimeType="video/mp4" segmentAlignment="true" startWithSAP="1">
+      <Representation id="libx264_ORIGINAL" codecs="avc1.640028,mp4a.40.2" width="1920" height="1080" bandwidth="16128000">
+        <SegmentTemplate timescale="1" duration="10" startNumber="0" initialization="h264/init.mp4" media="h264/$Number$.m4s"></SegmentTemplate>
+      </Representation>
+      <Representation id="libx264_STANDARD" codecs="avc1.640028,mp4a.40.2" width="852" height="480" bandwidth="1328000">
+        <SegmentTemplate timescale="1" duration="10" startNumber="0" initialization="h264_480/init.mp4" media="h264_480/$Number$.m4s"></SegmentTemplate>
+      </Representation>
+    </AdaptationSet>
+    <AdaptationSet mimeType="video/mp4" segmentAlignment="true" startWithSAP="1">
+      <Representation id="libvpx-vp9_ORIGINAL" codecs="vp09.00.40.08" width="1920" height="1080" bandwidth="16128000">
+        <SegmentTemplate timescale="1" duration="10" startNumber="0" initialization="vp9/init.mp4" media="vp9/$Number$.m4s"></SegmentTemplate>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`
+
+	assert.Equal(t, want, buf.String())
+}
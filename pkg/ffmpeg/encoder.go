@@ -161,3 +161,26 @@ func (e *Encoder) run(probeResult VideoFile, args []string) (string, error) {
 
 	return stdout.String(), nil
 }
+
+// runWithStderr runs ffmpeg and returns its stderr output, which is where
+// filters such as blackdetect log their results.
+func (e *Encoder) runWithStderr(probeResult VideoFile, args []string) (string, error) {
+	cmd := exec.Command(e.Path, args...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+
+	registerRunningEncoder(probeResult.Path, cmd.Process)
+	err := waitAndDeregister(probeResult.Path, cmd)
+
+	if err != nil {
+		logger.Errorf("ffmpeg error when running command <%s>: %s", strings.Join(cmd.Args, " "), stderr.String())
+		return stderr.String(), err
+	}
+
+	return stderr.String(), nil
+}
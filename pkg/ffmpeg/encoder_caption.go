@@ -0,0 +1,18 @@
+package ffmpeg
+
+import "strconv"
+
+// ExtractCaption returns the subtitle stream with the provided index,
+// converted to WebVTT.
+func (e *Encoder) ExtractCaption(probeResult VideoFile, streamIndex int) (string, error) {
+	args := []string{
+		"-v", "error",
+		"-i", probeResult.Path,
+		"-map", "0:" + strconv.Itoa(streamIndex),
+		"-c:s", "webvtt",
+		"-f", "webvtt",
+		"-",
+	}
+
+	return e.run(probeResult, args)
+}
@@ -0,0 +1,48 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"image"
+)
+
+type FrameSequenceOptions struct {
+	// Interval is the time in seconds between frames
+	Interval float64
+	// Width and Height are the dimensions frames are scaled to
+	Width  int
+	Height int
+}
+
+// FrameSequence decodes the video stream of the file in a single pass,
+// returning grayscale frames taken every interval, starting at the start of
+// the file. Frame i is at i * interval seconds.
+func (e *Encoder) FrameSequence(probeResult VideoFile, options FrameSequenceOptions) ([]image.Image, error) {
+	frameSize := options.Width * options.Height
+	if frameSize <= 0 || options.Interval <= 0 {
+		return nil, fmt.Errorf("invalid frame sequence options: %+v", options)
+	}
+
+	args := []string{
+		"-v", "error",
+		"-i", probeResult.Path,
+		"-an",
+		"-sn",
+		"-vf", fmt.Sprintf("fps=1/%v,scale=%d:%d", options.Interval, options.Width, options.Height),
+		"-pix_fmt", "gray",
+		"-f", "rawvideo",
+		"-",
+	}
+	data, err := e.run(probeResult, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []image.Image
+	for i := 0; i+frameSize <= len(data); i += frameSize {
+		img := image.NewGray(image.Rect(0, 0, options.Width, options.Height))
+		copy(img.Pix, data[i:i+frameSize])
+		ret = append(ret, img)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,80 @@
+package ffmpeg
+
+import "strconv"
+
+type ImageClipPreviewOptions struct {
+	OutputPath string
+	// MaxSize is the maximum width and height of the preview
+	MaxSize int
+	// Duration is the maximum duration of the preview, in seconds
+	Duration float64
+}
+
+// calculateImageClipScale returns the scale filter that fits the clip
+// within the maximum size. Clips smaller than the maximum size are not
+// scaled up.
+func calculateImageClipScale(probeResult VideoFile, maxSize int) string {
+	if probeResult.Width >= probeResult.Height {
+		if probeResult.Width <= maxSize {
+			return "trunc(iw/2)*2:-2"
+		}
+		return strconv.Itoa(maxSize) + ":-2"
+	}
+
+	if probeResult.Height <= maxSize {
+		return "-2:trunc(ih/2)*2"
+	}
+	return "-2:" + strconv.Itoa(maxSize)
+}
+
+// ImageClipScreenshotWidth returns the width of a screenshot of the clip
+// that fits within the maximum size.
+func ImageClipScreenshotWidth(probeResult VideoFile, maxSize int) int {
+	if probeResult.Width >= probeResult.Height || probeResult.Height == 0 {
+		if probeResult.Width < maxSize {
+			return probeResult.Width
+		}
+		return maxSize
+	}
+
+	if probeResult.Height < maxSize {
+		return probeResult.Width
+	}
+	return probeResult.Width * maxSize / probeResult.Height
+}
+
+// ImageClipPreview encodes a silent mp4 preview of an image clip, which is
+// played in a loop in place of the clip.
+func (e *Encoder) ImageClipPreview(probeResult VideoFile, options ImageClipPreviewOptions) error {
+	_, err := e.run(probeResult, imageClipPreviewArgs(probeResult, options))
+	return err
+}
+
+func imageClipPreviewArgs(probeResult VideoFile, options ImageClipPreviewOptions) []string {
+	args := []string{
+		"-v", "error",
+		"-i", probeResult.Path,
+	}
+
+	if options.Duration > 0 {
+		args = append(args, "-t", strconv.FormatFloat(options.Duration, 'f', 2, 64))
+	}
+
+	args = append(args,
+		"-y",
+		"-an",
+		"-vf", "scale="+calculateImageClipScale(probeResult, options.MaxSize),
+		"-c:v", "libx264",
+		"-pix_fmt", "yuv420p",
+		"-profile:v", "high",
+		"-level", "4.2",
+		"-preset", "veryfast",
+		"-crf", "23",
+		"-movflags", "+faststart",
+		"-strict", "-2",
+		"-f", "mp4",
+		options.OutputPath,
+	)
+
+	return args
+}
@@ -0,0 +1,79 @@
+package ffmpeg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateImageClipScale(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  int
+		height int
+		want   string
+	}{
+		{"small landscape", 320, 240, "trunc(iw/2)*2:-2"},
+		{"landscape", 1920, 1080, "640:-2"},
+		{"small portrait", 240, 320, "-2:trunc(ih/2)*2"},
+		{"portrait", 1080, 1920, "-2:640"},
+		{"square", 640, 640, "trunc(iw/2)*2:-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probeResult := VideoFile{Width: tt.width, Height: tt.height}
+			assert.Equal(t, tt.want, calculateImageClipScale(probeResult, 640))
+		})
+	}
+}
+
+func TestImageClipScreenshotWidth(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  int
+		height int
+		want   int
+	}{
+		{"small landscape", 320, 240, 320},
+		{"landscape", 1920, 1080, 640},
+		{"small portrait", 240, 320, 240},
+		{"portrait", 1080, 1920, 360},
+		{"unknown height", 1920, 0, 640},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probeResult := VideoFile{Width: tt.width, Height: tt.height}
+			assert.Equal(t, tt.want, ImageClipScreenshotWidth(probeResult, 640))
+		})
+	}
+}
+
+func TestImageClipPreviewArgs(t *testing.T) {
+	probeResult := VideoFile{
+		Path:   "clip.gif",
+		Width:  1920,
+		Height: 1080,
+	}
+
+	args := imageClipPreviewArgs(probeResult, ImageClipPreviewOptions{
+		OutputPath: "preview.mp4",
+		MaxSize:    640,
+		Duration:   5,
+	})
+
+	assert.Equal(t, []string{"-i", "clip.gif"}, args[2:4])
+	assert.Equal(t, []string{"-t", "5.00"}, args[4:6])
+	assert.Contains(t, args, "scale=640:-2")
+	// previews are silent
+	assert.Contains(t, args, "-an")
+	assert.Equal(t, "preview.mp4", args[len(args)-1])
+
+	// the whole clip is encoded without a duration
+	args = imageClipPreviewArgs(probeResult, ImageClipPreviewOptions{
+		OutputPath: "preview.mp4",
+		MaxSize:    640,
+	})
+	assert.NotContains(t, args, "-t")
+}
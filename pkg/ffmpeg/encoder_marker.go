@@ -5,18 +5,46 @@ import (
 	"strconv"
 )
 
+const (
+	defaultSceneMarkerVideoDuration = 20
+	sceneMarkerImageDuration        = 5
+)
+
 type SceneMarkerOptions struct {
-	ScenePath  string
-	Seconds    int
+	ScenePath string
+	Seconds   int
+	// Duration is the duration of the marker in seconds, or 0 if the marker
+	// has no end time
+	Duration   float64
 	Width      int
 	OutputPath string
 }
 
+// videoDuration returns the duration of the marker video. The video covers
+// the range of markers with an end time.
+func (o SceneMarkerOptions) videoDuration() float64 {
+	if o.Duration > 0 {
+		return o.Duration
+	}
+
+	return defaultSceneMarkerVideoDuration
+}
+
+// imageDuration returns the duration of the animated marker image, which is
+// no longer than the range of the marker.
+func (o SceneMarkerOptions) imageDuration() float64 {
+	if o.Duration > 0 && o.Duration < sceneMarkerImageDuration {
+		return o.Duration
+	}
+
+	return sceneMarkerImageDuration
+}
+
 func (e *Encoder) SceneMarkerVideo(probeResult VideoFile, options SceneMarkerOptions) error {
 	args := []string{
 		"-v", "error",
 		"-ss", strconv.Itoa(options.Seconds),
-		"-t", "20",
+		"-t", strconv.FormatFloat(options.videoDuration(), 'f', 2, 64),
 		"-i", probeResult.Path,
 		"-max_muxing_queue_size", "1024", // https://trac.ffmpeg.org/ticket/6375
 		"-c:v", "libx264",
@@ -42,7 +70,7 @@ func (e *Encoder) SceneMarkerImage(probeResult VideoFile, options SceneMarkerOpt
 	args := []string{
 		"-v", "error",
 		"-ss", strconv.Itoa(options.Seconds),
-		"-t", "5",
+		"-t", strconv.FormatFloat(options.imageDuration(), 'f', 2, 64),
 		"-i", probeResult.Path,
 		"-c:v", "libwebp",
 		"-lossless", "1",
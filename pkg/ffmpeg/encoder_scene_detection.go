@@ -0,0 +1,99 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sceneDetectionWidth is the width that frames are scaled to before
+// detection, to speed up decoding of the filters.
+const sceneDetectionWidth = 320
+
+var blackDetectRegex = regexp.MustCompile(`black_start:\s*(-?[\d.]+)\s+black_end:\s*(-?[\d.]+)`)
+var showInfoTimeRegex = regexp.MustCompile(`pts_time:\s*(-?[\d.]+)`)
+
+type SceneDetectionOptions struct {
+	// SceneThreshold is the minimum scene change score between 0 and 1 of
+	// a frame to be detected as a cut
+	SceneThreshold float64
+	// BlackMinDuration is the minimum duration in seconds of black segments
+	BlackMinDuration float64
+	// BlackPixelThreshold is the maximum luminance between 0 and 1 of
+	// pixels considered to be black
+	BlackPixelThreshold float64
+}
+
+// BlackSegment is a range of black frames. Start and End are in seconds.
+type BlackSegment struct {
+	Start float64
+	End   float64
+}
+
+type SceneDetectionResult struct {
+	BlackSegments []BlackSegment
+	// Cuts are the times in seconds of frames that start a new shot
+	Cuts []float64
+}
+
+// DetectScenes runs the blackdetect and scene change filters over the video
+// stream of the file, returning the detected black segments and cuts.
+func (e *Encoder) DetectScenes(probeResult VideoFile, options SceneDetectionOptions) (*SceneDetectionResult, error) {
+	filter := fmt.Sprintf("scale=%d:-2,blackdetect=d=%.2f:pix_th=%.2f,select='gt(scene,%.2f)',showinfo",
+		sceneDetectionWidth,
+		options.BlackMinDuration,
+		options.BlackPixelThreshold,
+		options.SceneThreshold,
+	)
+
+	args := []string{
+		"-hide_banner",
+		"-nostats",
+		"-i", probeResult.Path,
+		"-an",
+		"-sn",
+		"-vf", filter,
+		"-f", "null",
+		"-",
+	}
+
+	// the results of the filters are logged to stderr
+	stderr, err := e.runWithStderr(probeResult, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSceneDetection(stderr), nil
+}
+
+func parseSceneDetection(output string) *SceneDetectionResult {
+	ret := &SceneDetectionResult{}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, "blackdetect") {
+			match := blackDetectRegex.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+
+			start, _ := strconv.ParseFloat(match[1], 64)
+			end, _ := strconv.ParseFloat(match[2], 64)
+			ret.BlackSegments = append(ret.BlackSegments, BlackSegment{
+				Start: start,
+				End:   end,
+			})
+		} else if strings.Contains(line, "showinfo") {
+			match := showInfoTimeRegex.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+
+			t, err := strconv.ParseFloat(match[1], 64)
+			if err == nil {
+				ret.Cuts = append(ret.Cuts, t)
+			}
+		}
+	}
+
+	return ret
+}
@@ -11,21 +11,28 @@ type TranscodeOptions struct {
 	MaxTranscodeSize models.StreamingResolutionEnum
 }
 
-func calculateTranscodeScale(probeResult VideoFile, maxTranscodeSize models.StreamingResolutionEnum) string {
-	maxSize := 0
+// getTranscodeMaxSize returns the size of the smaller dimension of the
+// provided transcode resolution. Returns 0 for the original resolution.
+func getTranscodeMaxSize(maxTranscodeSize models.StreamingResolutionEnum) int {
 	switch maxTranscodeSize {
 	case models.StreamingResolutionEnumLow:
-		maxSize = 240
+		return 240
 	case models.StreamingResolutionEnumStandard:
-		maxSize = 480
+		return 480
 	case models.StreamingResolutionEnumStandardHd:
-		maxSize = 720
+		return 720
 	case models.StreamingResolutionEnumFullHd:
-		maxSize = 1080
+		return 1080
 	case models.StreamingResolutionEnumFourK:
-		maxSize = 2160
+		return 2160
 	}
 
+	return 0
+}
+
+func calculateTranscodeScale(probeResult VideoFile, maxTranscodeSize models.StreamingResolutionEnum) string {
+	maxSize := getTranscodeMaxSize(maxTranscodeSize)
+
 	// get the smaller dimension of the video file
 	videoSize := probeResult.Height
 	if probeResult.Width < videoSize {
@@ -49,6 +56,32 @@ func calculateTranscodeScale(probeResult VideoFile, maxTranscodeSize models.Stre
 	return strconv.Itoa(maxSize) + ":-2"
 }
 
+// calculateTranscodeDimensions returns the width and height of the video
+// file after scaling using calculateTranscodeScale.
+func calculateTranscodeDimensions(probeResult VideoFile, maxTranscodeSize models.StreamingResolutionEnum) (int, int) {
+	maxSize := getTranscodeMaxSize(maxTranscodeSize)
+	width := probeResult.Width
+	height := probeResult.Height
+
+	videoSize := height
+	if width < videoSize {
+		videoSize = width
+	}
+
+	if maxSize >= videoSize || maxSize == 0 || videoSize == 0 {
+		return width, height - height%2
+	}
+
+	// the other dimension is rounded to a multiple of 2
+	if width > height {
+		scaled := width * maxSize / height
+		return scaled - scaled%2, maxSize
+	}
+
+	scaled := height * maxSize / width
+	return maxSize, scaled - scaled%2
+}
+
 func (e *Encoder) Transcode(probeResult VideoFile, options TranscodeOptions) {
 	scale := calculateTranscodeScale(probeResult, options.MaxTranscodeSize)
 	args := []string{
@@ -53,6 +53,7 @@ const (
 	MimeMp4            string     = "video/mp4"
 	MimeHLS            string     = "application/vnd.apple.mpegurl"
 	MimeMpegts         string     = "video/MP2T"
+	MimeDASH           string     = "application/dash+xml"
 )
 
 // only support H264 by default, since Safari does not support VP8/VP9
@@ -216,14 +217,25 @@ type VideoFile struct {
 	Width        int
 	Height       int
 	FrameRate    float64
+	FrameCount   int
 	Rotation     int64
 
 	AudioCodec string
+
+	Chapters []Chapter
+}
+
+// Chapter is a chapter embedded in the container of a video file. Start and
+// End are in seconds.
+type Chapter struct {
+	Title string
+	Start float64
+	End   float64
 }
 
 // Execute exec command and bind result to struct.
 func NewVideoFile(ffprobePath string, videoPath string, stripExt bool) (*VideoFile, error) {
-	args := []string{"-v", "quiet", "-print_format", "json", "-show_format", "-show_streams", "-show_error", videoPath}
+	args := []string{"-v", "quiet", "-print_format", "json", "-show_format", "-show_streams", "-show_chapters", "-show_error", videoPath}
 	//// Extremely slow on windows for some reason
 	//if runtime.GOOS != "windows" {
 	//	args = append(args, "-count_frames")
@@ -301,6 +313,13 @@ func parse(filePath string, probeJSON *FFProbeJSON, stripExt bool) (*VideoFile,
 			framerate, _ = strconv.ParseFloat(videoStream.AvgFrameRate, 64)
 		}
 		result.FrameRate = math.Round(framerate*100) / 100
+
+		// the number of frames is not present in the headers of all formats
+		if frameCount, err := strconv.Atoi(videoStream.NbFrames); err == nil {
+			result.FrameCount = frameCount
+		} else {
+			result.FrameCount = int(math.Round(duration * framerate))
+		}
 		if rotate, err := strconv.ParseInt(videoStream.Tags.Rotate, 10, 64); err == nil && rotate != 180 {
 			result.Width = videoStream.Height
 			result.Height = videoStream.Width
@@ -310,9 +329,30 @@ func parse(filePath string, probeJSON *FFProbeJSON, stripExt bool) (*VideoFile,
 		}
 	}
 
+	result.Chapters = parseChapters(probeJSON.Chapters)
+
 	return result, nil
 }
 
+func parseChapters(chapters []FFProbeChapter) []Chapter {
+	var ret []Chapter
+	for _, c := range chapters {
+		start, err := strconv.ParseFloat(c.StartTime, 64)
+		if err != nil {
+			continue
+		}
+		end, _ := strconv.ParseFloat(c.EndTime, 64)
+
+		ret = append(ret, Chapter{
+			Title: strings.TrimSpace(c.Tags.Title),
+			Start: math.Round(start*100) / 100,
+			End:   math.Round(end*100) / 100,
+		})
+	}
+
+	return ret
+}
+
 func (v *VideoFile) GetAudioStream() *FFProbeStream {
 	index := v.getStreamIndex("audio", v.JSON)
 	if index != -1 {
@@ -321,6 +361,30 @@ func (v *VideoFile) GetAudioStream() *FFProbeStream {
 	return nil
 }
 
+// GetAudioStreams returns all audio streams of the file.
+func (v *VideoFile) GetAudioStreams() []*FFProbeStream {
+	var ret []*FFProbeStream
+	for i, stream := range v.JSON.Streams {
+		if stream.CodecType == "audio" {
+			ret = append(ret, &v.JSON.Streams[i])
+		}
+	}
+
+	return ret
+}
+
+// GetAudioStreamByIndex returns the audio stream with the provided stream
+// index. Returns nil if the stream does not exist or is not an audio stream.
+func (v *VideoFile) GetAudioStreamByIndex(index int) *FFProbeStream {
+	for _, stream := range v.GetAudioStreams() {
+		if stream.Index == index {
+			return stream
+		}
+	}
+
+	return nil
+}
+
 func (v *VideoFile) GetVideoStream() *FFProbeStream {
 	index := v.getStreamIndex("video", v.JSON)
 	if index != -1 {
@@ -329,6 +393,29 @@ func (v *VideoFile) GetVideoStream() *FFProbeStream {
 	return nil
 }
 
+// GetSubtitleStreams returns the text-based subtitle streams of the file.
+// Image-based subtitle streams cannot be converted to WebVTT and are not
+// returned.
+func (v *VideoFile) GetSubtitleStreams() []*FFProbeStream {
+	var ret []*FFProbeStream
+	for i, stream := range v.JSON.Streams {
+		if stream.CodecType == "subtitle" && isTextSubtitleCodec(stream.CodecName) {
+			ret = append(ret, &v.JSON.Streams[i])
+		}
+	}
+
+	return ret
+}
+
+func isTextSubtitleCodec(codec string) bool {
+	switch codec {
+	case "subrip", "srt", "ass", "ssa", "webvtt", "mov_text", "text":
+		return true
+	}
+
+	return false
+}
+
 func (v *VideoFile) getStreamIndex(fileType string, probeJSON FFProbeJSON) int {
 	for i, stream := range probeJSON.Streams {
 		if stream.CodecType == fileType {
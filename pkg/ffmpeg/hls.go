@@ -3,12 +3,83 @@ package ffmpeg
 import (
 	"fmt"
 	"io"
-	"strings"
+
+	"github.com/stashapp/stash/pkg/models"
 )
 
 const hlsSegmentLength = 10.0
 
-func WriteHLSPlaylist(probeResult VideoFile, baseUrl string, w io.Writer) {
+// transcodeVideoBitrates are the maximum video bitrates of segmented
+// transcodes for each resolution.
+var transcodeVideoBitrates = map[models.StreamingResolutionEnum]int64{
+	models.StreamingResolutionEnumLow:        400000,
+	models.StreamingResolutionEnumStandard:   1200000,
+	models.StreamingResolutionEnumStandardHd: 2800000,
+	models.StreamingResolutionEnumFullHd:     5000000,
+	models.StreamingResolutionEnumFourK:      16000000,
+}
+
+const transcodeAudioBitrate = 128000
+
+// getTranscodeVideoBitrate returns the maximum video bitrate of a segmented
+// transcode of the video file at the provided resolution. The bitrate of the
+// source file is used if it is lower. Returns 0 if the bitrate is unknown.
+func getTranscodeVideoBitrate(probeResult VideoFile, maxTranscodeSize models.StreamingResolutionEnum) int64 {
+	sourceBitrate := probeResult.VideoBitrate
+	if sourceBitrate == 0 {
+		sourceBitrate = probeResult.Bitrate
+	}
+
+	ret := transcodeVideoBitrates[maxTranscodeSize]
+	if sourceBitrate > 0 && (ret == 0 || sourceBitrate < ret) {
+		ret = sourceBitrate
+	}
+
+	return ret
+}
+
+// HLSRendition is a variant stream of an HLS master playlist.
+type HLSRendition struct {
+	Width     int
+	Height    int
+	Bandwidth int64
+	URL       string
+}
+
+// GetHLSRendition returns the rendition of the video file transcoded to the
+// provided resolution, served from the provided URL.
+func GetHLSRendition(probeResult VideoFile, maxTranscodeSize models.StreamingResolutionEnum, url string) HLSRendition {
+	width, height := calculateTranscodeDimensions(probeResult, maxTranscodeSize)
+
+	bandwidth := getTranscodeVideoBitrate(probeResult, maxTranscodeSize)
+	if bandwidth == 0 {
+		bandwidth = transcodeVideoBitrates[models.StreamingResolutionEnumFourK]
+	}
+
+	return HLSRendition{
+		Width:     width,
+		Height:    height,
+		Bandwidth: bandwidth + transcodeAudioBitrate,
+		URL:       url,
+	}
+}
+
+// WriteHLSMasterPlaylist writes a master playlist containing the provided
+// renditions.
+func WriteHLSMasterPlaylist(renditions []HLSRendition, w io.Writer) {
+	fmt.Fprint(w, "#EXTM3U\n")
+	fmt.Fprint(w, "#EXT-X-VERSION:3\n")
+
+	for _, r := range renditions {
+		fmt.Fprintf(w, "#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d\n", r.Bandwidth, r.Width, r.Height)
+		fmt.Fprintf(w, "%s\n", r.URL)
+	}
+}
+
+// WriteHLSPlaylist writes a VOD playlist of the video file, split into
+// segments of hlsSegmentLength seconds. segmentURL returns the URL of the
+// segment with the provided index.
+func WriteHLSPlaylist(probeResult VideoFile, segmentURL func(segment int) string, w io.Writer) {
 	fmt.Fprint(w, "#EXTM3U\n")
 	fmt.Fprint(w, "#EXT-X-VERSION:3\n")
 	fmt.Fprint(w, "#EXT-X-MEDIA-SEQUENCE:0\n")
@@ -19,11 +90,7 @@ func WriteHLSPlaylist(probeResult VideoFile, baseUrl string, w io.Writer) {
 	duration := probeResult.Duration
 
 	leftover := duration
-	upTo := 0.0
-
-	tsURL := baseUrl
-	i := strings.LastIndex(baseUrl, ".m3u8")
-	tsURL = baseUrl[0:i] + ".ts"
+	segment := 0
 
 	for leftover > 0 {
 		thisLength := hlsSegmentLength
@@ -32,10 +99,10 @@ func WriteHLSPlaylist(probeResult VideoFile, baseUrl string, w io.Writer) {
 		}
 
 		fmt.Fprintf(w, "#EXTINF: %f,\n", thisLength)
-		fmt.Fprintf(w, "%s?start=%f\n", tsURL, upTo)
+		fmt.Fprintf(w, "%s\n", segmentURL(segment))
 
 		leftover -= thisLength
-		upTo += thisLength
+		segment++
 	}
 
 	fmt.Fprint(w, "#EXT-X-ENDLIST\n")
@@ -0,0 +1,796 @@
+package ffmpeg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+const (
+	// maxSegmentGap is the number of segments ahead of the transcode
+	// position that will be waited for. Requests for segments further ahead
+	// start a new transcode session at the requested segment.
+	maxSegmentGap = 3
+
+	segmentWaitTimeout  = 30 * time.Second
+	segmentPollInterval = 200 * time.Millisecond
+	// segmentTranscodeIdle is the time after which the transcode of a session
+	// that is not being read is stopped. The session may then be restarted
+	// at another segment.
+	segmentTranscodeIdle = 30 * time.Second
+	segmentSessionIdle   = 2 * time.Minute
+	segmentCleanInterval = 10 * time.Second
+
+	segmentPlaylistName = "playlist.m3u8"
+	segmentInitName     = "init.mp4"
+
+	// initSegment is the index used for the initialisation segment of
+	// fragmented MP4 transcodes.
+	initSegment = -1
+)
+
+// ErrTranscodeUnavailable is returned when a transcode session could not
+// acquire a transcode slot.
+var ErrTranscodeUnavailable = errors.New("transcode unavailable")
+
+var errSessionKilled = errors.New("transcode session was killed")
+
+// errSegmentNotInSession is returned when a segment will not be produced by
+// the running transcode of a session, so it must be served by another
+// session.
+var errSegmentNotInSession = errors.New("segment is not produced by the transcode session")
+
+// AcquireTranscodeFunc is called before the transcode of a session is
+// started. It blocks until the transcode may start, and returns a function
+// that must be called once the transcode has ended. kill stops the transcode
+// session. stop stops the transcode, but keeps the session so that its
+// segments may still be served.
+type AcquireTranscodeFunc func(ctx context.Context, kill func(), stop func()) (release func(), err error)
+
+// SegmentFormat is the container format of the segments of a segmented
+// transcode.
+type SegmentFormat string
+
+const (
+	// SegmentFormatMpegts segments are MPEG transport streams, as used by
+	// HLS.
+	SegmentFormatMpegts SegmentFormat = "ts"
+	// SegmentFormatFMP4 segments are fragmented MP4, as used by DASH. The
+	// media segments are preceded by an initialisation segment.
+	SegmentFormatFMP4 SegmentFormat = "m4s"
+)
+
+func (f SegmentFormat) mimeType() string {
+	if f == SegmentFormatFMP4 {
+		return MimeMp4
+	}
+
+	return MimeMpegts
+}
+
+// SegmentOptions are the options for a segmented transcode of a video file.
+type SegmentOptions struct {
+	ProbeResult      VideoFile
+	Codec            Codec
+	Format           SegmentFormat
+	MaxTranscodeSize models.StreamingResolutionEnum
+	// transcode the video, remove the audio
+	VideoOnly bool
+	// index of the audio stream to transcode. The default audio stream is
+	// used if nil
+	AudioStreamIndex *int
+	// Acquire is called before the transcode of the session is started, if
+	// set. The slot is released when the transcode ends or is stopped.
+	Acquire AcquireTranscodeFunc
+}
+
+func (o SegmentOptions) key() string {
+	ret := utils.MD5FromString(o.ProbeResult.Path) + "_" + o.Codec.Codec + "_" + string(o.Format) + "_" + string(o.MaxTranscodeSize)
+	if o.AudioStreamIndex != nil && !o.VideoOnly {
+		ret += "_a" + strconv.Itoa(*o.AudioStreamIndex)
+	}
+
+	return ret
+}
+
+// segmentCodecArgs returns the extra arguments of the codec, excluding the
+// audio codec and container flags, which are set by the segmented transcode.
+func segmentCodecArgs(codec Codec) []string {
+	var ret []string
+	for i := 0; i < len(codec.extraArgs); i++ {
+		switch codec.extraArgs[i] {
+		case "-acodec", "-c:a", "-movflags":
+			// skip the value as well
+			i++
+		default:
+			ret = append(ret, codec.extraArgs[i])
+		}
+	}
+
+	return ret
+}
+
+func (o SegmentOptions) getArgs(dir string, startSegment int) []string {
+	startTime := float64(startSegment) * hlsSegmentLength
+
+	args := []string{
+		"-hide_banner",
+		"-v", "error",
+	}
+
+	if startSegment > 0 {
+		args = append(args, "-ss", fmt.Sprintf("%v", startTime))
+	}
+
+	args = append(args,
+		"-i", o.ProbeResult.Path,
+	)
+
+	if o.VideoOnly {
+		args = append(args, "-an")
+	} else {
+		args = append(args, getAudioStreamArgs(o.AudioStreamIndex)...)
+	}
+
+	scale := calculateTranscodeScale(o.ProbeResult, o.MaxTranscodeSize)
+	args = append(args,
+		"-c:v", o.Codec.Codec,
+		"-vf", "scale="+scale,
+	)
+	args = append(args, segmentCodecArgs(o.Codec)...)
+
+	// the codecs advertised in the DASH manifest depend on the profile
+	if o.Format == SegmentFormatFMP4 {
+		_, height := calculateTranscodeDimensions(o.ProbeResult, o.MaxTranscodeSize)
+		args = append(args, getDASHVideoProfile(o.Codec, height).args...)
+	}
+
+	// limit the bitrate so that it matches the advertised bandwidth
+	if bitrate := getTranscodeVideoBitrate(o.ProbeResult, o.MaxTranscodeSize); bitrate > 0 {
+		args = append(args,
+			"-maxrate", strconv.FormatInt(bitrate, 10),
+			"-bufsize", strconv.FormatInt(bitrate*2, 10),
+		)
+	}
+
+	if !o.VideoOnly {
+		args = append(args,
+			"-c:a", "aac",
+			"-ac", "2",
+		)
+	}
+
+	args = append(args,
+		// force keyframes at the segment boundaries so that segments from
+		// different sessions line up
+		"-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%d)", int(hlsSegmentLength)),
+		"-output_ts_offset", fmt.Sprintf("%v", startTime),
+		"-f", "hls",
+		"-hls_time", strconv.Itoa(int(hlsSegmentLength)),
+		"-hls_list_size", "0",
+	)
+
+	// the playlist written by ffmpeg is only used to determine which
+	// segments are complete, so the hls muxer is used for both formats
+	if o.Format == SegmentFormatFMP4 {
+		args = append(args,
+			"-hls_segment_type", "fmp4",
+			"-hls_fmp4_init_filename", segmentInitName,
+		)
+	} else {
+		args = append(args, "-hls_segment_type", "mpegts")
+	}
+
+	args = append(args,
+		// segments are renamed once written, so that segments being served
+		// are not overwritten by a restarted transcode
+		"-hls_flags", "temp_file",
+		"-start_number", strconv.Itoa(startSegment),
+		"-hls_segment_filename", filepath.Join(dir, "%d."+string(o.Format)),
+		filepath.Join(dir, segmentPlaylistName),
+	)
+
+	return args
+}
+
+type segmentSession struct {
+	dir     string
+	encoder Encoder
+	options SegmentOptions
+
+	mutex        sync.Mutex
+	cmd          *exec.Cmd
+	stderr       *bytes.Buffer
+	done         chan struct{}
+	startSegment int
+	completed    map[int]bool
+	lastAccess   time.Time
+	release      func()
+	killed       bool
+
+	// onKill is called after the session is killed
+	onKill func()
+}
+
+func (s *segmentSession) segmentPath(segment int) string {
+	if segment == initSegment {
+		return filepath.Join(s.dir, segmentInitName)
+	}
+
+	return filepath.Join(s.dir, strconv.Itoa(segment)+"."+string(s.options.Format))
+}
+
+func (s *segmentSession) isRunning() bool {
+	if s.done == nil {
+		return false
+	}
+
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// start starts the transcode at the provided segment, stopping the current
+// transcode if running. Must be called with the mutex held.
+func (s *segmentSession) start(segment int) error {
+	s.stop()
+
+	if err := utils.EnsureDirAll(s.dir); err != nil {
+		return err
+	}
+
+	// remove the playlist of the previous transcode so that it is not
+	// mistaken for the new one
+	_ = os.Remove(filepath.Join(s.dir, segmentPlaylistName))
+
+	args := s.options.getArgs(s.dir, segment)
+	cmd := exec.Command(s.encoder.Path, args...)
+	logger.Debugf("[stream] starting segmented transcode via: %s", strings.Join(cmd.Args, " "))
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	path := s.options.ProbeResult.Path
+	registerRunningEncoder(path, cmd.Process)
+
+	done := make(chan struct{})
+	go func() {
+		if err := waitAndDeregister(path, cmd); err != nil && stderr.Len() > 0 {
+			logger.Debugf("[stream] ffmpeg stderr: %s", stderr.String())
+		}
+		close(done)
+
+		// release the slot if the transcode ended by itself. Transcodes that
+		// were stopped or restarted have replaced done.
+		s.mutex.Lock()
+		if s.done == done {
+			s.releaseSlot()
+		}
+		s.mutex.Unlock()
+	}()
+
+	s.cmd = cmd
+	s.stderr = &stderr
+	s.done = done
+	s.startSegment = segment
+
+	return nil
+}
+
+// stop kills the transcode if it is running. Must be called with the mutex
+// held.
+func (s *segmentSession) stop() {
+	if !s.isRunning() {
+		return
+	}
+
+	_ = s.cmd.Process.Kill()
+	<-s.done
+}
+
+// acquire acquires a transcode slot for the session if it does not hold one.
+func (s *segmentSession) acquire(ctx context.Context) error {
+	s.mutex.Lock()
+	if s.killed {
+		s.mutex.Unlock()
+		return errSessionKilled
+	}
+	s.lastAccess = time.Now()
+	acquired := s.release != nil
+	s.mutex.Unlock()
+
+	if acquired || s.options.Acquire == nil {
+		return nil
+	}
+
+	// the mutex is not held while waiting for a slot, so that other
+	// sessions are not blocked
+	release, err := s.options.Acquire(ctx, s.kill, s.stopTranscode)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrTranscodeUnavailable, err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.killed {
+		// killed or removed while waiting
+		release()
+		return errSessionKilled
+	}
+
+	if s.release != nil {
+		// acquired by a concurrent request
+		release()
+		return nil
+	}
+
+	s.release = release
+	return nil
+}
+
+// releaseSlot stops the transcode and releases the transcode slot of the
+// session. The transcode is restarted by the next request for a segment.
+// Must be called with the mutex held.
+func (s *segmentSession) releaseSlot() {
+	if s.isRunning() {
+		s.stop()
+		// stopped rather than ended, so the transcode may be restarted
+		s.done = nil
+	}
+
+	if s.release != nil {
+		s.release()
+		s.release = nil
+	}
+}
+
+// stopTranscode stops the transcode and releases the transcode slot of the
+// session. Segments that have been written may still be served.
+func (s *segmentSession) stopTranscode() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.releaseSlot()
+}
+
+// kill stops the transcode and prevents it from being restarted.
+func (s *segmentSession) kill() {
+	s.mutex.Lock()
+	s.killed = true
+	s.releaseSlot()
+	s.mutex.Unlock()
+
+	// called without the mutex held, since the session manager locks its
+	// own mutex before the mutexes of its sessions
+	if s.onKill != nil {
+		s.onKill()
+	}
+}
+
+// refreshCompleted adds the segments that have been completely written to
+// the completed set. Must be called with the mutex held.
+func (s *segmentSession) refreshCompleted() {
+	f, err := os.Open(filepath.Join(s.dir, segmentPlaylistName))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	// the playlist only contains segments that have been completely written
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		segment, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(line), "."+string(s.options.Format)))
+		if err == nil {
+			s.completed[segment] = true
+		}
+	}
+}
+
+// latestSegment returns the latest segment written by the current
+// transcode. Must be called with the mutex held.
+func (s *segmentSession) latestSegment() int {
+	ret := s.startSegment - 1
+	for s.completed[ret+1] {
+		ret++
+	}
+
+	return ret
+}
+
+// hasSegment returns true if the segment has been written by the session,
+// or will be written by its transcode soon. Segments are not served by
+// sessions that would need to be restarted to produce them, since they may
+// be in use by other readers.
+func (s *segmentSession) hasSegment(segment int) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.killed {
+		return false
+	}
+
+	if segment == initSegment {
+		// the initialisation segment is the same for all sessions
+		return true
+	}
+
+	s.refreshCompleted()
+	return s.completed[segment] || (segment >= s.startSegment && segment <= s.latestSegment()+maxSegmentGap)
+}
+
+// getSegment returns the path of the provided segment, waiting for it to be
+// generated if necessary.
+func (s *segmentSession) getSegment(ctx context.Context, segment int) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, segmentWaitTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(segmentPollInterval)
+	defer ticker.Stop()
+
+	for {
+		ready, start, err := s.checkSegment(segment)
+		if err != nil {
+			return "", err
+		}
+
+		if ready {
+			return s.segmentPath(segment), nil
+		}
+
+		// a transcode slot is only needed to run the transcode, so written
+		// segments are served without one
+		if start {
+			if err := s.acquire(ctx); err != nil {
+				return "", err
+			}
+
+			if err := s.startAt(segment); err != nil {
+				return "", err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkSegment returns true if the segment is ready. start is true if the
+// transcode is not running and must be started at the segment. Returns
+// errSegmentNotInSession if the running transcode will not produce the
+// segment soon.
+func (s *segmentSession) checkSegment(segment int) (ready bool, start bool, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.killed {
+		return false, false, errSessionKilled
+	}
+
+	s.lastAccess = time.Now()
+
+	s.refreshCompleted()
+
+	if segment == initSegment {
+		return s.checkInitSegment()
+	}
+
+	if s.completed[segment] {
+		return true, false, nil
+	}
+
+	running := s.isRunning()
+	if running && segment >= s.startSegment && segment <= s.latestSegment()+maxSegmentGap {
+		// the current transcode will reach the segment shortly
+		return false, false, nil
+	}
+
+	if !running && s.done != nil && segment >= s.startSegment && segment <= s.latestSegment()+1 {
+		// the transcode ended without producing the segment. Reset so that
+		// the next request restarts the transcode.
+		s.done = nil
+		return false, false, fmt.Errorf("transcode ended before segment %d was generated: %s", segment, s.stderr.String())
+	}
+
+	if running {
+		// other readers are using the transcode, so it is not restarted
+		return false, false, errSegmentNotInSession
+	}
+
+	return false, true, nil
+}
+
+// checkInitSegment returns true if the initialisation segment is ready, or
+// if the transcode must be started to generate it. Must be called with the
+// mutex held.
+func (s *segmentSession) checkInitSegment() (ready bool, start bool, err error) {
+	// the initialisation segment is written before the first media segment
+	if len(s.completed) > 0 {
+		if exists, _ := utils.FileExists(s.segmentPath(initSegment)); exists {
+			return true, false, nil
+		}
+	}
+
+	if s.isRunning() {
+		return false, false, nil
+	}
+
+	if s.done != nil {
+		s.done = nil
+		return false, false, fmt.Errorf("transcode ended before the initialisation segment was generated: %s", s.stderr.String())
+	}
+
+	return false, true, nil
+}
+
+// startAt starts the transcode at the segment, unless it was started by a
+// concurrent request while the transcode slot was acquired.
+func (s *segmentSession) startAt(segment int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.killed {
+		return errSessionKilled
+	}
+
+	if s.isRunning() {
+		return nil
+	}
+
+	if segment == initSegment {
+		segment = 0
+	}
+
+	logger.Debugf("[stream] starting segmented transcode of %s at segment %d", s.options.ProbeResult.Path, segment)
+	return s.start(segment)
+}
+
+// SegmentManager manages segmented transcodes of video files. Sessions are
+// shared between clients reading the same file at the same resolution, and a
+// new session is started for each position that is not produced by an
+// existing session. Segments are written to the cache directory and sessions
+// are removed after a period of inactivity.
+type SegmentManager struct {
+	cacheDir string
+
+	mutex    sync.Mutex
+	sessions map[string][]*segmentSession
+	nextID   int
+	stopChan chan struct{}
+}
+
+// NewSegmentManager returns a new SegmentManager that writes segments to the
+// provided directory. Any existing contents of the directory are removed.
+func NewSegmentManager(cacheDir string) *SegmentManager {
+	if err := os.RemoveAll(cacheDir); err != nil {
+		logger.Warnf("could not clear stream cache directory %s: %s", cacheDir, err.Error())
+	}
+
+	ret := &SegmentManager{
+		cacheDir: cacheDir,
+		sessions: make(map[string][]*segmentSession),
+		stopChan: make(chan struct{}),
+	}
+
+	go ret.cleanIdleSessions()
+
+	return ret
+}
+
+// CacheDir returns the directory that segments are written to.
+func (m *SegmentManager) CacheDir() string {
+	return m.cacheDir
+}
+
+// getSession returns the session that has written or will soon write the
+// segment, creating a new session starting at the segment if there is none.
+func (m *SegmentManager) getSession(encoder Encoder, options SegmentOptions, segment int) *segmentSession {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := options.key()
+	for _, session := range m.sessions[key] {
+		if session.hasSegment(segment) {
+			return session
+		}
+	}
+
+	startSegment := segment
+	if segment == initSegment {
+		startSegment = 0
+	}
+
+	m.nextID++
+	session := &segmentSession{
+		dir:          filepath.Join(m.cacheDir, key, strconv.Itoa(m.nextID)),
+		encoder:      encoder,
+		options:      options,
+		startSegment: startSegment,
+		completed:    make(map[int]bool),
+		lastAccess:   time.Now(),
+	}
+	session.onKill = func() {
+		m.removeSession(key, session)
+	}
+	m.sessions[key] = append(m.sessions[key], session)
+
+	return session
+}
+
+// removeSession removes the session and its segments.
+func (m *SegmentManager) removeSession(key string, session *segmentSession) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.deleteSession(key, session)
+}
+
+// deleteSession removes the session and its segments. Must be called with
+// the mutex held.
+func (m *SegmentManager) deleteSession(key string, session *segmentSession) {
+	sessions := m.sessions[key]
+	for i, s := range sessions {
+		if s == session {
+			sessions = append(sessions[:i], sessions[i+1:]...)
+			break
+		}
+	}
+
+	if err := os.RemoveAll(session.dir); err != nil {
+		logger.Warnf("could not remove stream cache directory %s: %s", session.dir, err.Error())
+	}
+
+	if len(sessions) > 0 {
+		m.sessions[key] = sessions
+		return
+	}
+
+	delete(m.sessions, key)
+	dir := filepath.Join(m.cacheDir, key)
+	if err := os.RemoveAll(dir); err != nil {
+		logger.Warnf("could not remove stream cache directory %s: %s", dir, err.Error())
+	}
+}
+
+// ServeSegment serves the segment with the provided index, starting or
+// restarting the transcode session as needed.
+func (m *SegmentManager) ServeSegment(w http.ResponseWriter, r *http.Request, encoder Encoder, options SegmentOptions, segment int) {
+	if segment < 0 || float64(segment)*hlsSegmentLength >= options.ProbeResult.Duration {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	m.serveSegment(w, r, encoder, options, segment)
+}
+
+// ServeInitSegment serves the initialisation segment of a fragmented MP4
+// transcode, starting the transcode session as needed.
+func (m *SegmentManager) ServeInitSegment(w http.ResponseWriter, r *http.Request, encoder Encoder, options SegmentOptions) {
+	if options.Format != SegmentFormatFMP4 {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	m.serveSegment(w, r, encoder, options, initSegment)
+}
+
+func (m *SegmentManager) serveSegment(w http.ResponseWriter, r *http.Request, encoder Encoder, options SegmentOptions, segment int) {
+	var path string
+	var err error
+	for {
+		session := m.getSession(encoder, options, segment)
+		path, err = session.getSegment(r.Context(), segment)
+		if !errors.Is(err, errSegmentNotInSession) {
+			break
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+
+		if errors.Is(err, ErrTranscodeUnavailable) {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		if errors.Is(err, errSessionKilled) {
+			http.Error(w, err.Error(), http.StatusGone)
+			return
+		}
+
+		logger.Errorf("[stream] error getting segment %d of %s: %s", segment, options.ProbeResult.Path, err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", options.Format.mimeType())
+	http.ServeFile(w, r, path)
+}
+
+func (m *SegmentManager) cleanIdleSessions() {
+	ticker := time.NewTicker(segmentCleanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+		}
+
+		m.mutex.Lock()
+		for key, sessions := range m.sessions {
+			// copied, since idle sessions are removed from the slice
+			for _, session := range append([]*segmentSession{}, sessions...) {
+				session.mutex.Lock()
+				idle := time.Since(session.lastAccess)
+				switch {
+				case idle > segmentSessionIdle:
+					logger.Debugf("[stream] removing idle transcode session of %s", session.options.ProbeResult.Path)
+					session.killed = true
+					session.releaseSlot()
+					m.deleteSession(key, session)
+				case idle > segmentTranscodeIdle && session.isRunning():
+					logger.Debugf("[stream] stopping idle transcode of %s", session.options.ProbeResult.Path)
+					session.releaseSlot()
+				}
+				session.mutex.Unlock()
+			}
+		}
+		m.mutex.Unlock()
+	}
+}
+
+// Shutdown stops all running transcode sessions and removes the cached
+// segments.
+func (m *SegmentManager) Shutdown() {
+	close(m.stopChan)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for key, sessions := range m.sessions {
+		for _, session := range sessions {
+			session.mutex.Lock()
+			session.killed = true
+			session.releaseSlot()
+			session.mutex.Unlock()
+		}
+		delete(m.sessions, key)
+	}
+
+	if err := os.RemoveAll(m.cacheDir); err != nil {
+		logger.Warnf("could not clear stream cache directory %s: %s", m.cacheDir, err.Error())
+	}
+}
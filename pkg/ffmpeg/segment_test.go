@@ -0,0 +1,195 @@
+package ffmpeg
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentManagerGetSession(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-segments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := &SegmentManager{
+		cacheDir: dir,
+		sessions: make(map[string][]*segmentSession),
+	}
+
+	options := SegmentOptions{
+		ProbeResult: VideoFile{Path: "video.mp4"},
+		Codec:       CodecHLS,
+		Format:      SegmentFormatMpegts,
+	}
+
+	first := m.getSession(Encoder{}, options, 0)
+	assert.Equal(t, 0, first.startSegment)
+	first.completed[0] = true
+	first.completed[1] = true
+
+	// segments written or soon to be written by the session are shared
+	assert.Same(t, first, m.getSession(Encoder{}, options, 1))
+	assert.Same(t, first, m.getSession(Encoder{}, options, 1+maxSegmentGap))
+
+	// seeking ahead starts a new session rather than restarting the first
+	seek := m.getSession(Encoder{}, options, 50)
+	assert.NotSame(t, first, seek)
+	assert.Equal(t, 50, seek.startSegment)
+	assert.NotEqual(t, first.dir, seek.dir)
+	assert.Same(t, first, m.getSession(Encoder{}, options, 0))
+	assert.Same(t, seek, m.getSession(Encoder{}, options, 51))
+
+	// other files have their own sessions
+	other := options
+	other.ProbeResult.Path = "other.mp4"
+	assert.NotSame(t, first, m.getSession(Encoder{}, other, 0))
+
+	// killed sessions are removed
+	lastAccess := first.lastAccess
+	first.kill()
+	assert.Len(t, m.sessions[options.key()], 1)
+	assert.Equal(t, errSessionKilled, first.acquire(context.Background()))
+	assert.Equal(t, lastAccess, first.lastAccess)
+
+	replaced := m.getSession(Encoder{}, options, 0)
+	assert.NotSame(t, first, replaced)
+	assert.Len(t, m.sessions[options.key()], 2)
+
+	seek.kill()
+	replaced.kill()
+	_, found := m.sessions[options.key()]
+	assert.False(t, found)
+}
+
+func TestSegmentSessionAcquire(t *testing.T) {
+	released := 0
+	session := &segmentSession{
+		options: SegmentOptions{
+			Acquire: func(ctx context.Context, kill func(), stop func()) (func(), error) {
+				return func() { released++ }, nil
+			},
+		},
+		completed:  make(map[int]bool),
+		lastAccess: time.Now().Add(-time.Hour),
+	}
+
+	assert.Nil(t, session.acquire(context.Background()))
+	assert.NotNil(t, session.release)
+	assert.WithinDuration(t, time.Now(), session.lastAccess, time.Minute)
+
+	session.kill()
+	assert.Equal(t, 1, released)
+	assert.Nil(t, session.release)
+}
+
+// writeTestEncoder writes a script that imitates the segmented transcode of
+// ffmpeg, writing segments segments from the start segment. If wait is true,
+// the script keeps running until killed.
+func writeTestEncoder(t *testing.T, dir string, segments int, wait bool) Encoder {
+	if runtime.GOOS == "windows" {
+		t.Skip("test encoder requires a posix shell")
+	}
+
+	script := `#!/bin/sh
+while [ $# -gt 1 ]; do
+	case "$1" in
+		-start_number) start=$2; shift;;
+		-hls_segment_filename) pattern=$2; shift;;
+	esac
+	shift
+done
+echo "#EXTM3U" > "$1"
+i=$start
+while [ $i -lt $((start+` + strconv.Itoa(segments) + `)) ]; do
+	f=$(printf "$pattern" $i)
+	touch "$f"
+	basename "$f" >> "$1"
+	i=$((i+1))
+done
+`
+	if wait {
+		script += "exec sleep 60\n"
+	}
+
+	fn := filepath.Join(dir, "ffmpeg.sh")
+	if err := ioutil.WriteFile(fn, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	return Encoder{Path: fn}
+}
+
+func TestSegmentSessionReleaseEnded(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-segments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var mutex sync.Mutex
+	slots := 0
+	available := true
+
+	session := &segmentSession{
+		dir:     filepath.Join(dir, "session"),
+		encoder: writeTestEncoder(t, dir, 2, false),
+		options: SegmentOptions{
+			ProbeResult: VideoFile{Path: "video.mp4", Duration: 100},
+			Codec:       CodecHLS,
+			Format:      SegmentFormatMpegts,
+			Acquire: func(ctx context.Context, kill func(), stop func()) (func(), error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				if !available {
+					return nil, errors.New("no slots")
+				}
+
+				slots++
+				return func() {
+					mutex.Lock()
+					defer mutex.Unlock()
+					slots--
+				}, nil
+			},
+		},
+		completed: make(map[int]bool),
+	}
+
+	path, err := session.getSegment(context.Background(), 0)
+	if assert.Nil(t, err) {
+		assert.Equal(t, session.segmentPath(0), path)
+	}
+
+	// the slot is released once the transcode ends by itself
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return slots == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// written segments are served without a slot
+	mutex.Lock()
+	available = false
+	mutex.Unlock()
+
+	path, err = session.getSegment(context.Background(), 1)
+	if assert.Nil(t, err) {
+		assert.Equal(t, session.segmentPath(1), path)
+	}
+
+	// segments that must be transcoded need a slot
+	_, err = session.getSegment(context.Background(), 5)
+	assert.True(t, errors.Is(err, ErrTranscodeUnavailable))
+}
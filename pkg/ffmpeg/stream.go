@@ -124,6 +124,46 @@ var CodecMKVAudio = Codec{
 	},
 }
 
+// copy the video stream, transcode the audio and serve as MPEG-TS. Used to
+// serve a selected audio track to clients that cannot select it themselves
+var CodecMpegtsAudio = Codec{
+	Codec:    CopyStreamCodec,
+	format:   "mpegts",
+	MimeType: MimeMpegts,
+	extraArgs: []string{
+		"-c:a", "aac",
+	},
+}
+
+// transcode the video stream to H264 and the audio stream to AAC, and serve
+// as MPEG-TS. Used instead of CodecMpegtsAudio when the video stream cannot
+// be copied into MPEG-TS
+var CodecMpegtsH264Audio = Codec{
+	Codec:    "libx264",
+	format:   "mpegts",
+	MimeType: MimeMpegts,
+	extraArgs: []string{
+		"-c:a", "aac",
+		"-pix_fmt", "yuv420p",
+		"-preset", "veryfast",
+		"-crf", "25",
+	},
+}
+
+// video codecs that can be copied into MPEG-TS
+var validVideoForMpegts = []string{H264, H265, Hevc, "mpeg2video", "mpeg1video"}
+
+// GetAudioTrackCodec returns the codec used to serve the video file with a
+// selected audio track. The video stream is copied if MPEG-TS supports its
+// codec, and transcoded otherwise.
+func GetAudioTrackCodec(probeResult VideoFile) Codec {
+	if IsValidCodec(probeResult.VideoCodec, validVideoForMpegts) {
+		return CodecMpegtsAudio
+	}
+
+	return CodecMpegtsH264Audio
+}
+
 type TranscodeStreamOptions struct {
 	ProbeResult      VideoFile
 	Codec            Codec
@@ -133,6 +173,23 @@ type TranscodeStreamOptions struct {
 	// in some videos where the audio codec is not supported by ffmpeg
 	// ffmpeg fails if you try to transcode the audio
 	VideoOnly bool
+	// index of the audio stream to transcode. The default audio stream is
+	// used if nil
+	AudioStreamIndex *int
+}
+
+// getAudioStreamArgs returns the arguments that select the audio stream with
+// the provided index. The first video stream is selected as well, since
+// selecting any stream disables the default stream selection.
+func getAudioStreamArgs(audioStreamIndex *int) []string {
+	if audioStreamIndex == nil {
+		return nil
+	}
+
+	return []string{
+		"-map", "0:v:0",
+		"-map", "0:" + strconv.Itoa(*audioStreamIndex),
+	}
 }
 
 func GetTranscodeStreamOptions(probeResult VideoFile, videoCodec Codec, audioCodec AudioCodec) TranscodeStreamOptions {
@@ -170,6 +227,8 @@ func (o TranscodeStreamOptions) getStreamArgs() []string {
 
 	if o.VideoOnly {
 		args = append(args, "-an")
+	} else {
+		args = append(args, getAudioStreamArgs(o.AudioStreamIndex)...)
 	}
 
 	args = append(args,
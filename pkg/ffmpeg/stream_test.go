@@ -0,0 +1,44 @@
+package ffmpeg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAudioTrackCodec(t *testing.T) {
+	tests := []struct {
+		videoCodec string
+		want       Codec
+	}{
+		{H264, CodecMpegtsAudio},
+		{Hevc, CodecMpegtsAudio},
+		{"mpeg2video", CodecMpegtsAudio},
+		{Vp8, CodecMpegtsH264Audio},
+		{Vp9, CodecMpegtsH264Audio},
+		{"av1", CodecMpegtsH264Audio},
+		{"wmv3", CodecMpegtsH264Audio},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, GetAudioTrackCodec(VideoFile{VideoCodec: tt.videoCodec}), tt.videoCodec)
+	}
+}
+
+func TestAudioTrackStreamArgs(t *testing.T) {
+	audioStreamIndex := 2
+
+	// the video stream of webm files is transcoded
+	options := GetTranscodeStreamOptions(VideoFile{Path: "video.webm", VideoCodec: Vp9, Width: 1920, Height: 1080}, CodecMpegtsH264Audio, Opus)
+	options.AudioStreamIndex = &audioStreamIndex
+	args := strings.Join(options.getStreamArgs(), " ")
+	assert.Contains(t, args, "-c:v libx264 -vf scale=")
+	assert.Contains(t, args, "-c:a aac")
+	assert.Contains(t, args, "-f mpegts pipe:")
+
+	options.Codec = CodecMpegtsAudio
+	args = strings.Join(options.getStreamArgs(), " ")
+	assert.Contains(t, args, "-c:v copy -c:a aac")
+	assert.NotContains(t, args, "scale=")
+}
@@ -26,8 +26,9 @@ type FFProbeJSON struct {
 			Comment          string          `json:"comment"`
 		} `json:"tags"`
 	} `json:"format"`
-	Streams []FFProbeStream `json:"streams"`
-	Error   struct {
+	Streams  []FFProbeStream  `json:"streams"`
+	Chapters []FFProbeChapter `json:"chapters"`
+	Error    struct {
 		Code   int    `json:"code"`
 		String string `json:"string"`
 	} `json:"error"`
@@ -92,3 +93,15 @@ type FFProbeStream struct {
 	SampleFmt     string `json:"sample_fmt,omitempty"`
 	SampleRate    string `json:"sample_rate,omitempty"`
 }
+
+type FFProbeChapter struct {
+	ID        int    `json:"id"`
+	TimeBase  string `json:"time_base"`
+	Start     int64  `json:"start"`
+	StartTime string `json:"start_time"`
+	End       int64  `json:"end"`
+	EndTime   string `json:"end_time"`
+	Tags      struct {
+		Title string `json:"title"`
+	} `json:"tags"`
+}
@@ -0,0 +1,198 @@
+package gallery
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+var ErrAncestorOfSelf = errors.New("gallery cannot be an ancestor of itself")
+
+// FindFolderParent returns the folder-based gallery of the closest ancestor
+// directory of the path. Returns nil if none of the ancestor directories has
+// a gallery.
+func FindFolderParent(qb models.GalleryReader, path string) (*models.Gallery, error) {
+	dir := filepath.Dir(path)
+	for {
+		g, err := qb.FindByPath(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		if g != nil && !g.Zip {
+			return g, nil
+		}
+
+		parentDir := filepath.Dir(dir)
+		if parentDir == dir {
+			return nil, nil
+		}
+		dir = parentDir
+	}
+}
+
+// SetFolderParent sets the parent of the gallery to the folder-based gallery
+// of its closest ancestor directory, or clears the parent if none of the
+// ancestor directories has a gallery. Galleries within the directory of a
+// folder-based gallery that had the same parent become children of the
+// gallery, so that the hierarchy is independent of the order in which the
+// galleries were created.
+func SetFolderParent(qb models.GalleryReaderWriter, g *models.Gallery) error {
+	if !g.Path.Valid {
+		return nil
+	}
+
+	parentID, err := findFolderParentID(qb, g.Path.String)
+	if err != nil {
+		return err
+	}
+
+	if err := setParent(qb, g, parentID); err != nil {
+		return err
+	}
+
+	if g.Zip {
+		return nil
+	}
+
+	return adoptDescendants(qb, g)
+}
+
+// UpdateFolderParent updates the parent of the gallery after its path was
+// changed from oldPath. The parent is only changed if it is the folder-based
+// gallery of the old path, so that manually set parents are kept.
+func UpdateFolderParent(qb models.GalleryReaderWriter, g *models.Gallery, oldPath string) error {
+	if g.ParentID.Valid {
+		oldParentID, err := findFolderParentID(qb, oldPath)
+		if err != nil {
+			return err
+		}
+
+		if oldParentID != g.ParentID {
+			return nil
+		}
+	}
+
+	return SetFolderParent(qb, g)
+}
+
+// SetMissingFolderParents sets the parent of the galleries without a parent
+// to the folder-based gallery of their closest ancestor directory. This sets
+// the parents of galleries that were created before galleries had parents.
+// Returns the number of galleries that were updated.
+func SetMissingFolderParents(qb models.GalleryReaderWriter) (int, error) {
+	galleries, err := qb.All()
+	if err != nil {
+		return 0, err
+	}
+
+	ret := 0
+	for _, g := range galleries {
+		if g.ParentID.Valid || !g.Path.Valid {
+			continue
+		}
+
+		parentID, err := findFolderParentID(qb, g.Path.String)
+		if err != nil {
+			return ret, err
+		}
+
+		if !parentID.Valid {
+			continue
+		}
+
+		if err := setParent(qb, g, parentID); err != nil {
+			return ret, err
+		}
+		ret++
+	}
+
+	return ret, nil
+}
+
+func findFolderParentID(qb models.GalleryReader, path string) (sql.NullInt64, error) {
+	parent, err := FindFolderParent(qb, path)
+	if err != nil || parent == nil {
+		return sql.NullInt64{}, err
+	}
+
+	return models.NullInt64(int64(parent.ID)), nil
+}
+
+func setParent(qb models.GalleryWriter, g *models.Gallery, parentID sql.NullInt64) error {
+	if parentID == g.ParentID {
+		return nil
+	}
+
+	if _, err := qb.UpdatePartial(models.GalleryPartial{
+		ID:       g.ID,
+		ParentID: &parentID,
+	}); err != nil {
+		return err
+	}
+
+	g.ParentID = parentID
+	return nil
+}
+
+func adoptDescendants(qb models.GalleryReaderWriter, g *models.Gallery) error {
+	sep := string(filepath.Separator)
+	filter := &models.GalleryFilterType{
+		Path: &models.StringCriterionInput{
+			Modifier: models.CriterionModifierMatchesRegex,
+			Value:    "^" + regexp.QuoteMeta(g.Path.String+sep),
+		},
+	}
+	perPage := models.PerPageAll
+	descendants, _, err := qb.Query(filter, &models.FindFilterType{
+		PerPage: &perPage,
+	})
+	if err != nil {
+		return err
+	}
+
+	parentID := models.NullInt64(int64(g.ID))
+	for _, d := range descendants {
+		// only galleries that were attached to the gallery's ancestor are
+		// moved - the others already have a closer or manually set parent
+		if d.ID == g.ID || d.ParentID != g.ParentID {
+			continue
+		}
+
+		if _, err := qb.UpdatePartial(models.GalleryPartial{
+			ID:       d.ID,
+			ParentID: &parentID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateParent returns an error if setting the parent of the gallery would
+// make the gallery an ancestor of itself.
+func ValidateParent(qb models.GalleryReader, id int, parentID sql.NullInt64) error {
+	for parentID.Valid {
+		if parentID.Int64 == int64(id) {
+			return ErrAncestorOfSelf
+		}
+
+		parent, err := qb.Find(int(parentID.Int64))
+		if err != nil {
+			return fmt.Errorf("error finding parent gallery: %s", err.Error())
+		}
+
+		if parent == nil {
+			return fmt.Errorf("parent gallery with id %d not found", parentID.Int64)
+		}
+
+		parentID = parent.ParentID
+	}
+
+	return nil
+}
@@ -0,0 +1,261 @@
+package gallery
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	rootGalleryID = iota + 1
+	childGalleryID
+	grandChildGalleryID
+	siblingGalleryID
+	manualGalleryID
+	newGalleryID
+)
+
+var (
+	rootPath       = filepath.Join("stash", "photos")
+	childPath      = filepath.Join(rootPath, "2020")
+	grandChildPath = filepath.Join(childPath, "summer")
+)
+
+func folderGallery(id int, path string, parentID int) *models.Gallery {
+	ret := &models.Gallery{
+		ID:   id,
+		Path: models.NullString(path),
+	}
+
+	if parentID != 0 {
+		ret.ParentID = models.NullInt64(int64(parentID))
+	}
+
+	return ret
+}
+
+func TestFindFolderParent(t *testing.T) {
+	mockGalleryReader := &mocks.GalleryReaderWriter{}
+
+	root := folderGallery(rootGalleryID, rootPath, 0)
+	zipPath := filepath.Join(rootPath, "archive.zip")
+
+	mockGalleryReader.On("FindByPath", rootPath).Return(root, nil)
+	mockGalleryReader.On("FindByPath", childPath).Return(nil, nil)
+	mockGalleryReader.On("FindByPath", zipPath).Return(&models.Gallery{
+		ID:   childGalleryID,
+		Path: models.NullString(zipPath),
+		Zip:  true,
+	}, nil)
+	mockGalleryReader.On("FindByPath", mock.Anything).Return(nil, nil)
+
+	g, err := FindFolderParent(mockGalleryReader, grandChildPath)
+	assert.Nil(t, err)
+	assert.Equal(t, root, g)
+
+	// archive galleries are not folder galleries
+	g, err = FindFolderParent(mockGalleryReader, filepath.Join(zipPath, "image.jpg"))
+	assert.Nil(t, err)
+	assert.Equal(t, root, g)
+
+	g, err = FindFolderParent(mockGalleryReader, rootPath)
+	assert.Nil(t, err)
+	assert.Nil(t, g)
+
+	mockGalleryReader.AssertExpectations(t)
+}
+
+func TestFindFolderParentError(t *testing.T) {
+	mockGalleryReader := &mocks.GalleryReaderWriter{}
+
+	mockGalleryReader.On("FindByPath", childPath).Return(nil, errors.New("FindByPath error"))
+
+	_, err := FindFolderParent(mockGalleryReader, grandChildPath)
+	assert.NotNil(t, err)
+
+	mockGalleryReader.AssertExpectations(t)
+}
+
+func TestSetFolderParent(t *testing.T) {
+	mockGalleryReaderWriter := &mocks.GalleryReaderWriter{}
+
+	// a gallery is created for the folder between the root and a child
+	// gallery that was created first
+	root := folderGallery(rootGalleryID, rootPath, 0)
+	newGallery := folderGallery(newGalleryID, childPath, 0)
+	grandChild := folderGallery(grandChildGalleryID, grandChildPath, rootGalleryID)
+	manual := folderGallery(manualGalleryID, filepath.Join(childPath, "manual"), siblingGalleryID)
+
+	mockGalleryReaderWriter.On("FindByPath", rootPath).Return(root, nil).Once()
+
+	newParentID := models.NullInt64(rootGalleryID)
+	mockGalleryReaderWriter.On("UpdatePartial", models.GalleryPartial{
+		ID:       newGalleryID,
+		ParentID: &newParentID,
+	}).Return(nil, nil).Once()
+
+	mockGalleryReaderWriter.On("Query", mock.Anything, mock.Anything).Return([]*models.Gallery{
+		newGallery, grandChild, manual,
+	}, 3, nil).Once()
+
+	childParentID := models.NullInt64(newGalleryID)
+	mockGalleryReaderWriter.On("UpdatePartial", models.GalleryPartial{
+		ID:       grandChildGalleryID,
+		ParentID: &childParentID,
+	}).Return(nil, nil).Once()
+
+	err := SetFolderParent(mockGalleryReaderWriter, newGallery)
+	assert.Nil(t, err)
+	assert.Equal(t, newParentID, newGallery.ParentID)
+
+	mockGalleryReaderWriter.AssertExpectations(t)
+}
+
+func TestSetFolderParentArchive(t *testing.T) {
+	mockGalleryReaderWriter := &mocks.GalleryReaderWriter{}
+
+	zipPath := filepath.Join(rootPath, "archive.zip")
+	root := folderGallery(rootGalleryID, rootPath, 0)
+	zipGallery := &models.Gallery{
+		ID:   newGalleryID,
+		Path: models.NullString(zipPath),
+		Zip:  true,
+	}
+
+	mockGalleryReaderWriter.On("FindByPath", rootPath).Return(root, nil).Once()
+
+	parentID := models.NullInt64(rootGalleryID)
+	mockGalleryReaderWriter.On("UpdatePartial", models.GalleryPartial{
+		ID:       newGalleryID,
+		ParentID: &parentID,
+	}).Return(nil, nil).Once()
+
+	// archive galleries do not have children
+	err := SetFolderParent(mockGalleryReaderWriter, zipGallery)
+	assert.Nil(t, err)
+
+	mockGalleryReaderWriter.AssertExpectations(t)
+}
+
+func TestUpdateFolderParent(t *testing.T) {
+	otherPath := filepath.Join("stash", "other")
+	zipPath := filepath.Join(childPath, "archive.zip")
+	movedPath := filepath.Join(otherPath, "archive.zip")
+
+	root := folderGallery(rootGalleryID, rootPath, 0)
+	child := folderGallery(childGalleryID, childPath, rootGalleryID)
+	other := folderGallery(siblingGalleryID, otherPath, 0)
+
+	zipGallery := func(path string, parentID int) *models.Gallery {
+		ret := folderGallery(newGalleryID, path, parentID)
+		ret.Zip = true
+		return ret
+	}
+
+	tests := []struct {
+		name     string
+		gallery  *models.Gallery
+		oldPath  string
+		parentID sql.NullInt64
+	}{
+		{"folder parent", zipGallery(movedPath, childGalleryID), zipPath, models.NullInt64(siblingGalleryID)},
+		{"no parent", zipGallery(movedPath, 0), zipPath, models.NullInt64(siblingGalleryID)},
+		{"manual parent", zipGallery(movedPath, manualGalleryID), zipPath, models.NullInt64(manualGalleryID)},
+		{"moved out of folders", zipGallery(filepath.Join("archive.zip"), childGalleryID), zipPath, sql.NullInt64{}},
+	}
+
+	for _, tt := range tests {
+		mockGalleryReaderWriter := &mocks.GalleryReaderWriter{}
+
+		mockGalleryReaderWriter.On("FindByPath", rootPath).Return(root, nil)
+		mockGalleryReaderWriter.On("FindByPath", childPath).Return(child, nil)
+		mockGalleryReaderWriter.On("FindByPath", otherPath).Return(other, nil)
+		mockGalleryReaderWriter.On("FindByPath", mock.Anything).Return(nil, nil)
+		mockGalleryReaderWriter.On("UpdatePartial", models.GalleryPartial{
+			ID:       newGalleryID,
+			ParentID: &tt.parentID,
+		}).Return(nil, nil)
+
+		err := UpdateFolderParent(mockGalleryReaderWriter, tt.gallery, tt.oldPath)
+		assert.Nil(t, err, tt.name)
+		assert.Equal(t, tt.parentID, tt.gallery.ParentID, tt.name)
+
+		if tt.parentID.Int64 == manualGalleryID {
+			mockGalleryReaderWriter.AssertNotCalled(t, "UpdatePartial", mock.Anything)
+		}
+	}
+}
+
+func TestSetMissingFolderParents(t *testing.T) {
+	mockGalleryReaderWriter := &mocks.GalleryReaderWriter{}
+
+	// galleries created before galleries had parents
+	root := folderGallery(rootGalleryID, rootPath, 0)
+	child := folderGallery(childGalleryID, childPath, 0)
+	grandChild := folderGallery(grandChildGalleryID, grandChildPath, 0)
+	manual := folderGallery(manualGalleryID, filepath.Join(childPath, "manual"), siblingGalleryID)
+	zipGallery := &models.Gallery{
+		ID:   newGalleryID,
+		Path: models.NullString(filepath.Join(grandChildPath, "archive.zip")),
+		Zip:  true,
+	}
+	userGallery := &models.Gallery{ID: siblingGalleryID}
+
+	mockGalleryReaderWriter.On("All").Return([]*models.Gallery{
+		root, child, grandChild, manual, zipGallery, userGallery,
+	}, nil).Once()
+
+	mockGalleryReaderWriter.On("FindByPath", rootPath).Return(root, nil)
+	mockGalleryReaderWriter.On("FindByPath", childPath).Return(child, nil)
+	mockGalleryReaderWriter.On("FindByPath", grandChildPath).Return(grandChild, nil)
+	mockGalleryReaderWriter.On("FindByPath", mock.Anything).Return(nil, nil)
+
+	expectParent := func(id int, parentID int) {
+		v := models.NullInt64(int64(parentID))
+		mockGalleryReaderWriter.On("UpdatePartial", models.GalleryPartial{
+			ID:       id,
+			ParentID: &v,
+		}).Return(nil, nil).Once()
+	}
+
+	expectParent(childGalleryID, rootGalleryID)
+	expectParent(grandChildGalleryID, childGalleryID)
+	expectParent(newGalleryID, grandChildGalleryID)
+
+	count, err := SetMissingFolderParents(mockGalleryReaderWriter)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, models.NullInt64(siblingGalleryID), manual.ParentID)
+	assert.False(t, root.ParentID.Valid)
+
+	mockGalleryReaderWriter.AssertExpectations(t)
+}
+
+func TestValidateParent(t *testing.T) {
+	mockGalleryReader := &mocks.GalleryReaderWriter{}
+
+	mockGalleryReader.On("Find", rootGalleryID).Return(folderGallery(rootGalleryID, rootPath, 0), nil)
+	mockGalleryReader.On("Find", childGalleryID).Return(folderGallery(childGalleryID, childPath, rootGalleryID), nil)
+	mockGalleryReader.On("Find", manualGalleryID).Return(nil, nil)
+	mockGalleryReader.On("Find", siblingGalleryID).Return(nil, errors.New("Find error"))
+
+	assert.Nil(t, ValidateParent(mockGalleryReader, grandChildGalleryID, models.NullInt64(childGalleryID)))
+
+	// unsetting the parent
+	assert.Nil(t, ValidateParent(mockGalleryReader, rootGalleryID, sql.NullInt64{}))
+
+	err := ValidateParent(mockGalleryReader, rootGalleryID, models.NullInt64(childGalleryID))
+	assert.Equal(t, ErrAncestorOfSelf, err)
+
+	err = ValidateParent(mockGalleryReader, childGalleryID, models.NullInt64(childGalleryID))
+	assert.Equal(t, ErrAncestorOfSelf, err)
+
+	assert.NotNil(t, ValidateParent(mockGalleryReader, grandChildGalleryID, models.NullInt64(manualGalleryID)))
+	assert.NotNil(t, ValidateParent(mockGalleryReader, grandChildGalleryID, models.NullInt64(siblingGalleryID)))
+}
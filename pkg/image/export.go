@@ -3,6 +3,7 @@ package image
 import (
 	"github.com/stashapp/stash/pkg/manager/jsonschema"
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
 )
 
 // ToBasicJSON converts a image object into its JSON object equivalent. It
@@ -19,6 +20,14 @@ func ToBasicJSON(image *models.Image) *jsonschema.Image {
 		newImageJSON.Title = image.Title.String
 	}
 
+	if image.Phash.Valid {
+		newImageJSON.Phash = utils.PhashToString(image.Phash.Int64)
+	}
+
+	if image.Date.Valid {
+		newImageJSON.Date = utils.GetYMDFromDatabaseDate(image.Date.String)
+	}
+
 	if image.Rating.Valid {
 		newImageJSON.Rating = int(image.Rating.Int64)
 	}
@@ -84,3 +93,19 @@ func GetStudioName(reader models.StudioReader, image *models.Image) (string, err
 
 // 	return "", nil
 // }
+
+// GetOHistory returns the times that the o-counter of the provided image was
+// incremented.
+func GetOHistory(reader models.ImageReader, image *models.Image) ([]models.JSONTime, error) {
+	dates, err := reader.GetODates(image.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []models.JSONTime
+	for _, t := range dates {
+		ret = append(ret, models.JSONTime{Time: t})
+	}
+
+	return ret, nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/stashapp/stash/pkg/manager/jsonschema"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stashapp/stash/pkg/utils"
 	"github.com/stretchr/testify/assert"
 
 	"testing"
@@ -40,6 +41,8 @@ const (
 const (
 	checksum  = "checksum"
 	title     = "title"
+	date      = "2001-01-01"
+	phash     = -3846826108889195
 	rating    = 5
 	organized = true
 	ocounter  = 2
@@ -66,6 +69,8 @@ func createFullImage(id int) models.Image {
 		ID:        id,
 		Title:     models.NullString(title),
 		Checksum:  checksum,
+		Date:      models.SQLiteDate{String: date, Valid: true},
+		Phash:     models.NullInt64(phash),
 		Height:    models.NullInt64(height),
 		OCounter:  ocounter,
 		Rating:    models.NullInt64(rating),
@@ -97,6 +102,8 @@ func createFullJSONImage() *jsonschema.Image {
 	return &jsonschema.Image{
 		Title:     title,
 		Checksum:  checksum,
+		Date:      date,
+		Phash:     utils.PhashToString(phash),
 		OCounter:  ocounter,
 		Rating:    rating,
 		Organized: organized,
@@ -204,6 +211,30 @@ func TestGetStudioName(t *testing.T) {
 	mockStudioReader.AssertExpectations(t)
 }
 
+func TestGetOHistory(t *testing.T) {
+	mockImageReader := &mocks.ImageReaderWriter{}
+
+	oTime := time.Date(2003, 01, 01, 0, 0, 0, 0, time.UTC)
+	historyErr := errors.New("error getting o-counter history")
+
+	mockImageReader.On("GetODates", imageID).Return([]time.Time{oTime}, nil).Once()
+	mockImageReader.On("GetODates", noImageID).Return(nil, nil).Once()
+	mockImageReader.On("GetODates", errImageID).Return(nil, historyErr).Once()
+
+	history, err := GetOHistory(mockImageReader, &models.Image{ID: imageID})
+	assert.Nil(t, err)
+	assert.Equal(t, []models.JSONTime{{Time: oTime}}, history)
+
+	history, err = GetOHistory(mockImageReader, &models.Image{ID: noImageID})
+	assert.Nil(t, err)
+	assert.Len(t, history, 0)
+
+	_, err = GetOHistory(mockImageReader, &models.Image{ID: errImageID})
+	assert.NotNil(t, err)
+
+	mockImageReader.AssertExpectations(t)
+}
+
 // var getGalleryChecksumScenarios = []stringTestScenario{
 // 	{
 // 		createEmptyImage(imageID),
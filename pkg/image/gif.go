@@ -0,0 +1,126 @@
+package image
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// GIF block introducers
+const (
+	gifExtension       = 0x21
+	gifImageDescriptor = 0x2c
+	gifTrailer         = 0x3b
+)
+
+var errInvalidGIF = errors.New("invalid gif file")
+
+// IsAnimatedGIF returns true if the GIF file at the path has more than one
+// frame. The frames are not decoded, and the file is only read up to the
+// start of the second frame.
+func IsAnimatedGIF(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	frames, err := countGIFFrames(bufio.NewReader(f), 2)
+	if err != nil {
+		return false, err
+	}
+
+	return frames > 1, nil
+}
+
+// countGIFFrames returns the number of frames of the GIF, stopping once max
+// frames have been found.
+func countGIFFrames(r *bufio.Reader, max int) (int, error) {
+	// header and logical screen descriptor
+	header := make([]byte, 13)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, err
+	}
+
+	if string(header[:6]) != "GIF87a" && string(header[:6]) != "GIF89a" {
+		return 0, errInvalidGIF
+	}
+
+	if err := skipGIFColorTable(r, header[10]); err != nil {
+		return 0, err
+	}
+
+	frames := 0
+	for frames < max {
+		introducer, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		switch introducer {
+		case gifExtension:
+			// label
+			if _, err := r.ReadByte(); err != nil {
+				return 0, err
+			}
+		case gifImageDescriptor:
+			frames++
+
+			descriptor := make([]byte, 9)
+			if _, err := io.ReadFull(r, descriptor); err != nil {
+				return 0, err
+			}
+			if err := skipGIFColorTable(r, descriptor[8]); err != nil {
+				return 0, err
+			}
+
+			// LZW minimum code size
+			if _, err := r.ReadByte(); err != nil {
+				return 0, err
+			}
+		case gifTrailer:
+			return frames, nil
+		default:
+			return 0, errInvalidGIF
+		}
+
+		if err := skipGIFSubBlocks(r); err != nil {
+			return 0, err
+		}
+	}
+
+	return frames, nil
+}
+
+// skipGIFColorTable skips the color table described by the packed fields of
+// a logical screen or image descriptor.
+func skipGIFColorTable(r io.Reader, packed byte) error {
+	if packed&0x80 == 0 {
+		return nil
+	}
+
+	size := int64(3 << ((packed & 0x07) + 1))
+	_, err := io.CopyN(ioutil.Discard, r, size)
+	return err
+}
+
+// skipGIFSubBlocks skips data sub-blocks up to and including the block
+// terminator.
+func skipGIFSubBlocks(r *bufio.Reader) error {
+	for {
+		size, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		if size == 0 {
+			return nil
+		}
+
+		if _, err := r.Discard(int(size)); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestGIF(t *testing.T, fn string, frames int) {
+	g := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		img := image.NewPaletted(image.Rect(0, 0, 16, 16), palette.Plan9)
+		img.Set(i, i, color.White)
+		g.Image = append(g.Image, img)
+		g.Delay = append(g.Delay, 10)
+	}
+
+	f, err := os.Create(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := gif.EncodeAll(f, g); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsAnimatedGIF(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name     string
+		frames   int
+		animated bool
+	}{
+		{"static.gif", 1, false},
+		{"two.gif", 2, true},
+		{"many.gif", 10, true},
+	}
+
+	for _, tt := range tests {
+		fn := filepath.Join(dir, tt.name)
+		writeTestGIF(t, fn, tt.frames)
+
+		animated, err := IsAnimatedGIF(fn)
+		if assert.Nil(t, err, tt.name) {
+			assert.Equal(t, tt.animated, animated, tt.name)
+		}
+	}
+
+	invalid := filepath.Join(dir, "invalid.gif")
+	if err := ioutil.WriteFile(invalid, []byte("not a gif file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = IsAnimatedGIF(invalid)
+	assert.NotNil(t, err)
+
+	_, err = IsAnimatedGIF(filepath.Join(dir, "missing.gif"))
+	assert.NotNil(t, err)
+}
@@ -1,10 +1,12 @@
 package image
 
 import (
-	"archive/zip"
+	"bytes"
 	"database/sql"
 	"fmt"
 	"image"
+	"image/jpeg"
+	"image/png"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -13,26 +15,45 @@ import (
 	"strings"
 	"time"
 
+	"github.com/stashapp/stash/pkg/archive"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/utils"
 	_ "golang.org/x/image/webp"
 )
 
-const zipSeparator = "\x00"
+const archiveSeparator = "\x00"
 
+// orientedJPEGQuality is the quality of full-size JPEG images that are
+// re-encoded to be served upright.
+const orientedJPEGQuality = 90
+
+// GetSourceImage returns the decoded image, rotated and flipped according to
+// its EXIF orientation so that it is upright.
 func GetSourceImage(i *models.Image) (image.Image, error) {
-	f, err := openSourceImage(i.Path)
+	srcImage, _, err := decodeSourceImage(i.Path)
+	return srcImage, err
+}
+
+// decodeSourceImage decodes the image at the path and transforms it
+// according to its EXIF orientation. Returns the format name of the image.
+func decodeSourceImage(path string) (image.Image, string, error) {
+	f, err := openSourceImage(path)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer f.Close()
 
-	srcImage, _, err := image.Decode(f)
+	data, err := ioutil.ReadAll(f)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return srcImage, nil
+	srcImage, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return Orient(srcImage, getOrientation(data)), format, nil
 }
 
 func CalculateMD5(path string) (string, error) {
@@ -55,77 +76,35 @@ func FileExists(path string) bool {
 	return true
 }
 
-func ZipFilename(zipFilename, filenameInZip string) string {
-	return zipFilename + zipSeparator + filenameInZip
+// ArchiveFilename returns the path of a file within an archive file. The
+// path is the archive path and the name of the file within the archive,
+// separated by a null byte.
+func ArchiveFilename(archiveFilename, filenameInArchive string) string {
+	return archiveFilename + archiveSeparator + filenameInArchive
 }
 
-// IsZipPath returns true if the path includes the zip separator byte,
-// indicating it is within a zip file.
+// IsArchivePath returns true if the path includes the archive separator
+// byte, indicating it is within an archive file.
 // TODO - this should be moved to utils
-func IsZipPath(p string) bool {
-	return strings.Contains(p, zipSeparator)
-}
-
-type imageReadCloser struct {
-	src io.ReadCloser
-	zrc *zip.ReadCloser
-}
-
-func (i *imageReadCloser) Read(p []byte) (n int, err error) {
-	return i.src.Read(p)
-}
-
-func (i *imageReadCloser) Close() error {
-	err := i.src.Close()
-	var err2 error
-	if i.zrc != nil {
-		err2 = i.zrc.Close()
-	}
-
-	if err != nil {
-		return err
-	}
-	return err2
+func IsArchivePath(p string) bool {
+	return strings.Contains(p, archiveSeparator)
 }
 
 func openSourceImage(path string) (io.ReadCloser, error) {
-	// may need to read from a zip file
-	zipFilename, filename := getFilePath(path)
-	if zipFilename != "" {
-		r, err := zip.OpenReader(zipFilename)
-		if err != nil {
-			return nil, err
-		}
-
-		// defer closing of zip to the calling function, unless an error
-		// is returned, in which case it should be closed immediately
-
-		// find the file matching the filename
-		for _, f := range r.File {
-			if f.Name == filename {
-				src, err := f.Open()
-				if err != nil {
-					r.Close()
-					return nil, err
-				}
-				return &imageReadCloser{
-					src: src,
-					zrc: r,
-				}, nil
-			}
-		}
-
-		r.Close()
-		return nil, fmt.Errorf("file with name '%s' not found in zip file '%s'", filename, zipFilename)
+	// may need to read from an archive file
+	archiveFilename, filename := getFilePath(path)
+	if archiveFilename != "" {
+		// closing the returned reader closes the archive
+		return archive.OpenFile(archiveFilename, filename)
 	}
 
 	return os.Open(filename)
 }
 
-func getFilePath(path string) (zipFilename, filename string) {
-	nullIndex := strings.Index(path, zipSeparator)
+func getFilePath(path string) (archiveFilename, filename string) {
+	nullIndex := strings.Index(path, archiveSeparator)
 	if nullIndex != -1 {
-		zipFilename = path[0:nullIndex]
+		archiveFilename = path[0:nullIndex]
 		filename = path[nullIndex+1:]
 	} else {
 		filename = path
@@ -175,7 +154,8 @@ func SetFileDetails(i *models.Image) error {
 	return nil
 }
 
-// GetFileModTime gets the file modification time, handling files in zip files.
+// GetFileModTime gets the file modification time, handling files in archive
+// files.
 func GetFileModTime(path string) (time.Time, error) {
 	fi, err := stat(path)
 	if err != nil {
@@ -190,40 +170,27 @@ func GetFileModTime(path string) (time.Time, error) {
 }
 
 func stat(path string) (os.FileInfo, error) {
-	// may need to read from a zip file
-	zipFilename, filename := getFilePath(path)
-	if zipFilename != "" {
-		r, err := zip.OpenReader(zipFilename)
-		if err != nil {
-			return nil, err
-		}
-		defer r.Close()
-
-		// find the file matching the filename
-		for _, f := range r.File {
-			if f.Name == filename {
-				return f.FileInfo(), nil
-			}
-		}
-
-		return nil, fmt.Errorf("file with name '%s' not found in zip file '%s'", filename, zipFilename)
+	// may need to read from an archive file
+	archiveFilename, filename := getFilePath(path)
+	if archiveFilename != "" {
+		return archive.Stat(archiveFilename, filename)
 	}
 
 	return os.Stat(filename)
 }
 
-// PathDisplayName converts an image path for display. It translates the zip
-// file separator character into '/', since this character is also used for
-// path separators within zip files. It returns the original provided path
+// PathDisplayName converts an image path for display. It translates the
+// archive file separator character into '/', since this character is also
+// used for path separators within archive files. It returns the original provided path
 // if it does not contain the zip file separator character.
 func PathDisplayName(path string) string {
-	return strings.Replace(path, zipSeparator, "/", -1)
+	return strings.Replace(path, archiveSeparator, "/", -1)
 }
 
 func Serve(w http.ResponseWriter, r *http.Request, path string) {
-	zipFilename, _ := getFilePath(path)
+	archiveFilename, _ := getFilePath(path)
 	w.Header().Add("Cache-Control", "max-age=604800000") // 1 Week
-	if zipFilename == "" {
+	if archiveFilename == "" {
 		http.ServeFile(w, r, path)
 	} else {
 		rc, err := openSourceImage(path)
@@ -244,6 +211,42 @@ func Serve(w http.ResponseWriter, r *http.Request, path string) {
 	}
 }
 
+// ServeOriented serves the image rotated and flipped according to its EXIF
+// orientation. PNG images are served as PNG, and all other formats are
+// served as JPEG. Images that do not need to be transformed are served
+// unchanged.
+func ServeOriented(w http.ResponseWriter, r *http.Request, i *models.Image) {
+	if !IsRotated(i) {
+		Serve(w, r, i.Path)
+		return
+	}
+
+	srcImage, format, err := decodeSourceImage(i.Path)
+	if err != nil {
+		// assume not found
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	contentType := "image/jpeg"
+	if format == "png" {
+		contentType = "image/png"
+		err = png.Encode(buf, srcImage)
+	} else {
+		err = jpeg.Encode(buf, srcImage, &jpeg.Options{Quality: orientedJPEGQuality})
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Cache-Control", "max-age=604800000") // 1 Week
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
+}
+
 func IsCover(img *models.Image) bool {
 	_, fn := getFilePath(img.Path)
 	return strings.HasSuffix(fn, "cover.jpg")
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/stashapp/stash/pkg/manager/jsonschema"
 	"github.com/stashapp/stash/pkg/models"
@@ -25,6 +26,7 @@ type Importer struct {
 	galleries  []*models.Gallery
 	performers []*models.Performer
 	tags       []*models.Tag
+	oDates     []time.Time
 }
 
 func (i *Importer) PreImport() error {
@@ -58,12 +60,22 @@ func (i *Importer) imageJSONToImage(imageJSON jsonschema.Image) models.Image {
 	if imageJSON.Title != "" {
 		newImage.Title = sql.NullString{String: imageJSON.Title, Valid: true}
 	}
+	if imageJSON.Phash != "" {
+		hash, err := utils.StringToPhash(imageJSON.Phash)
+		newImage.Phash = sql.NullInt64{Int64: hash, Valid: err == nil}
+	}
+	if imageJSON.Date != "" {
+		newImage.Date = models.SQLiteDate{String: imageJSON.Date, Valid: true}
+	}
 	if imageJSON.Rating != 0 {
 		newImage.Rating = sql.NullInt64{Int64: int64(imageJSON.Rating), Valid: true}
 	}
 
 	newImage.Organized = imageJSON.Organized
 	newImage.OCounter = imageJSON.OCounter
+	for _, t := range imageJSON.OHistory {
+		i.oDates = append(i.oDates, t.GetTime())
+	}
 	newImage.CreatedAt = models.SQLiteTimestamp{Timestamp: imageJSON.CreatedAt.GetTime()}
 	newImage.UpdatedAt = models.SQLiteTimestamp{Timestamp: imageJSON.UpdatedAt.GetTime()}
 
@@ -257,6 +269,12 @@ func (i *Importer) PostImport(id int) error {
 		}
 	}
 
+	if len(i.oDates) > 0 {
+		if err := i.ReaderWriter.UpdateODates(id, i.oDates); err != nil {
+			return fmt.Errorf("failed to set o-counter history: %s", err.Error())
+		}
+	}
+
 	return nil
 }
 
@@ -3,6 +3,7 @@ package image
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stashapp/stash/pkg/manager/jsonschema"
 	"github.com/stashapp/stash/pkg/models"
@@ -463,6 +464,30 @@ func TestImporterPostImportUpdateTags(t *testing.T) {
 	readerWriter.AssertExpectations(t)
 }
 
+func TestImporterPostImportUpdateOHistory(t *testing.T) {
+	readerWriter := &mocks.ImageReaderWriter{}
+
+	oTime := time.Date(2003, 01, 01, 0, 0, 0, 0, time.UTC)
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		oDates:       []time.Time{oTime},
+	}
+
+	updateErr := errors.New("UpdateODates error")
+
+	readerWriter.On("UpdateODates", imageID, []time.Time{oTime}).Return(nil).Once()
+	readerWriter.On("UpdateODates", errImageID, mock.AnythingOfType("[]time.Time")).Return(updateErr).Once()
+
+	err := i.PostImport(imageID)
+	assert.Nil(t, err)
+
+	err = i.PostImport(errImageID)
+	assert.NotNil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
+
 func TestImporterFindExistingID(t *testing.T) {
 	readerWriter := &mocks.ImageReaderWriter{}
 
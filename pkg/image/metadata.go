@@ -0,0 +1,252 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// OrientationNormal is the EXIF orientation of images that do not need to be
+// transformed to be displayed.
+const OrientationNormal = 1
+
+// Metadata is the EXIF and XMP metadata embedded in an image file. Fields
+// are nil or empty if they are not present in the file.
+type Metadata struct {
+	CapturedAt   *time.Time
+	CameraMake   string
+	CameraModel  string
+	Lens         string
+	ExposureTime *float64
+	FNumber      *float64
+	ISO          *int
+	FocalLength  *float64
+	Latitude     *float64
+	Longitude    *float64
+	// Orientation is the EXIF orientation, from 1 to 8. Defaults to
+	// OrientationNormal.
+	Orientation int
+	Keywords    []string
+	Rating      *int
+	Creator     string
+}
+
+// GetMetadata reads the EXIF and XMP metadata of the image at the path,
+// which may be within an archive file.
+func GetMetadata(path string) (*Metadata, error) {
+	src, err := openSourceImage(path)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	data, err := ioutil.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseMetadata(data), nil
+}
+
+// ParseMetadata returns the EXIF and XMP metadata of the JPEG, PNG, WebP or
+// TIFF image data. Metadata that cannot be parsed is ignored.
+func ParseMetadata(data []byte) *Metadata {
+	ret := &Metadata{
+		Orientation: OrientationNormal,
+	}
+
+	if exifData := findEXIF(data); exifData != nil {
+		if x, err := exif.Decode(bytes.NewReader(exifData)); err == nil {
+			ret.setEXIF(x)
+		}
+	}
+
+	if packet := findXMP(data); packet != nil {
+		if x, err := parseXMP(packet); err == nil {
+			ret.setXMP(x)
+		}
+	}
+
+	return ret
+}
+
+var (
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+	tiffLE       = []byte("II*\x00")
+	tiffBE       = []byte("MM\x00*")
+)
+
+// findEXIF returns the EXIF data of the image, in a form that can be decoded
+// by the exif package.
+func findEXIF(data []byte) []byte {
+	switch {
+	case bytes.HasPrefix(data, []byte("\xff\xd8")):
+		// the exif package finds the APP1 segment of JPEG files
+		return data
+	case bytes.HasPrefix(data, tiffLE), bytes.HasPrefix(data, tiffBE):
+		return data
+	case bytes.HasPrefix(data, pngSignature):
+		return findPNGChunk(data, "eXIf")
+	case isWebP(data):
+		return findWebPChunk(data, "EXIF")
+	}
+
+	return nil
+}
+
+func findPNGChunk(data []byte, chunkType string) []byte {
+	// chunks consist of the length, type, data and CRC
+	offset := len(pngSignature)
+	for offset+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		name := string(data[offset+4 : offset+8])
+		start := offset + 8
+		end := start + length
+		if length < 0 || end > len(data) {
+			return nil
+		}
+
+		if name == chunkType {
+			return data[start:end]
+		}
+
+		if name == "IEND" {
+			return nil
+		}
+
+		offset = end + 4
+	}
+
+	return nil
+}
+
+func isWebP(data []byte) bool {
+	return len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP"
+}
+
+func findWebPChunk(data []byte, chunkType string) []byte {
+	// chunks consist of the type, little-endian length and data, padded to
+	// an even length
+	offset := 12
+	for offset+8 <= len(data) {
+		name := string(data[offset : offset+4])
+		length := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		start := offset + 8
+		end := start + length
+		if length < 0 || end > len(data) {
+			return nil
+		}
+
+		if name == chunkType {
+			return data[start:end]
+		}
+
+		offset = end + length%2
+	}
+
+	return nil
+}
+
+func (m *Metadata) setEXIF(x *exif.Exif) {
+	if t, err := x.DateTime(); err == nil && !t.IsZero() {
+		m.CapturedAt = &t
+	}
+
+	m.CameraMake = exifString(x, exif.Make)
+	m.CameraModel = exifString(x, exif.Model)
+	m.Lens = exifString(x, exif.LensModel)
+	m.ExposureTime = exifRat(x, exif.ExposureTime)
+	m.FNumber = exifRat(x, exif.FNumber)
+	m.FocalLength = exifRat(x, exif.FocalLength)
+
+	if tag, err := x.Get(exif.ISOSpeedRatings); err == nil {
+		if v, err := tag.Int(0); err == nil && v > 0 {
+			m.ISO = &v
+		}
+	}
+
+	m.Orientation = exifOrientation(x)
+
+	if lat, long, err := x.LatLong(); err == nil {
+		m.Latitude = &lat
+		m.Longitude = &long
+	}
+}
+
+// getOrientation returns the EXIF orientation of the image data, or
+// OrientationNormal if it is not present.
+func getOrientation(data []byte) int {
+	exifData := findEXIF(data)
+	if exifData == nil {
+		return OrientationNormal
+	}
+
+	x, err := exif.Decode(bytes.NewReader(exifData))
+	if err != nil {
+		return OrientationNormal
+	}
+
+	return exifOrientation(x)
+}
+
+func exifOrientation(x *exif.Exif) int {
+	tag, err := x.Get(exif.Orientation)
+	if err != nil {
+		return OrientationNormal
+	}
+
+	v, err := tag.Int(0)
+	if err != nil || v < 1 || v > 8 {
+		return OrientationNormal
+	}
+
+	return v
+}
+
+func exifString(x *exif.Exif, name exif.FieldName) string {
+	tag, err := x.Get(name)
+	if err != nil || tag.Format() != tiff.StringVal {
+		return ""
+	}
+
+	v, err := tag.StringVal()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(strings.Trim(v, "\x00"))
+}
+
+func exifRat(x *exif.Exif, name exif.FieldName) *float64 {
+	tag, err := x.Get(name)
+	if err != nil {
+		return nil
+	}
+
+	r, err := tag.Rat(0)
+	if err != nil {
+		return nil
+	}
+
+	v, _ := r.Float64()
+	return &v
+}
+
+func (m *Metadata) setXMP(x *xmpData) {
+	m.Keywords = x.keywords
+	m.Creator = strings.Join(x.creators, ", ")
+
+	if x.rating != nil {
+		m.Rating = x.rating
+	}
+
+	// EXIF dates take precedence, since they are set by the camera
+	if m.CapturedAt == nil && x.createDate != nil {
+		m.CapturedAt = x.createDate
+	}
+}
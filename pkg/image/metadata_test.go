@@ -0,0 +1,351 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	tiffTypeASCII    = 2
+	tiffTypeShort    = 3
+	tiffTypeLong     = 4
+	tiffTypeRational = 5
+)
+
+type tiffEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	data  []byte
+}
+
+func asciiEntry(tag uint16, v string) tiffEntry {
+	data := append([]byte(v), 0)
+	return tiffEntry{tag, tiffTypeASCII, uint32(len(data)), data}
+}
+
+func shortEntry(tag uint16, v uint16) tiffEntry {
+	data := make([]byte, 2)
+	binary.LittleEndian.PutUint16(data, v)
+	return tiffEntry{tag, tiffTypeShort, 1, data}
+}
+
+func longEntry(tag uint16, v uint32) tiffEntry {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, v)
+	return tiffEntry{tag, tiffTypeLong, 1, data}
+}
+
+func rationalEntry(tag uint16, v ...uint32) tiffEntry {
+	data := make([]byte, len(v)*4)
+	for i, n := range v {
+		binary.LittleEndian.PutUint32(data[i*4:], n)
+	}
+	return tiffEntry{tag, tiffTypeRational, uint32(len(v) / 2), data}
+}
+
+func ifdSize(entries []tiffEntry) int {
+	ret := 2 + 12*len(entries) + 4
+	for _, e := range entries {
+		if len(e.data) > 4 {
+			ret += len(e.data) + len(e.data)%2
+		}
+	}
+	return ret
+}
+
+func writeIFD(buf *bytes.Buffer, entries []tiffEntry) {
+	dataOffset := buf.Len() + 2 + 12*len(entries) + 4
+	var data []byte
+
+	_ = binary.Write(buf, binary.LittleEndian, uint16(len(entries)))
+	for _, e := range entries {
+		_ = binary.Write(buf, binary.LittleEndian, e.tag)
+		_ = binary.Write(buf, binary.LittleEndian, e.typ)
+		_ = binary.Write(buf, binary.LittleEndian, e.count)
+
+		if len(e.data) <= 4 {
+			value := make([]byte, 4)
+			copy(value, e.data)
+			buf.Write(value)
+			continue
+		}
+
+		_ = binary.Write(buf, binary.LittleEndian, uint32(dataOffset+len(data)))
+		data = append(data, e.data...)
+		if len(e.data)%2 == 1 {
+			data = append(data, 0)
+		}
+	}
+
+	// no next IFD
+	_ = binary.Write(buf, binary.LittleEndian, uint32(0))
+	buf.Write(data)
+}
+
+// makeTIFF returns little-endian TIFF data with EXIF and GPS sub-IFDs.
+func makeTIFF() []byte {
+	exifIFD := []tiffEntry{
+		rationalEntry(0x829A, 1, 250),
+		rationalEntry(0x829D, 28, 10),
+		shortEntry(0x8827, 200),
+		asciiEntry(0x9003, "2019:05:04 13:14:15"),
+		rationalEntry(0x920A, 50, 1),
+		asciiEntry(0xA434, "EF50mm f/1.8"),
+	}
+
+	gpsIFD := []tiffEntry{
+		asciiEntry(0x0001, "N"),
+		rationalEntry(0x0002, 51, 1, 30, 1, 0, 1),
+		asciiEntry(0x0003, "W"),
+		rationalEntry(0x0004, 0, 1, 6, 1, 0, 1),
+	}
+
+	ifd0 := []tiffEntry{
+		asciiEntry(0x010F, "Canon"),
+		asciiEntry(0x0110, "Canon EOS 5D"),
+		shortEntry(0x0112, 6),
+		longEntry(0x8769, 0),
+		longEntry(0x8825, 0),
+	}
+
+	exifOffset := 8 + ifdSize(ifd0)
+	gpsOffset := exifOffset + ifdSize(exifIFD)
+	ifd0[3] = longEntry(0x8769, uint32(exifOffset))
+	ifd0[4] = longEntry(0x8825, uint32(gpsOffset))
+
+	buf := &bytes.Buffer{}
+	buf.Write(tiffLE)
+	_ = binary.Write(buf, binary.LittleEndian, uint32(8))
+	writeIFD(buf, ifd0)
+	writeIFD(buf, exifIFD)
+	writeIFD(buf, gpsIFD)
+
+	return buf.Bytes()
+}
+
+const testXMP = `<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmp:Rating="4">
+   <dc:subject>
+    <rdf:Bag>
+     <rdf:li>beach</rdf:li>
+     <rdf:li>sunset</rdf:li>
+     <rdf:li>Beach</rdf:li>
+    </rdf:Bag>
+   </dc:subject>
+   <dc:creator>
+    <rdf:Seq>
+     <rdf:li>Jane Doe</rdf:li>
+    </rdf:Seq>
+   </dc:creator>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>`
+
+func makeJPEG(tiff []byte, xmp string) []byte {
+	buf := &bytes.Buffer{}
+	buf.Write([]byte("\xff\xd8"))
+
+	writeSegment := func(data []byte) {
+		buf.Write([]byte("\xff\xe1"))
+		_ = binary.Write(buf, binary.BigEndian, uint16(len(data)+2))
+		buf.Write(data)
+	}
+
+	if tiff != nil {
+		writeSegment(append([]byte("Exif\x00\x00"), tiff...))
+	}
+	if xmp != "" {
+		writeSegment(append([]byte("http://ns.adobe.com/xap/1.0/\x00"), xmp...))
+	}
+
+	buf.Write([]byte("\xff\xd9"))
+	return buf.Bytes()
+}
+
+func makePNG(tiff []byte, xmp string) []byte {
+	buf := &bytes.Buffer{}
+	buf.Write(pngSignature)
+
+	writeChunk := func(name string, data []byte) {
+		_ = binary.Write(buf, binary.BigEndian, uint32(len(data)))
+		buf.WriteString(name)
+		buf.Write(data)
+		// CRC is not checked
+		_ = binary.Write(buf, binary.BigEndian, uint32(0))
+	}
+
+	writeChunk("IHDR", make([]byte, 13))
+	if xmp != "" {
+		writeChunk("iTXt", append([]byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"), xmp...))
+	}
+	writeChunk("eXIf", tiff)
+	writeChunk("IEND", nil)
+
+	return buf.Bytes()
+}
+
+func makeWebP(tiff []byte) []byte {
+	chunks := &bytes.Buffer{}
+	writeChunk := func(name string, data []byte) {
+		chunks.WriteString(name)
+		_ = binary.Write(chunks, binary.LittleEndian, uint32(len(data)))
+		chunks.Write(data)
+		if len(data)%2 == 1 {
+			chunks.WriteByte(0)
+		}
+	}
+
+	writeChunk("VP8X", make([]byte, 10))
+	writeChunk("ICCP", make([]byte, 3))
+	writeChunk("EXIF", tiff)
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("RIFF")
+	_ = binary.Write(buf, binary.LittleEndian, uint32(chunks.Len()+4))
+	buf.WriteString("WEBP")
+	buf.Write(chunks.Bytes())
+
+	return buf.Bytes()
+}
+
+func assertEXIF(t *testing.T, name string, m *Metadata) {
+	assert := assert.New(t)
+
+	assert.Equal("Canon", m.CameraMake, name)
+	assert.Equal("Canon EOS 5D", m.CameraModel, name)
+	assert.Equal("EF50mm f/1.8", m.Lens, name)
+	assert.Equal(6, m.Orientation, name)
+
+	if assert.NotNil(m.CapturedAt, name) {
+		assert.Equal("2019-05-04 13:14:15", m.CapturedAt.Format("2006-01-02 15:04:05"), name)
+	}
+	if assert.NotNil(m.ExposureTime, name) {
+		assert.InDelta(0.004, *m.ExposureTime, 0.0001, name)
+	}
+	if assert.NotNil(m.FNumber, name) {
+		assert.InDelta(2.8, *m.FNumber, 0.0001, name)
+	}
+	if assert.NotNil(m.FocalLength, name) {
+		assert.InDelta(50, *m.FocalLength, 0.0001, name)
+	}
+	if assert.NotNil(m.ISO, name) {
+		assert.Equal(200, *m.ISO, name)
+	}
+	if assert.NotNil(m.Latitude, name) && assert.NotNil(m.Longitude, name) {
+		assert.InDelta(51.5, *m.Latitude, 0.0001, name)
+		assert.InDelta(-0.1, *m.Longitude, 0.0001, name)
+	}
+}
+
+func assertXMP(t *testing.T, name string, m *Metadata) {
+	assert := assert.New(t)
+
+	assert.Equal([]string{"beach", "sunset"}, m.Keywords, name)
+	assert.Equal("Jane Doe", m.Creator, name)
+	if assert.NotNil(m.Rating, name) {
+		assert.Equal(4, *m.Rating, name)
+	}
+}
+
+func TestParseMetadata(t *testing.T) {
+	tiff := makeTIFF()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		hasEXIF bool
+		hasXMP  bool
+	}{
+		{"jpeg", makeJPEG(tiff, testXMP), true, true},
+		{"jpeg exif only", makeJPEG(tiff, ""), true, false},
+		{"jpeg xmp only", makeJPEG(nil, testXMP), false, true},
+		{"png", makePNG(tiff, testXMP), true, true},
+		{"webp", makeWebP(tiff), true, false},
+		{"tiff", tiff, true, false},
+	}
+
+	for _, tt := range tests {
+		m := ParseMetadata(tt.data)
+
+		if tt.hasEXIF {
+			assertEXIF(t, tt.name, m)
+		} else {
+			assert.Equal(t, OrientationNormal, m.Orientation, tt.name)
+			assert.Empty(t, m.CameraMake, tt.name)
+		}
+
+		if tt.hasXMP {
+			assertXMP(t, tt.name, m)
+		} else {
+			assert.Nil(t, m.Keywords, tt.name)
+			assert.Nil(t, m.Rating, tt.name)
+		}
+	}
+}
+
+func TestParseMetadataInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"garbage", []byte("not an image")},
+		{"truncated png", makePNG(makeTIFF(), "")[:30]},
+		{"truncated jpeg", makeJPEG(makeTIFF(), "")[:40]},
+		{"invalid xmp", makeJPEG(nil, "<x:xmpmeta><rdf:li></x:xmpmeta>")},
+	}
+
+	for _, tt := range tests {
+		m := ParseMetadata(tt.data)
+		assert.Equal(t, &Metadata{Orientation: OrientationNormal}, m, tt.name)
+	}
+}
+
+func TestParseXMP(t *testing.T) {
+	const packet = `<x:xmpmeta xmlns:x="adobe:ns:meta/">
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+ <rdf:Description rdf:about=""
+   xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+   xmlns:photoshop="http://ns.adobe.com/photoshop/1.0/"
+   xmlns:dc="http://purl.org/dc/elements/1.1/"
+   photoshop:DateCreated="2018-07-01T10:20:30">
+  <xmp:Rating>5.0</xmp:Rating>
+  <xmp:CreateDate>2020-01-01T00:00:00Z</xmp:CreateDate>
+  <dc:creator><rdf:Seq><rdf:li>A</rdf:li><rdf:li>B</rdf:li></rdf:Seq></dc:creator>
+  <dc:title><rdf:Alt><rdf:li xml:lang="x-default">Title</rdf:li></rdf:Alt></dc:title>
+ </rdf:Description>
+</rdf:RDF>
+</x:xmpmeta>`
+
+	x, err := parseXMP([]byte(packet))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	if assert.NotNil(t, x.rating) {
+		assert.Equal(t, 5, *x.rating)
+	}
+	assert.Equal(t, []string{"A", "B"}, x.creators)
+	assert.Nil(t, x.keywords)
+
+	// photoshop:DateCreated takes precedence over xmp:CreateDate
+	if assert.NotNil(t, x.createDate) {
+		assert.Equal(t, time.Date(2018, 7, 1, 10, 20, 30, 0, time.UTC), *x.createDate)
+	}
+
+	// XMP dates are used if there is no EXIF date
+	m := ParseMetadata(makeJPEG(nil, packet))
+	if assert.NotNil(t, m.CapturedAt) {
+		assert.Equal(t, "2018-07-01", m.CapturedAt.Format("2006-01-02"))
+	}
+	assert.Equal(t, "A, B", m.Creator)
+}
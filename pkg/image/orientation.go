@@ -0,0 +1,38 @@
+package image
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// IsRotated returns true if the image must be rotated or flipped according
+// to its EXIF orientation to be displayed upright.
+func IsRotated(i *models.Image) bool {
+	return i.Orientation.Valid && i.Orientation.Int64 != OrientationNormal
+}
+
+// Orient transforms the image according to the EXIF orientation, so that it
+// is displayed upright. The image is returned unchanged if the orientation
+// is normal or invalid.
+func Orient(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return imaging.FlipH(img)
+	case 3:
+		return imaging.Rotate180(img)
+	case 4:
+		return imaging.FlipV(img)
+	case 5:
+		return imaging.Transpose(img)
+	case 6:
+		return imaging.Rotate270(img)
+	case 7:
+		return imaging.Transverse(img)
+	case 8:
+		return imaging.Rotate90(img)
+	}
+
+	return img
+}
@@ -0,0 +1,57 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	red  = color.NRGBA{R: 255, A: 255}
+	blue = color.NRGBA{B: 255, A: 255}
+)
+
+func TestOrient(t *testing.T) {
+	// a 2x1 image with a red pixel on the left and a blue pixel on the right
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, red)
+	src.Set(1, 0, blue)
+
+	tests := []struct {
+		orientation int
+		width       int
+		height      int
+		// position of the red pixel after transformation
+		redX int
+		redY int
+	}{
+		{OrientationNormal, 2, 1, 0, 0},
+		{0, 2, 1, 0, 0},
+		{2, 2, 1, 1, 0},
+		{3, 2, 1, 1, 0},
+		{4, 2, 1, 0, 0},
+		{5, 1, 2, 0, 0},
+		{6, 1, 2, 0, 0},
+		{7, 1, 2, 0, 1},
+		{8, 1, 2, 0, 1},
+	}
+
+	for _, tt := range tests {
+		img := Orient(src, tt.orientation)
+		bounds := img.Bounds()
+
+		assert.Equal(t, tt.width, bounds.Dx(), "orientation %d", tt.orientation)
+		assert.Equal(t, tt.height, bounds.Dy(), "orientation %d", tt.orientation)
+
+		r, _, _, _ := img.At(bounds.Min.X+tt.redX, bounds.Min.Y+tt.redY).RGBA()
+		assert.Equal(t, uint32(0xffff), r, "orientation %d", tt.orientation)
+	}
+}
+
+func TestGetOrientation(t *testing.T) {
+	assert.Equal(t, 6, getOrientation(makeJPEG(makeTIFF(), "")))
+	assert.Equal(t, OrientationNormal, getOrientation(makeJPEG(nil, testXMP)))
+	assert.Equal(t, OrientationNormal, getOrientation(nil))
+}
@@ -0,0 +1,23 @@
+package image
+
+import (
+	"github.com/corona10/goimagehash"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// GetPhash returns the perceptual hash of the image. The hash is calculated
+// from the upright image, so that copies of the image with different sizes
+// or orientations have similar hashes.
+func GetPhash(i *models.Image) (int64, error) {
+	srcImage, err := GetSourceImage(i)
+	if err != nil {
+		return 0, err
+	}
+
+	hash, err := goimagehash.PerceptionHash(srcImage)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(hash.GetHash()), nil
+}
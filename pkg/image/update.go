@@ -1,7 +1,11 @@
 package image
 
 import (
+	"database/sql"
+	"fmt"
+
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/tag"
 	"github.com/stashapp/stash/pkg/utils"
 )
 
@@ -12,6 +16,154 @@ func UpdateFileModTime(qb models.ImageWriter, id int, modTime models.NullSQLiteT
 	})
 }
 
+// UpdateMetadata sets the fields of the image that are read from the
+// metadata embedded in the image file, including its keywords.
+func UpdateMetadata(qb models.ImageReaderWriter, id int, m *Metadata) (*models.Image, error) {
+	partial := models.ImagePartial{
+		ID:             id,
+		CapturedAt:     &models.NullSQLiteTimestamp{},
+		CameraMake:     nullString(m.CameraMake),
+		CameraModel:    nullString(m.CameraModel),
+		Lens:           nullString(m.Lens),
+		ExposureTime:   nullFloat64(m.ExposureTime),
+		FNumber:        nullFloat64(m.FNumber),
+		ISO:            nullInt64(m.ISO),
+		FocalLength:    nullFloat64(m.FocalLength),
+		GPSLatitude:    nullFloat64(m.Latitude),
+		GPSLongitude:   nullFloat64(m.Longitude),
+		Orientation:    &sql.NullInt64{Int64: int64(m.Orientation), Valid: true},
+		Creator:        nullString(m.Creator),
+		MetadataRating: nullInt64(m.Rating),
+	}
+
+	if m.CapturedAt != nil {
+		partial.CapturedAt = &models.NullSQLiteTimestamp{
+			Timestamp: *m.CapturedAt,
+			Valid:     true,
+		}
+	}
+
+	ret, err := qb.Update(partial)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := qb.UpdateKeywords(id, m.Keywords); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// MetadataOptions selects the fields of images that are set from the
+// metadata embedded in the image file.
+type MetadataOptions struct {
+	// SetDate sets the date from the capture date
+	SetDate bool
+	// SetRating sets the rating from the XMP rating
+	SetRating bool
+	// SetTags adds tags from the XMP keywords
+	SetTags bool
+}
+
+// ApplyMetadata sets the date, rating and tags of the image from the file
+// metadata, as selected by the options. The date and rating are only set if
+// they are not already set. Keywords are matched to tags by name or alias,
+// and tags are created for keywords that do not match.
+func ApplyMetadata(qb models.ImageReaderWriter, tqb models.TagReaderWriter, i *models.Image, m *Metadata, options MetadataOptions) error {
+	partial := models.ImagePartial{
+		ID: i.ID,
+	}
+	changed := false
+
+	if options.SetDate && !i.Date.Valid && m.CapturedAt != nil {
+		partial.Date = &models.SQLiteDate{
+			String: m.CapturedAt.Format("2006-01-02"),
+			Valid:  true,
+		}
+		changed = true
+	}
+
+	// XMP ratings of 0 or less are unrated or rejected
+	if options.SetRating && !i.Rating.Valid && m.Rating != nil && *m.Rating > 0 {
+		rating := *m.Rating
+		if rating > 5 {
+			rating = 5
+		}
+		partial.Rating = &sql.NullInt64{Int64: int64(rating), Valid: true}
+		changed = true
+	}
+
+	if changed {
+		if _, err := qb.Update(partial); err != nil {
+			return err
+		}
+	}
+
+	if options.SetTags && len(m.Keywords) > 0 {
+		if err := addKeywordTags(qb, tqb, i.ID, m.Keywords); err != nil {
+			return fmt.Errorf("error adding keyword tags: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
+func addKeywordTags(qb models.ImageReaderWriter, tqb models.TagReaderWriter, id int, keywords []string) error {
+	tagIDs, err := qb.GetTagIDs(id)
+	if err != nil {
+		return err
+	}
+
+	oldLen := len(tagIDs)
+	for _, keyword := range keywords {
+		t, err := tag.ByName(tqb, keyword)
+		if err != nil {
+			return err
+		}
+
+		if t == nil {
+			t, err = tag.ByAlias(tqb, keyword)
+			if err != nil {
+				return err
+			}
+		}
+
+		if t == nil {
+			t, err = tqb.Create(*models.NewTag(keyword))
+			if err != nil {
+				return err
+			}
+		}
+
+		tagIDs = utils.IntAppendUnique(tagIDs, t.ID)
+	}
+
+	if len(tagIDs) == oldLen {
+		return nil
+	}
+
+	return qb.UpdateTags(id, tagIDs)
+}
+
+func nullString(v string) *sql.NullString {
+	return &sql.NullString{String: v, Valid: v != ""}
+}
+
+func nullFloat64(v *float64) *sql.NullFloat64 {
+	if v == nil {
+		return &sql.NullFloat64{}
+	}
+	return &sql.NullFloat64{Float64: *v, Valid: true}
+}
+
+func nullInt64(v *int) *sql.NullInt64 {
+	if v == nil {
+		return &sql.NullInt64{}
+	}
+	return &sql.NullInt64{Int64: int64(*v), Valid: true}
+}
+
 func AddPerformer(qb models.ImageReaderWriter, id int, performerID int) (bool, error) {
 	performerIDs, err := qb.GetPerformerIDs(id)
 	if err != nil {
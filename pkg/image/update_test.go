@@ -0,0 +1,143 @@
+package image
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	metadataImageID = 300
+	keywordTagID    = 301
+	aliasTagID      = 302
+	createdTagID    = 303
+)
+
+const (
+	nameKeyword   = "name keyword"
+	aliasKeyword  = "alias keyword"
+	newKeyword    = "new keyword"
+	errTagKeyword = "error keyword"
+)
+
+func tagNameFilter(name string) interface{} {
+	return mock.MatchedBy(func(f *models.TagFilterType) bool {
+		return f != nil && f.Name != nil && f.Name.Value == name
+	})
+}
+
+func tagAliasFilter(alias string) interface{} {
+	return mock.MatchedBy(func(f *models.TagFilterType) bool {
+		return f != nil && f.Aliases != nil && f.Aliases.Value == alias
+	})
+}
+
+func TestApplyMetadata(t *testing.T) {
+	mockImageReaderWriter := &mocks.ImageReaderWriter{}
+	mockTagReaderWriter := &mocks.TagReaderWriter{}
+
+	capturedAt := time.Date(2019, 5, 4, 13, 14, 15, 0, time.UTC)
+	rating := 7
+	m := &Metadata{
+		CapturedAt: &capturedAt,
+		Rating:     &rating,
+		Keywords:   []string{nameKeyword, aliasKeyword, newKeyword},
+	}
+
+	i := &models.Image{
+		ID: metadataImageID,
+	}
+
+	// the rating is clamped to 5
+	mockImageReaderWriter.On("Update", models.ImagePartial{
+		ID:     metadataImageID,
+		Date:   &models.SQLiteDate{String: "2019-05-04", Valid: true},
+		Rating: &sql.NullInt64{Int64: 5, Valid: true},
+	}).Return(nil, nil).Once()
+
+	mockImageReaderWriter.On("GetTagIDs", metadataImageID).Return([]int{keywordTagID}, nil).Once()
+
+	mockTagReaderWriter.On("Query", tagNameFilter(nameKeyword), mock.Anything).Return([]*models.Tag{{ID: keywordTagID}}, 1, nil).Once()
+	mockTagReaderWriter.On("Query", tagNameFilter(aliasKeyword), mock.Anything).Return(nil, 0, nil).Once()
+	mockTagReaderWriter.On("Query", tagAliasFilter(aliasKeyword), mock.Anything).Return([]*models.Tag{{ID: aliasTagID}}, 1, nil).Once()
+	mockTagReaderWriter.On("Query", tagNameFilter(newKeyword), mock.Anything).Return(nil, 0, nil).Once()
+	mockTagReaderWriter.On("Query", tagAliasFilter(newKeyword), mock.Anything).Return(nil, 0, nil).Once()
+	mockTagReaderWriter.On("Create", mock.MatchedBy(func(t models.Tag) bool {
+		return t.Name == newKeyword
+	})).Return(&models.Tag{ID: createdTagID}, nil).Once()
+
+	mockImageReaderWriter.On("UpdateTags", metadataImageID, []int{keywordTagID, aliasTagID, createdTagID}).Return(nil).Once()
+
+	err := ApplyMetadata(mockImageReaderWriter, mockTagReaderWriter, i, m, MetadataOptions{
+		SetDate:   true,
+		SetRating: true,
+		SetTags:   true,
+	})
+	assert.Nil(t, err)
+
+	mockImageReaderWriter.AssertExpectations(t)
+	mockTagReaderWriter.AssertExpectations(t)
+}
+
+func TestApplyMetadataExisting(t *testing.T) {
+	mockImageReaderWriter := &mocks.ImageReaderWriter{}
+	mockTagReaderWriter := &mocks.TagReaderWriter{}
+
+	capturedAt := time.Date(2019, 5, 4, 13, 14, 15, 0, time.UTC)
+	rating := 3
+	m := &Metadata{
+		CapturedAt: &capturedAt,
+		Rating:     &rating,
+		Keywords:   []string{nameKeyword},
+	}
+
+	// existing values are not overwritten, and existing tags are not re-set
+	i := &models.Image{
+		ID:     metadataImageID,
+		Date:   models.SQLiteDate{String: "2001-01-01", Valid: true},
+		Rating: sql.NullInt64{Int64: 1, Valid: true},
+	}
+
+	mockImageReaderWriter.On("GetTagIDs", metadataImageID).Return([]int{keywordTagID}, nil).Once()
+	mockTagReaderWriter.On("Query", tagNameFilter(nameKeyword), mock.Anything).Return([]*models.Tag{{ID: keywordTagID}}, 1, nil).Once()
+
+	err := ApplyMetadata(mockImageReaderWriter, mockTagReaderWriter, i, m, MetadataOptions{
+		SetDate:   true,
+		SetRating: true,
+		SetTags:   true,
+	})
+	assert.Nil(t, err)
+
+	// nothing is set if the options are not selected
+	err = ApplyMetadata(mockImageReaderWriter, mockTagReaderWriter, &models.Image{ID: metadataImageID}, m, MetadataOptions{})
+	assert.Nil(t, err)
+
+	mockImageReaderWriter.AssertExpectations(t)
+	mockTagReaderWriter.AssertExpectations(t)
+}
+
+func TestApplyMetadataError(t *testing.T) {
+	mockImageReaderWriter := &mocks.ImageReaderWriter{}
+	mockTagReaderWriter := &mocks.TagReaderWriter{}
+
+	m := &Metadata{
+		Keywords: []string{errTagKeyword},
+	}
+
+	mockImageReaderWriter.On("GetTagIDs", metadataImageID).Return(nil, nil).Once()
+	mockTagReaderWriter.On("Query", tagNameFilter(errTagKeyword), mock.Anything).Return(nil, 0, errors.New("Query error")).Once()
+
+	err := ApplyMetadata(mockImageReaderWriter, mockTagReaderWriter, &models.Image{ID: metadataImageID}, m, MetadataOptions{
+		SetTags: true,
+	})
+	assert.NotNil(t, err)
+
+	mockImageReaderWriter.AssertExpectations(t)
+	mockTagReaderWriter.AssertExpectations(t)
+}
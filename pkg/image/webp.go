@@ -0,0 +1,55 @@
+package image
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+// webpAnimationFlag is the flag of the VP8X chunk that is set if the file
+// contains an animation. Animated files store their frames in ANIM and ANMF
+// chunks.
+const webpAnimationFlag = 0x02
+
+var errInvalidWebP = errors.New("invalid webp file")
+
+// IsAnimatedWebP returns true if the WebP file at the path is animated. Only
+// the file header is read.
+func IsAnimatedWebP(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	return isAnimatedWebP(f)
+}
+
+func isAnimatedWebP(r io.Reader) (bool, error) {
+	// RIFF header, followed by the header of the first chunk and the flags
+	// of the VP8X chunk
+	header := make([]byte, 21)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, errInvalidWebP
+		}
+		return false, err
+	}
+
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WEBP" {
+		return false, errInvalidWebP
+	}
+
+	// simple files have a VP8 or VP8L chunk, and only extended files may
+	// be animated
+	if string(header[12:16]) != "VP8X" {
+		return false, nil
+	}
+
+	if binary.LittleEndian.Uint32(header[16:20]) < 10 {
+		return false, errInvalidWebP
+	}
+
+	return header[20]&webpAnimationFlag != 0, nil
+}
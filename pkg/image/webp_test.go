@@ -0,0 +1,72 @@
+package image
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testWebP returns the start of a webp file with a first chunk of the
+// provided type. The flags are only written for VP8X chunks.
+func testWebP(chunk string, flags byte) []byte {
+	data := make([]byte, 10)
+	data[0] = flags
+
+	ret := []byte("RIFF")
+	ret = append(ret, make([]byte, 4)...)
+	ret = append(ret, []byte("WEBP"+chunk)...)
+	size := make([]byte, 4)
+	binary.LittleEndian.PutUint32(size, uint32(len(data)))
+	ret = append(ret, size...)
+	ret = append(ret, data...)
+	binary.LittleEndian.PutUint32(ret[4:8], uint32(len(ret)-8))
+
+	return ret
+}
+
+func TestIsAnimatedWebP(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		animated bool
+		wantErr  bool
+	}{
+		{"lossy.webp", testWebP("VP8 ", 0), false, false},
+		{"lossless.webp", testWebP("VP8L", 0), false, false},
+		{"alpha.webp", testWebP("VP8X", 0x10), false, false},
+		{"animated.webp", testWebP("VP8X", webpAnimationFlag), true, false},
+		{"animated_alpha.webp", testWebP("VP8X", 0x10|webpAnimationFlag), true, false},
+		{"invalid.webp", []byte("not a webp file at all"), false, true},
+		{"truncated.webp", []byte("RIFF"), false, true},
+	}
+
+	for _, tt := range tests {
+		fn := filepath.Join(dir, tt.name)
+		if err := ioutil.WriteFile(fn, tt.data, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		animated, err := IsAnimatedWebP(fn)
+		if tt.wantErr {
+			assert.NotNil(t, err, tt.name)
+			continue
+		}
+
+		if assert.Nil(t, err, tt.name) {
+			assert.Equal(t, tt.animated, animated, tt.name)
+		}
+	}
+
+	_, err = IsAnimatedWebP(filepath.Join(dir, "missing.webp"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,167 @@
+package image
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	xmpNamespaceRDF       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	xmpNamespaceDC        = "http://purl.org/dc/elements/1.1/"
+	xmpNamespaceXMP       = "http://ns.adobe.com/xap/1.0/"
+	xmpNamespacePhotoshop = "http://ns.adobe.com/photoshop/1.0/"
+)
+
+// xmpPacketBounds are the start and end of the elements containing XMP
+// metadata, in order of preference.
+var xmpPacketBounds = [][2]string{
+	{"<x:xmpmeta", "</x:xmpmeta>"},
+	{"<rdf:RDF", "</rdf:RDF>"},
+}
+
+var xmpDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+type xmpData struct {
+	keywords   []string
+	creators   []string
+	rating     *int
+	createDate *time.Time
+}
+
+// findXMP returns the XMP packet embedded in the image data. XMP packets are
+// stored uncompressed in all of the supported formats, so the packet is
+// found by searching for its root element.
+func findXMP(data []byte) []byte {
+	for _, b := range xmpPacketBounds {
+		start := bytes.Index(data, []byte(b[0]))
+		if start == -1 {
+			continue
+		}
+
+		end := bytes.Index(data[start:], []byte(b[1]))
+		if end == -1 {
+			continue
+		}
+
+		return data[start : start+end+len(b[1])]
+	}
+
+	return nil
+}
+
+// parseXMP returns the keywords, creators, rating and creation date of the
+// XMP packet. Properties may be stored as either elements or attributes of
+// rdf:Description elements.
+func parseXMP(packet []byte) (*xmpData, error) {
+	ret := &xmpData{}
+	decoder := xml.NewDecoder(bytes.NewReader(packet))
+
+	// the stack of the properties containing the current element
+	var properties []xml.Name
+	var text strings.Builder
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space == xmpNamespaceRDF && t.Name.Local == "Description" {
+				for _, attr := range t.Attr {
+					ret.setProperty(attr.Name, nil, attr.Value)
+				}
+			}
+
+			properties = append(properties, t.Name)
+			text.Reset()
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if len(properties) == 0 {
+				continue
+			}
+
+			properties = properties[:len(properties)-1]
+			value := strings.TrimSpace(text.String())
+			text.Reset()
+
+			if value == "" {
+				continue
+			}
+
+			// array items are within a container within the property
+			if t.Name.Space == xmpNamespaceRDF && t.Name.Local == "li" {
+				if len(properties) >= 2 {
+					ret.setProperty(properties[len(properties)-2], &t.Name, value)
+				}
+				continue
+			}
+
+			ret.setProperty(t.Name, nil, value)
+		}
+	}
+
+	return ret, nil
+}
+
+// setProperty sets the value of a property. item is set if the value is an
+// item of an array property.
+func (x *xmpData) setProperty(name xml.Name, item *xml.Name, value string) {
+	switch {
+	case name.Space == xmpNamespaceDC && name.Local == "subject" && item != nil:
+		x.keywords = appendUniqueKeyword(x.keywords, value)
+	case name.Space == xmpNamespaceDC && name.Local == "creator" && item != nil:
+		x.creators = append(x.creators, value)
+	case name.Space == xmpNamespaceXMP && name.Local == "Rating" && item == nil:
+		// ratings are integers from -1 (rejected) to 5, but some
+		// applications write decimals
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			rating := int(v)
+			x.rating = &rating
+		}
+	case name.Space == xmpNamespacePhotoshop && name.Local == "DateCreated" && item == nil:
+		x.setCreateDate(value, true)
+	case name.Space == xmpNamespaceXMP && name.Local == "CreateDate" && item == nil:
+		x.setCreateDate(value, false)
+	}
+}
+
+// setCreateDate sets the creation date. photoshop:DateCreated is the date
+// the photo was taken, so it overrides xmp:CreateDate.
+func (x *xmpData) setCreateDate(value string, override bool) {
+	if x.createDate != nil && !override {
+		return
+	}
+
+	for _, layout := range xmpDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			x.createDate = &t
+			return
+		}
+	}
+}
+
+func appendUniqueKeyword(keywords []string, keyword string) []string {
+	for _, k := range keywords {
+		if strings.EqualFold(k, keyword) {
+			return keywords
+		}
+	}
+
+	return append(keywords, keyword)
+}
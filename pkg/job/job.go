@@ -18,6 +18,14 @@ func (j *jobExecImpl) Execute(ctx context.Context, progress *Progress) {
 	j.fn(ctx, progress)
 }
 
+// ResultProvider is implemented by JobExec implementations that produce a
+// result when executed.
+type ResultProvider interface {
+	// Result returns the result of the job. It is called once Execute has
+	// returned. Returns nil if there is no result.
+	Result() interface{}
+}
+
 // MakeJobExec returns a simple JobExec implementation using the provided
 // function.
 func MakeJobExec(fn func(ctx context.Context, progress *Progress)) JobExec {
@@ -54,6 +62,9 @@ type Job struct {
 	StartTime *time.Time
 	EndTime   *time.Time
 	AddTime   time.Time
+	// Result is the result of the finished job, if the JobExec implements
+	// ResultProvider. Nil otherwise.
+	Result interface{}
 
 	outerCtx   context.Context
 	exec       JobExec
@@ -185,7 +185,12 @@ func (m *Manager) dispatch(j *Job) (done chan struct{}) {
 		progress := m.newProgress(j)
 		j.exec.Execute(ctx, progress)
 
-		m.onJobFinish(j)
+		var result interface{}
+		if rp, ok := j.exec.(ResultProvider); ok {
+			result = rp.Result()
+		}
+
+		m.onJobFinish(j, result)
 
 		close(done)
 	}()
@@ -195,10 +200,12 @@ func (m *Manager) dispatch(j *Job) (done chan struct{}) {
 	return
 }
 
-func (m *Manager) onJobFinish(job *Job) {
+func (m *Manager) onJobFinish(job *Job, result interface{}) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	job.Result = result
+
 	if job.Status == StatusStopping {
 		job.Status = StatusCancelled
 	} else {
@@ -341,3 +341,38 @@ func TestSubscribe(t *testing.T) {
 
 	cancel()
 }
+
+type resultExec struct {
+	*testExec
+	result string
+}
+
+func (e *resultExec) Result() interface{} {
+	return e.result
+}
+
+func TestResult(t *testing.T) {
+	m := NewManager()
+
+	exec := &resultExec{
+		testExec: newTestExec(nil),
+		result:   "result",
+	}
+	jobID := m.Add(context.Background(), "test job", exec)
+
+	exec2 := newTestExec(nil)
+	jobID2 := m.Add(context.Background(), "test job", exec2)
+
+	// wait for the jobs to finish
+	time.Sleep(sleepTime)
+
+	assert := assert.New(t)
+	j := m.GetJob(jobID)
+	assert.Equal(StatusFinished, j.Status)
+	assert.Equal("result", j.Result)
+
+	// job without a result provider
+	j = m.GetJob(jobID2)
+	assert.Equal(StatusFinished, j.Status)
+	assert.Nil(j.Result)
+}
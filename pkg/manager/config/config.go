@@ -43,12 +43,49 @@ const ImageExtensions = "image_extensions"
 
 var defaultImageExtensions = []string{"png", "jpg", "jpeg", "gif", "webp"}
 
+// ImageClipExtensions is the config key for the extensions of short video
+// clips and animated images that are scanned as images.
+const ImageClipExtensions = "image_clip_extensions"
+
+var defaultImageClipExtensions = []string{"gif", "webp", "mp4", "m4v", "webm"}
+
 const GalleryExtensions = "gallery_extensions"
 
-var defaultGalleryExtensions = []string{"zip", "cbz"}
+var defaultGalleryExtensions = []string{"zip", "cbz", "rar", "cbr", "7z", "cb7", "tar", "cbt", "tar.gz", "tgz"}
 
 const CreateGalleriesFromFolders = "create_galleries_from_folders"
 
+// AutoRotateImages is the config key used to determine if full-size images
+// are served rotated according to their EXIF orientation.
+const AutoRotateImages = "auto_rotate_images"
+
+// ChapterTagMappings is the config key for the mappings of chapter titles to
+// the primary tags of the scene markers created from embedded chapters.
+const ChapterTagMappings = "chapter_tag_mappings"
+
+// ChapterDefaultTag is the config key for the name of the primary tag of
+// scene markers created from chapters without a tag mapping.
+const ChapterDefaultTag = "chapter_default_tag"
+const chapterDefaultTagDefault = "Chapter"
+
+// WatchStashPaths is the config key used to determine if the stash paths are
+// watched for changes, which are then scanned automatically.
+const WatchStashPaths = "watch_stash_paths"
+
+// WatchDebounceDelay is the config key for the number of seconds to wait
+// after the last change to the watched paths before scanning the changes.
+const WatchDebounceDelay = "watch_debounce_delay"
+const watchDebounceDelayDefault = 10
+
+// WatchUsePolling is the config key used to determine if the stash paths are
+// polled for changes, rather than using file system notifications.
+const WatchUsePolling = "watch_use_polling"
+
+// WatchPollInterval is the config key for the number of seconds between
+// polls of the stash paths.
+const WatchPollInterval = "watch_poll_interval"
+const watchPollIntervalDefault = 60
+
 // CalculateMD5 is the config key used to determine if MD5 should be calculated
 // for video files.
 const CalculateMD5 = "calculate_md5"
@@ -60,6 +97,15 @@ const VideoFileNamingAlgorithm = "video_file_naming_algorithm"
 const MaxTranscodeSize = "max_transcode_size"
 const MaxStreamingTranscodeSize = "max_streaming_transcode_size"
 
+// MaxConcurrentTranscodes is the config key for the maximum number of
+// concurrent streaming transcodes. A value of 0 means no limit.
+const MaxConcurrentTranscodes = "max_concurrent_transcodes"
+
+// TranscodeQueueTimeout is the config key for the number of seconds that a
+// streaming transcode waits for a free slot before being rejected.
+const TranscodeQueueTimeout = "transcode_queue_timeout"
+const transcodeQueueTimeoutDefault = 30
+
 const ParallelTasks = "parallel_tasks"
 const parallelTasksDefault = 1
 
@@ -300,6 +346,18 @@ func (i *Instance) GetImageExtensions() []string {
 	return ret
 }
 
+// GetImageClipExtensions returns the extensions of files that are scanned as
+// image clips. Files with a video extension are only scanned as image clips
+// in stash paths that exclude videos, and gif and webp files are only scanned
+// as image clips if they are animated.
+func (i *Instance) GetImageClipExtensions() []string {
+	ret := viper.GetStringSlice(ImageClipExtensions)
+	if ret == nil {
+		ret = defaultImageClipExtensions
+	}
+	return ret
+}
+
 func (i *Instance) GetGalleryExtensions() []string {
 	ret := viper.GetStringSlice(GalleryExtensions)
 	if ret == nil {
@@ -312,6 +370,62 @@ func (i *Instance) GetCreateGalleriesFromFolders() bool {
 	return viper.GetBool(CreateGalleriesFromFolders)
 }
 
+// GetAutoRotateImages returns true if full-size images should be served
+// rotated according to their EXIF orientation.
+func (i *Instance) GetAutoRotateImages() bool {
+	return viper.GetBool(AutoRotateImages)
+}
+
+// GetChapterTagMappings returns the mappings of chapter titles to the primary
+// tags of the scene markers created from embedded chapters.
+func (i *Instance) GetChapterTagMappings() []*models.ChapterTagMapping {
+	var mappings []*models.ChapterTagMapping
+	viper.UnmarshalKey(ChapterTagMappings, &mappings)
+	return mappings
+}
+
+// GetChapterDefaultTag returns the name of the primary tag of scene markers
+// created from chapters without a tag mapping.
+func (i *Instance) GetChapterDefaultTag() string {
+	viper.SetDefault(ChapterDefaultTag, chapterDefaultTagDefault)
+	return viper.GetString(ChapterDefaultTag)
+}
+
+// GetWatchStashPaths returns true if the stash paths should be watched for
+// changes.
+func (i *Instance) GetWatchStashPaths() bool {
+	return viper.GetBool(WatchStashPaths)
+}
+
+// GetWatchDebounceDelay returns the number of seconds to wait after the last
+// change to the watched paths before scanning the changes.
+func (i *Instance) GetWatchDebounceDelay() int {
+	ret := viper.GetInt(WatchDebounceDelay)
+	if ret < 0 {
+		return 0
+	}
+
+	return ret
+}
+
+// GetWatchUsePolling returns true if the stash paths should be polled for
+// changes, rather than using file system notifications.
+func (i *Instance) GetWatchUsePolling() bool {
+	return viper.GetBool(WatchUsePolling)
+}
+
+// GetWatchPollInterval returns the number of seconds between polls of the
+// stash paths. Polling is used if WatchUsePolling is set, or if file system
+// notifications are unavailable.
+func (i *Instance) GetWatchPollInterval() int {
+	ret := viper.GetInt(WatchPollInterval)
+	if ret <= 0 {
+		return watchPollIntervalDefault
+	}
+
+	return ret
+}
+
 func (i *Instance) GetLanguage() string {
 	ret := viper.GetString(Language)
 
@@ -479,6 +593,29 @@ func (i *Instance) GetMaxStreamingTranscodeSize() models.StreamingResolutionEnum
 	return models.StreamingResolutionEnum(ret)
 }
 
+// GetMaxConcurrentTranscodes returns the maximum number of streaming
+// transcodes that may run at once. Returns 0 if there is no limit.
+func (i *Instance) GetMaxConcurrentTranscodes() int {
+	ret := viper.GetInt(MaxConcurrentTranscodes)
+	if ret < 0 {
+		return 0
+	}
+
+	return ret
+}
+
+// GetTranscodeQueueTimeout returns the number of seconds that a streaming
+// transcode waits for a free slot before being rejected. Returns 0 if
+// transcodes are rejected immediately when there are no free slots.
+func (i *Instance) GetTranscodeQueueTimeout() int {
+	ret := viper.GetInt(TranscodeQueueTimeout)
+	if ret < 0 {
+		return 0
+	}
+
+	return ret
+}
+
 func (i *Instance) GetAPIKey() string {
 	return viper.GetString(ApiKey)
 }
@@ -752,6 +889,9 @@ func (i *Instance) Validate() error {
 
 func (i *Instance) setDefaultValues() error {
 	viper.SetDefault(ParallelTasks, parallelTasksDefault)
+	viper.SetDefault(TranscodeQueueTimeout, transcodeQueueTimeoutDefault)
+	viper.SetDefault(WatchDebounceDelay, watchDebounceDelayDefault)
+	viper.SetDefault(WatchPollInterval, watchPollIntervalDefault)
 	viper.SetDefault(PreviewSegmentDuration, previewSegmentDurationDefault)
 	viper.SetDefault(PreviewSegments, previewSegmentsDefault)
 	viper.SetDefault(PreviewExcludeStart, previewExcludeStartDefault)
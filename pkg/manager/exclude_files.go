@@ -1,7 +1,6 @@
 package manager
 
 import (
-	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -87,10 +86,12 @@ func matchFileSimple(file string, regExps []*regexp.Regexp) bool {
 	return false
 }
 
+// matchExtension returns true if the path has one of the extensions,
+// ignoring case. Extensions may include multiple parts, such as tar.gz.
 func matchExtension(path string, extensions []string) bool {
-	ext := filepath.Ext(path)
+	path = strings.ToLower(path)
 	for _, e := range extensions {
-		if strings.EqualFold(ext, "."+e) {
+		if strings.HasSuffix(path, "."+strings.ToLower(e)) {
 			return true
 		}
 	}
@@ -29,6 +29,13 @@ type GeneratorInfo struct {
 	VideoFile ffmpeg.VideoFile
 
 	Audio bool // used for preview generation
+
+	// IntroEnd, OutroStart and Cuts are the results of scene detection, used
+	// to place preview chunks. OutroStart is 0 if scene detection has not been
+	// run.
+	IntroEnd   float64
+	OutroStart float64
+	Cuts       []float64
 }
 
 func newGeneratorInfo(videoFile ffmpeg.VideoFile) (*GeneratorInfo, error) {
@@ -149,3 +156,54 @@ func (g GeneratorInfo) getStepSizeAndOffset() (stepSize float64, offset float64)
 	stepSize = duration / float64(g.ChunkCount)
 	return
 }
+
+// getChunkStartTimes returns the start times of the preview chunks with the
+// provided duration.
+//
+// Chunks are spaced evenly using the step size and offset. If scene detection
+// has been run, the intro and outro of the scene are excluded where there is
+// room for the chunks, and each chunk is moved to the nearest cut within half
+// a step so that chunks start at the start of a shot.
+func (g GeneratorInfo) getChunkStartTimes(chunkDuration float64) []float64 {
+	stepSize, offset := g.getStepSizeAndOffset()
+	end := offset + stepSize*float64(g.ChunkCount)
+
+	if g.OutroStart > 0 {
+		start := math.Max(offset, g.IntroEnd)
+		outroEnd := math.Min(end, g.OutroStart)
+		if outroEnd-start >= chunkDuration*float64(g.ChunkCount) {
+			offset = start
+			end = outroEnd
+			stepSize = (end - offset) / float64(g.ChunkCount)
+		}
+	}
+
+	ret := make([]float64, g.ChunkCount)
+	last := math.Inf(-1)
+	for i := range ret {
+		t := offset + float64(i)*stepSize
+		if cut, found := nearestCut(g.Cuts, t, stepSize/2); found && cut > last && cut+chunkDuration <= end {
+			t = cut
+		}
+
+		ret[i] = t
+		last = t
+	}
+
+	return ret
+}
+
+// nearestCut returns the cut nearest to t, if it is within maxDistance of t.
+func nearestCut(cuts []float64, t float64, maxDistance float64) (float64, bool) {
+	found := false
+	var ret float64
+	for _, c := range cuts {
+		d := math.Abs(c - t)
+		if d <= maxDistance && (!found || d < math.Abs(ret-t)) {
+			ret = c
+			found = true
+		}
+	}
+
+	return ret, found
+}
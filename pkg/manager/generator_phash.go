@@ -11,9 +11,14 @@ import (
 
 	"github.com/stashapp/stash/pkg/ffmpeg"
 	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/utils"
 )
 
+// phashFrameSize is the width and height that frames are scaled to when
+// generating phash sequences. The perception hash resizes images to 64x64.
+const phashFrameSize = 64
+
 type PhashGenerator struct {
 	Info *GeneratorInfo
 
@@ -57,6 +62,37 @@ func (g *PhashGenerator) Generate() (*uint64, error) {
 	return &hashValue, nil
 }
 
+// GenerateSequence generates the phashes of frames of the video taken every
+// interval seconds.
+func (g *PhashGenerator) GenerateSequence(interval float64) ([]models.ScenePhashFrame, error) {
+	logger.Infof("[generator] generating phash sequence for %s", g.Info.VideoFile.Path)
+
+	encoder := ffmpeg.NewEncoder(instance.FFMPEGPath)
+	frames, err := encoder.FrameSequence(g.Info.VideoFile, ffmpeg.FrameSequenceOptions{
+		Interval: interval,
+		Width:    phashFrameSize,
+		Height:   phashFrameSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []models.ScenePhashFrame
+	for i, frame := range frames {
+		hash, err := goimagehash.PerceptionHash(frame)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, models.ScenePhashFrame{
+			Seconds: float64(i) * interval,
+			Phash:   int64(hash.GetHash()),
+		})
+	}
+
+	return ret, nil
+}
+
 func (g *PhashGenerator) generateSprite(encoder *ffmpeg.Encoder) (image.Image, error) {
 	logger.Infof("[generator] generating phash sprite for %s", g.Info.VideoFile.Path)
 
@@ -105,8 +105,6 @@ func (g *PreviewGenerator) generateVideo(encoder *ffmpeg.Encoder, fallback bool)
 	tmpFiles = append(tmpFiles, g.getConcatFilePath()) // add concat filename to tmpFiles
 	defer func() { removeFiles(tmpFiles) }()           // remove tmpFiles when done
 
-	stepSize, offset := g.Info.getStepSizeAndOffset()
-
 	durationSegment := g.Info.ChunkDuration
 	if durationSegment < 0.75 { // a very short duration can create files without a video stream
 		durationSegment = 0.75 // use 0.75 in that case
@@ -115,8 +113,7 @@ func (g *PreviewGenerator) generateVideo(encoder *ffmpeg.Encoder, fallback bool)
 
 	includeAudio := g.Info.Audio
 
-	for i := 0; i < g.Info.ChunkCount; i++ {
-		time := offset + (float64(i) * stepSize)
+	for i, time := range g.Info.getChunkStartTimes(durationSegment) {
 		num := fmt.Sprintf("%.3d", i)
 		filename := "preview_" + g.VideoChecksum + "_" + num + ".mp4"
 		chunkOutputPath := instance.Paths.Generated.GetTmpPath(filename)
@@ -0,0 +1,71 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetChunkStartTimes(t *testing.T) {
+	const chunkDuration = 1
+
+	tests := []struct {
+		name       string
+		introEnd   float64
+		outroStart float64
+		cuts       []float64
+		want       []float64
+	}{
+		{
+			"no detection",
+			0,
+			0,
+			nil,
+			[]float64{0, 25, 50, 75},
+		},
+		{
+			"intro and outro",
+			20,
+			60,
+			nil,
+			[]float64{20, 30, 40, 50},
+		},
+		{
+			"intro and outro too long",
+			50,
+			52,
+			nil,
+			[]float64{0, 25, 50, 75},
+		},
+		{
+			"cuts",
+			0,
+			100,
+			[]float64{
+				3,
+				// no cut within half a step of 25
+				56,
+				// nearest cut is used
+				72, 80,
+			},
+			[]float64{3, 25, 56, 72},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := GeneratorInfo{
+				ChunkCount: 4,
+				VideoFile: ffmpeg.VideoFile{
+					Duration: 100,
+				},
+				IntroEnd:   tt.introEnd,
+				OutroStart: tt.outroStart,
+				Cuts:       tt.cuts,
+			}
+
+			assert.Equal(t, tt.want, g.getChunkStartTimes(chunkDuration))
+		})
+	}
+}
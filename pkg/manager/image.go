@@ -1,10 +1,13 @@
 package manager
 
 import (
-	"archive/zip"
+	"database/sql"
 	"os"
 	"strings"
 
+	"github.com/stashapp/stash/pkg/archive"
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/logger"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/utils"
@@ -12,16 +15,99 @@ import (
 
 // DeleteGeneratedImageFiles deletes generated files for the provided image.
 func DeleteGeneratedImageFiles(image *models.Image) {
-	thumbPath := GetInstance().Paths.Generated.GetThumbnailPath(image.Checksum, models.DefaultGthumbWidth)
-	exists, _ := utils.FileExists(thumbPath)
+	deleteGeneratedImageFile(GetInstance().Paths.Generated.GetThumbnailPath(image.Checksum, models.DefaultGthumbWidth))
+	deleteGeneratedImageFile(GetInstance().Paths.Generated.GetImageClipPreviewPath(image.Checksum, models.DefaultGthumbWidth))
+}
+
+func deleteGeneratedImageFile(path string) {
+	exists, _ := utils.FileExists(path)
 	if exists {
-		err := os.Remove(thumbPath)
+		err := os.Remove(path)
 		if err != nil {
-			logger.Warnf("Could not delete file %s: %s", thumbPath, err.Error())
+			logger.Warnf("Could not delete file %s: %s", path, err.Error())
 		}
 	}
 }
 
+// animatedImageExtensions are the extensions of image formats that may or
+// may not be animated. These files are only scanned as image clips if they
+// are animated.
+var animatedImageExtensions = []string{"gif", "webp"}
+
+// isStaticImage returns true if the file is a gif or webp file that is not
+// animated.
+func isStaticImage(path string) bool {
+	var animated bool
+	var err error
+	switch {
+	case matchExtension(path, []string{"gif"}):
+		animated, err = image.IsAnimatedGIF(path)
+	case matchExtension(path, []string{"webp"}):
+		animated, err = image.IsAnimatedWebP(path)
+	default:
+		return false
+	}
+
+	if err != nil {
+		logger.Warnf("error reading frames of %s: %s", path, err.Error())
+		return true
+	}
+
+	return !animated
+}
+
+// imageClipChanged returns true if the image must be rescanned because it
+// may now be scanned differently as an image clip, such as when the image
+// clip extensions have changed.
+func imageClipChanged(i *models.Image) bool {
+	clip := isImageClip(i.Path)
+	if !clip || i.Clip {
+		return clip != i.Clip
+	}
+
+	// the frame count of gif and webp files that are not clips is set once
+	// they have been found to be static
+	return !matchExtension(i.Path, animatedImageExtensions) || !i.FrameCount.Valid
+}
+
+// setImageFileDetails sets the dimensions and size of the image file. The
+// duration and frame count are also set for image clips. The frame count of
+// static gif and webp files is set to 1.
+func setImageFileDetails(i *models.Image) error {
+	if !isImageClip(i.Path) {
+		i.Clip = false
+		i.Duration = sql.NullFloat64{}
+		i.FrameCount = sql.NullInt64{}
+		return image.SetFileDetails(i)
+	}
+
+	if isStaticImage(i.Path) {
+		i.Clip = false
+		i.Duration = sql.NullFloat64{}
+		i.FrameCount = models.NullInt64(1)
+		return image.SetFileDetails(i)
+	}
+
+	videoFile, err := ffmpeg.NewVideoFile(instance.FFProbePath, i.Path, false)
+	if err != nil {
+		return err
+	}
+
+	setImageClipDetails(i, videoFile)
+	return nil
+}
+
+// setImageClipDetails sets the details of the image clip from its probe
+// result.
+func setImageClipDetails(i *models.Image, videoFile *ffmpeg.VideoFile) {
+	i.Clip = true
+	i.Width = models.NullInt64(int64(videoFile.Width))
+	i.Height = models.NullInt64(int64(videoFile.Height))
+	i.Size = models.NullInt64(videoFile.Size)
+	i.Duration = sql.NullFloat64{Float64: videoFile.Duration, Valid: true}
+	i.FrameCount = models.NullInt64(int64(videoFile.FrameCount))
+}
+
 // DeleteImageFile deletes the image file from the filesystem.
 func DeleteImageFile(image *models.Image) {
 	err := os.Remove(image.Path)
@@ -30,23 +116,23 @@ func DeleteImageFile(image *models.Image) {
 	}
 }
 
-func walkGalleryZip(path string, walkFunc func(file *zip.File) error) error {
-	readCloser, err := zip.OpenReader(path)
+func walkGalleryArchive(path string, walkFunc func(file archive.File) error) error {
+	r, err := archive.Open(path)
 	if err != nil {
 		return err
 	}
-	defer readCloser.Close()
+	defer r.Close()
 
-	for _, file := range readCloser.File {
+	for _, file := range r.Files() {
 		if file.FileInfo().IsDir() {
 			continue
 		}
 
-		if strings.Contains(file.Name, "__MACOSX") {
+		if strings.Contains(file.Name(), "__MACOSX") {
 			continue
 		}
 
-		if !isImage(file.Name) {
+		if !isImage(file.Name()) {
 			continue
 		}
 
@@ -59,9 +145,9 @@ func walkGalleryZip(path string, walkFunc func(file *zip.File) error) error {
 	return nil
 }
 
-func countImagesInZip(path string) int {
+func countImagesInArchive(path string) int {
 	ret := 0
-	walkGalleryZip(path, func(file *zip.File) error {
+	walkGalleryArchive(path, func(file archive.File) error {
 		ret++
 		return nil
 	})
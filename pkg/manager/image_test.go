@@ -0,0 +1,240 @@
+package manager
+
+import (
+	"database/sql"
+	"image"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	imagepkg "github.com/stashapp/stash/pkg/image"
+	"github.com/stashapp/stash/pkg/manager/config"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const (
+	imageClipTestVideos = "/stash/videos"
+	imageClipTestImages = "/stash/images"
+)
+
+// setImageClipTestConfig sets the stash paths and extensions used by the
+// image clip tests. Returns a function that resets the configuration.
+func setImageClipTestConfig(stashPaths ...*models.StashConfig) func() {
+	c := config.GetInstance()
+	c.Set(config.Stash, stashPaths)
+	c.Set(config.VideoExtensions, []string{"mp4", "webm"})
+	c.Set(config.ImageExtensions, []string{"jpg", "png", "gif", "webp"})
+	c.Set(config.ImageClipExtensions, []string{"gif", "webp", "mp4"})
+
+	return func() {
+		c.Set(config.Stash, nil)
+		c.Set(config.VideoExtensions, nil)
+		c.Set(config.ImageExtensions, nil)
+		c.Set(config.ImageClipExtensions, nil)
+	}
+}
+
+func TestIsImageClip(t *testing.T) {
+	defer setImageClipTestConfig(
+		&models.StashConfig{Path: imageClipTestVideos},
+		&models.StashConfig{Path: imageClipTestImages, ExcludeVideo: true},
+	)()
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{filepath.Join(imageClipTestVideos, "animation.gif"), true},
+		{filepath.Join(imageClipTestVideos, "Animation.GIF"), true},
+		{filepath.Join(imageClipTestVideos, "animation.webp"), true},
+		// videos are scanned as scenes
+		{filepath.Join(imageClipTestVideos, "clip.mp4"), false},
+		// unless videos are excluded from the stash path
+		{filepath.Join(imageClipTestImages, "clip.mp4"), true},
+		// not a clip extension
+		{filepath.Join(imageClipTestImages, "clip.webm"), false},
+		{filepath.Join(imageClipTestImages, "photo.jpg"), false},
+		// clips within archives are not supported
+		{imagepkg.ArchiveFilename(filepath.Join(imageClipTestImages, "gallery.zip"), "animation.gif"), false},
+		// not in a stash path
+		{"/other/clip.mp4", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isImageClip(tt.path), tt.path)
+	}
+}
+
+func writeImageClipTestGIF(t *testing.T, fn string, frames int) {
+	g := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		g.Image = append(g.Image, image.NewPaletted(image.Rect(0, 0, 16, 8), palette.Plan9))
+		g.Delay = append(g.Delay, 10)
+	}
+
+	f, err := os.Create(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := gif.EncodeAll(f, g); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetImageFileDetails(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-image-clip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer setImageClipTestConfig(&models.StashConfig{Path: dir, ExcludeVideo: true})()
+
+	staticGIF := filepath.Join(dir, "static.gif")
+	writeImageClipTestGIF(t, staticGIF, 1)
+
+	pngFile := filepath.Join(dir, "image.png")
+	f, err := os.Create(pngFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, 16, 8))); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	// static gif files are not clips, even though gif is a clip extension
+	i := &models.Image{
+		Path: staticGIF,
+		Clip: true,
+	}
+	if assert.Nil(t, setImageFileDetails(i)) {
+		assert.False(t, i.Clip)
+		assert.Equal(t, models.NullInt64(16), i.Width)
+		assert.Equal(t, models.NullInt64(8), i.Height)
+		assert.Equal(t, models.NullInt64(1), i.FrameCount)
+		assert.False(t, i.Duration.Valid)
+		assert.True(t, i.Size.Valid)
+	}
+
+	i = &models.Image{
+		Path:       pngFile,
+		Clip:       true,
+		Duration:   sql.NullFloat64{Float64: 1, Valid: true},
+		FrameCount: models.NullInt64(10),
+	}
+	if assert.Nil(t, setImageFileDetails(i)) {
+		assert.False(t, i.Clip)
+		assert.Equal(t, models.NullInt64(16), i.Width)
+		assert.False(t, i.FrameCount.Valid)
+		assert.False(t, i.Duration.Valid)
+	}
+
+	// animated gif files are probed as clips
+	i = &models.Image{}
+	setImageClipDetails(i, &ffmpeg.VideoFile{
+		Width:      320,
+		Height:     240,
+		Size:       1024,
+		Duration:   2.5,
+		FrameCount: 25,
+	})
+	assert.Equal(t, &models.Image{
+		Clip:       true,
+		Width:      models.NullInt64(320),
+		Height:     models.NullInt64(240),
+		Size:       models.NullInt64(1024),
+		Duration:   sql.NullFloat64{Float64: 2.5, Valid: true},
+		FrameCount: models.NullInt64(25),
+	}, i)
+}
+
+func TestImageClipChanged(t *testing.T) {
+	defer setImageClipTestConfig(&models.StashConfig{Path: imageClipTestImages, ExcludeVideo: true})()
+
+	gifPath := filepath.Join(imageClipTestImages, "animation.gif")
+	webpPath := filepath.Join(imageClipTestImages, "animation.webp")
+	mp4Path := filepath.Join(imageClipTestImages, "clip.mp4")
+	jpgPath := filepath.Join(imageClipTestImages, "photo.jpg")
+
+	tests := []struct {
+		name  string
+		image models.Image
+		want  bool
+	}{
+		{"clip", models.Image{Path: mp4Path, Clip: true}, false},
+		{"new clip extension", models.Image{Path: mp4Path}, true},
+		{"removed clip extension", models.Image{Path: jpgPath, Clip: true}, true},
+		{"image", models.Image{Path: jpgPath}, false},
+		{"animated gif", models.Image{Path: gifPath, Clip: true}, false},
+		{"static gif", models.Image{Path: gifPath, FrameCount: models.NullInt64(1)}, false},
+		// scanned before gif files were clips
+		{"unchecked gif", models.Image{Path: gifPath}, true},
+		{"animated webp", models.Image{Path: webpPath, Clip: true}, false},
+		{"static webp", models.Image{Path: webpPath, FrameCount: models.NullInt64(1)}, false},
+		{"unchecked webp", models.Image{Path: webpPath}, true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, imageClipChanged(&tt.image), tt.name)
+	}
+}
+
+// writeImageClipTestWebP writes the header of an extended webp file, which
+// is enough to determine if it is animated.
+func writeImageClipTestWebP(t *testing.T, fn string, animated bool) {
+	var flags byte
+	if animated {
+		flags = 0x02
+	}
+
+	data := []byte("RIFF\x16\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00")
+	data = append(data, flags)
+	data = append(data, make([]byte, 9)...)
+
+	if err := ioutil.WriteFile(fn, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsStaticImage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-image-clip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	staticGIF := filepath.Join(dir, "static.gif")
+	writeImageClipTestGIF(t, staticGIF, 1)
+	animatedGIF := filepath.Join(dir, "animated.gif")
+	writeImageClipTestGIF(t, animatedGIF, 2)
+	staticWebP := filepath.Join(dir, "static.webp")
+	writeImageClipTestWebP(t, staticWebP, false)
+	animatedWebP := filepath.Join(dir, "Animated.WEBP")
+	writeImageClipTestWebP(t, animatedWebP, true)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{staticGIF, true},
+		{animatedGIF, false},
+		{staticWebP, true},
+		{animatedWebP, false},
+		// other formats are clips by extension
+		{filepath.Join(dir, "clip.mp4"), false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isStaticImage(tt.path), tt.path)
+	}
+}
@@ -11,6 +11,7 @@ import (
 type SceneMarker struct {
 	Title      string          `json:"title,omitempty"`
 	Seconds    string          `json:"seconds,omitempty"`
+	EndSeconds string          `json:"end_seconds,omitempty"`
 	PrimaryTag string          `json:"primary_tag,omitempty"`
 	Tags       []string        `json:"tags,omitempty"`
 	CreatedAt  models.JSONTime `json:"created_at,omitempty"`
@@ -36,26 +37,32 @@ type SceneMovie struct {
 }
 
 type Scene struct {
-	Title      string          `json:"title,omitempty"`
-	Checksum   string          `json:"checksum,omitempty"`
-	OSHash     string          `json:"oshash,omitempty"`
-	Phash      string          `json:"phash,omitempty"`
-	Studio     string          `json:"studio,omitempty"`
-	URL        string          `json:"url,omitempty"`
-	Date       string          `json:"date,omitempty"`
-	Rating     int             `json:"rating,omitempty"`
-	Organized  bool            `json:"organized,omitempty"`
-	OCounter   int             `json:"o_counter,omitempty"`
-	Details    string          `json:"details,omitempty"`
-	Galleries  []string        `json:"galleries,omitempty"`
-	Performers []string        `json:"performers,omitempty"`
-	Movies     []SceneMovie    `json:"movies,omitempty"`
-	Tags       []string        `json:"tags,omitempty"`
-	Markers    []SceneMarker   `json:"markers,omitempty"`
-	File       *SceneFile      `json:"file,omitempty"`
-	Cover      string          `json:"cover,omitempty"`
-	CreatedAt  models.JSONTime `json:"created_at,omitempty"`
-	UpdatedAt  models.JSONTime `json:"updated_at,omitempty"`
+	Title        string            `json:"title,omitempty"`
+	Checksum     string            `json:"checksum,omitempty"`
+	OSHash       string            `json:"oshash,omitempty"`
+	Phash        string            `json:"phash,omitempty"`
+	Studio       string            `json:"studio,omitempty"`
+	URL          string            `json:"url,omitempty"`
+	Date         string            `json:"date,omitempty"`
+	Rating       int               `json:"rating,omitempty"`
+	Organized    bool              `json:"organized,omitempty"`
+	OCounter     int               `json:"o_counter,omitempty"`
+	Details      string            `json:"details,omitempty"`
+	Galleries    []string          `json:"galleries,omitempty"`
+	Performers   []string          `json:"performers,omitempty"`
+	Movies       []SceneMovie      `json:"movies,omitempty"`
+	Tags         []string          `json:"tags,omitempty"`
+	Markers      []SceneMarker     `json:"markers,omitempty"`
+	File         *SceneFile        `json:"file,omitempty"`
+	Cover        string            `json:"cover,omitempty"`
+	CreatedAt    models.JSONTime   `json:"created_at,omitempty"`
+	UpdatedAt    models.JSONTime   `json:"updated_at,omitempty"`
+	ResumeTime   float64           `json:"resume_time,omitempty"`
+	PlayDuration float64           `json:"play_duration,omitempty"`
+	PlayCount    int               `json:"play_count,omitempty"`
+	LastPlayedAt *models.JSONTime  `json:"last_played_at,omitempty"`
+	OHistory     []models.JSONTime `json:"o_history,omitempty"`
+	PlayHistory  []models.JSONTime `json:"play_history,omitempty"`
 }
 
 func LoadSceneFile(filePath string) (*Scene, error) {
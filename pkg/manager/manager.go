@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime/pprof"
 	"sync"
 	"time"
@@ -43,9 +44,22 @@ type singleton struct {
 
 	DLNAService *dlna.Service
 
+	StreamManager  *ffmpeg.SegmentManager
+	StreamSessions *StreamSessionManager
+
+	Watcher      *Watcher
+	watcherMutex sync.Mutex
+	// watcherConfig is the configuration the watcher was started with
+	watcherConfig *watcherConfig
+
 	TxnManager models.TransactionManager
 
 	scanSubs *subscriptionManager
+
+	// generatedUsage is the usage of the generated directory found by the
+	// last clean generated job
+	generatedUsage      []*GeneratedUsage
+	generatedUsageMutex sync.Mutex
 }
 
 var instance *singleton
@@ -69,10 +83,11 @@ func Initialize() *singleton {
 		initProfiling(cfg.GetCPUProfilePath())
 
 		instance = &singleton{
-			Config:        cfg,
-			JobManager:    job.NewManager(),
-			DownloadStore: NewDownloadStore(),
-			PluginCache:   plugin.NewCache(cfg),
+			Config:         cfg,
+			JobManager:     job.NewManager(),
+			DownloadStore:  NewDownloadStore(),
+			StreamSessions: NewStreamSessionManager(),
+			PluginCache:    plugin.NewCache(cfg),
 
 			TxnManager: sqlite.NewTransactionManager(),
 
@@ -242,7 +257,109 @@ func (s *singleton) RefreshConfig() {
 		utils.EnsureDir(s.Paths.Generated.Markers)
 		utils.EnsureDir(s.Paths.Generated.Transcodes)
 		utils.EnsureDir(s.Paths.Generated.Downloads)
+
+		s.refreshStreamManager()
+
+		// the watcher is started after migration if the database is not
+		// yet ready
+		if database.Ready() == nil {
+			s.refreshWatcher()
+		}
+	}
+}
+
+// refreshStreamManager creates the stream manager if it does not exist, or
+// if the stream cache directory has changed.
+func (s *singleton) refreshStreamManager() {
+	cacheDir := s.Config.GetCachePath()
+	if cacheDir == "" {
+		cacheDir = s.Paths.Generated.Tmp
+	}
+	cacheDir = filepath.Join(cacheDir, "stream")
+
+	if s.StreamManager != nil {
+		if s.StreamManager.CacheDir() == cacheDir {
+			return
+		}
+
+		s.StreamManager.Shutdown()
+	}
+
+	s.StreamManager = ffmpeg.NewSegmentManager(cacheDir)
+}
+
+// watcherConfig is the configuration that affects the watcher. The watcher
+// is only restarted when it changes, since setting up the watches walks the
+// stash paths.
+type watcherConfig struct {
+	stashPaths    []models.StashConfig
+	usePolling    bool
+	pollInterval  time.Duration
+	debounceDelay time.Duration
+
+	// the scan filter determines which directories are watched
+	videoExtensions   []string
+	imageExtensions   []string
+	clipExtensions    []string
+	galleryExtensions []string
+	excludes          []string
+	imageExcludes     []string
+	generatedPath     string
+}
+
+// getWatcherConfig returns the current watcher configuration, or nil if
+// watching the stash paths is disabled.
+func getWatcherConfig(c *config.Instance) *watcherConfig {
+	stashPaths := c.GetStashPaths()
+	if !c.GetWatchStashPaths() || len(stashPaths) == 0 {
+		return nil
+	}
+
+	ret := &watcherConfig{
+		usePolling:        c.GetWatchUsePolling(),
+		pollInterval:      time.Duration(c.GetWatchPollInterval()) * time.Second,
+		debounceDelay:     time.Duration(c.GetWatchDebounceDelay()) * time.Second,
+		videoExtensions:   c.GetVideoExtensions(),
+		imageExtensions:   c.GetImageExtensions(),
+		clipExtensions:    c.GetImageClipExtensions(),
+		galleryExtensions: c.GetGalleryExtensions(),
+		excludes:          c.GetExcludes(),
+		imageExcludes:     c.GetImageExcludes(),
+		generatedPath:     c.GetGeneratedPath(),
+	}
+	for _, p := range stashPaths {
+		ret.stashPaths = append(ret.stashPaths, *p)
+	}
+
+	return ret
+}
+
+// refreshWatcher restarts the watcher if the watcher configuration has
+// changed. The watcher is stopped if watching the stash paths is disabled.
+func (s *singleton) refreshWatcher() {
+	s.watcherMutex.Lock()
+	defer s.watcherMutex.Unlock()
+
+	c := s.Config
+	watcherConfig := getWatcherConfig(c)
+	if reflect.DeepEqual(watcherConfig, s.watcherConfig) {
+		return
 	}
+
+	if s.Watcher != nil {
+		s.Watcher.Stop()
+		s.Watcher = nil
+	}
+
+	s.watcherConfig = watcherConfig
+	if watcherConfig == nil {
+		return
+	}
+
+	s.Watcher = NewWatcher(c.GetStashPaths(), newScanFilter(), watcherConfig.usePolling, watcherConfig.pollInterval, watcherConfig.debounceDelay, s.scanChangedPaths)
+
+	// setting up the watches walks the stash paths, which may take a while
+	go s.Watcher.Start()
 }
 
 // RefreshScraperCache refreshes the scraper cache. Call this when scraper
@@ -323,6 +440,7 @@ func (s *singleton) Migrate(input models.MigrateInput) error {
 		return fmt.Errorf("error backing up database: %s", err)
 	}
 
+	fromVersion := database.Version()
 	if err := database.RunMigrations(); err != nil {
 		errStr := fmt.Sprintf("error performing migration: %s", err)
 
@@ -339,6 +457,7 @@ func (s *singleton) Migrate(input models.MigrateInput) error {
 
 	// perform post-migration operations
 	s.PostMigrate()
+	s.migrateData(fromVersion)
 
 	// if no backup path was provided, then delete the created backup
 	if input.BackupPath == "" {
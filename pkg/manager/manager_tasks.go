@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/remeh/sizedwaitgroup"
 
+	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/job"
 	"github.com/stashapp/stash/pkg/logger"
 	"github.com/stashapp/stash/pkg/manager/config"
@@ -32,6 +34,23 @@ func isImage(pathname string) bool {
 	return matchExtension(pathname, imgExt)
 }
 
+// isImageClip returns true if the file is scanned as an image clip. Files
+// with a video extension are scanned as scenes, unless they are in a stash
+// path that excludes videos. Clips within archive files are not supported.
+func isImageClip(pathname string) bool {
+	clipExt := config.GetInstance().GetImageClipExtensions()
+	if !matchExtension(pathname, clipExt) || image.IsArchivePath(pathname) {
+		return false
+	}
+
+	if !isVideo(pathname) {
+		return true
+	}
+
+	s := getStashFromPath(pathname)
+	return s != nil && s.ExcludeVideo
+}
+
 func getScanPaths(inputPaths []string) []*models.StashConfig {
 	if len(inputPaths) == 0 {
 		return config.GetInstance().GetStashPaths()
@@ -61,8 +80,11 @@ func (s *singleton) ScanSubscribe(ctx context.Context) <-chan bool {
 }
 
 func (s *singleton) Scan(ctx context.Context, input models.ScanMetadataInput) (int, error) {
-	if err := s.validateFFMPEG(); err != nil {
-		return 0, err
+	// a dry run does not probe the files
+	if !utils.IsTrue(input.DryRun) {
+		if err := s.validateFFMPEG(); err != nil {
+			return 0, err
+		}
 	}
 
 	scanJob := ScanJob{
@@ -71,7 +93,76 @@ func (s *singleton) Scan(ctx context.Context, input models.ScanMetadataInput) (i
 		subscriptions: s.scanSubs,
 	}
 
-	return s.JobManager.Add(ctx, "Scanning...", &scanJob), nil
+	description := "Scanning..."
+	if utils.IsTrue(input.DryRun) {
+		description = "Scanning (dry run)..."
+	}
+
+	return s.JobManager.Add(ctx, description, &scanJob), nil
+}
+
+// scanChangedPaths queues a job that scans the provided paths that exist,
+// and cleans the objects with files at the provided paths that do not.
+func (s *singleton) scanChangedPaths(paths []string) {
+	var changed []string
+	var removed []string
+	for _, p := range paths {
+		_, err := os.Stat(p)
+		switch {
+		case err == nil:
+			changed = append(changed, p)
+		case os.IsNotExist(err):
+			// don't clean files if the stash path itself is unavailable
+			stash := getStashFromDirPath(p)
+			if stash == nil {
+				continue
+			}
+			if exists, _ := utils.DirExists(stash.Path); !exists {
+				logger.Warnf("[watcher] stash path %s not found. Not cleaning %s", stash.Path, p)
+				continue
+			}
+			removed = append(removed, p)
+		default:
+			logger.Warnf("[watcher] error accessing %s: %s", p, err.Error())
+		}
+	}
+
+	changed = filterNestedPaths(changed)
+	removed = filterNestedPaths(removed)
+
+	if len(changed) > 0 {
+		if err := s.validateFFMPEG(); err != nil {
+			logger.Errorf("[watcher] unable to scan changed files: %s", err.Error())
+			changed = nil
+		}
+	}
+
+	if len(changed) == 0 && len(removed) == 0 {
+		return
+	}
+
+	logger.Infof("[watcher] %d paths changed, %d paths removed", len(changed), len(removed))
+
+	j := job.MakeJobExec(func(ctx context.Context, progress *job.Progress) {
+		// scan before cleaning so that moved files are detected by the scan
+		if len(changed) > 0 {
+			scanJob := ScanJob{
+				txnManager: s.TxnManager,
+				input: models.ScanMetadataInput{
+					Paths: changed,
+				},
+				subscriptions: s.scanSubs,
+			}
+			scanJob.Execute(ctx, progress)
+		}
+
+		if len(removed) > 0 && !job.IsCancelled(ctx) {
+			cleanPaths(ctx, s.TxnManager, removed)
+			s.scanSubs.notify()
+		}
+	})
+
+	s.JobManager.Add(context.Background(), "Scanning changed files...", j)
 }
 
 func (s *singleton) Import(ctx context.Context) (int, error) {
@@ -120,6 +211,24 @@ func (s *singleton) Export(ctx context.Context) (int, error) {
 	return s.JobManager.Add(ctx, "Exporting...", j), nil
 }
 
+func (s *singleton) ExportNFO(ctx context.Context, input models.ExportNfoInput) int {
+	j := &ExportNFOJob{
+		txnManager: s.TxnManager,
+		input:      input,
+	}
+
+	return s.JobManager.Add(ctx, "Exporting NFO files...", j)
+}
+
+func (s *singleton) RegenerateImageThumbnails(ctx context.Context, input models.RegenerateImageThumbnailsInput) int {
+	j := &RegenerateImageThumbnailsJob{
+		txnManager: s.TxnManager,
+		input:      input,
+	}
+
+	return s.JobManager.Add(ctx, "Regenerating image thumbnails...", j)
+}
+
 func (s *singleton) RunSingleTask(ctx context.Context, t Task) int {
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -179,6 +288,7 @@ func (s *singleton) Generate(ctx context.Context, input models.GenerateMetadataI
 		var scenes []*models.Scene
 		var err error
 		var markers []*models.SceneMarker
+		var images []*models.Image
 
 		if err := s.TxnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
 			qb := r.Scene()
@@ -199,6 +309,13 @@ func (s *singleton) Generate(ctx context.Context, input models.GenerateMetadataI
 				}
 			}
 
+			if utils.IsTrue(input.ImagePhashes) {
+				images, err = r.Image().All()
+				if err != nil {
+					return err
+				}
+			}
+
 			return nil
 		}); err != nil {
 			logger.Error(err.Error())
@@ -212,7 +329,11 @@ func (s *singleton) Generate(ctx context.Context, input models.GenerateMetadataI
 		wg := sizedwaitgroup.New(parallelTasks)
 
 		lenScenes := len(scenes)
-		total := lenScenes + len(markers)
+		total := lenScenes + len(markers) + len(images)
+		sceneDetection := utils.IsTrue(input.SceneDetection)
+		if sceneDetection {
+			total += lenScenes
+		}
 		progress.SetTotal(total)
 
 		if job.IsCancelled(ctx) {
@@ -231,7 +352,7 @@ func (s *singleton) Generate(ctx context.Context, input models.GenerateMetadataI
 				logger.Infof("Taking too long to count content. Skipping...")
 				logger.Infof("Generating content")
 			} else {
-				logger.Infof("Generating %d sprites %d previews %d image previews %d markers %d transcodes %d phashes", totalsNeeded.sprites, totalsNeeded.previews, totalsNeeded.imagePreviews, totalsNeeded.markers, totalsNeeded.transcodes, totalsNeeded.phashes)
+				logger.Infof("Generating %d sprites %d previews %d image previews %d markers %d transcodes %d phashes %d phash sequences %d scene detections", totalsNeeded.sprites, totalsNeeded.previews, totalsNeeded.imagePreviews, totalsNeeded.markers, totalsNeeded.transcodes, totalsNeeded.phashes, totalsNeeded.phashSequences, totalsNeeded.sceneDetections)
 			}
 		})
 
@@ -252,6 +373,44 @@ func (s *singleton) Generate(ctx context.Context, input models.GenerateMetadataI
 		start := time.Now()
 		instance.Paths.Generated.EnsureTmpDir()
 
+		// scene detection is run first so that previews can be placed on the
+		// detected cuts
+		if sceneDetection {
+			for _, scene := range scenes {
+				progress.Increment()
+				if job.IsCancelled(ctx) {
+					logger.Info("Stopping due to user request")
+					wg.Wait()
+					instance.Paths.Generated.EmptyTmpDir()
+					return
+				}
+
+				if scene == nil {
+					logger.Errorf("nil scene, skipping generate")
+					continue
+				}
+
+				task := GenerateSceneDetectionTask{
+					Scene:      *scene,
+					Overwrite:  overwrite,
+					txnManager: s.TxnManager,
+				}
+				if input.SceneDetectionThreshold != nil {
+					task.Threshold = *input.SceneDetectionThreshold
+				}
+				if !task.shouldGenerate() {
+					continue
+				}
+
+				wg.Add()
+				go progress.ExecuteTask(fmt.Sprintf("Detecting scenes for %s", scene.Path), func() {
+					task.Start(&wg)
+				})
+			}
+
+			wg.Wait()
+		}
+
 		for _, scene := range scenes {
 			progress.Increment()
 			if job.IsCancelled(ctx) {
@@ -280,6 +439,7 @@ func (s *singleton) Generate(ctx context.Context, input models.GenerateMetadataI
 
 			if input.Previews {
 				task := GeneratePreviewTask{
+					TxnManager:          s.TxnManager,
 					Scene:               *scene,
 					ImagePreview:        input.ImagePreviews,
 					Options:             *generatePreviewOptions,
@@ -328,6 +488,19 @@ func (s *singleton) Generate(ctx context.Context, input models.GenerateMetadataI
 					task.Start(&wg)
 				})
 			}
+
+			if utils.IsTrue(input.PhashSequences) {
+				task := GeneratePhashSequenceTask{
+					Scene:               *scene,
+					Overwrite:           overwrite,
+					fileNamingAlgorithm: fileNamingAlgo,
+					txnManager:          s.TxnManager,
+				}
+				wg.Add()
+				go progress.ExecuteTask(fmt.Sprintf("Generating phash sequence for %s", scene.Path), func() {
+					task.Start(&wg)
+				})
+			}
 		}
 
 		wg.Wait()
@@ -362,6 +535,34 @@ func (s *singleton) Generate(ctx context.Context, input models.GenerateMetadataI
 
 		wg.Wait()
 
+		for _, i := range images {
+			progress.Increment()
+			if job.IsCancelled(ctx) {
+				logger.Info("Stopping due to user request")
+				wg.Wait()
+				instance.Paths.Generated.EmptyTmpDir()
+				elapsed := time.Since(start)
+				logger.Info(fmt.Sprintf("Generate finished (%s)", elapsed))
+				return
+			}
+
+			task := GenerateImagePhashTask{
+				Image:      *i,
+				Overwrite:  overwrite,
+				txnManager: s.TxnManager,
+			}
+			if !task.shouldGenerate() {
+				continue
+			}
+
+			wg.Add()
+			go progress.ExecuteTask(fmt.Sprintf("Generating phash for image %s", image.PathDisplayName(i.Path)), func() {
+				task.Start(&wg)
+			})
+		}
+
+		wg.Wait()
+
 		instance.Paths.Generated.EmptyTmpDir()
 		elapsed := time.Since(start)
 		logger.Info(fmt.Sprintf("Generate finished (%s)", elapsed))
@@ -558,6 +759,15 @@ func (s *singleton) Clean(ctx context.Context, input models.CleanMetadataInput)
 	return s.JobManager.Add(ctx, "Cleaning...", j)
 }
 
+func (s *singleton) CleanGenerated(ctx context.Context, input models.CleanGeneratedInput) int {
+	j := &CleanGeneratedJob{
+		txnManager: s.TxnManager,
+		input:      input,
+	}
+
+	return s.JobManager.Add(ctx, "Cleaning generated files...", j)
+}
+
 func (s *singleton) MigrateHash(ctx context.Context) int {
 	j := job.MakeJobExec(func(ctx context.Context, progress *job.Progress) {
 		fileNamingAlgo := config.GetInstance().GetVideoFileNamingAlgorithm()
@@ -603,12 +813,14 @@ func (s *singleton) MigrateHash(ctx context.Context) int {
 }
 
 type totalsGenerate struct {
-	sprites       int64
-	previews      int64
-	imagePreviews int64
-	markers       int64
-	transcodes    int64
-	phashes       int64
+	sprites         int64
+	previews        int64
+	imagePreviews   int64
+	markers         int64
+	transcodes      int64
+	phashes         int64
+	phashSequences  int64
+	sceneDetections int64
 }
 
 func (s *singleton) neededGenerate(scenes []*models.Scene, input models.GenerateMetadataInput) *totalsGenerate {
@@ -693,6 +905,29 @@ func (s *singleton) neededGenerate(scenes []*models.Scene, input models.Generate
 					totals.phashes++
 				}
 			}
+
+			if utils.IsTrue(input.PhashSequences) {
+				task := GeneratePhashSequenceTask{
+					Scene:      *scene,
+					Overwrite:  overwrite,
+					txnManager: s.TxnManager,
+				}
+
+				if task.shouldGenerate() {
+					totals.phashSequences++
+				}
+			}
+
+			if utils.IsTrue(input.SceneDetection) {
+				task := GenerateSceneDetectionTask{
+					Scene:     *scene,
+					Overwrite: overwrite,
+				}
+
+				if task.shouldGenerate() {
+					totals.sceneDetections++
+				}
+			}
 		}
 		//check for timeout
 		select {
@@ -65,3 +65,10 @@ func (gp *generatedPaths) GetThumbnailPath(checksum string, width int) string {
 	fname := fmt.Sprintf("%s_%d.jpg", checksum, width)
 	return filepath.Join(gp.Thumbnails, utils.GetIntraDir(checksum, thumbDirDepth, thumbDirLength), fname)
 }
+
+// GetImageClipPreviewPath returns the path of the looping preview of an
+// image clip.
+func (gp *generatedPaths) GetImageClipPreviewPath(checksum string, width int) string {
+	fname := fmt.Sprintf("%s_%d.mp4", checksum, width)
+	return filepath.Join(gp.Thumbnails, utils.GetIntraDir(checksum, thumbDirDepth, thumbDirLength), fname)
+}
@@ -15,10 +15,27 @@ func newSceneMarkerPaths(p Paths) *sceneMarkerPaths {
 	return &sp
 }
 
-func (sp *sceneMarkerPaths) GetStreamPath(checksum string, seconds int) string {
-	return filepath.Join(sp.generated.Markers, checksum, strconv.Itoa(seconds)+".mp4")
+// GetStreamPath returns the path of the generated video of a marker.
+// endSeconds is 0 for markers without an end time.
+func (sp *sceneMarkerPaths) GetStreamPath(checksum string, seconds int, endSeconds int) string {
+	return filepath.Join(sp.generated.Markers, checksum, markerBaseFilename(seconds, endSeconds)+".mp4")
 }
 
-func (sp *sceneMarkerPaths) GetStreamPreviewImagePath(checksum string, seconds int) string {
-	return filepath.Join(sp.generated.Markers, checksum, strconv.Itoa(seconds)+".webp")
+// GetStreamPreviewImagePath returns the path of the generated preview image
+// of a marker. endSeconds is 0 for markers without an end time.
+func (sp *sceneMarkerPaths) GetStreamPreviewImagePath(checksum string, seconds int, endSeconds int) string {
+	return filepath.Join(sp.generated.Markers, checksum, markerBaseFilename(seconds, endSeconds)+".webp")
+}
+
+// markerBaseFilename returns the filename of the generated files of a
+// marker, without extension. The files of markers with an end time are keyed
+// on their range, so that they are distinct from the files of markers
+// starting at the same time.
+func markerBaseFilename(seconds int, endSeconds int) string {
+	ret := strconv.Itoa(seconds)
+	if endSeconds > 0 {
+		ret += "-" + strconv.Itoa(endSeconds)
+	}
+
+	return ret
 }
@@ -1,6 +1,45 @@
 package manager
 
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/gallery"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// galleryParentsSchemaVersion is the schema version that added the parents
+// of galleries.
+const galleryParentsSchemaVersion = 31
+
 // PostMigrate is executed after migrations have been executed.
 func (s *singleton) PostMigrate() {
 	setInitialMD5Config(s.TxnManager)
+	s.refreshWatcher()
+}
+
+// migrateData updates existing data after the database was migrated from
+// the provided schema version.
+func (s *singleton) migrateData(fromVersion uint) {
+	if fromVersion < galleryParentsSchemaVersion {
+		setMissingGalleryParents(s.TxnManager)
+	}
+}
+
+// setMissingGalleryParents sets the parents of the existing folder-based and
+// archive galleries, which were created before galleries had parents.
+func setMissingGalleryParents(txnManager models.TransactionManager) {
+	var count int
+	if err := txnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+		var err error
+		count, err = gallery.SetMissingFolderParents(r.Gallery())
+		return err
+	}); err != nil {
+		logger.Errorf("Error while setting gallery parents: %s", err.Error())
+		return
+	}
+
+	if count > 0 {
+		logger.Infof("Set the parent gallery of %d galleries", count)
+	}
 }
@@ -1,6 +1,9 @@
 package manager
 
 import (
+	"context"
+	"fmt"
+	"net"
 	"net/http"
 	"sync"
 
@@ -82,6 +85,68 @@ func (s *SceneServer) StreamSceneDirect(scene *models.Scene, w http.ResponseWrit
 	WaitAndDeregisterStream(filepath, &w, r)
 }
 
+// StreamSceneAudioTrack streams the scene with the audio stream with the
+// provided index. The video stream is copied if MPEG-TS supports its codec,
+// and transcoded to H264 otherwise. The audio stream is transcoded to AAC.
+func (s *SceneServer) StreamSceneAudioTrack(scene *models.Scene, audioStreamIndex int, w http.ResponseWriter, r *http.Request) {
+	videoFile, err := ffmpeg.NewVideoFile(GetInstance().FFProbePath, scene.Path, false)
+	if err != nil {
+		logger.Errorf("[stream] error reading video file: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if videoFile.GetAudioStreamByIndex(audioStreamIndex) == nil {
+		http.Error(w, fmt.Sprintf("invalid audio track: %d", audioStreamIndex), http.StatusBadRequest)
+		return
+	}
+
+	codec := ffmpeg.GetAudioTrackCodec(*videoFile)
+	options := ffmpeg.GetTranscodeStreamOptions(*videoFile, codec, ffmpeg.AudioCodec(videoFile.AudioCodec))
+	options.AudioStreamIndex = &audioStreamIndex
+	options.VideoOnly = false
+
+	resolution := models.StreamingResolutionEnumOriginal
+	if codec.Codec != ffmpeg.CopyStreamCodec {
+		resolution = config.GetInstance().GetMaxStreamingTranscodeSize()
+	}
+	options.MaxTranscodeSize = resolution
+
+	// killing or replacing the session cancels the request context, which
+	// stops the stream
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	r = r.WithContext(ctx)
+
+	clientIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		clientIP = r.RemoteAddr
+	}
+
+	release, err := GetInstance().StreamSessions.Start(ctx, StreamSession{
+		SceneID:    scene.ID,
+		ClientIP:   clientIP,
+		Codec:      codec.Codec,
+		Resolution: resolution,
+	}, cancel, cancel)
+	if err != nil {
+		logger.Warnf("[stream] could not start transcode: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer release()
+
+	encoder := ffmpeg.NewEncoder(GetInstance().FFMPEGPath)
+	stream, err := encoder.GetTranscodeStream(options)
+	if err != nil {
+		logger.Errorf("[stream] error transcoding video file: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	stream.Serve(w, r)
+}
+
 func (s *SceneServer) ServeScreenshot(scene *models.Scene, w http.ResponseWriter, r *http.Request) {
 	filepath := GetInstance().Paths.Scene.GetScreenshotPath(scene.GetHash(config.GetInstance().GetVideoFileNamingAlgorithm()))
 
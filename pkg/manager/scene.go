@@ -53,15 +53,28 @@ func DestroySceneMarker(scene *models.Scene, sceneMarker *models.SceneMarker, qb
 
 	// delete the preview for the marker
 	return func() {
-		seconds := int(sceneMarker.Seconds)
-		DeleteSceneMarkerFiles(scene, seconds, config.GetInstance().GetVideoFileNamingAlgorithm())
+		DeleteSceneMarkerFiles(scene, sceneMarker, config.GetInstance().GetVideoFileNamingAlgorithm())
 	}, nil
 }
 
 // DeleteGeneratedSceneFiles deletes generated files for the provided scene.
 func DeleteGeneratedSceneFiles(scene *models.Scene, fileNamingAlgo models.HashAlgorithm) {
+	deleteGeneratedSceneHashFiles(scene.GetHash(fileNamingAlgo))
+}
+
+// DeleteGeneratedSceneFileFiles deletes the generated files for the provided
+// additional files of the scene. Generated files shared with the primary
+// file of the scene are not deleted.
+func DeleteGeneratedSceneFileFiles(scene *models.Scene, files []*models.SceneFile, fileNamingAlgo models.HashAlgorithm) {
 	sceneHash := scene.GetHash(fileNamingAlgo)
+	for _, f := range files {
+		if hash := f.GetHash(fileNamingAlgo); hash != sceneHash {
+			deleteGeneratedSceneHashFiles(hash)
+		}
+	}
+}
 
+func deleteGeneratedSceneHashFiles(sceneHash string) {
 	if sceneHash == "" {
 		return
 	}
@@ -143,11 +156,13 @@ func DeleteGeneratedSceneFiles(scene *models.Scene, fileNamingAlgo models.HashAl
 	}
 }
 
-// DeleteSceneMarkerFiles deletes generated files for a scene marker with the
-// provided scene and timestamp.
-func DeleteSceneMarkerFiles(scene *models.Scene, seconds int, fileNamingAlgo models.HashAlgorithm) {
-	videoPath := GetInstance().Paths.SceneMarkers.GetStreamPath(scene.GetHash(fileNamingAlgo), seconds)
-	imagePath := GetInstance().Paths.SceneMarkers.GetStreamPreviewImagePath(scene.GetHash(fileNamingAlgo), seconds)
+// DeleteSceneMarkerFiles deletes generated files for the provided scene
+// marker of the provided scene.
+func DeleteSceneMarkerFiles(scene *models.Scene, sceneMarker *models.SceneMarker, fileNamingAlgo models.HashAlgorithm) {
+	seconds := int(sceneMarker.Seconds)
+	endSeconds := sceneMarker.GetEndSeconds()
+	videoPath := GetInstance().Paths.SceneMarkers.GetStreamPath(scene.GetHash(fileNamingAlgo), seconds, endSeconds)
+	imagePath := GetInstance().Paths.SceneMarkers.GetStreamPreviewImagePath(scene.GetHash(fileNamingAlgo), seconds, endSeconds)
 
 	exists, _ := utils.FileExists(videoPath)
 	if exists {
@@ -166,14 +181,23 @@ func DeleteSceneMarkerFiles(scene *models.Scene, seconds int, fileNamingAlgo mod
 	}
 }
 
-// DeleteSceneFile deletes the scene video file from the filesystem.
-func DeleteSceneFile(scene *models.Scene) {
+// DeleteSceneFile deletes the scene video file, along with the provided
+// additional scene files, from the filesystem.
+func DeleteSceneFile(scene *models.Scene, files []*models.SceneFile) {
+	deleteVideoFile(scene.Path)
+
+	for _, f := range files {
+		deleteVideoFile(f.Path)
+	}
+}
+
+func deleteVideoFile(path string) {
 	// kill any running encoders
-	KillRunningStreams(scene.Path)
+	KillRunningStreams(path)
 
-	err := os.Remove(scene.Path)
+	err := os.Remove(path)
 	if err != nil {
-		logger.Warnf("Could not delete file %s: %s", scene.Path, err.Error())
+		logger.Warnf("Could not delete file %s: %s", path, err.Error())
 	}
 }
 
@@ -217,6 +241,43 @@ func includeSceneStreamPath(scene *models.Scene, streamingResolution models.Stre
 	return int64(maxStreamingResolution.GetMinResolution()) >= minResolution
 }
 
+// streamingResolutions are the transcode resolutions, from highest to lowest.
+var streamingResolutions = []models.StreamingResolutionEnum{
+	models.StreamingResolutionEnumFourK,
+	models.StreamingResolutionEnumFullHd,
+	models.StreamingResolutionEnumStandardHd,
+	models.StreamingResolutionEnumStandard,
+	models.StreamingResolutionEnumLow,
+}
+
+// GetSceneStreamResolutions returns the transcode resolutions that are
+// allowed for the scene, from highest to lowest. The original resolution is
+// included if it is allowed and larger than the largest transcode
+// resolution.
+func GetSceneStreamResolutions(scene *models.Scene, maxStreamingTranscodeSize models.StreamingResolutionEnum) []models.StreamingResolutionEnum {
+	var ret []models.StreamingResolutionEnum
+	for _, res := range streamingResolutions {
+		if includeSceneStreamPath(scene, res, maxStreamingTranscodeSize) {
+			ret = append(ret, res)
+		}
+	}
+
+	if maxStreamingTranscodeSize == models.StreamingResolutionEnumOriginal {
+		largest := 0
+		if len(ret) > 0 {
+			// the minimum of the resolution range is the transcode size
+			convertedRes := models.ResolutionEnum(ret[0])
+			largest = convertedRes.GetMinResolution()
+		}
+
+		if scene.GetMinResolution() > int64(largest) {
+			ret = append([]models.StreamingResolutionEnum{models.StreamingResolutionEnumOriginal}, ret...)
+		}
+	}
+
+	return ret
+}
+
 func makeStreamEndpoint(streamURL string, streamingResolution models.StreamingResolutionEnum, mimeType, label string) *models.SceneStreamEndpoint {
 	return &models.SceneStreamEndpoint{
 		URL:      fmt.Sprintf("%s?resolution=%s", streamURL, streamingResolution.String()),
@@ -233,10 +294,12 @@ func GetSceneStreamPaths(scene *models.Scene, directStreamURL string, maxStreami
 	var ret []*models.SceneStreamEndpoint
 	mimeWebm := ffmpeg.MimeWebm
 	mimeHLS := ffmpeg.MimeHLS
+	mimeDASH := ffmpeg.MimeDASH
 	mimeMp4 := ffmpeg.MimeMp4
 
 	labelWebm := "webm"
 	labelHLS := "HLS"
+	labelDASH := "DASH"
 
 	// direct stream should only apply when the audio codec is supported
 	audioCodec := ffmpeg.MissingUnsupported
@@ -274,6 +337,12 @@ func GetSceneStreamPaths(scene *models.Scene, directStreamURL string, maxStreami
 	}
 	ret = append(ret, &hls)
 
+	ret = append(ret, &models.SceneStreamEndpoint{
+		URL:      directStreamURL + ".mpd",
+		MimeType: &mimeDASH,
+		Label:    &labelDASH,
+	})
+
 	// WEBM quality transcoding options
 	// Note: These have the wrong mime type intentionally to allow jwplayer to selection between mp4/webm
 	webmLabelFourK := "WEBM 4K (2160p)"         // "FOUR_K"
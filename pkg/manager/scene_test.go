@@ -0,0 +1,74 @@
+package manager
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSceneStreamResolutions(t *testing.T) {
+	makeScene := func(width, height int64) *models.Scene {
+		return &models.Scene{
+			Width:  sql.NullInt64{Int64: width, Valid: true},
+			Height: sql.NullInt64{Int64: height, Valid: true},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		scene *models.Scene
+		max   models.StreamingResolutionEnum
+		want  []models.StreamingResolutionEnum
+	}{
+		{
+			"720p limited to 480p",
+			makeScene(1280, 720),
+			models.StreamingResolutionEnumStandard,
+			[]models.StreamingResolutionEnum{
+				models.StreamingResolutionEnumStandard,
+				models.StreamingResolutionEnumLow,
+			},
+		},
+		{
+			"1080p original",
+			makeScene(1920, 1080),
+			models.StreamingResolutionEnumOriginal,
+			[]models.StreamingResolutionEnum{
+				models.StreamingResolutionEnumFullHd,
+				models.StreamingResolutionEnumStandardHd,
+				models.StreamingResolutionEnumStandard,
+				models.StreamingResolutionEnumLow,
+			},
+		},
+		{
+			"1440p original",
+			makeScene(2560, 1440),
+			models.StreamingResolutionEnumOriginal,
+			[]models.StreamingResolutionEnum{
+				models.StreamingResolutionEnumOriginal,
+				models.StreamingResolutionEnumFullHd,
+				models.StreamingResolutionEnumStandardHd,
+				models.StreamingResolutionEnumStandard,
+				models.StreamingResolutionEnumLow,
+			},
+		},
+		{
+			"1440p limited to 4k",
+			makeScene(2560, 1440),
+			models.StreamingResolutionEnumFourK,
+			[]models.StreamingResolutionEnum{
+				models.StreamingResolutionEnumFullHd,
+				models.StreamingResolutionEnumStandardHd,
+				models.StreamingResolutionEnumStandard,
+				models.StreamingResolutionEnumLow,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got := GetSceneStreamResolutions(tt.scene, tt.max)
+		assert.Equal(t, tt.want, got, tt.name)
+	}
+}
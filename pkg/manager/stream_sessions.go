@@ -0,0 +1,181 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/manager/config"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// ErrTranscodeLimitReached is returned when a streaming transcode cannot be
+// started because the maximum number of concurrent transcodes are running.
+var ErrTranscodeLimitReached = errors.New("maximum number of concurrent transcodes reached")
+
+// StreamSession is a running streaming transcode.
+type StreamSession struct {
+	ID         int
+	SceneID    int
+	ClientIP   string
+	Codec      string
+	Resolution models.StreamingResolutionEnum
+	StartTime  time.Time
+
+	kill func()
+	stop func()
+}
+
+// Uptime returns the time since the session was started.
+func (s StreamSession) Uptime() time.Duration {
+	return time.Since(s.StartTime)
+}
+
+// StreamSessionManager tracks the running streaming transcodes and limits
+// the number that may run at once.
+type StreamSessionManager struct {
+	mutex    sync.Mutex
+	sessions map[int]*StreamSession
+	nextID   int
+
+	// closed and replaced whenever a session ends, to wake queued sessions
+	released chan struct{}
+}
+
+func NewStreamSessionManager() *StreamSessionManager {
+	return &StreamSessionManager{
+		sessions: make(map[int]*StreamSession),
+		released: make(chan struct{}),
+	}
+}
+
+// Start registers a new streaming transcode session, using the configured
+// limits. See StartWithLimit.
+func (m *StreamSessionManager) Start(ctx context.Context, session StreamSession, kill func(), stop func()) (func(), error) {
+	c := config.GetInstance()
+	timeout := time.Duration(c.GetTranscodeQueueTimeout()) * time.Second
+	return m.StartWithLimit(ctx, session, kill, stop, c.GetMaxConcurrentTranscodes(), timeout)
+}
+
+// StartWithLimit registers a new streaming transcode session. If max
+// sessions are already running, then it waits up to timeout for a session to
+// end, returning ErrTranscodeLimitReached if none do. A max of 0 means no
+// limit. kill is called to stop the transcode when the session is killed.
+// stop is called to stop the transcode when it is replaced by a new session
+// of the same client and scene, such as when the client seeks. The returned
+// function must be called when the transcode ends.
+func (m *StreamSessionManager) StartWithLimit(ctx context.Context, session StreamSession, kill func(), stop func(), max int, timeout time.Duration) (func(), error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		m.mutex.Lock()
+		if max == 0 || len(m.sessions) < max {
+			m.nextID++
+			session.ID = m.nextID
+			session.StartTime = time.Now()
+			session.kill = kill
+			session.stop = stop
+			m.sessions[session.ID] = &session
+			m.mutex.Unlock()
+
+			logger.Debugf("[stream] started transcode session %d for scene %d", session.ID, session.SceneID)
+
+			id := session.ID
+			return func() {
+				m.end(id)
+			}, nil
+		}
+		released := m.released
+		replaced := m.getReplaced(session)
+		m.mutex.Unlock()
+
+		// the client does not wait for its own transcode to end
+		if replaced != nil {
+			logger.Debugf("[stream] stopping transcode session %d for scene %d, replaced by a new session", replaced.ID, replaced.SceneID)
+			replaced.stop()
+		}
+
+		select {
+		case <-released:
+		case <-timer.C:
+			return nil, ErrTranscodeLimitReached
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// getReplaced returns the oldest running session of the client and scene of
+// the provided session that may be stopped, or nil if there is none. Must be
+// called with the mutex held.
+func (m *StreamSessionManager) getReplaced(session StreamSession) *StreamSession {
+	var ret *StreamSession
+	for _, s := range m.sessions {
+		if s.stop == nil || s.SceneID != session.SceneID || s.ClientIP != session.ClientIP {
+			continue
+		}
+
+		if ret == nil || s.ID < ret.ID {
+			ret = s
+		}
+	}
+
+	return ret
+}
+
+// end removes the session, waking any queued sessions. Returns the removed
+// session, or nil if the session was not found.
+func (m *StreamSessionManager) end(id int) *StreamSession {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	session := m.sessions[id]
+	if session == nil {
+		return nil
+	}
+
+	delete(m.sessions, id)
+	close(m.released)
+	m.released = make(chan struct{})
+
+	logger.Debugf("[stream] ended transcode session %d for scene %d", id, session.SceneID)
+
+	return session
+}
+
+// Sessions returns the running sessions, ordered by ID.
+func (m *StreamSessionManager) Sessions() []StreamSession {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	ret := make([]StreamSession, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		ret = append(ret, *s)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ID < ret[j].ID
+	})
+
+	return ret
+}
+
+// Kill stops the session with the provided ID. Returns false if the session
+// was not found.
+func (m *StreamSessionManager) Kill(id int) bool {
+	session := m.end(id)
+	if session == nil {
+		return false
+	}
+
+	logger.Infof("[stream] killing transcode session %d for scene %d", id, session.SceneID)
+	if session.kill != nil {
+		session.kill()
+	}
+
+	return true
+}
@@ -0,0 +1,187 @@
+package manager
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+)
+
+func TestStreamSessionLimit(t *testing.T) {
+	m := NewStreamSessionManager()
+	ctx := context.Background()
+
+	release, err := m.StartWithLimit(ctx, StreamSession{SceneID: 1}, nil, nil, 1, 0)
+	assert.Nil(t, err)
+
+	// no free slots - rejected immediately
+	_, err = m.StartWithLimit(ctx, StreamSession{SceneID: 2}, nil, nil, 1, 0)
+	assert.Equal(t, ErrTranscodeLimitReached, err)
+
+	// queued until the first session ends
+	done := make(chan error)
+	go func() {
+		_, err := m.StartWithLimit(ctx, StreamSession{SceneID: 3}, nil, nil, 1, time.Minute)
+		done <- err
+	}()
+
+	release()
+	assert.Nil(t, <-done)
+
+	sessions := m.Sessions()
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, 3, sessions[0].SceneID)
+
+	// releasing twice has no effect
+	release()
+	assert.Len(t, m.Sessions(), 1)
+
+	// no limit
+	_, err = m.StartWithLimit(ctx, StreamSession{SceneID: 4}, nil, nil, 0, 0)
+	assert.Nil(t, err)
+	assert.Len(t, m.Sessions(), 2)
+}
+
+func TestStreamSessionQueueCancelled(t *testing.T) {
+	m := NewStreamSessionManager()
+
+	_, err := m.StartWithLimit(context.Background(), StreamSession{SceneID: 1}, nil, nil, 1, 0)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = m.StartWithLimit(ctx, StreamSession{SceneID: 2}, nil, nil, 1, time.Minute)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestStreamSessionKill(t *testing.T) {
+	m := NewStreamSessionManager()
+
+	killed := false
+	release, err := m.StartWithLimit(context.Background(), StreamSession{SceneID: 1}, func() {
+		killed = true
+	}, nil, 0, 0)
+	assert.Nil(t, err)
+
+	id := m.Sessions()[0].ID
+	assert.True(t, m.Kill(id))
+	assert.True(t, killed)
+	assert.Len(t, m.Sessions(), 0)
+
+	// already removed
+	assert.False(t, m.Kill(id))
+	release()
+}
+
+func TestStreamSessionReplaced(t *testing.T) {
+	m := NewStreamSessionManager()
+	ctx := context.Background()
+
+	stopped := 0
+	var release func()
+	release, err := m.StartWithLimit(ctx, StreamSession{SceneID: 1, ClientIP: "a"}, nil, func() {
+		stopped++
+		release()
+	}, 1, 0)
+	assert.Nil(t, err)
+
+	// other clients and scenes wait for the session
+	_, err = m.StartWithLimit(ctx, StreamSession{SceneID: 1, ClientIP: "b"}, nil, nil, 1, 0)
+	assert.Equal(t, ErrTranscodeLimitReached, err)
+	_, err = m.StartWithLimit(ctx, StreamSession{SceneID: 2, ClientIP: "a"}, nil, nil, 1, 0)
+	assert.Equal(t, ErrTranscodeLimitReached, err)
+	assert.Equal(t, 0, stopped)
+
+	// the same client replaces its session
+	_, err = m.StartWithLimit(ctx, StreamSession{SceneID: 1, ClientIP: "a"}, nil, nil, 1, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, stopped)
+	assert.Len(t, m.Sessions(), 1)
+}
+
+// writeTestEncoder writes a script that imitates the segmented transcode of
+// ffmpeg, writing a segment at the start segment and running until killed.
+func writeTestEncoder(t *testing.T, dir string) ffmpeg.Encoder {
+	if runtime.GOOS == "windows" {
+		t.Skip("test encoder requires a posix shell")
+	}
+
+	script := `#!/bin/sh
+while [ $# -gt 1 ]; do
+	case "$1" in
+		-start_number) start=$2; shift;;
+		-hls_segment_filename) pattern=$2; shift;;
+	esac
+	shift
+done
+f=$(printf "$pattern" $start)
+touch "$f"
+printf "#EXTM3U\n%s\n" "$(basename "$f")" > "$1"
+exec sleep 60
+`
+
+	fn := filepath.Join(dir, "ffmpeg.sh")
+	if err := ioutil.WriteFile(fn, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	return ffmpeg.NewEncoder(fn)
+}
+
+func TestStreamSessionSeek(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	encoder := writeTestEncoder(t, dir)
+	segments := ffmpeg.NewSegmentManager(filepath.Join(dir, "cache"))
+	defer segments.Shutdown()
+
+	m := NewStreamSessionManager()
+
+	// a single transcode slot
+	options := func(clientIP string) ffmpeg.SegmentOptions {
+		return ffmpeg.SegmentOptions{
+			ProbeResult: ffmpeg.VideoFile{Path: "video.mp4", Duration: 1000},
+			Codec:       ffmpeg.CodecHLS,
+			Format:      ffmpeg.SegmentFormatMpegts,
+			Acquire: func(ctx context.Context, kill func(), stop func()) (func(), error) {
+				session := StreamSession{SceneID: 1, ClientIP: clientIP}
+				return m.StartWithLimit(ctx, session, kill, stop, 1, 500*time.Millisecond)
+			},
+		}
+	}
+
+	serve := func(clientIP string, segment int) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		segments.ServeSegment(w, r, encoder, options(clientIP), segment)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("a", 0))
+	assert.Len(t, m.Sessions(), 1)
+
+	// seeking replaces the transcode of the client instead of waiting for
+	// its slot
+	assert.Equal(t, http.StatusOK, serve("a", 50))
+	assert.Len(t, m.Sessions(), 1)
+
+	// the segments of the stopped transcode are still served
+	assert.Equal(t, http.StatusOK, serve("a", 0))
+
+	// other clients wait for the slot
+	assert.Equal(t, http.StatusServiceUnavailable, serve("b", 80))
+}
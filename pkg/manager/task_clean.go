@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sync"
 
 	"github.com/stashapp/stash/pkg/image"
@@ -26,8 +27,12 @@ type CleanTask struct {
 func (t *CleanTask) Start(wg *sync.WaitGroup, dryRun bool) {
 	defer wg.Done()
 
-	if t.Scene != nil && t.shouldCleanScene(t.Scene) && !dryRun {
-		t.deleteScene(t.Scene.ID)
+	t.clean(dryRun)
+}
+
+func (t *CleanTask) clean(dryRun bool) {
+	if t.Scene != nil {
+		t.cleanScene(t.Scene, dryRun)
 	}
 
 	if t.Gallery != nil && t.shouldCleanGallery(t.Gallery) && !dryRun {
@@ -40,7 +45,7 @@ func (t *CleanTask) Start(wg *sync.WaitGroup, dryRun bool) {
 }
 
 func (t *CleanTask) shouldClean(path string) bool {
-	// use image.FileExists for zip file checking
+	// use image.FileExists for archive file checking
 	fileExists := image.FileExists(path)
 
 	// #1102 - clean anything in generated path
@@ -53,25 +58,122 @@ func (t *CleanTask) shouldClean(path string) bool {
 	return false
 }
 
-func (t *CleanTask) shouldCleanScene(s *models.Scene) bool {
-	if t.shouldClean(s.Path) {
+// cleanScene removes the additional files of the scene that should be
+// cleaned. If the primary file should be cleaned, then the first remaining
+// additional file is made the primary file. If there are no remaining files,
+// then the scene is deleted.
+func (t *CleanTask) cleanScene(s *models.Scene, dryRun bool) {
+	var files []*models.SceneFile
+	if err := t.TxnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
+		var err error
+		files, err = r.Scene().GetFiles(s.ID)
+		return err
+	}); err != nil {
+		logger.Errorf("Error getting scene files: %s", err.Error())
+		return
+	}
+
+	var remaining []*models.SceneFile
+	var removed []*models.SceneFile
+	for _, f := range files {
+		if t.shouldCleanScene(f.Path) {
+			removed = append(removed, f)
+		} else {
+			remaining = append(remaining, f)
+		}
+	}
+
+	cleanPrimary := t.shouldCleanScene(s.Path)
+
+	if dryRun {
+		return
+	}
+
+	if cleanPrimary && len(remaining) == 0 {
+		t.deleteScene(s.ID)
+		DeleteGeneratedSceneFileFiles(s, removed, t.fileNamingAlgorithm)
+		return
+	}
+
+	if len(removed) == 0 && !cleanPrimary {
+		return
+	}
+
+	var updated *models.Scene
+	if err := t.TxnManager.WithTxn(context.TODO(), func(repo models.Repository) error {
+		qb := repo.Scene()
+		for _, f := range removed {
+			if err := qb.DestroyFile(f.ID); err != nil {
+				return err
+			}
+		}
+
+		if cleanPrimary {
+			// the file now contains the details of the old primary file
+			newPrimary := remaining[0]
+			logger.Infof("Setting primary file of scene to \"%s\"", newPrimary.Path)
+			if err := qb.SetPrimaryFile(s.ID, newPrimary.ID); err != nil {
+				return err
+			}
+
+			if err := qb.DestroyFile(newPrimary.ID); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		updated, err = qb.Find(s.ID)
+		return err
+	}); err != nil {
+		logger.Errorf("Error cleaning scene files: %s", err.Error())
+		return
+	}
+
+	// keep the generated files for the scene
+	if cleanPrimary {
+		oldHash := s.GetHash(t.fileNamingAlgorithm)
+		newHash := updated.GetHash(t.fileNamingAlgorithm)
+		if newHash != oldHash {
+			MigrateHash(oldHash, newHash)
+		}
+	}
+
+	// keep the generated files shared with the remaining files
+	remainingHashes := make(map[string]bool)
+	for _, f := range remaining {
+		remainingHashes[f.GetHash(t.fileNamingAlgorithm)] = true
+	}
+
+	var unused []*models.SceneFile
+	for _, f := range removed {
+		if !remainingHashes[f.GetHash(t.fileNamingAlgorithm)] {
+			unused = append(unused, f)
+		}
+	}
+	DeleteGeneratedSceneFileFiles(updated, unused, t.fileNamingAlgorithm)
+
+	GetInstance().PluginCache.ExecutePostHooks(t.ctx, s.ID, plugin.SceneUpdatePost, nil, nil)
+}
+
+func (t *CleanTask) shouldCleanScene(path string) bool {
+	if t.shouldClean(path) {
 		return true
 	}
 
-	stash := getStashFromPath(s.Path)
+	stash := getStashFromPath(path)
 	if stash.ExcludeVideo {
-		logger.Infof("File in stash library that excludes video. Cleaning: \"%s\"", s.Path)
+		logger.Infof("File in stash library that excludes video. Cleaning: \"%s\"", path)
 		return true
 	}
 
 	config := config.GetInstance()
-	if !matchExtension(s.Path, config.GetVideoExtensions()) {
-		logger.Infof("File extension does not match video extensions. Cleaning: \"%s\"", s.Path)
+	if !matchExtension(path, config.GetVideoExtensions()) {
+		logger.Infof("File extension does not match video extensions. Cleaning: \"%s\"", path)
 		return true
 	}
 
-	if matchFile(s.Path, config.GetExcludes()) {
-		logger.Infof("File matched regex. Cleaning: \"%s\"", s.Path)
+	if matchFile(path, config.GetExcludes()) {
+		logger.Infof("File matched regex. Cleaning: \"%s\"", path)
 		return true
 	}
 
@@ -106,7 +208,7 @@ func (t *CleanTask) shouldCleanGallery(g *models.Gallery) bool {
 		return true
 	}
 
-	if countImagesInZip(path) == 0 {
+	if countImagesInArchive(path) == 0 {
 		logger.Infof("Gallery has 0 images. Cleaning: \"%s\"", path)
 		return true
 	}
@@ -126,7 +228,7 @@ func (t *CleanTask) shouldCleanImage(s *models.Image) bool {
 	}
 
 	config := config.GetInstance()
-	if !matchExtension(s.Path, config.GetImageExtensions()) {
+	if !matchExtension(s.Path, config.GetImageExtensions()) && !isImageClip(s.Path) {
 		logger.Infof("File extension does not match image extensions. Cleaning: \"%s\"", s.Path)
 		return true
 	}
@@ -192,9 +294,105 @@ func (t *CleanTask) deleteImage(imageID int) {
 		logger.Errorf("Error deleting thumbnail image from cache: %s", pathErr)
 	}
 
+	if t.Image.Clip {
+		deleteGeneratedImageFile(GetInstance().Paths.Generated.GetImageClipPreviewPath(t.Image.Checksum, models.DefaultGthumbWidth))
+	}
+
 	GetInstance().PluginCache.ExecutePostHooks(t.ctx, imageID, plugin.ImageDestroyPost, nil, nil)
 }
 
+// cleanPaths cleans the scenes, images and galleries with files at or within
+// the provided paths. The paths are expected to no longer exist.
+func cleanPaths(ctx context.Context, txnManager models.TransactionManager, paths []string) {
+	var tasks []*CleanTask
+	fileNamingAlgo := config.GetInstance().GetVideoFileNamingAlgorithm()
+
+	if err := txnManager.WithReadTxn(ctx, func(r models.ReaderRepository) error {
+		sceneIDs := make(map[int]bool)
+		pp := models.PerPageAll
+		findFilter := &models.FindFilterType{
+			PerPage: &pp,
+		}
+
+		for _, p := range paths {
+			pathFilter := pathCriterion(p)
+			scenes, _, err := r.Scene().Query(&models.SceneFilterType{Path: pathFilter}, findFilter)
+			if err != nil {
+				return err
+			}
+
+			// additional files of scenes are only matched by exact path
+			f, err := r.Scene().FindFileByPath(p)
+			if err != nil {
+				return err
+			}
+			if f != nil {
+				scene, err := r.Scene().Find(f.SceneID)
+				if err != nil {
+					return err
+				}
+				scenes = append(scenes, scene)
+			}
+
+			for _, scene := range scenes {
+				if scene == nil || sceneIDs[scene.ID] {
+					continue
+				}
+				sceneIDs[scene.ID] = true
+				tasks = append(tasks, &CleanTask{
+					ctx:                 ctx,
+					TxnManager:          txnManager,
+					Scene:               scene,
+					fileNamingAlgorithm: fileNamingAlgo,
+				})
+			}
+
+			images, _, err := r.Image().Query(&models.ImageFilterType{Path: pathFilter}, findFilter)
+			if err != nil {
+				return err
+			}
+			for _, img := range images {
+				tasks = append(tasks, &CleanTask{
+					ctx:        ctx,
+					TxnManager: txnManager,
+					Image:      img,
+				})
+			}
+
+			galleries, _, err := r.Gallery().Query(&models.GalleryFilterType{Path: pathFilter}, findFilter)
+			if err != nil {
+				return err
+			}
+			for _, g := range galleries {
+				tasks = append(tasks, &CleanTask{
+					ctx:        ctx,
+					TxnManager: txnManager,
+					Gallery:    g,
+				})
+			}
+		}
+
+		return nil
+	}); err != nil {
+		logger.Errorf("Error finding objects to clean: %s", err.Error())
+		return
+	}
+
+	for _, task := range tasks {
+		task.clean(false)
+	}
+}
+
+// pathCriterion returns a criterion matching the path, and the paths within
+// it if it is a directory.
+func pathCriterion(path string) *models.StringCriterionInput {
+	sep := string(filepath.Separator)
+	return &models.StringCriterionInput{
+		Modifier: models.CriterionModifierMatchesRegex,
+		Value:    "^" + regexp.QuoteMeta(path) + "(" + regexp.QuoteMeta(sep) + "|$)",
+	}
+}
+
 func getStashFromPath(pathToCheck string) *models.StashConfig {
 	for _, s := range config.GetInstance().GetStashPaths() {
 		if utils.IsPathInDir(s.Path, filepath.Dir(pathToCheck)) {
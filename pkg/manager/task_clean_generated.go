@@ -0,0 +1,391 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/job"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/manager/config"
+	"github.com/stashapp/stash/pkg/manager/paths"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// GeneratedUsage is the disk usage of a category of generated files.
+type GeneratedUsage struct {
+	Category    models.GeneratedFileCategory `json:"category"`
+	Files       int                          `json:"files"`
+	Size        int64                        `json:"size"`
+	OrphanFiles int                          `json:"orphan_files"`
+	OrphanSize  int64                        `json:"orphan_size"`
+}
+
+// GeneratedReport contains the disk usage of the generated directory, and
+// the generated files that do not belong to any scene, marker or image.
+type GeneratedReport struct {
+	Categories []*GeneratedUsage `json:"categories"`
+	Orphans    []string          `json:"orphans"`
+	// Deleted is true if the orphaned files were deleted.
+	Deleted bool `json:"deleted"`
+
+	// DownloadHash is the hash of the report JSON file in the download
+	// store.
+	DownloadHash string `json:"-"`
+}
+
+func newGeneratedReport() *GeneratedReport {
+	ret := &GeneratedReport{}
+	for _, c := range models.AllGeneratedFileCategory {
+		ret.Categories = append(ret.Categories, &GeneratedUsage{Category: c})
+	}
+	return ret
+}
+
+func (r *GeneratedReport) add(path string, size int64, category models.GeneratedFileCategory, orphan bool) {
+	for _, u := range r.Categories {
+		if u.Category != category {
+			continue
+		}
+
+		u.Files++
+		u.Size += size
+		if orphan {
+			u.OrphanFiles++
+			u.OrphanSize += size
+			r.Orphans = append(r.Orphans, path)
+		}
+	}
+}
+
+// generatedInventory contains the generated files that belong to the scenes,
+// markers and images in the database.
+type generatedInventory struct {
+	paths *paths.Paths
+
+	// files contains the paths of the generated files of scenes and markers
+	files map[string]bool
+	// imageChecksums contains the checksums of images, since image
+	// thumbnails may have been generated with different widths
+	imageChecksums map[string]bool
+}
+
+func newGeneratedInventory(p *paths.Paths) *generatedInventory {
+	return &generatedInventory{
+		paths:          p,
+		files:          make(map[string]bool),
+		imageChecksums: make(map[string]bool),
+	}
+}
+
+func (i *generatedInventory) addScene(hash string) {
+	if hash == "" {
+		return
+	}
+
+	sp := i.paths.Scene
+	for _, p := range []string{
+		sp.GetScreenshotPath(hash),
+		sp.GetThumbnailScreenshotPath(hash),
+		sp.GetStreamPreviewPath(hash),
+		sp.GetStreamPreviewImagePath(hash),
+		sp.GetSpriteImageFilePath(hash),
+		sp.GetSpriteVttFilePath(hash),
+		sp.GetTranscodePath(hash),
+	} {
+		i.files[p] = true
+	}
+}
+
+func (i *generatedInventory) addMarker(hash string, m *models.SceneMarker) {
+	if hash == "" {
+		return
+	}
+
+	seconds := int(m.Seconds)
+	endSeconds := m.GetEndSeconds()
+	i.files[i.paths.SceneMarkers.GetStreamPath(hash, seconds, endSeconds)] = true
+	i.files[i.paths.SceneMarkers.GetStreamPreviewImagePath(hash, seconds, endSeconds)] = true
+}
+
+func (i *generatedInventory) addImage(checksum string) {
+	i.imageChecksums[checksum] = true
+}
+
+// load adds the generated files of all scenes, markers and images. Files
+// named with either the checksum or oshash of a scene or any of its
+// additional files are included, so that files are not orphaned by changing
+// the file naming algorithm or the primary file of a scene.
+func (i *generatedInventory) load(r models.ReaderRepository) error {
+	scenes, err := r.Scene().All()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range scenes {
+		files, err := r.Scene().GetFiles(s.ID)
+		if err != nil {
+			return err
+		}
+
+		hashes := []string{s.Checksum.String, s.OSHash.String}
+		for _, f := range files {
+			hashes = append(hashes, f.Checksum.String, f.OSHash.String)
+		}
+
+		for _, h := range hashes {
+			i.addScene(h)
+		}
+
+		markers, err := r.SceneMarker().FindBySceneID(s.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range markers {
+			for _, h := range hashes {
+				i.addMarker(h, m)
+			}
+		}
+	}
+
+	images, err := r.Image().All()
+	if err != nil {
+		return err
+	}
+
+	for _, img := range images {
+		i.addImage(img.Checksum)
+	}
+
+	return nil
+}
+
+// category returns the category of the generated file.
+func (i *generatedInventory) category(path string) models.GeneratedFileCategory {
+	gp := i.paths.Generated
+	dir := filepath.Dir(path)
+	name := filepath.Base(path)
+
+	switch {
+	case dir == gp.Screenshots && strings.HasSuffix(name, ".mp4"):
+		return models.GeneratedFileCategoryPreviews
+	case dir == gp.Screenshots && strings.HasSuffix(name, ".webp"):
+		return models.GeneratedFileCategoryWebp
+	case dir == gp.Screenshots:
+		return models.GeneratedFileCategoryScreenshots
+	case dir == gp.Vtt && strings.HasSuffix(name, "_sprite.jpg"):
+		return models.GeneratedFileCategorySprites
+	case dir == gp.Vtt:
+		return models.GeneratedFileCategoryVtt
+	case dir == gp.Transcodes:
+		return models.GeneratedFileCategoryTranscodes
+	case utils.IsPathInDir(gp.Markers, path):
+		return models.GeneratedFileCategoryMarkers
+	case utils.IsPathInDir(gp.Thumbnails, path):
+		return models.GeneratedFileCategoryImageThumbnails
+	}
+
+	return models.GeneratedFileCategoryOther
+}
+
+// isOrphan returns true if the generated file with the provided category does
+// not belong to any scene, marker or image.
+func (i *generatedInventory) isOrphan(path string, category models.GeneratedFileCategory) bool {
+	switch category {
+	case models.GeneratedFileCategoryOther:
+		return false
+	case models.GeneratedFileCategoryImageThumbnails:
+		// thumbnails are named <checksum>_<width>
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if idx := strings.LastIndex(name, "_"); idx != -1 {
+			name = name[:idx]
+		}
+		return !i.imageChecksums[name]
+	}
+
+	return !i.files[path]
+}
+
+var errGeneratedStopping = errors.New("stopping")
+
+// walk walks the files in the generated directory, adding them to the report.
+func (i *generatedInventory) walk(ctx context.Context, root string, report *GeneratedReport) error {
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			logger.Warnf("error walking %s: %s", path, err.Error())
+			return nil
+		}
+
+		if job.IsCancelled(ctx) {
+			return errGeneratedStopping
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		category := i.category(path)
+		report.add(path, info.Size(), category, i.isOrphan(path, category))
+		return nil
+	})
+
+	sort.Strings(report.Orphans)
+	return err
+}
+
+// deleteGeneratedOrphans deletes the orphaned files of the report, and the
+// marker directories of scenes left empty.
+func deleteGeneratedOrphans(report *GeneratedReport, markersDir string) {
+	dirs := make(map[string]bool)
+	for _, path := range report.Orphans {
+		if err := os.Remove(path); err != nil {
+			logger.Warnf("Could not delete file %s: %s", path, err.Error())
+			continue
+		}
+
+		if dir := filepath.Dir(path); filepath.Dir(dir) == markersDir {
+			dirs[dir] = true
+		}
+	}
+
+	for dir := range dirs {
+		if files, err := ioutil.ReadDir(dir); err == nil && len(files) == 0 {
+			if err := os.Remove(dir); err != nil {
+				logger.Warnf("Could not delete folder %s: %s", dir, err.Error())
+			}
+		}
+	}
+
+	report.Deleted = true
+}
+
+// usage returns the disk usage of the generated directory by category after
+// the orphaned files were deleted, if they were deleted.
+func (r *GeneratedReport) usage() []*GeneratedUsage {
+	ret := make([]*GeneratedUsage, len(r.Categories))
+	for i, u := range r.Categories {
+		c := *u
+		if r.Deleted {
+			c.Files -= c.OrphanFiles
+			c.Size -= c.OrphanSize
+			c.OrphanFiles = 0
+			c.OrphanSize = 0
+		}
+		ret[i] = &c
+	}
+
+	return ret
+}
+
+// GetGeneratedUsage returns the disk usage of the generated directory by
+// category, as found by the last clean generated job. Walking the generated
+// directory is slow, so it is only done by the job. Returns nil if the job
+// has not been run since startup.
+func (s *singleton) GetGeneratedUsage() []*GeneratedUsage {
+	s.generatedUsageMutex.Lock()
+	defer s.generatedUsageMutex.Unlock()
+
+	return s.generatedUsage
+}
+
+func (s *singleton) setGeneratedUsage(usage []*GeneratedUsage) {
+	s.generatedUsageMutex.Lock()
+	defer s.generatedUsageMutex.Unlock()
+
+	s.generatedUsage = usage
+}
+
+func inventoryGenerated(ctx context.Context, txnManager models.TransactionManager) (*GeneratedReport, error) {
+	inventory := newGeneratedInventory(instance.Paths)
+	if err := txnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
+		return inventory.load(r)
+	}); err != nil {
+		return nil, err
+	}
+
+	report := newGeneratedReport()
+	root := config.GetInstance().GetGeneratedPath()
+	if root == "" {
+		return report, nil
+	}
+
+	if err := inventory.walk(ctx, root, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// CleanGeneratedJob finds the generated files that do not belong to any
+// scene, marker or image, and deletes them unless the input is a dry run.
+type CleanGeneratedJob struct {
+	txnManager models.TransactionManager
+	input      models.CleanGeneratedInput
+
+	report *GeneratedReport
+}
+
+// Result returns the report of the job.
+func (j *CleanGeneratedJob) Result() interface{} {
+	if j.report == nil {
+		return nil
+	}
+
+	return j.report
+}
+
+func (j *CleanGeneratedJob) Execute(ctx context.Context, progress *job.Progress) {
+	var report *GeneratedReport
+	var err error
+	progress.ExecuteTask("Finding generated files...", func() {
+		report, err = inventoryGenerated(ctx, j.txnManager)
+	})
+
+	if err == errGeneratedStopping {
+		logger.Info("Stopping due to user request")
+		return
+	}
+
+	if err != nil {
+		logger.Errorf("error finding generated files: %s", err.Error())
+		return
+	}
+
+	for _, u := range report.Categories {
+		logger.Infof("Generated %s: %d files (%.1f MB), %d orphaned (%.1f MB)", strings.ToLower(u.Category.String()), u.Files, bytesToMB(u.Size), u.OrphanFiles, bytesToMB(u.OrphanSize))
+	}
+
+	if !j.input.DryRun && !job.IsCancelled(ctx) {
+		progress.ExecuteTask("Deleting orphaned generated files...", func() {
+			deleteGeneratedOrphans(report, instance.Paths.Generated.Markers)
+		})
+		logger.Infof("Deleted %d orphaned generated files", len(report.Orphans))
+	}
+
+	if err := writeGeneratedReportDownload(report); err != nil {
+		logger.Errorf("error writing generated report: %s", err.Error())
+	}
+
+	instance.setGeneratedUsage(report.usage())
+	j.report = report
+}
+
+func bytesToMB(size int64) float64 {
+	return float64(size) / (1024 * 1024)
+}
+
+func writeGeneratedReportDownload(report *GeneratedReport) error {
+	hash, err := writeJSONDownload(report, "generated_report*.json")
+	if err != nil {
+		return err
+	}
+
+	report.DownloadHash = hash
+	return nil
+}
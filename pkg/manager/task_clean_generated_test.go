@@ -0,0 +1,203 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stashapp/stash/pkg/manager/paths"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+)
+
+func TestGeneratedInventory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-generated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const (
+		sceneHash     = "scene"
+		imageChecksum = "imagechecksum"
+	)
+
+	p := paths.NewPaths(dir)
+	inventory := newGeneratedInventory(p)
+	inventory.addScene(sceneHash)
+	inventory.addMarker(sceneHash, &models.SceneMarker{Seconds: 10})
+	inventory.addImage(imageChecksum)
+
+	valid := []string{
+		p.Scene.GetScreenshotPath(sceneHash),
+		p.Scene.GetStreamPreviewPath(sceneHash),
+		p.Scene.GetSpriteImageFilePath(sceneHash),
+		p.SceneMarkers.GetStreamPath(sceneHash, 10, 0),
+		p.Generated.GetThumbnailPath(imageChecksum, models.DefaultGthumbWidth),
+		p.Generated.GetThumbnailPath(imageChecksum, 1280),
+		// other files are never orphaned
+		p.Generated.GetTmpPath("segment.ts"),
+		filepath.Join(p.Generated.Downloads, "export.zip"),
+	}
+
+	orphans := []string{
+		p.Scene.GetStreamPreviewPath("deleted"),
+		p.Scene.GetStreamPreviewImagePath("deleted"),
+		p.Scene.GetSpriteVttFilePath("deleted"),
+		p.Scene.GetTranscodePath("deleted"),
+		// marker that no longer exists
+		p.SceneMarkers.GetStreamPreviewImagePath(sceneHash, 20, 0),
+		p.SceneMarkers.GetStreamPath("deleted", 5, 0),
+		p.Generated.GetThumbnailPath("deleted", models.DefaultGthumbWidth),
+	}
+
+	for _, f := range append(append([]string{}, valid...), orphans...) {
+		if err := os.MkdirAll(filepath.Dir(f), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(f, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report := newGeneratedReport()
+	if err := inventory.walk(context.Background(), dir, report); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.ElementsMatch(t, orphans, report.Orphans)
+
+	usage := make(map[models.GeneratedFileCategory]*GeneratedUsage)
+	for _, u := range report.Categories {
+		usage[u.Category] = u
+	}
+
+	assert.Equal(t, 2, usage[models.GeneratedFileCategoryPreviews].Files)
+	assert.Equal(t, 1, usage[models.GeneratedFileCategoryPreviews].OrphanFiles)
+	assert.Equal(t, int64(4), usage[models.GeneratedFileCategoryPreviews].OrphanSize)
+	assert.Equal(t, 3, usage[models.GeneratedFileCategoryMarkers].Files)
+	assert.Equal(t, 2, usage[models.GeneratedFileCategoryMarkers].OrphanFiles)
+	assert.Equal(t, 3, usage[models.GeneratedFileCategoryImageThumbnails].Files)
+	assert.Equal(t, 1, usage[models.GeneratedFileCategoryImageThumbnails].OrphanFiles)
+	assert.Equal(t, 2, usage[models.GeneratedFileCategoryOther].Files)
+	assert.Equal(t, 0, usage[models.GeneratedFileCategoryOther].OrphanFiles)
+
+	deleteGeneratedOrphans(report, p.Generated.Markers)
+	assert.True(t, report.Deleted)
+
+	for _, f := range valid {
+		_, err := os.Stat(f)
+		assert.Nil(t, err, f)
+	}
+	for _, f := range orphans {
+		_, err := os.Stat(f)
+		assert.True(t, os.IsNotExist(err), f)
+	}
+
+	// the marker directory of the deleted scene is removed
+	_, err = os.Stat(filepath.Join(p.Generated.Markers, "deleted"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(p.Generated.Markers, sceneHash))
+	assert.Nil(t, err)
+}
+
+func TestGeneratedInventoryLoad(t *testing.T) {
+	const (
+		sceneID       = 1
+		checksum      = "checksum"
+		oshash        = "oshash"
+		fileChecksum  = "filechecksum"
+		fileOSHash    = "fileoshash"
+		imageChecksum = "imagechecksum"
+	)
+
+	mockTxn := mocks.NewTransactionManager()
+	sceneReader := mockTxn.Scene().(*mocks.SceneReaderWriter)
+	markerReader := mockTxn.SceneMarker().(*mocks.SceneMarkerReaderWriter)
+	imageReader := mockTxn.Image().(*mocks.ImageReaderWriter)
+
+	sceneReader.On("All").Return([]*models.Scene{
+		{
+			ID:       sceneID,
+			Checksum: sql.NullString{String: checksum, Valid: true},
+			OSHash:   sql.NullString{String: oshash, Valid: true},
+		},
+	}, nil).Once()
+	sceneReader.On("GetFiles", sceneID).Return([]*models.SceneFile{
+		{
+			SceneID:  sceneID,
+			Checksum: sql.NullString{String: fileChecksum, Valid: true},
+			OSHash:   sql.NullString{String: fileOSHash, Valid: true},
+		},
+	}, nil).Once()
+	markerReader.On("FindBySceneID", sceneID).Return([]*models.SceneMarker{
+		{Seconds: 10},
+	}, nil).Once()
+	imageReader.On("All").Return([]*models.Image{
+		{Checksum: imageChecksum},
+	}, nil).Once()
+
+	p := paths.NewPaths("generated")
+	inventory := newGeneratedInventory(p)
+	if err := mockTxn.WithReadTxn(context.Background(), inventory.load); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, h := range []string{checksum, oshash, fileChecksum, fileOSHash} {
+		for _, f := range []string{
+			p.Scene.GetScreenshotPath(h),
+			p.Scene.GetStreamPreviewPath(h),
+			p.SceneMarkers.GetStreamPath(h, 10, 0),
+		} {
+			assert.False(t, inventory.isOrphan(f, inventory.category(f)), f)
+		}
+	}
+
+	f := p.Generated.GetThumbnailPath(imageChecksum, models.DefaultGthumbWidth)
+	assert.False(t, inventory.isOrphan(f, inventory.category(f)))
+
+	f = p.Scene.GetScreenshotPath("deleted")
+	assert.True(t, inventory.isOrphan(f, inventory.category(f)))
+
+	sceneReader.AssertExpectations(t)
+	markerReader.AssertExpectations(t)
+	imageReader.AssertExpectations(t)
+}
+
+func TestGeneratedReportUsage(t *testing.T) {
+	report := newGeneratedReport()
+	report.add("a", 10, models.GeneratedFileCategoryScreenshots, false)
+	report.add("b", 20, models.GeneratedFileCategoryScreenshots, true)
+
+	find := func(usage []*GeneratedUsage) *GeneratedUsage {
+		for _, u := range usage {
+			if u.Category == models.GeneratedFileCategoryScreenshots {
+				return u
+			}
+		}
+		return nil
+	}
+
+	assert.Equal(t, &GeneratedUsage{
+		Category:    models.GeneratedFileCategoryScreenshots,
+		Files:       2,
+		Size:        30,
+		OrphanFiles: 1,
+		OrphanSize:  20,
+	}, find(report.usage()))
+
+	report.Deleted = true
+	assert.Equal(t, &GeneratedUsage{
+		Category: models.GeneratedFileCategoryScreenshots,
+		Files:    1,
+		Size:     10,
+	}, find(report.usage()))
+
+	// the report itself is unchanged
+	assert.Equal(t, 2, find(report.Categories).Files)
+}
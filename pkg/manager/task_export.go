@@ -510,6 +510,12 @@ func exportImage(wg *sync.WaitGroup, jobChan <-chan *models.Image, repo models.R
 			continue
 		}
 
+		newImageJSON.OHistory, err = image.GetOHistory(repo.Image(), s)
+		if err != nil {
+			logger.Errorf("[images] <%s> error getting image o-counter history: %s", imageHash, err.Error())
+			continue
+		}
+
 		imageGalleries, err := galleryReader.FindByImageID(s.ID)
 		if err != nil {
 			logger.Errorf("[images] <%s> error getting image galleries: %s", imageHash, err.Error())
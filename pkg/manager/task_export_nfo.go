@@ -0,0 +1,266 @@
+package manager
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/stashapp/stash/pkg/job"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/movie"
+	"github.com/stashapp/stash/pkg/nfo"
+	"github.com/stashapp/stash/pkg/scene"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// ExportNFOJob writes Kodi NFO and artwork files for scenes, and for the
+// movies of the scenes.
+type ExportNFOJob struct {
+	txnManager models.TransactionManager
+	input      models.ExportNfoInput
+}
+
+// nfoFile is a file to be written by the export.
+type nfoFile struct {
+	path string
+	data []byte
+}
+
+func (j *ExportNFOJob) Execute(ctx context.Context, progress *job.Progress) {
+	var scenes []*models.Scene
+	if err := j.txnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
+		var err error
+		if len(j.input.SceneIDs) > 0 {
+			ids, _ := utils.StringSliceToIntSlice(j.input.SceneIDs)
+			scenes, err = r.Scene().FindMany(ids)
+		} else {
+			scenes, err = r.Scene().All()
+		}
+		return err
+	}); err != nil {
+		logger.Errorf("[nfo] failed to fetch scenes: %s", err.Error())
+		return
+	}
+
+	logger.Infof("[nfo] exporting %d scenes", len(scenes))
+	progress.SetTotal(len(scenes))
+
+	var movieIDs []int
+	written := 0
+	for _, s := range scenes {
+		if job.IsCancelled(ctx) {
+			logger.Info("Stopping due to user request")
+			return
+		}
+
+		progress.ExecuteTask("Exporting "+s.Path, func() {
+			ids, n, err := j.exportScene(s)
+			if err != nil {
+				logger.Errorf("[nfo] <%s> error exporting scene: %s", s.Path, err.Error())
+			}
+
+			movieIDs = utils.IntAppendUniques(movieIDs, ids)
+			written += n
+		})
+
+		progress.Increment()
+	}
+
+	for _, id := range movieIDs {
+		if job.IsCancelled(ctx) {
+			logger.Info("Stopping due to user request")
+			return
+		}
+
+		n, err := j.exportMovie(id)
+		if err != nil {
+			logger.Errorf("[nfo] <movie %d> error exporting movie: %s", id, err.Error())
+		}
+		written += n
+	}
+
+	logger.Infof("[nfo] export complete: %d files written", written)
+}
+
+// exportScene writes the NFO and artwork files of the scene. Returns the IDs
+// of the movies of the scene and the number of files written.
+func (j *ExportNFOJob) exportScene(s *models.Scene) ([]int, int, error) {
+	videoPath := j.getOutputPath(s.Path)
+	if videoPath == "" {
+		logger.Warnf("[nfo] <%s> scene is not in a stash path, skipping", s.Path)
+		return nil, 0, nil
+	}
+
+	var files []nfoFile
+	var movieIDs []int
+	if err := j.txnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
+		qb := r.Scene()
+		v, err := scene.ToNFO(qb, r.Studio(), r.Movie(), r.Performer(), r.Tag(), s)
+		if err != nil {
+			return err
+		}
+
+		data, err := nfo.Marshal(v)
+		if err != nil {
+			return err
+		}
+		files = append(files, nfoFile{path: nfo.VideoPath(videoPath), data: data})
+
+		poster, err := scene.GetNFOPoster(qb, r.Movie(), s)
+		if err != nil {
+			return err
+		}
+		if len(poster) > 0 {
+			files = append(files, nfoFile{path: nfo.ArtworkPath(videoPath, nfo.Poster, poster), data: poster})
+		}
+
+		cover, err := qb.GetCover(s.ID)
+		if err != nil {
+			return err
+		}
+		if len(cover) > 0 {
+			files = append(files, nfoFile{path: nfo.ArtworkPath(videoPath, nfo.Fanart, cover), data: cover})
+		}
+
+		movieIDs, err = scene.GetDependentMovieIDs(qb, s)
+		return err
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	n, err := j.writeFiles(files)
+	return movieIDs, n, err
+}
+
+// exportMovie writes the movie.nfo and poster files of the movie. These
+// apply to all video files in a directory, so they are only written if the
+// scenes of the movie are the only scenes in their directory.
+func (j *ExportNFOJob) exportMovie(id int) (int, error) {
+	var files []nfoFile
+	if err := j.txnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
+		m, err := r.Movie().Find(id)
+		if err != nil || m == nil {
+			return err
+		}
+
+		dir, err := getMovieDir(r.Scene(), id)
+		if err != nil {
+			return err
+		}
+
+		if dir == "" {
+			logger.Debugf("[nfo] <%s> movie scenes do not have their own directory, skipping", m.Name.String)
+			return nil
+		}
+
+		outputDir := j.getOutputPath(dir)
+		if outputDir == "" {
+			return nil
+		}
+
+		v, err := movie.ToNFO(r.Studio(), m)
+		if err != nil {
+			return err
+		}
+
+		data, err := nfo.Marshal(v)
+		if err != nil {
+			return err
+		}
+		files = append(files, nfoFile{path: nfo.MoviePath(outputDir), data: data})
+
+		frontImage, err := r.Movie().GetFrontImage(id)
+		if err != nil {
+			return err
+		}
+		if len(frontImage) > 0 {
+			files = append(files, nfoFile{path: nfo.FolderArtworkPath(outputDir, nfo.Poster, frontImage), data: frontImage})
+		}
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return j.writeFiles(files)
+}
+
+// getMovieDir returns the directory containing the scenes of the movie.
+// Returns an empty string if the scenes are in different directories, or if
+// the directory contains scenes that are not in the movie.
+func getMovieDir(qb models.SceneReader, movieID int) (string, error) {
+	scenes, err := qb.FindByMovieID(movieID)
+	if err != nil || len(scenes) == 0 {
+		return "", err
+	}
+
+	dir := filepath.Dir(scenes[0].Path)
+	for _, s := range scenes {
+		if filepath.Dir(s.Path) != dir {
+			return "", nil
+		}
+	}
+
+	// only the count is required
+	perPage := 1
+	_, count, err := qb.Query(&models.SceneFilterType{Path: pathCriterion(dir)}, &models.FindFilterType{PerPage: &perPage})
+	if err != nil {
+		return "", err
+	}
+
+	if count != len(scenes) {
+		return "", nil
+	}
+
+	return dir, nil
+}
+
+// getOutputPath returns the path to write the file for the provided path to.
+// If an output path is set, this is the path relative to its stash path,
+// within a directory named after the stash path. Returns an empty string if
+// the path is not within a stash path.
+func (j *ExportNFOJob) getOutputPath(p string) string {
+	if j.input.OutputPath == nil || *j.input.OutputPath == "" {
+		return p
+	}
+
+	stash := getStashFromDirPath(p)
+	if stash == nil {
+		return ""
+	}
+
+	rel, err := filepath.Rel(stash.Path, p)
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(*j.input.OutputPath, filepath.Base(stash.Path), rel)
+}
+
+// writeFiles writes the files, skipping existing files unless overwrite is
+// set. Returns the number of files written.
+func (j *ExportNFOJob) writeFiles(files []nfoFile) (int, error) {
+	written := 0
+	for _, f := range files {
+		if !utils.IsTrue(j.input.Overwrite) {
+			if _, err := os.Stat(f.path); err == nil {
+				logger.Debugf("[nfo] %s already exists, skipping", f.path)
+				continue
+			}
+		}
+
+		if err := utils.EnsureDir(filepath.Dir(f.path)); err != nil {
+			return written, err
+		}
+
+		if err := ioutil.WriteFile(f.path, f.data, 0644); err != nil {
+			return written, err
+		}
+
+		written++
+	}
+
+	return written, nil
+}
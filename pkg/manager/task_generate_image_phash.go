@@ -0,0 +1,49 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/remeh/sizedwaitgroup"
+
+	"github.com/stashapp/stash/pkg/image"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// GenerateImagePhashTask generates the perceptual hash of an image.
+type GenerateImagePhashTask struct {
+	Image      models.Image
+	Overwrite  bool
+	txnManager models.TransactionManager
+}
+
+func (t *GenerateImagePhashTask) Start(wg *sizedwaitgroup.SizedWaitGroup) {
+	defer wg.Done()
+
+	if !t.shouldGenerate() {
+		return
+	}
+
+	hash, err := image.GetPhash(&t.Image)
+	if err != nil {
+		logger.Errorf("error generating phash for image %s: %s", t.Image.Path, err.Error())
+		return
+	}
+
+	if err := t.txnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+		_, err := r.Image().Update(models.ImagePartial{
+			ID:    t.Image.ID,
+			Phash: &sql.NullInt64{Int64: hash, Valid: true},
+		})
+		return err
+	}); err != nil {
+		logger.Error(err.Error())
+	}
+}
+
+// shouldGenerate returns true if the phash should be generated. Phashes are
+// not generated for image clips.
+func (t *GenerateImagePhashTask) shouldGenerate() bool {
+	return !t.Image.Clip && (t.Overwrite || !t.Image.Phash.Valid)
+}
@@ -0,0 +1,119 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/image"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// maxImageClipPreviewDuration is the maximum duration in seconds of the
+// looping previews of image clips.
+const maxImageClipPreviewDuration = 30
+
+// GenerateImageThumbnailTask generates the thumbnail of an image, and the
+// looping preview of an image clip.
+type GenerateImageThumbnailTask struct {
+	Image     *models.Image
+	Overwrite bool
+}
+
+// Start generates the thumbnail. Thumbnails are generated for images that
+// are larger than the thumbnail size, and for images that must be rotated
+// to be displayed upright.
+func (t *GenerateImageThumbnailTask) Start() {
+	if t.Image.Clip {
+		t.generateClip()
+		return
+	}
+
+	thumbPath := GetInstance().Paths.Generated.GetThumbnailPath(t.Image.Checksum, models.DefaultGthumbWidth)
+	exists, _ := utils.FileExists(thumbPath)
+	if exists && !t.Overwrite {
+		return
+	}
+
+	srcImage, err := image.GetSourceImage(t.Image)
+	if err != nil {
+		logger.Errorf("error reading image %s: %s", t.Image.Path, err.Error())
+		return
+	}
+
+	if !image.ThumbnailNeeded(srcImage, models.DefaultGthumbWidth) && !image.IsRotated(t.Image) {
+		// the original image is served in place of the thumbnail
+		if exists {
+			if err := os.Remove(thumbPath); err != nil {
+				logger.Warnf("Could not delete file %s: %s", thumbPath, err.Error())
+			}
+		}
+		return
+	}
+
+	data, err := image.GetThumbnail(srcImage, models.DefaultGthumbWidth)
+	if err != nil {
+		logger.Errorf("error getting thumbnail for image %s: %s", t.Image.Path, err.Error())
+		return
+	}
+
+	err = utils.WriteFile(thumbPath, data)
+	if err != nil {
+		logger.Errorf("error writing thumbnail for image %s: %s", t.Image.Path, err)
+	}
+}
+
+// generateClip generates the thumbnail of an image clip from its first
+// frame, and a looping preview of clips with more than one frame.
+func (t *GenerateImageThumbnailTask) generateClip() {
+	thumbPath := GetInstance().Paths.Generated.GetThumbnailPath(t.Image.Checksum, models.DefaultGthumbWidth)
+	previewPath := GetInstance().Paths.Generated.GetImageClipPreviewPath(t.Image.Checksum, models.DefaultGthumbWidth)
+
+	thumbExists, _ := utils.FileExists(thumbPath)
+	previewExists, _ := utils.FileExists(previewPath)
+	generateThumb := t.Overwrite || !thumbExists
+	generatePreview := t.Image.FrameCount.Int64 > 1 && (t.Overwrite || !previewExists)
+	if !generateThumb && !generatePreview {
+		return
+	}
+
+	videoFile, err := ffmpeg.NewVideoFile(instance.FFProbePath, t.Image.Path, false)
+	if err != nil {
+		logger.Errorf("error reading image clip %s: %s", t.Image.Path, err.Error())
+		return
+	}
+
+	if err := utils.EnsureDirAll(filepath.Dir(thumbPath)); err != nil {
+		logger.Errorf("error creating thumbnail directory: %s", err.Error())
+		return
+	}
+
+	encoder := ffmpeg.NewEncoder(instance.FFMPEGPath)
+
+	if generateThumb {
+		options := ffmpeg.ScreenshotOptions{
+			OutputPath: thumbPath,
+			Width:      ffmpeg.ImageClipScreenshotWidth(*videoFile, models.DefaultGthumbWidth),
+		}
+
+		if err := encoder.Screenshot(*videoFile, options); err != nil {
+			logger.Errorf("error generating thumbnail for image clip %s: %s", t.Image.Path, err.Error())
+			_ = os.Remove(thumbPath)
+		}
+	}
+
+	if generatePreview {
+		options := ffmpeg.ImageClipPreviewOptions{
+			OutputPath: previewPath,
+			MaxSize:    models.DefaultGthumbWidth,
+			Duration:   maxImageClipPreviewDuration,
+		}
+
+		if err := encoder.ImageClipPreview(*videoFile, options); err != nil {
+			logger.Errorf("error generating preview for image clip %s: %s", t.Image.Path, err.Error())
+			_ = os.Remove(previewPath)
+		}
+	}
+}
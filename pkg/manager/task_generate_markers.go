@@ -3,7 +3,6 @@ package manager
 import (
 	"context"
 	"path/filepath"
-	"strconv"
 
 	"github.com/remeh/sizedwaitgroup"
 
@@ -92,25 +91,26 @@ func (t *GenerateMarkersTask) generateSceneMarkers() {
 func (t *GenerateMarkersTask) generateMarker(videoFile *ffmpeg.VideoFile, scene *models.Scene, sceneMarker *models.SceneMarker) {
 	sceneHash := t.Scene.GetHash(t.fileNamingAlgorithm)
 	seconds := int(sceneMarker.Seconds)
+	endSeconds := sceneMarker.GetEndSeconds()
 
-	videoExists := t.videoExists(sceneHash, seconds)
-	imageExists := t.imageExists(sceneHash, seconds)
-
-	baseFilename := strconv.Itoa(seconds)
+	videoExists := t.videoExists(sceneHash, seconds, endSeconds)
+	imageExists := t.imageExists(sceneHash, seconds, endSeconds)
 
 	options := ffmpeg.SceneMarkerOptions{
 		ScenePath: scene.Path,
 		Seconds:   seconds,
 		Width:     640,
 	}
+	if duration := sceneMarker.GetDuration(); duration != nil {
+		options.Duration = *duration
+	}
 
 	encoder := ffmpeg.NewEncoder(instance.FFMPEGPath)
 
 	if t.Overwrite || !videoExists {
-		videoFilename := baseFilename + ".mp4"
-		videoPath := instance.Paths.SceneMarkers.GetStreamPath(sceneHash, seconds)
+		videoPath := instance.Paths.SceneMarkers.GetStreamPath(sceneHash, seconds, endSeconds)
 
-		options.OutputPath = instance.Paths.Generated.GetTmpPath(videoFilename) // tmp output in case the process ends abruptly
+		options.OutputPath = instance.Paths.Generated.GetTmpPath(filepath.Base(videoPath)) // tmp output in case the process ends abruptly
 		if err := encoder.SceneMarkerVideo(*videoFile, options); err != nil {
 			logger.Errorf("[generator] failed to generate marker video: %s", err)
 		} else {
@@ -120,10 +120,9 @@ func (t *GenerateMarkersTask) generateMarker(videoFile *ffmpeg.VideoFile, scene
 	}
 
 	if t.Overwrite || !imageExists {
-		imageFilename := baseFilename + ".webp"
-		imagePath := instance.Paths.SceneMarkers.GetStreamPreviewImagePath(sceneHash, seconds)
+		imagePath := instance.Paths.SceneMarkers.GetStreamPreviewImagePath(sceneHash, seconds, endSeconds)
 
-		options.OutputPath = instance.Paths.Generated.GetTmpPath(imageFilename) // tmp output in case the process ends abruptly
+		options.OutputPath = instance.Paths.Generated.GetTmpPath(filepath.Base(imagePath)) // tmp output in case the process ends abruptly
 		if err := encoder.SceneMarkerImage(*videoFile, options); err != nil {
 			logger.Errorf("[generator] failed to generate marker image: %s", err)
 		} else {
@@ -153,7 +152,7 @@ func (t *GenerateMarkersTask) isMarkerNeeded() int {
 	for _, sceneMarker := range sceneMarkers {
 		seconds := int(sceneMarker.Seconds)
 
-		if t.Overwrite || !t.markerExists(sceneHash, seconds) {
+		if t.Overwrite || !t.markerExists(sceneHash, seconds, sceneMarker.GetEndSeconds()) {
 			markers++
 		}
 	}
@@ -161,36 +160,36 @@ func (t *GenerateMarkersTask) isMarkerNeeded() int {
 	return markers
 }
 
-func (t *GenerateMarkersTask) markerExists(sceneChecksum string, seconds int) bool {
+func (t *GenerateMarkersTask) markerExists(sceneChecksum string, seconds int, endSeconds int) bool {
 	if sceneChecksum == "" {
 		return false
 	}
 
-	videoPath := instance.Paths.SceneMarkers.GetStreamPath(sceneChecksum, seconds)
-	imagePath := instance.Paths.SceneMarkers.GetStreamPreviewImagePath(sceneChecksum, seconds)
+	videoPath := instance.Paths.SceneMarkers.GetStreamPath(sceneChecksum, seconds, endSeconds)
+	imagePath := instance.Paths.SceneMarkers.GetStreamPreviewImagePath(sceneChecksum, seconds, endSeconds)
 	videoExists, _ := utils.FileExists(videoPath)
 	imageExists, _ := utils.FileExists(imagePath)
 
 	return videoExists && imageExists
 }
 
-func (t *GenerateMarkersTask) videoExists(sceneChecksum string, seconds int) bool {
+func (t *GenerateMarkersTask) videoExists(sceneChecksum string, seconds int, endSeconds int) bool {
 	if sceneChecksum == "" {
 		return false
 	}
 
-	videoPath := instance.Paths.SceneMarkers.GetStreamPath(sceneChecksum, seconds)
+	videoPath := instance.Paths.SceneMarkers.GetStreamPath(sceneChecksum, seconds, endSeconds)
 	videoExists, _ := utils.FileExists(videoPath)
 
 	return videoExists
 }
 
-func (t *GenerateMarkersTask) imageExists(sceneChecksum string, seconds int) bool {
+func (t *GenerateMarkersTask) imageExists(sceneChecksum string, seconds int, endSeconds int) bool {
 	if sceneChecksum == "" {
 		return false
 	}
 
-	imagePath := instance.Paths.SceneMarkers.GetStreamPreviewImagePath(sceneChecksum, seconds)
+	imagePath := instance.Paths.SceneMarkers.GetStreamPreviewImagePath(sceneChecksum, seconds, endSeconds)
 	imageExists, _ := utils.FileExists(imagePath)
 
 	return imageExists
@@ -20,41 +20,79 @@ type GeneratePhashTask struct {
 func (t *GeneratePhashTask) Start(wg *sizedwaitgroup.SizedWaitGroup) {
 	defer wg.Done()
 
-	if !t.shouldGenerate() {
+	if t.shouldGenerate() {
+		hash := t.generatePhash(t.Scene.Path, t.Scene.GetHash(t.fileNamingAlgorithm))
+		if hash != nil {
+			if err := t.txnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+				qb := r.Scene()
+				scenePartial := models.ScenePartial{
+					ID:    t.Scene.ID,
+					Phash: hash,
+				}
+				_, err := qb.Update(scenePartial)
+				return err
+			}); err != nil {
+				logger.Error(err.Error())
+			}
+		}
+	}
+
+	t.generateFilePhashes()
+}
+
+// generateFilePhashes generates the phashes of the additional files of the
+// scene that do not have one.
+func (t *GeneratePhashTask) generateFilePhashes() {
+	var files []*models.SceneFile
+	if err := t.txnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
+		var err error
+		files, err = r.Scene().GetFiles(t.Scene.ID)
+		return err
+	}); err != nil {
+		logger.Errorf("error getting scene files: %s", err.Error())
 		return
 	}
 
-	videoFile, err := ffmpeg.NewVideoFile(instance.FFProbePath, t.Scene.Path, false)
+	for _, f := range files {
+		if f.Phash.Valid {
+			continue
+		}
+
+		hash := t.generatePhash(f.Path, f.GetHash(t.fileNamingAlgorithm))
+		if hash == nil {
+			continue
+		}
+
+		f.Phash = *hash
+		if err := t.txnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+			_, err := r.Scene().UpdateFile(*f)
+			return err
+		}); err != nil {
+			logger.Error(err.Error())
+		}
+	}
+}
+
+func (t *GeneratePhashTask) generatePhash(path string, checksum string) *sql.NullInt64 {
+	videoFile, err := ffmpeg.NewVideoFile(instance.FFProbePath, path, false)
 	if err != nil {
 		logger.Errorf("error reading video file: %s", err.Error())
-		return
+		return nil
 	}
 
-	sceneHash := t.Scene.GetHash(t.fileNamingAlgorithm)
-	generator, err := NewPhashGenerator(*videoFile, sceneHash)
+	generator, err := NewPhashGenerator(*videoFile, checksum)
 
 	if err != nil {
 		logger.Errorf("error creating phash generator: %s", err.Error())
-		return
+		return nil
 	}
 	hash, err := generator.Generate()
 	if err != nil {
 		logger.Errorf("error generating phash: %s", err.Error())
-		return
+		return nil
 	}
 
-	if err := t.txnManager.WithTxn(context.TODO(), func(r models.Repository) error {
-		qb := r.Scene()
-		hashValue := sql.NullInt64{Int64: int64(*hash), Valid: true}
-		scenePartial := models.ScenePartial{
-			ID:    t.Scene.ID,
-			Phash: &hashValue,
-		}
-		_, err := qb.Update(scenePartial)
-		return err
-	}); err != nil {
-		logger.Error(err.Error())
-	}
+	return &sql.NullInt64{Int64: int64(*hash), Valid: true}
 }
 
 func (t *GeneratePhashTask) shouldGenerate() bool {
@@ -0,0 +1,75 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/remeh/sizedwaitgroup"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// phashSequenceInterval is the time in seconds between the frames of phash
+// sequences. All sequences must use the same interval to be compared.
+const phashSequenceInterval = 5
+
+// GeneratePhashSequenceTask generates the phashes of frames of the primary
+// file of a scene, used to find scenes that partially match other scenes.
+type GeneratePhashSequenceTask struct {
+	Scene               models.Scene
+	Overwrite           bool
+	fileNamingAlgorithm models.HashAlgorithm
+	txnManager          models.TransactionManager
+}
+
+func (t *GeneratePhashSequenceTask) Start(wg *sizedwaitgroup.SizedWaitGroup) {
+	defer wg.Done()
+
+	if !t.shouldGenerate() {
+		return
+	}
+
+	videoFile, err := ffmpeg.NewVideoFile(instance.FFProbePath, t.Scene.Path, false)
+	if err != nil {
+		logger.Errorf("error reading video file: %s", err.Error())
+		return
+	}
+
+	generator, err := NewPhashGenerator(*videoFile, t.Scene.GetHash(t.fileNamingAlgorithm))
+	if err != nil {
+		logger.Errorf("error creating phash generator: %s", err.Error())
+		return
+	}
+
+	frames, err := generator.GenerateSequence(phashSequenceInterval)
+	if err != nil {
+		logger.Errorf("error generating phash sequence: %s", err.Error())
+		return
+	}
+
+	if err := t.txnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+		return r.Scene().UpdatePhashFrames(t.Scene.ID, frames)
+	}); err != nil {
+		logger.Error(err.Error())
+	}
+}
+
+// shouldGenerate returns true if the scene does not have phash frames.
+func (t *GeneratePhashSequenceTask) shouldGenerate() bool {
+	if t.Overwrite {
+		return true
+	}
+
+	var frames []*models.ScenePhashFrame
+	if err := t.txnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
+		var err error
+		frames, err = r.Scene().GetPhashFrames(t.Scene.ID)
+		return err
+	}); err != nil {
+		logger.Errorf("error getting phash frames of %s: %s", t.Scene.Path, err.Error())
+		return false
+	}
+
+	return len(frames) == 0
+}
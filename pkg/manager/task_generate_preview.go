@@ -1,6 +1,8 @@
 package manager
 
 import (
+	"context"
+
 	"github.com/remeh/sizedwaitgroup"
 
 	"github.com/stashapp/stash/pkg/ffmpeg"
@@ -11,6 +13,7 @@ import (
 )
 
 type GeneratePreviewTask struct {
+	TxnManager   models.TransactionManager
 	Scene        models.Scene
 	ImagePreview bool
 
@@ -53,12 +56,43 @@ func (t *GeneratePreviewTask) Start(wg *sizedwaitgroup.SizedWaitGroup) {
 	generator.Info.ExcludeEnd = *t.Options.PreviewExcludeEnd
 	generator.Info.Audio = config.GetInstance().GetPreviewAudio()
 
+	if err := t.setSceneDetection(generator.Info); err != nil {
+		logger.Errorf("error getting scene detection of %s: %s", t.Scene.Path, err.Error())
+		return
+	}
+
 	if err := generator.Generate(); err != nil {
 		logger.Errorf("error generating preview: %s", err.Error())
 		return
 	}
 }
 
+// setSceneDetection sets the intro, outro and cuts detected in the scene, so
+// that the preview chunks are placed on the detected cuts. The scene is
+// reloaded since detection may have been run after it was loaded.
+func (t *GeneratePreviewTask) setSceneDetection(info *GeneratorInfo) error {
+	if t.TxnManager == nil {
+		return nil
+	}
+
+	return t.TxnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
+		qb := r.Scene()
+		s, err := qb.Find(t.Scene.ID)
+		if err != nil {
+			return err
+		}
+
+		if s == nil || !s.IntroEnd.Valid {
+			return nil
+		}
+
+		info.IntroEnd = s.IntroEnd.Float64
+		info.OutroStart = s.OutroStart.Float64
+		info.Cuts, err = qb.GetCuts(t.Scene.ID)
+		return err
+	})
+}
+
 func (t GeneratePreviewTask) required() bool {
 	sceneHash := t.Scene.GetHash(t.fileNamingAlgorithm)
 	videoExists := t.doesVideoPreviewExist(sceneHash)
@@ -0,0 +1,87 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/remeh/sizedwaitgroup"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/scene"
+)
+
+const (
+	defaultSceneDetectionThreshold = 0.4
+	blackDetectionMinDuration      = 0.5
+	blackDetectionPixelThreshold   = 0.1
+)
+
+// GenerateSceneDetectionTask detects the black segments and cuts in the file
+// of a scene, and stores the intro, outro and cuts of the scene determined
+// from them.
+type GenerateSceneDetectionTask struct {
+	Scene     models.Scene
+	Overwrite bool
+	// Threshold is the minimum scene change score of cuts, between 0 and 1
+	Threshold  float64
+	txnManager models.TransactionManager
+}
+
+func (t *GenerateSceneDetectionTask) Start(wg *sizedwaitgroup.SizedWaitGroup) {
+	defer wg.Done()
+
+	if !t.shouldGenerate() {
+		return
+	}
+
+	videoFile, err := ffmpeg.NewVideoFile(instance.FFProbePath, t.Scene.Path, false)
+	if err != nil {
+		logger.Errorf("error reading video file: %s", err.Error())
+		return
+	}
+
+	threshold := t.Threshold
+	if threshold <= 0 || threshold >= 1 {
+		threshold = defaultSceneDetectionThreshold
+	}
+
+	encoder := ffmpeg.NewEncoder(instance.FFMPEGPath)
+	result, err := encoder.DetectScenes(*videoFile, ffmpeg.SceneDetectionOptions{
+		SceneThreshold:      threshold,
+		BlackMinDuration:    blackDetectionMinDuration,
+		BlackPixelThreshold: blackDetectionPixelThreshold,
+	})
+	if err != nil {
+		logger.Errorf("error detecting scenes of %s: %s", t.Scene.Path, err.Error())
+		return
+	}
+
+	detection := scene.GetDetection(videoFile.Duration, *result)
+
+	if err := t.txnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+		qb := r.Scene()
+		scenePartial := models.ScenePartial{
+			ID:         t.Scene.ID,
+			IntroEnd:   &sql.NullFloat64{Float64: detection.IntroEnd, Valid: true},
+			OutroStart: &sql.NullFloat64{Float64: detection.OutroStart, Valid: true},
+		}
+		if _, err := qb.Update(scenePartial); err != nil {
+			return err
+		}
+
+		return qb.UpdateCuts(t.Scene.ID, detection.Cuts)
+	}); err != nil {
+		logger.Error(err.Error())
+		return
+	}
+
+	logger.Debugf("detected intro end %.2f, outro start %.2f and %d cuts in %s", detection.IntroEnd, detection.OutroStart, len(detection.Cuts), t.Scene.Path)
+}
+
+// shouldGenerate returns true if scene detection has not been run on the
+// scene. The intro end of the scene is set once detection has been run.
+func (t *GenerateSceneDetectionTask) shouldGenerate() bool {
+	return t.Overwrite || !t.Scene.IntroEnd.Valid
+}
@@ -0,0 +1,110 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/image"
+	"github.com/stashapp/stash/pkg/job"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// RegenerateImageThumbnailsJob regenerates the thumbnails of existing
+// images, and updates their dimensions. The orientation of images that were
+// scanned before file metadata was read is read first.
+type RegenerateImageThumbnailsJob struct {
+	txnManager models.TransactionManager
+	input      models.RegenerateImageThumbnailsInput
+}
+
+func (j *RegenerateImageThumbnailsJob) Execute(ctx context.Context, progress *job.Progress) {
+	var images []*models.Image
+	if err := j.txnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
+		var err error
+		if len(j.input.ImageIDs) > 0 {
+			ids, _ := utils.StringSliceToIntSlice(j.input.ImageIDs)
+			images, err = r.Image().FindMany(ids)
+		} else {
+			images, err = r.Image().All()
+		}
+		return err
+	}); err != nil {
+		logger.Errorf("failed to fetch images: %s", err.Error())
+		return
+	}
+
+	rotatedOnly := j.input.RotatedOnly != nil && *j.input.RotatedOnly
+
+	logger.Infof("Regenerating thumbnails of %d images", len(images))
+	progress.SetTotal(len(images))
+
+	for _, i := range images {
+		if job.IsCancelled(ctx) {
+			logger.Info("Stopping due to user request")
+			return
+		}
+
+		progress.ExecuteTask("Regenerating thumbnail for "+image.PathDisplayName(i.Path), func() {
+			if err := j.regenerate(i, rotatedOnly); err != nil {
+				logger.Errorf("error regenerating thumbnail for image %s: %s", i.Path, err.Error())
+			}
+		})
+
+		progress.Increment()
+	}
+
+	logger.Info("Finished regenerating image thumbnails")
+}
+
+func (j *RegenerateImageThumbnailsJob) regenerate(i *models.Image, rotatedOnly bool) error {
+	if !i.Orientation.Valid {
+		task := ScanTask{
+			TxnManager: j.txnManager,
+			FilePath:   i.Path,
+		}
+
+		var err error
+		i, err = task.scanImageMetadata(i)
+		if err != nil {
+			return err
+		}
+	}
+
+	if rotatedOnly && !image.IsRotated(i) {
+		return nil
+	}
+
+	// the dimensions of rotated images may have been stored before rotation
+	fileDetails := &models.Image{
+		Path: i.Path,
+	}
+	if err := setImageFileDetails(fileDetails); err != nil {
+		return err
+	}
+
+	if fileDetails.Width != i.Width || fileDetails.Height != i.Height || fileDetails.Clip != i.Clip || fileDetails.FrameCount != i.FrameCount {
+		if err := j.txnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+			var err error
+			i, err = r.Image().Update(models.ImagePartial{
+				ID:         i.ID,
+				Width:      &fileDetails.Width,
+				Height:     &fileDetails.Height,
+				Clip:       &fileDetails.Clip,
+				Duration:   &fileDetails.Duration,
+				FrameCount: &fileDetails.FrameCount,
+			})
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+
+	task := GenerateImageThumbnailTask{
+		Image:     i,
+		Overwrite: true,
+	}
+	task.Start()
+
+	return nil
+}
@@ -1,19 +1,20 @@
 package manager
 
 import (
-	"archive/zip"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/remeh/sizedwaitgroup"
 
+	"github.com/stashapp/stash/pkg/archive"
 	"github.com/stashapp/stash/pkg/ffmpeg"
 	"github.com/stashapp/stash/pkg/gallery"
 	"github.com/stashapp/stash/pkg/image"
@@ -30,12 +31,30 @@ type ScanJob struct {
 	txnManager    models.TransactionManager
 	input         models.ScanMetadataInput
 	subscriptions *subscriptionManager
+
+	// report is set by a dry run
+	report *ScanReport
+}
+
+// Result returns the report of a dry run. Returns nil if the scan was not a
+// dry run.
+func (j *ScanJob) Result() interface{} {
+	if j.report == nil {
+		return nil
+	}
+
+	return j.report
 }
 
 func (j *ScanJob) Execute(ctx context.Context, progress *job.Progress) {
 	input := j.input
 	paths := getScanPaths(input.Paths)
 
+	if utils.IsTrue(input.DryRun) {
+		j.dryRun(ctx, progress, paths)
+		return
+	}
+
 	var total *int
 	var newFiles *int
 	progress.ExecuteTask("Counting files to scan...", func() {
@@ -67,6 +86,12 @@ func (j *ScanJob) Execute(ctx context.Context, progress *job.Progress) {
 	fileNamingAlgo := config.GetVideoFileNamingAlgorithm()
 	calculateMD5 := config.IsCalculateMD5()
 
+	// create missing studios, performers, movies and tags by default
+	nfoMissingRefBehaviour := models.ImportMissingRefEnumCreate
+	if input.NfoMissingRefBehaviour != nil {
+		nfoMissingRefBehaviour = *input.NfoMissingRefBehaviour
+	}
+
 	stoppingErr := errors.New("stopping")
 	var err error
 
@@ -91,19 +116,27 @@ func (j *ScanJob) Execute(ctx context.Context, progress *job.Progress) {
 
 			wg.Add()
 			task := ScanTask{
-				TxnManager:           j.txnManager,
-				FilePath:             path,
-				UseFileMetadata:      utils.IsTrue(input.UseFileMetadata),
-				StripFileExtension:   utils.IsTrue(input.StripFileExtension),
-				fileNamingAlgorithm:  fileNamingAlgo,
-				calculateMD5:         calculateMD5,
-				GeneratePreview:      utils.IsTrue(input.ScanGeneratePreviews),
-				GenerateImagePreview: utils.IsTrue(input.ScanGenerateImagePreviews),
-				GenerateSprite:       utils.IsTrue(input.ScanGenerateSprites),
-				GeneratePhash:        utils.IsTrue(input.ScanGeneratePhashes),
-				progress:             progress,
-				CaseSensitiveFs:      csFs,
-				ctx:                  ctx,
+				TxnManager:               j.txnManager,
+				FilePath:                 path,
+				UseFileMetadata:          utils.IsTrue(input.UseFileMetadata),
+				UseNFOFiles:              utils.IsTrue(input.UseNfoFiles),
+				NFOMissingRefBehaviour:   nfoMissingRefBehaviour,
+				StripFileExtension:       utils.IsTrue(input.StripFileExtension),
+				UseImageMetadataDate:     utils.IsTrue(input.UseImageMetadataDate),
+				UseImageMetadataRating:   utils.IsTrue(input.UseImageMetadataRating),
+				UseImageMetadataKeywords: utils.IsTrue(input.UseImageMetadataKeywords),
+				ImportChapters:           utils.IsTrue(input.ImportChapters),
+				GroupFilesByName:         utils.IsTrue(input.GroupFilesByName),
+				fileNamingAlgorithm:      fileNamingAlgo,
+				calculateMD5:             calculateMD5,
+				GeneratePreview:          utils.IsTrue(input.ScanGeneratePreviews),
+				GenerateImagePreview:     utils.IsTrue(input.ScanGenerateImagePreviews),
+				GenerateSprite:           utils.IsTrue(input.ScanGenerateSprites),
+				GeneratePhash:            utils.IsTrue(input.ScanGeneratePhashes),
+				GenerateImagePhash:       utils.IsTrue(input.ScanGenerateImagePhashes),
+				progress:                 progress,
+				CaseSensitiveFs:          csFs,
+				ctx:                      ctx,
 			}
 
 			go func() {
@@ -203,20 +236,28 @@ func (j *ScanJob) neededScan(ctx context.Context, paths []*models.StashConfig) (
 }
 
 type ScanTask struct {
-	ctx                  context.Context
-	TxnManager           models.TransactionManager
-	FilePath             string
-	UseFileMetadata      bool
-	StripFileExtension   bool
-	calculateMD5         bool
-	fileNamingAlgorithm  models.HashAlgorithm
-	GenerateSprite       bool
-	GeneratePhash        bool
-	GeneratePreview      bool
-	GenerateImagePreview bool
-	zipGallery           *models.Gallery
-	progress             *job.Progress
-	CaseSensitiveFs      bool
+	ctx                      context.Context
+	TxnManager               models.TransactionManager
+	FilePath                 string
+	UseFileMetadata          bool
+	UseNFOFiles              bool
+	NFOMissingRefBehaviour   models.ImportMissingRefEnum
+	StripFileExtension       bool
+	UseImageMetadataDate     bool
+	UseImageMetadataRating   bool
+	UseImageMetadataKeywords bool
+	ImportChapters           bool
+	GroupFilesByName         bool
+	calculateMD5             bool
+	fileNamingAlgorithm      models.HashAlgorithm
+	GenerateSprite           bool
+	GeneratePhash            bool
+	GeneratePreview          bool
+	GenerateImagePreview     bool
+	GenerateImagePhash       bool
+	archiveGallery           *models.Gallery
+	progress                 *job.Progress
+	CaseSensitiveFs          bool
 }
 
 func (t *ScanTask) Start(wg *sizedwaitgroup.SizedWaitGroup) {
@@ -227,6 +268,8 @@ func (t *ScanTask) Start(wg *sizedwaitgroup.SizedWaitGroup) {
 	t.progress.ExecuteTask("Scanning "+t.FilePath, func() {
 		if isGallery(t.FilePath) {
 			t.scanGallery()
+		} else if isImageClip(t.FilePath) {
+			t.scanImage()
 		} else if isVideo(t.FilePath) {
 			s = t.scanScene()
 		} else if isImage(t.FilePath) {
@@ -284,6 +327,7 @@ func (t *ScanTask) Start(wg *sizedwaitgroup.SizedWaitGroup) {
 				}
 
 				taskPreview := GeneratePreviewTask{
+					TxnManager:          t.TxnManager,
 					Scene:               *s,
 					ImagePreview:        t.GenerateImagePreview,
 					Options:             previewOptions,
@@ -310,7 +354,7 @@ func (t *ScanTask) scanGallery() {
 		if g != nil && err != nil {
 			images, err = r.Image().CountByGalleryID(g.ID)
 			if err != nil {
-				return fmt.Errorf("error getting images for zip gallery %s: %s", t.FilePath, err.Error())
+				return fmt.Errorf("error getting images for archive gallery %s: %s", t.FilePath, err.Error())
 			}
 		}
 
@@ -331,7 +375,7 @@ func (t *ScanTask) scanGallery() {
 
 		// if file mod time is not set, set it now
 		if !g.FileModTime.Valid {
-			// we will also need to rescan the zip contents
+			// we will also need to rescan the archive contents
 			scanImages = true
 			logger.Infof("setting file modification time on %s", t.FilePath)
 
@@ -354,7 +398,7 @@ func (t *ScanTask) scanGallery() {
 			}
 		}
 
-		// if the mod time of the zip file is different than that of the associated
+		// if the mod time of the archive file is different than that of the associated
 		// gallery, then recalculate the checksum
 		modified := t.isFileModified(fileModTime, g.FileModTime)
 		if modified {
@@ -388,7 +432,7 @@ func (t *ScanTask) scanGallery() {
 			}
 		}
 
-		// scan the zip files if the gallery has no images
+		// scan the archive files if the gallery has no images
 		scanImages = scanImages || images == 0
 	} else {
 		// Ignore directories.
@@ -419,6 +463,7 @@ func (t *ScanTask) scanGallery() {
 					logger.Infof("%s already exists.  Duplicate of %s ", t.FilePath, g.Path.String)
 				} else {
 					logger.Infof("%s already exists.  Updating path...", t.FilePath)
+					oldPath := g.Path.String
 					g.Path = sql.NullString{
 						String: t.FilePath,
 						Valid:  true,
@@ -428,6 +473,11 @@ func (t *ScanTask) scanGallery() {
 						return err
 					}
 
+					// move the gallery to the gallery of its new folder
+					if err := gallery.UpdateFolderParent(qb, g, oldPath); err != nil {
+						return err
+					}
+
 					GetInstance().PluginCache.ExecutePostHooks(t.ctx, g.ID, plugin.GalleryUpdatePost, nil, nil)
 				}
 			} else {
@@ -453,7 +503,7 @@ func (t *ScanTask) scanGallery() {
 				}
 
 				// don't create gallery if it has no images
-				if countImagesInZip(t.FilePath) > 0 {
+				if countImagesInArchive(t.FilePath) > 0 {
 					// only warn when creating the gallery
 					ok, err := utils.IsZipFileUncompressed(t.FilePath)
 					if err == nil && !ok {
@@ -465,6 +515,10 @@ func (t *ScanTask) scanGallery() {
 					if err != nil {
 						return err
 					}
+
+					if err := gallery.SetFolderParent(qb, g); err != nil {
+						return err
+					}
 					scanImages = true
 
 					GetInstance().PluginCache.ExecutePostHooks(t.ctx, g.ID, plugin.GalleryCreatePost, nil, nil)
@@ -480,10 +534,10 @@ func (t *ScanTask) scanGallery() {
 
 	if g != nil {
 		if scanImages {
-			t.scanZipImages(g)
+			t.scanArchiveImages(g)
 		} else {
 			// in case thumbnails have been deleted, regenerate them
-			t.regenerateZipImages(g)
+			t.regenerateArchiveImages(g)
 		}
 	}
 }
@@ -574,10 +628,16 @@ func (t *ScanTask) scanScene() *models.Scene {
 
 	var retScene *models.Scene
 	var s *models.Scene
+	var f *models.SceneFile
 
 	if err := t.TxnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
 		var err error
 		s, err = r.Scene().FindByPath(t.FilePath)
+		if err != nil || s != nil {
+			return err
+		}
+
+		f, err = r.Scene().FindFileByPath(t.FilePath)
 		return err
 	}); err != nil {
 		logger.Error(err.Error())
@@ -590,6 +650,17 @@ func (t *ScanTask) scanScene() *models.Scene {
 	}
 	interactive := t.getInteractive()
 
+	if f != nil {
+		// additional file of an existing scene
+		if t.isFileModified(fileModTime, f.FileModTime) || !f.Size.Valid {
+			if err := t.rescanSceneFile(f, fileModTime); err != nil {
+				return logError(err)
+			}
+		}
+
+		return nil
+	}
+
 	if s != nil {
 		// if file mod time is not set, set it now
 		if !s.FileModTime.Valid {
@@ -617,7 +688,8 @@ func (t *ScanTask) scanScene() *models.Scene {
 		// scene, then recalculate the checksum and regenerate the thumbnail
 		modified := t.isFileModified(fileModTime, s.FileModTime)
 		config := config.GetInstance()
-		if modified || !s.Size.Valid {
+		rescanned := modified || !s.Size.Valid
+		if rescanned {
 			oldHash := s.GetHash(config.GetVideoFileNamingAlgorithm())
 			s, err = t.rescanScene(s, fileModTime)
 			if err != nil {
@@ -711,6 +783,28 @@ func (t *ScanTask) scanScene() *models.Scene {
 			}
 		}
 
+		// check for added or removed sidecar captions. Captions of rescanned
+		// files have already been updated.
+		if !rescanned && t.captionsModified(s) {
+			if err := t.updateCaptions(s.ID, nil); err != nil {
+				return logError(err)
+			}
+		}
+
+		// audio tracks of rescanned files have already been updated
+		if !rescanned {
+			if err := t.populateAudioTracks(s); err != nil {
+				return logError(err)
+			}
+		}
+
+		// chapters of rescanned files have already been imported
+		if !rescanned && !s.ChaptersImported {
+			if err := t.importChapters(s.ID, nil); err != nil {
+				logger.Errorf("error importing chapters of %s: %s", t.FilePath, err.Error())
+			}
+		}
+
 		return nil
 	}
 
@@ -758,6 +852,15 @@ func (t *ScanTask) scanScene() *models.Scene {
 			s, _ = qb.FindByOSHash(oshash)
 		}
 
+		// check the additional files of scenes as well
+		if s == nil && checksum != "" {
+			f, _ = qb.FindFileByChecksum(checksum)
+		}
+
+		if s == nil && f == nil {
+			f, _ = qb.FindFileByOSHash(oshash)
+		}
+
 		return nil
 	})
 
@@ -770,17 +873,13 @@ func (t *ScanTask) scanScene() *models.Scene {
 	t.makeScreenshots(videoFile, sceneHash)
 
 	if s != nil {
-		exists, _ := utils.FileExists(s.Path)
-		if !t.CaseSensitiveFs {
-			// #1426 - if file exists but is a case-insensitive match for the
-			// original filename, then treat it as a move
-			if exists && strings.EqualFold(t.FilePath, s.Path) {
-				exists = false
-			}
-		}
+		exists := t.existsAtOtherPath(s.Path)
 
 		if exists {
-			logger.Infof("%s already exists. Duplicate of %s", t.FilePath, s.Path)
+			logger.Infof("%s already exists. Adding as an additional file of %s", t.FilePath, s.Path)
+			if err := t.addSceneFile(s.ID, videoFile, container, checksum, oshash, fileModTime); err != nil {
+				return logError(err)
+			}
 		} else {
 			logger.Infof("%s already exists. Updating path...", t.FilePath)
 			scenePartial := models.ScenePartial{
@@ -795,8 +894,46 @@ func (t *ScanTask) scanScene() *models.Scene {
 				return logError(err)
 			}
 
+			if err := t.updateCaptions(s.ID, videoFile); err != nil {
+				logger.Errorf("error updating captions of %s: %s", t.FilePath, err.Error())
+			}
+
+			if err := t.updateAudioTracks(s.ID, videoFile); err != nil {
+				logger.Errorf("error updating audio tracks of %s: %s", t.FilePath, err.Error())
+			}
+
+			if err := t.importChapters(s.ID, videoFile); err != nil {
+				logger.Errorf("error importing chapters of %s: %s", t.FilePath, err.Error())
+			}
+
 			GetInstance().PluginCache.ExecutePostHooks(t.ctx, s.ID, plugin.SceneUpdatePost, nil, nil)
 		}
+	} else if f != nil {
+		exists := t.existsAtOtherPath(f.Path)
+
+		if exists {
+			logger.Infof("%s already exists. Adding as an additional file of the scene containing %s", t.FilePath, f.Path)
+			if err := t.addSceneFile(f.SceneID, videoFile, container, checksum, oshash, fileModTime); err != nil {
+				return logError(err)
+			}
+		} else {
+			logger.Infof("%s already exists. Updating path...", t.FilePath)
+			f.Path = t.FilePath
+			f.UpdatedAt = models.SQLiteTimestamp{Timestamp: time.Now()}
+			if err := t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+				_, err := r.Scene().UpdateFile(*f)
+				return err
+			}); err != nil {
+				return logError(err)
+			}
+
+			GetInstance().PluginCache.ExecutePostHooks(t.ctx, f.SceneID, plugin.SceneUpdatePost, nil, nil)
+		}
+	} else if g := t.findSceneByFileGroup(); g != nil {
+		logger.Infof("Adding %s as an additional file of %s", t.FilePath, g.Path)
+		if err := t.addSceneFile(g.ID, videoFile, container, checksum, oshash, fileModTime); err != nil {
+			return logError(err)
+		}
 	} else {
 		logger.Infof("%s doesn't exist. Creating new item...", t.FilePath)
 		currentTime := time.Now()
@@ -828,14 +965,33 @@ func (t *ScanTask) scanScene() *models.Scene {
 			newScene.Date = models.SQLiteDate{String: videoFile.CreationTime.Format("2006-01-02")}
 		}
 
-		if err := t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
-			var err error
-			retScene, err = r.Scene().Create(newScene)
-			return err
-		}); err != nil {
+		var nfoImporter *scene.NFOImporter
+		if t.UseNFOFiles {
+			nfoImporter = t.loadNFO()
+		}
+
+		var err error
+		retScene, nfoImporter, err = t.createScene(newScene, nfoImporter)
+		if err != nil {
 			return logError(err)
 		}
 
+		if nfoImporter != nil {
+			t.updatePerformerImages(nfoImporter.PerformerImages)
+		}
+
+		if err := t.updateCaptions(retScene.ID, videoFile); err != nil {
+			logger.Errorf("error updating captions of %s: %s", t.FilePath, err.Error())
+		}
+
+		if err := t.updateAudioTracks(retScene.ID, videoFile); err != nil {
+			logger.Errorf("error updating audio tracks of %s: %s", t.FilePath, err.Error())
+		}
+
+		if err := t.importChapters(retScene.ID, videoFile); err != nil {
+			logger.Errorf("error importing chapters of %s: %s", t.FilePath, err.Error())
+		}
+
 		GetInstance().PluginCache.ExecutePostHooks(t.ctx, retScene.ID, plugin.SceneCreatePost, nil, nil)
 	}
 
@@ -893,19 +1049,44 @@ func (t *ScanTask) rescanScene(s *models.Scene, fileModTime time.Time) (*models.
 			Timestamp: fileModTime,
 			Valid:     true,
 		},
-		UpdatedAt: &models.SQLiteTimestamp{Timestamp: currentTime},
+		// the file contents have changed, so scene detection must be run again
+		IntroEnd:   &sql.NullFloat64{},
+		OutroStart: &sql.NullFloat64{},
+		UpdatedAt:  &models.SQLiteTimestamp{Timestamp: currentTime},
 	}
 
 	var ret *models.Scene
 	if err := t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+		qb := r.Scene()
 		var err error
-		ret, err = r.Scene().Update(scenePartial)
-		return err
+		ret, err = qb.Update(scenePartial)
+		if err != nil {
+			return err
+		}
+
+		if err := qb.UpdateCuts(ret.ID, nil); err != nil {
+			return err
+		}
+
+		// the phash sequence is of the previous file contents
+		return qb.UpdatePhashFrames(ret.ID, nil)
 	}); err != nil {
 		logger.Error(err.Error())
 		return nil, err
 	}
 
+	if err := t.updateCaptions(ret.ID, videoFile); err != nil {
+		logger.Errorf("error updating captions of %s: %s", t.FilePath, err.Error())
+	}
+
+	if err := t.updateAudioTracks(ret.ID, videoFile); err != nil {
+		logger.Errorf("error updating audio tracks of %s: %s", t.FilePath, err.Error())
+	}
+
+	if err := t.importChapters(ret.ID, videoFile); err != nil {
+		logger.Errorf("error importing chapters of %s: %s", t.FilePath, err.Error())
+	}
+
 	GetInstance().PluginCache.ExecutePostHooks(t.ctx, ret.ID, plugin.SceneUpdatePost, nil, nil)
 
 	// leave the generated files as is - the scene file may have been moved
@@ -913,6 +1094,210 @@ func (t *ScanTask) rescanScene(s *models.Scene, fileModTime time.Time) (*models.
 
 	return ret, nil
 }
+
+// updateCaptions updates the sidecar captions of the scene. The embedded
+// captions are updated as well if videoFile is not nil. The modification
+// time of the folder of the scene file is stored, so that the sidecar
+// captions are only read again when files in the folder are added, removed
+// or renamed.
+func (t *ScanTask) updateCaptions(sceneID int, videoFile *ffmpeg.VideoFile) error {
+	folderModTime, err := t.getFolderModTime()
+	if err != nil {
+		return err
+	}
+
+	captions, err := scene.GetSidecarCaptions(t.FilePath)
+	if err != nil {
+		return err
+	}
+
+	if videoFile != nil {
+		captions = append(captions, scene.GetEmbeddedCaptions(videoFile)...)
+	}
+
+	return t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+		qb := r.Scene()
+		changed, err := scene.UpdateCaptions(qb, sceneID, captions, videoFile == nil)
+		if err != nil {
+			return err
+		}
+
+		if changed {
+			logger.Infof("Updated captions of %s", t.FilePath)
+		}
+
+		_, err = qb.Update(models.ScenePartial{
+			ID:              sceneID,
+			CaptionsModTime: &models.NullSQLiteTimestamp{Timestamp: folderModTime, Valid: true},
+		})
+		return err
+	})
+}
+
+// captionsModified returns true if the folder of the scene file has been
+// modified since the sidecar captions of the scene were last read.
+func (t *ScanTask) captionsModified(s *models.Scene) bool {
+	folderModTime, err := t.getFolderModTime()
+	if err != nil {
+		logger.Warnf("error getting modification time of the folder of %s: %s", t.FilePath, err.Error())
+		return false
+	}
+
+	return t.isFileModified(folderModTime, s.CaptionsModTime)
+}
+
+func (t *ScanTask) getFolderModTime() (time.Time, error) {
+	dir := filepath.Dir(t.FilePath)
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error performing stat on %s: %s", dir, err.Error())
+	}
+
+	// truncate to seconds, since we don't store beyond that in the database
+	return fi.ModTime().Truncate(time.Second), nil
+}
+
+// updateAudioTracks sets the audio tracks of the scene to the audio streams
+// of the file, and marks the audio tracks of the scene as probed.
+func (t *ScanTask) updateAudioTracks(sceneID int, videoFile *ffmpeg.VideoFile) error {
+	tracks := scene.GetAudioTracks(videoFile)
+
+	return t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+		qb := r.Scene()
+		changed, err := scene.UpdateAudioTracks(qb, sceneID, tracks)
+		if err != nil {
+			return err
+		}
+
+		if changed {
+			logger.Infof("Updated audio tracks of %s", t.FilePath)
+		}
+
+		probed := true
+		_, err = qb.Update(models.ScenePartial{
+			ID:                sceneID,
+			AudioTracksProbed: &probed,
+		})
+		return err
+	})
+}
+
+// populateAudioTracks probes the file of an existing scene and sets its audio
+// tracks, if the scene has audio and its audio tracks have not been probed.
+// This populates the audio tracks of scenes that were scanned before audio
+// tracks were stored.
+func (t *ScanTask) populateAudioTracks(s *models.Scene) error {
+	if s.AudioTracksProbed || !s.AudioCodec.Valid || s.AudioCodec.String == "" {
+		return nil
+	}
+
+	videoFile, err := ffmpeg.NewVideoFile(GetInstance().FFProbePath, t.FilePath, t.StripFileExtension)
+	if err != nil {
+		return err
+	}
+
+	return t.updateAudioTracks(s.ID, videoFile)
+}
+
+// existsAtOtherPath returns true if a file exists at the provided path, and
+// the provided path is not the path being scanned.
+func (t *ScanTask) existsAtOtherPath(path string) bool {
+	exists, _ := utils.FileExists(path)
+	if !t.CaseSensitiveFs {
+		// #1426 - if file exists but is a case-insensitive match for the
+		// original filename, then treat it as a move
+		if exists && strings.EqualFold(t.FilePath, path) {
+			exists = false
+		}
+	}
+
+	return exists
+}
+
+func (t *ScanTask) addSceneFile(sceneID int, videoFile *ffmpeg.VideoFile, container ffmpeg.Container, checksum string, oshash string, fileModTime time.Time) error {
+	currentTime := time.Now()
+	newFile := models.SceneFile{
+		SceneID:    sceneID,
+		Path:       t.FilePath,
+		Checksum:   sql.NullString{String: checksum, Valid: checksum != ""},
+		OSHash:     sql.NullString{String: oshash, Valid: oshash != ""},
+		Duration:   sql.NullFloat64{Float64: videoFile.Duration, Valid: true},
+		VideoCodec: sql.NullString{String: videoFile.VideoCodec, Valid: true},
+		AudioCodec: sql.NullString{String: videoFile.AudioCodec, Valid: true},
+		Format:     sql.NullString{String: string(container), Valid: true},
+		Width:      sql.NullInt64{Int64: int64(videoFile.Width), Valid: true},
+		Height:     sql.NullInt64{Int64: int64(videoFile.Height), Valid: true},
+		Framerate:  sql.NullFloat64{Float64: videoFile.FrameRate, Valid: true},
+		Bitrate:    sql.NullInt64{Int64: videoFile.Bitrate, Valid: true},
+		Size:       sql.NullString{String: strconv.FormatInt(videoFile.Size, 10), Valid: true},
+		FileModTime: models.NullSQLiteTimestamp{
+			Timestamp: fileModTime,
+			Valid:     true,
+		},
+		CreatedAt: models.SQLiteTimestamp{Timestamp: currentTime},
+		UpdatedAt: models.SQLiteTimestamp{Timestamp: currentTime},
+	}
+
+	if err := t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+		_, err := r.Scene().CreateFile(newFile)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	GetInstance().PluginCache.ExecutePostHooks(t.ctx, sceneID, plugin.SceneUpdatePost, nil, nil)
+	return nil
+}
+
+func (t *ScanTask) rescanSceneFile(f *models.SceneFile, fileModTime time.Time) error {
+	logger.Infof("%s has been updated: rescanning", t.FilePath)
+
+	oshash, err := utils.OSHashFromFilePath(t.FilePath)
+	if err != nil {
+		return err
+	}
+
+	if t.calculateMD5 {
+		checksum, err := t.calculateChecksum()
+		if err != nil {
+			return err
+		}
+
+		f.Checksum = sql.NullString{String: checksum, Valid: true}
+	}
+
+	videoFile, err := ffmpeg.NewVideoFile(instance.FFProbePath, t.FilePath, t.StripFileExtension)
+	if err != nil {
+		return err
+	}
+	container := ffmpeg.MatchContainer(videoFile.Container, t.FilePath)
+
+	f.OSHash = sql.NullString{String: oshash, Valid: true}
+	f.Duration = sql.NullFloat64{Float64: videoFile.Duration, Valid: true}
+	f.VideoCodec = sql.NullString{String: videoFile.VideoCodec, Valid: true}
+	f.AudioCodec = sql.NullString{String: videoFile.AudioCodec, Valid: true}
+	f.Format = sql.NullString{String: string(container), Valid: true}
+	f.Width = sql.NullInt64{Int64: int64(videoFile.Width), Valid: true}
+	f.Height = sql.NullInt64{Int64: int64(videoFile.Height), Valid: true}
+	f.Framerate = sql.NullFloat64{Float64: videoFile.FrameRate, Valid: true}
+	f.Bitrate = sql.NullInt64{Int64: videoFile.Bitrate, Valid: true}
+	f.Size = sql.NullString{String: strconv.FormatInt(videoFile.Size, 10), Valid: true}
+	f.FileModTime = models.NullSQLiteTimestamp{Timestamp: fileModTime, Valid: true}
+	// the file contents have changed, so the phash is no longer valid
+	f.Phash = sql.NullInt64{}
+	f.UpdatedAt = models.SQLiteTimestamp{Timestamp: time.Now()}
+
+	if err := t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+		_, err := r.Scene().UpdateFile(*f)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	GetInstance().PluginCache.ExecutePostHooks(t.ctx, f.SceneID, plugin.SceneUpdatePost, nil, nil)
+	return nil
+}
+
 func (t *ScanTask) makeScreenshots(probeResult *ffmpeg.VideoFile, checksum string) {
 	thumbPath := instance.Paths.Scene.GetThumbnailScreenshotPath(checksum)
 	normalPath := instance.Paths.Scene.GetScreenshotPath(checksum)
@@ -948,14 +1333,22 @@ func (t *ScanTask) makeScreenshots(probeResult *ffmpeg.VideoFile, checksum strin
 	}
 }
 
-func (t *ScanTask) scanZipImages(zipGallery *models.Gallery) {
-	err := walkGalleryZip(zipGallery.Path.String, func(file *zip.File) error {
+func (t *ScanTask) scanArchiveImages(archiveGallery *models.Gallery) {
+	// keep the archive open so that it is not listed again for each image
+	release, err := archive.Hold(archiveGallery.Path.String)
+	if err != nil {
+		logger.Warnf("failed to open archive file %s: %s", archiveGallery.Path.String, err.Error())
+		return
+	}
+	defer release()
+
+	err = walkGalleryArchive(archiveGallery.Path.String, func(file archive.File) error {
 		// copy this task and change the filename
 		subTask := *t
 
-		// filepath is the zip file and the internal file name, separated by a null byte
-		subTask.FilePath = image.ZipFilename(zipGallery.Path.String, file.Name)
-		subTask.zipGallery = zipGallery
+		// filepath is the archive file and the internal file name, separated by a null byte
+		subTask.FilePath = image.ArchiveFilename(archiveGallery.Path.String, file.Name())
+		subTask.archiveGallery = archiveGallery
 
 		// run the subtask and wait for it to complete
 		iwg := sizedwaitgroup.New(1)
@@ -964,23 +1357,30 @@ func (t *ScanTask) scanZipImages(zipGallery *models.Gallery) {
 		return nil
 	})
 	if err != nil {
-		logger.Warnf("failed to scan zip file images for %s: %s", zipGallery.Path.String, err.Error())
+		logger.Warnf("failed to scan archive file images for %s: %s", archiveGallery.Path.String, err.Error())
 	}
 }
 
-func (t *ScanTask) regenerateZipImages(zipGallery *models.Gallery) {
+func (t *ScanTask) regenerateArchiveImages(archiveGallery *models.Gallery) {
 	var images []*models.Image
 	if err := t.TxnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
 		iqb := r.Image()
 
 		var err error
-		images, err = iqb.FindByGalleryID(zipGallery.ID)
+		images, err = iqb.FindByGalleryID(archiveGallery.ID)
 		return err
 	}); err != nil {
 		logger.Warnf("failed to find gallery images: %s", err.Error())
 		return
 	}
 
+	release, err := archive.Hold(archiveGallery.Path.String)
+	if err != nil {
+		logger.Warnf("failed to open archive file %s: %s", archiveGallery.Path.String, err.Error())
+		return
+	}
+	defer release()
+
 	for _, img := range images {
 		t.generateThumbnail(img)
 	}
@@ -1029,8 +1429,9 @@ func (t *ScanTask) scanImage() {
 		}
 
 		// if the mod time of the file is different than that of the associated
-		// image, then recalculate the checksum and regenerate the thumbnail
-		modified := t.isFileModified(fileModTime, i.FileModTime)
+		// image, then recalculate the checksum and regenerate the thumbnail.
+		// Images are also rescanned if the image clip extensions have changed.
+		modified := t.isFileModified(fileModTime, i.FileModTime) || imageClipChanged(i)
 		if modified {
 			i, err = t.rescanImage(i, fileModTime)
 			if err != nil {
@@ -1039,9 +1440,17 @@ func (t *ScanTask) scanImage() {
 			}
 		}
 
+		// read the file metadata if it has not been read yet
+		i, err = t.scanImageMetadata(i)
+		if err != nil {
+			logger.Error(err.Error())
+			return
+		}
+
 		// We already have this item in the database
 		// check for thumbnails
 		t.generateThumbnail(i)
+		t.generateImagePhash(i)
 	} else {
 		// Ignore directories.
 		if isDir, _ := utils.DirExists(t.FilePath); isDir {
@@ -1113,14 +1522,21 @@ func (t *ScanTask) scanImage() {
 			newImage.Title.String = image.GetFilename(&newImage, t.StripFileExtension)
 			newImage.Title.Valid = true
 
-			if err := image.SetFileDetails(&newImage); err != nil {
+			if err := setImageFileDetails(&newImage); err != nil {
 				logger.Error(err.Error())
 				return
 			}
 
+			metadata := t.readImageMetadata()
+
 			if err := t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
 				var err error
 				i, err = r.Image().Create(newImage)
+				if err != nil {
+					return err
+				}
+
+				i, err = t.updateImageMetadata(r, i, metadata)
 				return err
 			}); err != nil {
 				logger.Error(err.Error())
@@ -1130,10 +1546,10 @@ func (t *ScanTask) scanImage() {
 			GetInstance().PluginCache.ExecutePostHooks(t.ctx, i.ID, plugin.ImageCreatePost, nil, nil)
 		}
 
-		if t.zipGallery != nil {
+		if t.archiveGallery != nil {
 			// associate with gallery
 			if err := t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
-				return gallery.AddImage(r.Gallery(), t.zipGallery.ID, i.ID)
+				return gallery.AddImage(r.Gallery(), t.archiveGallery.ID, i.ID)
 			}); err != nil {
 				logger.Error(err.Error())
 				return
@@ -1152,6 +1568,7 @@ func (t *ScanTask) scanImage() {
 
 	if i != nil {
 		t.generateThumbnail(i)
+		t.generateImagePhash(i)
 	}
 }
 
@@ -1167,18 +1584,23 @@ func (t *ScanTask) rescanImage(i *models.Image, fileModTime time.Time) (*models.
 	}
 
 	// regenerate the file details as well
-	fileDetails, err := image.GetFileDetails(t.FilePath)
-	if err != nil {
+	fileDetails := &models.Image{
+		Path: t.FilePath,
+	}
+	if err := setImageFileDetails(fileDetails); err != nil {
 		return nil, err
 	}
 
 	currentTime := time.Now()
 	imagePartial := models.ImagePartial{
-		ID:       i.ID,
-		Checksum: &checksum,
-		Width:    &fileDetails.Width,
-		Height:   &fileDetails.Height,
-		Size:     &fileDetails.Size,
+		ID:         i.ID,
+		Checksum:   &checksum,
+		Width:      &fileDetails.Width,
+		Height:     &fileDetails.Height,
+		Size:       &fileDetails.Size,
+		Clip:       &fileDetails.Clip,
+		Duration:   &fileDetails.Duration,
+		FrameCount: &fileDetails.FrameCount,
 		FileModTime: &models.NullSQLiteTimestamp{
 			Timestamp: fileModTime,
 			Valid:     true,
@@ -1186,21 +1608,31 @@ func (t *ScanTask) rescanImage(i *models.Image, fileModTime time.Time) (*models.
 		UpdatedAt: &models.SQLiteTimestamp{Timestamp: currentTime},
 	}
 
+	// the phash must be regenerated if the image has changed
+	if oldChecksum != checksum {
+		imagePartial.Phash = &sql.NullInt64{}
+	}
+
+	metadata := t.readImageMetadata()
+
 	var ret *models.Image
 	if err := t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
 		var err error
 		ret, err = r.Image().Update(imagePartial)
+		if err != nil {
+			return err
+		}
+
+		ret, err = t.updateImageMetadata(r, ret, metadata)
 		return err
 	}); err != nil {
 		return nil, err
 	}
 
-	// remove the old thumbnail if the checksum changed - we'll regenerate it
-	if oldChecksum != checksum {
-		err = os.Remove(GetInstance().Paths.Generated.GetThumbnailPath(oldChecksum, models.DefaultGthumbWidth)) // remove cache dir of gallery
-		if err != nil {
-			logger.Errorf("Error deleting thumbnail image: %s", err)
-		}
+	// remove the old thumbnail if the checksum or the kind of image changed
+	// - we'll regenerate it
+	if oldChecksum != checksum || i.Clip != fileDetails.Clip {
+		DeleteGeneratedImageFiles(i)
 	}
 
 	GetInstance().PluginCache.ExecutePostHooks(t.ctx, ret.ID, plugin.ImageUpdatePost, nil, nil)
@@ -1241,6 +1673,11 @@ func (t *ScanTask) associateImageWithFolderGallery(imageID int, qb models.Galler
 		if err != nil {
 			return err
 		}
+
+		// nest the gallery within the gallery of the closest parent folder
+		if err := gallery.SetFolderParent(qb, g); err != nil {
+			return err
+		}
 	}
 
 	// associate image with gallery
@@ -1249,30 +1686,22 @@ func (t *ScanTask) associateImageWithFolderGallery(imageID int, qb models.Galler
 }
 
 func (t *ScanTask) generateThumbnail(i *models.Image) {
-	thumbPath := GetInstance().Paths.Generated.GetThumbnailPath(i.Checksum, models.DefaultGthumbWidth)
-	exists, _ := utils.FileExists(thumbPath)
-	if exists {
-		return
-	}
+	task := GenerateImageThumbnailTask{Image: i}
+	task.Start()
+}
 
-	srcImage, err := image.GetSourceImage(i)
-	if err != nil {
-		logger.Errorf("error reading image %s: %s", i.Path, err.Error())
+func (t *ScanTask) generateImagePhash(i *models.Image) {
+	if !t.GenerateImagePhash {
 		return
 	}
 
-	if image.ThumbnailNeeded(srcImage, models.DefaultGthumbWidth) {
-		data, err := image.GetThumbnail(srcImage, models.DefaultGthumbWidth)
-		if err != nil {
-			logger.Errorf("error getting thumbnail for image %s: %s", i.Path, err.Error())
-			return
-		}
-
-		err = utils.WriteFile(thumbPath, data)
-		if err != nil {
-			logger.Errorf("error writing thumbnail for image %s: %s", i.Path, err)
-		}
+	wg := sizedwaitgroup.New(1)
+	wg.Add()
+	task := GenerateImagePhashTask{
+		Image:      *i,
+		txnManager: t.TxnManager,
 	}
+	task.Start(&wg)
 }
 
 func (t *ScanTask) calculateChecksum() (string, error) {
@@ -1287,7 +1716,7 @@ func (t *ScanTask) calculateChecksum() (string, error) {
 
 func (t *ScanTask) calculateImageChecksum() (string, error) {
 	logger.Infof("Calculating checksum for %s...", image.PathDisplayName(t.FilePath))
-	// uses image.CalculateMD5 to read files in zips
+	// uses image.CalculateMD5 to read files in archives
 	checksum, err := image.CalculateMD5(t.FilePath)
 	if err != nil {
 		return "", err
@@ -1309,12 +1738,15 @@ func (t *ScanTask) doesPathExist() bool {
 			if gallery != nil {
 				ret = true
 			}
-		} else if matchExtension(t.FilePath, vidExt) {
+		} else if matchExtension(t.FilePath, vidExt) && !isImageClip(t.FilePath) {
 			s, _ := r.Scene().FindByPath(t.FilePath)
 			if s != nil {
 				ret = true
+			} else {
+				f, _ := r.Scene().FindFileByPath(t.FilePath)
+				ret = f != nil
 			}
-		} else if matchExtension(t.FilePath, imgExt) {
+		} else if matchExtension(t.FilePath, imgExt) || isImageClip(t.FilePath) {
 			i, _ := r.Image().FindByPath(t.FilePath)
 			if i != nil {
 				ret = true
@@ -1327,21 +1759,74 @@ func (t *ScanTask) doesPathExist() bool {
 	return ret
 }
 
-func walkFilesToScan(s *models.StashConfig, f filepath.WalkFunc) error {
+// scanFilter determines which files and directories in the stash paths are
+// scanned, based on the configured extensions and exclusion patterns.
+type scanFilter struct {
+	vidExt          []string
+	imgExt          []string
+	clipExt         []string
+	gExt            []string
+	excludeVidRegex []*regexp.Regexp
+	excludeImgRegex []*regexp.Regexp
+	generatedPath   string
+}
+
+func newScanFilter() *scanFilter {
 	config := config.GetInstance()
-	vidExt := config.GetVideoExtensions()
-	imgExt := config.GetImageExtensions()
-	gExt := config.GetGalleryExtensions()
-	excludeVidRegex := generateRegexps(config.GetExcludes())
-	excludeImgRegex := generateRegexps(config.GetImageExcludes())
+	return &scanFilter{
+		vidExt:          config.GetVideoExtensions(),
+		imgExt:          config.GetImageExtensions(),
+		clipExt:         config.GetImageClipExtensions(),
+		gExt:            config.GetGalleryExtensions(),
+		excludeVidRegex: generateRegexps(config.GetExcludes()),
+		excludeImgRegex: generateRegexps(config.GetImageExcludes()),
+		generatedPath:   config.GetGeneratedPath(),
+	}
+}
 
-	// don't scan zip images directly
-	if image.IsZipPath(s.Path) {
-		logger.Warnf("Cannot rescan zip image %s. Rescan zip gallery instead.", s.Path)
-		return nil
+// skipDir returns true if the directory and its contents should not be
+// scanned.
+func (f *scanFilter) skipDir(s *models.StashConfig, path string) bool {
+	// #1102 - ignore files in generated path
+	if f.generatedPath != "" && utils.IsPathInDir(f.generatedPath, path) {
+		return true
+	}
+
+	// skip the directory entirely if it matches both exclusion patterns
+	// add a trailing separator so that it correctly matches against patterns like path/.*
+	pathExcludeTest := path + string(filepath.Separator)
+	return (s.ExcludeVideo || matchFileRegex(pathExcludeTest, f.excludeVidRegex)) && (s.ExcludeImage || matchFileRegex(pathExcludeTest, f.excludeImgRegex))
+}
+
+// matchFile returns true if the file should be scanned.
+func (f *scanFilter) matchFile(s *models.StashConfig, path string) bool {
+	if !s.ExcludeVideo && matchExtension(path, f.vidExt) && !matchFileRegex(path, f.excludeVidRegex) {
+		return true
 	}
 
-	generatedPath := config.GetGeneratedPath()
+	if !s.ExcludeImage {
+		if (matchExtension(path, f.imgExt) || matchExtension(path, f.gExt) || f.matchImageClip(s, path)) && !matchFileRegex(path, f.excludeImgRegex) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchImageClip returns true if the file has an image clip extension, and
+// is not scanned as a video.
+func (f *scanFilter) matchImageClip(s *models.StashConfig, path string) bool {
+	return matchExtension(path, f.clipExt) && (s.ExcludeVideo || !matchExtension(path, f.vidExt))
+}
+
+func walkFilesToScan(s *models.StashConfig, f filepath.WalkFunc) error {
+	filter := newScanFilter()
+
+	// don't scan archive images directly
+	if image.IsArchivePath(s.Path) {
+		logger.Warnf("Cannot rescan archive image %s. Rescan archive gallery instead.", s.Path)
+		return nil
+	}
 
 	return utils.SymWalk(s.Path, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -1350,31 +1835,17 @@ func walkFilesToScan(s *models.StashConfig, f filepath.WalkFunc) error {
 		}
 
 		if info.IsDir() {
-			// #1102 - ignore files in generated path
-			if utils.IsPathInDir(generatedPath, path) {
-				return filepath.SkipDir
-			}
-
-			// shortcut: skip the directory entirely if it matches both exclusion patterns
-			// add a trailing separator so that it correctly matches against patterns like path/.*
-			pathExcludeTest := path + string(filepath.Separator)
-			if (s.ExcludeVideo || matchFileRegex(pathExcludeTest, excludeVidRegex)) && (s.ExcludeImage || matchFileRegex(pathExcludeTest, excludeImgRegex)) {
+			if filter.skipDir(s, path) {
 				return filepath.SkipDir
 			}
 
 			return nil
 		}
 
-		if !s.ExcludeVideo && matchExtension(path, vidExt) && !matchFileRegex(path, excludeVidRegex) {
+		if filter.matchFile(s, path) {
 			return f(path, info, err)
 		}
 
-		if !s.ExcludeImage {
-			if (matchExtension(path, imgExt) || matchExtension(path, gExt)) && !matchFileRegex(path, excludeImgRegex) {
-				return f(path, info, err)
-			}
-		}
-
 		return nil
 	})
 }
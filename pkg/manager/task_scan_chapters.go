@@ -0,0 +1,58 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/manager/config"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/scene"
+)
+
+// importChapters creates scene markers from the chapters embedded in the
+// scanned file, and marks the chapters of the scene as imported. The file is
+// probed if videoFile is nil. Chapters that already have a marker are not
+// imported again.
+func (t *ScanTask) importChapters(sceneID int, videoFile *ffmpeg.VideoFile) error {
+	if !t.ImportChapters {
+		return nil
+	}
+
+	if videoFile == nil {
+		var err error
+		videoFile, err = ffmpeg.NewVideoFile(GetInstance().FFProbePath, t.FilePath, t.StripFileExtension)
+		if err != nil {
+			return err
+		}
+	}
+
+	c := config.GetInstance()
+
+	return t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+		if len(videoFile.Chapters) > 0 {
+			importer := scene.ChapterImporter{
+				MarkerWriter: r.SceneMarker(),
+				TagWriter:    r.Tag(),
+				TagMappings:  c.GetChapterTagMappings(),
+				DefaultTag:   c.GetChapterDefaultTag(),
+			}
+
+			created, err := importer.Import(sceneID, videoFile.Chapters)
+			if err != nil {
+				return err
+			}
+
+			if created > 0 {
+				logger.Infof("Created %d scene markers from the chapters of %s", created, t.FilePath)
+			}
+		}
+
+		imported := true
+		_, err := r.Scene().Update(models.ScenePartial{
+			ID:               sceneID,
+			ChaptersImported: &imported,
+		})
+		return err
+	})
+}
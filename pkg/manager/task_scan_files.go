@@ -0,0 +1,86 @@
+package manager
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// resolutionSuffixRE matches a resolution or quality suffix at the end of a
+// file name, such as "movie.1080p" or "movie - 4k".
+var resolutionSuffixRE = regexp.MustCompile(`(?i)[ ._-]+(\d{3,4}p|[248]k|uhd|fhd|hd|sd)$`)
+
+// sceneFileGroupName returns the name used to group video files into the
+// same scene. Files with the same group name in the same folder are
+// different versions of the same scene, such as re-encodes or different
+// resolutions.
+func sceneFileGroupName(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	name = resolutionSuffixRE.ReplaceAllString(name, "")
+	return strings.ToLower(name)
+}
+
+// findSceneByFileGroup returns the scene with a primary or additional file
+// in the same folder with the same group name as the file being scanned.
+// Returns nil if GroupFilesByName is false or no such scene exists.
+func (t *ScanTask) findSceneByFileGroup() *models.Scene {
+	if !t.GroupFilesByName {
+		return nil
+	}
+
+	dir := filepath.Dir(t.FilePath)
+	name := sceneFileGroupName(t.FilePath)
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		logger.Warnf("error reading folder %s: %s", dir, err.Error())
+		return nil
+	}
+
+	var ret *models.Scene
+	if err := t.TxnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
+		qb := r.Scene()
+		for _, info := range files {
+			path := filepath.Join(dir, info.Name())
+			if info.IsDir() || path == t.FilePath || !isVideo(path) || sceneFileGroupName(path) != name {
+				continue
+			}
+
+			s, err := qb.FindByPath(path)
+			if err != nil {
+				return err
+			}
+
+			if s == nil {
+				f, err := qb.FindFileByPath(path)
+				if err != nil {
+					return err
+				}
+
+				if f != nil {
+					s, err = qb.Find(f.SceneID)
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+			if s != nil {
+				ret = s
+				return nil
+			}
+		}
+
+		return nil
+	}); err != nil {
+		logger.Warnf("error finding scene of files named like %s: %s", t.FilePath, err.Error())
+		return nil
+	}
+
+	return ret
+}
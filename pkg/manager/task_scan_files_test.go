@@ -0,0 +1,29 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSceneFileGroupName(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/videos/movie.mp4", "movie"},
+		{"/videos/Movie.mkv", "movie"},
+		{"/videos/movie.1080p.mp4", "movie"},
+		{"/videos/movie_720P.mp4", "movie"},
+		{"/videos/movie - 4k.mp4", "movie"},
+		{"/videos/movie.hd.mp4", "movie"},
+		// only a trailing suffix is removed
+		{"/videos/1080p movie.mp4", "1080p movie"},
+		{"/videos/movie 2.mp4", "movie 2"},
+		{"/videos/movie.part1.mp4", "movie.part1"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, sceneFileGroupName(tt.path), tt.path)
+	}
+}
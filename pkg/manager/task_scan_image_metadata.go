@@ -0,0 +1,78 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/image"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// readImageMetadata reads the EXIF and XMP metadata of the image file.
+// Returns nil if the metadata could not be read, or if the file is an image
+// clip.
+func (t *ScanTask) readImageMetadata() *image.Metadata {
+	if isImageClip(t.FilePath) {
+		return nil
+	}
+
+	m, err := image.GetMetadata(t.FilePath)
+	if err != nil {
+		logger.Warnf("error reading metadata of %s: %s", t.FilePath, err.Error())
+		return nil
+	}
+
+	return m
+}
+
+// updateImageMetadata stores the file metadata of the image, and sets the
+// image fields from it as selected by the scan options.
+func (t *ScanTask) updateImageMetadata(r models.Repository, i *models.Image, m *image.Metadata) (*models.Image, error) {
+	if m == nil {
+		return i, nil
+	}
+
+	ret, err := image.UpdateMetadata(r.Image(), i.ID, m)
+	if err != nil {
+		return nil, err
+	}
+
+	options := image.MetadataOptions{
+		SetDate:   t.UseImageMetadataDate,
+		SetRating: t.UseImageMetadataRating,
+		SetTags:   t.UseImageMetadataKeywords,
+	}
+
+	if err := image.ApplyMetadata(r.Image(), r.Tag(), ret, m, options); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// scanImageMetadata reads and stores the file metadata of an existing image
+// that was scanned before metadata was read. The orientation is always set
+// once the metadata has been read.
+func (t *ScanTask) scanImageMetadata(i *models.Image) (*models.Image, error) {
+	if i.Orientation.Valid {
+		return i, nil
+	}
+
+	m := t.readImageMetadata()
+	if m == nil {
+		return i, nil
+	}
+
+	logger.Infof("setting file metadata on %s", t.FilePath)
+
+	var ret *models.Image
+	if err := t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+		var err error
+		ret, err = t.updateImageMetadata(r, i, m)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
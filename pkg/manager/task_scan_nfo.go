@@ -0,0 +1,134 @@
+package manager
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/nfo"
+	"github.com/stashapp/stash/pkg/scene"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// loadNFO reads the NFO files of the scanned file. Returns nil if there are
+// none, or if they could not be read.
+func (t *ScanTask) loadNFO() *scene.NFOImporter {
+	metadata, err := nfo.Load(t.FilePath)
+	if err != nil {
+		logger.Warnf("error reading NFO file of %s: %s", t.FilePath, err.Error())
+		return nil
+	}
+
+	if metadata == nil {
+		return nil
+	}
+
+	logger.Debugf("Using NFO metadata for %s", t.FilePath)
+
+	return &scene.NFOImporter{
+		Input:               *metadata,
+		MissingRefBehaviour: t.NFOMissingRefBehaviour,
+	}
+}
+
+// nfoImportError is returned when the NFO metadata of a new scene could not
+// be applied.
+type nfoImportError struct {
+	err error
+}
+
+func (e *nfoImportError) Error() string {
+	return "error importing NFO metadata: " + e.err.Error()
+}
+
+// preImportNFO applies the NFO metadata to the new scene. The objects
+// created by the import are not removed if this fails, so an error must roll
+// back the transaction.
+func (t *ScanTask) preImportNFO(r models.Repository, i *scene.NFOImporter, s *models.Scene) error {
+	i.ReaderWriter = r.Scene()
+	i.StudioWriter = r.Studio()
+	i.PerformerWriter = r.Performer()
+	i.MovieWriter = r.Movie()
+	i.TagWriter = r.Tag()
+
+	if err := i.PreImport(s); err != nil {
+		return &nfoImportError{err: err}
+	}
+
+	return nil
+}
+
+// postImportNFO associates the objects referenced by the NFO metadata with
+// the new scene.
+func (t *ScanTask) postImportNFO(i *scene.NFOImporter, id int) error {
+	if err := i.PostImport(id); err != nil {
+		return &nfoImportError{err: err}
+	}
+
+	return nil
+}
+
+// createScene creates the scene, applying the NFO metadata if nfoImporter is
+// not nil. If the metadata could not be applied, the transaction is rolled
+// back and the scene is created without it. Returns the created scene and
+// the importer of the metadata that was applied, if any.
+func (t *ScanTask) createScene(newScene models.Scene, nfoImporter *scene.NFOImporter) (*models.Scene, *scene.NFOImporter, error) {
+	var ret *models.Scene
+	create := func() error {
+		return t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+			toCreate := newScene
+			if nfoImporter != nil {
+				if err := t.preImportNFO(r, nfoImporter, &toCreate); err != nil {
+					return err
+				}
+			}
+
+			var err error
+			ret, err = r.Scene().Create(toCreate)
+			if err != nil {
+				return err
+			}
+
+			if nfoImporter != nil {
+				return t.postImportNFO(nfoImporter, ret.ID)
+			}
+
+			return nil
+		})
+	}
+
+	err := create()
+	var nfoErr *nfoImportError
+	if errors.As(err, &nfoErr) {
+		// the objects created by the import were rolled back with the
+		// transaction, so the scene is created without the metadata
+		logger.Warnf("Ignoring NFO metadata of %s: %s", t.FilePath, nfoErr.err.Error())
+		nfoImporter = nil
+		err = create()
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ret, nfoImporter, nil
+}
+
+// updatePerformerImages downloads the thumbnails of the performers created
+// from NFO metadata and sets them as the performer images.
+func (t *ScanTask) updatePerformerImages(images map[int]string) {
+	for id, url := range images {
+		data, err := utils.ReadImageFromURL(url)
+		if err != nil {
+			logger.Warnf("error reading performer image from %s: %s", url, err.Error())
+			continue
+		}
+
+		if err := t.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+			return r.Performer().UpdateImage(id, data)
+		}); err != nil {
+			logger.Warnf("error updating performer image: %s", err.Error())
+		}
+	}
+}
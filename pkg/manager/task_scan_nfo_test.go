@@ -0,0 +1,104 @@
+package manager
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stashapp/stash/pkg/nfo"
+	"github.com/stashapp/stash/pkg/scene"
+)
+
+const (
+	nfoSceneID = iota + 1
+	nfoTagID
+)
+
+func TestScanTaskCreateSceneNFO(t *testing.T) {
+	fileScene := models.Scene{
+		Path:  "scene.mp4",
+		Title: sql.NullString{String: "file title", Valid: true},
+	}
+
+	nfoScene := fileScene
+	nfoScene.Title = sql.NullString{String: "nfo title", Valid: true}
+
+	tests := []struct {
+		name          string
+		tagsErr       error
+		updateTagsErr error
+		want          models.Scene
+		nfoApplied    bool
+	}{
+		{"applied", nil, nil, nfoScene, true},
+		{"pre import error", errors.New("FindByNames error"), nil, fileScene, false},
+		{"post import error", nil, errors.New("UpdateTags error"), fileScene, false},
+	}
+
+	for _, tt := range tests {
+		mockTxn := mocks.NewTransactionManager()
+		mockSceneReader := mockTxn.Scene().(*mocks.SceneReaderWriter)
+		mockTagReader := mockTxn.Tag().(*mocks.TagReaderWriter)
+
+		mockTagReader.On("FindByNames", []string{"tag"}, false).Return([]*models.Tag{
+			{ID: nfoTagID, Name: "tag"},
+		}, tt.tagsErr)
+
+		created := &models.Scene{ID: nfoSceneID}
+		mockSceneReader.On("Create", mock.Anything).Return(created, nil)
+		mockSceneReader.On("UpdateTags", nfoSceneID, []int{nfoTagID}).Return(tt.updateTagsErr)
+
+		task := &ScanTask{
+			TxnManager: mockTxn,
+			FilePath:   fileScene.Path,
+		}
+
+		importer := &scene.NFOImporter{
+			Input: nfo.Metadata{
+				Title: "nfo title",
+				Tags:  []string{"tag"},
+			},
+			MissingRefBehaviour: models.ImportMissingRefEnumFail,
+		}
+
+		got, applied, err := task.createScene(fileScene, importer)
+		if !assert.Nil(t, err, tt.name) {
+			continue
+		}
+
+		assert.Same(t, created, got, tt.name)
+		assert.Equal(t, tt.nfoApplied, applied != nil, tt.name)
+
+		// the scene is created without the metadata after a failed import
+		var createdScenes []interface{}
+		for _, c := range mockSceneReader.Calls {
+			if c.Method == "Create" {
+				createdScenes = append(createdScenes, c.Arguments.Get(0))
+			}
+		}
+		assert.Equal(t, tt.want, createdScenes[len(createdScenes)-1], tt.name)
+	}
+}
+
+func TestScanTaskCreateSceneError(t *testing.T) {
+	mockTxn := mocks.NewTransactionManager()
+	mockSceneReader := mockTxn.Scene().(*mocks.SceneReaderWriter)
+
+	mockSceneReader.On("Create", mock.Anything).Return(nil, errors.New("Create error")).Once()
+
+	task := &ScanTask{
+		TxnManager: mockTxn,
+		FilePath:   "scene.mp4",
+	}
+
+	// errors that are not caused by the metadata are not retried
+	_, _, err := task.createScene(models.Scene{Path: "scene.mp4"}, nil)
+	assert.NotNil(t, err)
+
+	mockSceneReader.AssertExpectations(t)
+}
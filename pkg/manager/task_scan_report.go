@@ -0,0 +1,475 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/remeh/sizedwaitgroup"
+
+	"github.com/stashapp/stash/pkg/image"
+	"github.com/stashapp/stash/pkg/job"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/manager/config"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// ScanReportMatch is a scanned file that has the same hash as an existing
+// file.
+type ScanReportMatch struct {
+	Path         string `json:"path"`
+	ExistingPath string `json:"existing_path"`
+}
+
+// ScanReport contains the changes that a scan would make, without making
+// them.
+type ScanReport struct {
+	// New contains the files that would be added.
+	New []string `json:"new"`
+	// Modified contains the existing files with a changed modification time.
+	Modified []string `json:"modified"`
+	// Moved contains the files that match an existing file which no longer
+	// exists. The path of the existing file would be updated.
+	Moved []ScanReportMatch `json:"moved"`
+	// Duplicates contains the files that match an existing file which still
+	// exists, or that have the same name as an existing file when grouping
+	// files by name. The files would be added as additional scene files.
+	Duplicates []ScanReportMatch `json:"duplicates"`
+	// Excluded contains the files with a scanned extension that are excluded
+	// from scanning.
+	Excluded []string `json:"excluded"`
+
+	// DownloadHash is the hash of the report JSON file in the download
+	// store.
+	DownloadHash string `json:"-"`
+
+	mutex sync.Mutex
+}
+
+func (r *ScanReport) addNew(path string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.New = append(r.New, path)
+}
+
+func (r *ScanReport) addModified(path string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Modified = append(r.Modified, path)
+}
+
+func (r *ScanReport) addMatch(path string, existingPath string, exists bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	m := ScanReportMatch{
+		Path:         path,
+		ExistingPath: existingPath,
+	}
+
+	if exists {
+		r.Duplicates = append(r.Duplicates, m)
+	} else {
+		r.Moved = append(r.Moved, m)
+	}
+}
+
+func (r *ScanReport) addExcluded(path string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Excluded = append(r.Excluded, path)
+}
+
+// sort sorts the entries of the report by path, since files are reported
+// in the order they are scanned.
+func (r *ScanReport) sort() {
+	sort.Strings(r.New)
+	sort.Strings(r.Modified)
+	sort.Strings(r.Excluded)
+
+	for _, matches := range [][]ScanReportMatch{r.Moved, r.Duplicates} {
+		sort.Slice(matches, func(i, j int) bool {
+			return matches[i].Path < matches[j].Path
+		})
+	}
+}
+
+// writeDownload writes the report as JSON to the downloads directory, and
+// registers it with the download store.
+func (r *ScanReport) writeDownload() error {
+	hash, err := writeJSONDownload(r, "scan_report*.json")
+	if err != nil {
+		return err
+	}
+
+	r.DownloadHash = hash
+	return nil
+}
+
+// writeJSONDownload writes v as JSON to a new file in the downloads directory
+// with a name matching pattern, and registers it with the download store.
+// Returns the hash of the registered file.
+func writeJSONDownload(v interface{}, pattern string) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	downloads := instance.Paths.Generated.Downloads
+	if err := utils.EnsureDir(downloads); err != nil {
+		return "", err
+	}
+
+	f, err := ioutil.TempFile(downloads, pattern)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return "", err
+	}
+
+	// the file is removed once it has been downloaded, or when the
+	// downloads directory is cleared on startup, since reports of large
+	// libraries may be large
+	return instance.DownloadStore.RegisterFile(f.Name(), "application/json", false), nil
+}
+
+// dryRun walks the scan paths, reporting the changes that the scan would
+// make without making them.
+func (j *ScanJob) dryRun(ctx context.Context, progress *job.Progress, paths []*models.StashConfig) {
+	logger.Infof("Starting scan dry run")
+
+	report := &ScanReport{}
+	config := config.GetInstance()
+	wg := sizedwaitgroup.New(config.GetParallelTasksWithAutoDetection())
+	filter := newScanFilter()
+
+	stoppingErr := errors.New("stopping")
+
+	for _, sp := range paths {
+		csFs, er := utils.IsFsPathCaseSensitive(sp.Path)
+		if er != nil {
+			logger.Warnf("Cannot determine fs case sensitivity: %s", er.Error())
+		}
+
+		err := walkFilesToReport(sp, filter, func(path string, excluded bool) error {
+			if job.IsCancelled(ctx) {
+				return stoppingErr
+			}
+
+			if excluded {
+				report.addExcluded(path)
+				return nil
+			}
+
+			task := ScanTask{
+				TxnManager:          j.txnManager,
+				FilePath:            path,
+				fileNamingAlgorithm: config.GetVideoFileNamingAlgorithm(),
+				calculateMD5:        config.IsCalculateMD5(),
+				GroupFilesByName:    utils.IsTrue(j.input.GroupFilesByName),
+				CaseSensitiveFs:     csFs,
+				ctx:                 ctx,
+			}
+
+			wg.Add()
+			go progress.ExecuteTask("Checking "+path, func() {
+				defer wg.Done()
+				if err := task.report(report); err != nil {
+					logger.Errorf("error checking %s: %s", path, err.Error())
+				}
+			})
+
+			return nil
+		})
+
+		if err == stoppingErr {
+			logger.Info("Stopping due to user request")
+			wg.Wait()
+			return
+		}
+
+		if err != nil {
+			logger.Errorf("Error encountered checking files: %s", err.Error())
+			break
+		}
+	}
+
+	wg.Wait()
+
+	report.sort()
+	if err := report.writeDownload(); err != nil {
+		logger.Errorf("error writing scan report: %s", err.Error())
+	}
+
+	logger.Infof("Scan dry run finished: %d new, %d modified, %d moved, %d duplicate and %d excluded files", len(report.New), len(report.Modified), len(report.Moved), len(report.Duplicates), len(report.Excluded))
+
+	j.report = report
+}
+
+// walkFilesToReport walks the files in the stash path with a scanned
+// extension, including those that are excluded from scanning. Files in the
+// generated path are not included.
+func walkFilesToReport(s *models.StashConfig, filter *scanFilter, f func(path string, excluded bool) error) error {
+	// directories that the scan would skip
+	var skippedDirs []string
+	inSkippedDir := func(path string) bool {
+		for _, dir := range skippedDirs {
+			if utils.IsPathInDir(dir, path) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return utils.SymWalk(s.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			logger.Warnf("error scanning %s: %s", path, err.Error())
+			return nil
+		}
+
+		if info.IsDir() {
+			if filter.generatedPath != "" && utils.IsPathInDir(filter.generatedPath, path) {
+				return filepath.SkipDir
+			}
+
+			if !inSkippedDir(path) && filter.skipDir(s, path) {
+				skippedDirs = append(skippedDirs, path)
+			}
+
+			return nil
+		}
+
+		if !matchExtension(path, filter.vidExt) && !matchExtension(path, filter.imgExt) && !matchExtension(path, filter.clipExt) && !matchExtension(path, filter.gExt) {
+			return nil
+		}
+
+		excluded := inSkippedDir(path) || !filter.matchFile(s, path)
+		return f(path, excluded)
+	})
+}
+
+// report adds the change that the scan would make to the file to the report.
+func (t *ScanTask) report(r *ScanReport) error {
+	switch {
+	case isGallery(t.FilePath):
+		return t.reportGallery(r)
+	case isImageClip(t.FilePath):
+		return t.reportImage(r)
+	case isVideo(t.FilePath):
+		return t.reportScene(r)
+	case isImage(t.FilePath):
+		return t.reportImage(r)
+	}
+
+	return nil
+}
+
+func (t *ScanTask) reportScene(r *ScanReport) error {
+	fileModTime, err := t.getFileModTime()
+	if err != nil {
+		return err
+	}
+
+	var modTime *models.NullSQLiteTimestamp
+	if err := t.TxnManager.WithReadTxn(context.TODO(), func(repo models.ReaderRepository) error {
+		qb := repo.Scene()
+		s, err := qb.FindByPath(t.FilePath)
+		if err != nil {
+			return err
+		}
+		if s != nil {
+			modTime = &s.FileModTime
+			return nil
+		}
+
+		f, err := qb.FindFileByPath(t.FilePath)
+		if err != nil {
+			return err
+		}
+		if f != nil {
+			modTime = &f.FileModTime
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if modTime != nil {
+		if modTime.Valid && t.isFileModified(fileModTime, *modTime) {
+			r.addModified(t.FilePath)
+		}
+		return nil
+	}
+
+	oshash, err := utils.OSHashFromFilePath(t.FilePath)
+	if err != nil {
+		return err
+	}
+
+	existingPath, err := t.findExistingScenePath(oshash, "")
+	if err != nil {
+		return err
+	}
+
+	// only calculate the checksum if the scan would
+	if existingPath == "" && (t.fileNamingAlgorithm == models.HashAlgorithmMd5 || t.calculateMD5) {
+		checksum, err := t.calculateChecksum()
+		if err != nil {
+			return err
+		}
+
+		existingPath, err = t.findExistingScenePath("", checksum)
+		if err != nil {
+			return err
+		}
+	}
+
+	if existingPath == "" {
+		// the file would be added to the scene of a file with the same name
+		if g := t.findSceneByFileGroup(); g != nil {
+			r.addMatch(t.FilePath, g.Path, true)
+			return nil
+		}
+
+		r.addNew(t.FilePath)
+	} else {
+		r.addMatch(t.FilePath, existingPath, t.existsAtOtherPath(existingPath))
+	}
+
+	return nil
+}
+
+// findExistingScenePath returns the path of the scene or additional scene
+// file with the provided oshash or checksum. Returns an empty string if none
+// is found.
+func (t *ScanTask) findExistingScenePath(oshash string, checksum string) (string, error) {
+	var ret string
+	err := t.TxnManager.WithReadTxn(context.TODO(), func(repo models.ReaderRepository) error {
+		qb := repo.Scene()
+
+		var s *models.Scene
+		var f *models.SceneFile
+		var err error
+		if oshash != "" {
+			s, err = qb.FindByOSHash(oshash)
+			if err == nil && s == nil {
+				f, err = qb.FindFileByOSHash(oshash)
+			}
+		} else {
+			s, err = qb.FindByChecksum(checksum)
+			if err == nil && s == nil {
+				f, err = qb.FindFileByChecksum(checksum)
+			}
+		}
+
+		if s != nil {
+			ret = s.Path
+		} else if f != nil {
+			ret = f.Path
+		}
+
+		return err
+	})
+
+	return ret, err
+}
+
+func (t *ScanTask) reportImage(r *ScanReport) error {
+	fileModTime, err := image.GetFileModTime(t.FilePath)
+	if err != nil {
+		return err
+	}
+
+	var i *models.Image
+	if err := t.TxnManager.WithReadTxn(context.TODO(), func(repo models.ReaderRepository) error {
+		var err error
+		i, err = repo.Image().FindByPath(t.FilePath)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if i != nil {
+		if i.FileModTime.Valid && t.isFileModified(fileModTime, i.FileModTime) {
+			r.addModified(t.FilePath)
+		}
+		return nil
+	}
+
+	checksum, err := t.calculateImageChecksum()
+	if err != nil {
+		return err
+	}
+
+	if err := t.TxnManager.WithReadTxn(context.TODO(), func(repo models.ReaderRepository) error {
+		var err error
+		i, err = repo.Image().FindByChecksum(checksum)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if i == nil {
+		r.addNew(t.FilePath)
+	} else {
+		exists := image.FileExists(i.Path) && (t.CaseSensitiveFs || !strings.EqualFold(t.FilePath, i.Path))
+		r.addMatch(t.FilePath, i.Path, exists)
+	}
+
+	return nil
+}
+
+func (t *ScanTask) reportGallery(r *ScanReport) error {
+	fileModTime, err := t.getFileModTime()
+	if err != nil {
+		return err
+	}
+
+	var g *models.Gallery
+	if err := t.TxnManager.WithReadTxn(context.TODO(), func(repo models.ReaderRepository) error {
+		var err error
+		g, err = repo.Gallery().FindByPath(t.FilePath)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if g != nil {
+		if g.FileModTime.Valid && t.isFileModified(fileModTime, g.FileModTime) {
+			r.addModified(t.FilePath)
+		}
+		return nil
+	}
+
+	checksum, err := t.calculateChecksum()
+	if err != nil {
+		return err
+	}
+
+	if err := t.TxnManager.WithReadTxn(context.TODO(), func(repo models.ReaderRepository) error {
+		var err error
+		g, err = repo.Gallery().FindByChecksum(checksum)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if g == nil {
+		r.addNew(t.FilePath)
+	} else {
+		r.addMatch(t.FilePath, g.Path.String, t.existsAtOtherPath(g.Path.String))
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package manager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func TestWalkFilesToReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-scan-report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := []string{
+		"scene.mp4",
+		"image.jpg",
+		"ignored.txt",
+		"scene.excluded.mp4",
+		filepath.Join("skipped", "scene.mp4"),
+		filepath.Join("skipped", "sub", "image.jpg"),
+		filepath.Join("generated", "scene.mp4"),
+	}
+
+	for _, f := range files {
+		p := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filter := &scanFilter{
+		vidExt:          []string{"mp4"},
+		imgExt:          []string{"jpg"},
+		excludeVidRegex: generateRegexps([]string{`\.excluded\.`, "/skipped/$"}),
+		excludeImgRegex: generateRegexps([]string{"/skipped/$"}),
+		generatedPath:   filepath.Join(dir, "generated"),
+	}
+
+	var scanned []string
+	var excluded []string
+	err = walkFilesToReport(&models.StashConfig{Path: dir}, filter, func(path string, isExcluded bool) error {
+		rel, _ := filepath.Rel(dir, path)
+		if isExcluded {
+			excluded = append(excluded, rel)
+		} else {
+			scanned = append(scanned, rel)
+		}
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"scene.mp4", "image.jpg"}, scanned)
+	assert.ElementsMatch(t, []string{
+		"scene.excluded.mp4",
+		filepath.Join("skipped", "scene.mp4"),
+		filepath.Join("skipped", "sub", "image.jpg"),
+	}, excluded)
+}
+
+func TestScanReportSort(t *testing.T) {
+	r := &ScanReport{
+		New: []string{"b", "a"},
+		Moved: []ScanReportMatch{
+			{Path: "d", ExistingPath: "c"},
+			{Path: "b", ExistingPath: "a"},
+		},
+	}
+
+	r.sort()
+
+	assert.Equal(t, []string{"a", "b"}, r.New)
+	assert.Equal(t, "b", r.Moved[0].Path)
+	assert.Equal(t, "d", r.Moved[1].Path)
+}
@@ -0,0 +1,402 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// changeDebouncer collects changed paths, and calls flush with the collected
+// paths once no paths have been added for the delay.
+type changeDebouncer struct {
+	mutex   sync.Mutex
+	delay   time.Duration
+	flush   func(paths []string)
+	pending map[string]struct{}
+	timer   *time.Timer
+	stopped bool
+
+	// incremented whenever the timer is reset, so that a timer that fired
+	// while a path was being added does not flush early
+	generation int
+}
+
+func newChangeDebouncer(delay time.Duration, flush func(paths []string)) *changeDebouncer {
+	return &changeDebouncer{
+		delay:   delay,
+		flush:   flush,
+		pending: make(map[string]struct{}),
+	}
+}
+
+func (d *changeDebouncer) add(path string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.stopped {
+		return
+	}
+
+	d.pending[path] = struct{}{}
+
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+
+	d.generation++
+	generation := d.generation
+	d.timer = time.AfterFunc(d.delay, func() {
+		d.fire(generation)
+	})
+}
+
+func (d *changeDebouncer) fire(generation int) {
+	d.mutex.Lock()
+	if d.stopped || generation != d.generation || len(d.pending) == 0 {
+		d.mutex.Unlock()
+		return
+	}
+
+	paths := make([]string, 0, len(d.pending))
+	for p := range d.pending {
+		paths = append(paths, p)
+	}
+	d.pending = make(map[string]struct{})
+	d.mutex.Unlock()
+
+	sort.Strings(paths)
+	d.flush(paths)
+}
+
+// stop discards the pending paths. Paths added after stop is called are
+// ignored.
+func (d *changeDebouncer) stop() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.stopped = true
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.pending = make(map[string]struct{})
+}
+
+// pollEntry is the state of a file when the stash paths were last polled.
+type pollEntry struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher watches the stash paths for changes to the files that are scanned.
+// Changes are detected using file system notifications, falling back to
+// polling if these are unavailable. Once no changes have been made for the
+// debounce delay, the changed paths are passed to the change handler. This
+// includes paths that have been created, modified, moved or deleted.
+type Watcher struct {
+	stashPaths   []*models.StashConfig
+	filter       *scanFilter
+	usePolling   bool
+	pollInterval time.Duration
+	debouncer    *changeDebouncer
+
+	// directories watched using file system notifications
+	dirsMutex sync.Mutex
+	dirs      map[string]struct{}
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWatcher returns a new Watcher for the stash paths. onChange is called
+// with the paths that have changed. Start must be called to begin watching.
+func NewWatcher(stashPaths []*models.StashConfig, filter *scanFilter, usePolling bool, pollInterval time.Duration, debounceDelay time.Duration, onChange func(paths []string)) *Watcher {
+	return &Watcher{
+		stashPaths:   stashPaths,
+		filter:       filter,
+		usePolling:   usePolling,
+		pollInterval: pollInterval,
+		debouncer:    newChangeDebouncer(debounceDelay, onChange),
+		dirs:         make(map[string]struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+// Start begins watching the stash paths. It returns once the initial
+// watches have been set up, which may take some time for large libraries.
+func (w *Watcher) Start() {
+	if !w.usePolling {
+		fsWatcher, err := w.startNotify()
+		if err == nil {
+			logger.Infof("[watcher] watching %d directories for changes", len(w.dirs))
+			go w.notifyLoop(fsWatcher)
+			return
+		}
+
+		logger.Warnf("[watcher] unable to use file system notifications, falling back to polling: %s", err.Error())
+	}
+
+	files := w.pollFiles(nil)
+	logger.Infof("[watcher] polling %d files for changes every %s", len(files), w.pollInterval)
+	go w.pollLoop(files)
+}
+
+// Stop stops watching the stash paths. Changes that have not yet been passed
+// to the change handler are discarded.
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+		w.debouncer.stop()
+	})
+}
+
+func (w *Watcher) getStash(path string) *models.StashConfig {
+	for _, s := range w.stashPaths {
+		if utils.IsPathInDir(s.Path, path) {
+			return s
+		}
+	}
+
+	return nil
+}
+
+func (w *Watcher) startNotify() (*fsnotify.Watcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range w.stashPaths {
+		if err := w.addDirs(fsWatcher, s, s.Path); err != nil {
+			fsWatcher.Close()
+			return nil, err
+		}
+	}
+
+	return fsWatcher, nil
+}
+
+// addDirs adds watches for the directory and its subdirectories.
+func (w *Watcher) addDirs(fsWatcher *fsnotify.Watcher, s *models.StashConfig, dir string) error {
+	return utils.SymWalk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			logger.Warnf("[watcher] error walking %s: %s", path, err.Error())
+			return nil
+		}
+
+		if !info.IsDir() {
+			return nil
+		}
+
+		if w.filter.skipDir(s, path) {
+			return filepath.SkipDir
+		}
+
+		if err := fsWatcher.Add(path); err != nil {
+			return err
+		}
+
+		w.dirsMutex.Lock()
+		w.dirs[path] = struct{}{}
+		w.dirsMutex.Unlock()
+
+		return nil
+	})
+}
+
+// removeDirs forgets the watched directory and its subdirectories. The
+// watches themselves are removed when the directories are deleted.
+func (w *Watcher) removeDirs(dir string) bool {
+	w.dirsMutex.Lock()
+	defer w.dirsMutex.Unlock()
+
+	found := false
+	for d := range w.dirs {
+		if utils.IsPathInDir(dir, d) {
+			delete(w.dirs, d)
+			found = true
+		}
+	}
+
+	return found
+}
+
+func (w *Watcher) notifyLoop(fsWatcher *fsnotify.Watcher) {
+	defer fsWatcher.Close()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return
+			}
+			w.handleEvent(fsWatcher, event)
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return
+			}
+			logger.Warnf("[watcher] %s", err.Error())
+		}
+	}
+}
+
+func (w *Watcher) handleEvent(fsWatcher *fsnotify.Watcher, event fsnotify.Event) {
+	path := event.Name
+	s := w.getStash(path)
+	if s == nil {
+		return
+	}
+
+	if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		// the path no longer exists, so a directory can only be identified
+		// by whether it was being watched
+		isDir := w.removeDirs(path)
+		if isDir || w.filter.matchFile(s, path) {
+			w.debouncer.add(path)
+		}
+		return
+	}
+
+	if event.Op&(fsnotify.Create|fsnotify.Write) == 0 {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		// removed since the event was raised
+		return
+	}
+
+	if info.IsDir() {
+		if event.Op&fsnotify.Create == 0 || w.filter.skipDir(s, path) {
+			return
+		}
+
+		// watch the new directory, and scan any files that were added to it
+		// before it was watched
+		if err := w.addDirs(fsWatcher, s, path); err != nil {
+			logger.Warnf("[watcher] unable to watch %s: %s", path, err.Error())
+		}
+		w.debouncer.add(path)
+		return
+	}
+
+	if w.filter.matchFile(s, path) {
+		w.debouncer.add(path)
+	}
+}
+
+// pollFiles returns the state of the files to scan in the stash paths. If a
+// stash path cannot be found, then the entries for the stash path are copied
+// from previous, so that the files are not treated as deleted while the
+// path is unavailable.
+func (w *Watcher) pollFiles(previous map[string]pollEntry) map[string]pollEntry {
+	ret := make(map[string]pollEntry)
+
+	for _, s := range w.stashPaths {
+		if exists, _ := utils.DirExists(s.Path); !exists {
+			for p, e := range previous {
+				if w.getStash(p) == s {
+					ret[p] = e
+				}
+			}
+			continue
+		}
+
+		_ = utils.SymWalk(s.Path, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+
+			if info.IsDir() {
+				if w.filter.skipDir(s, path) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if w.filter.matchFile(s, path) {
+				ret[path] = pollEntry{
+					modTime: info.ModTime(),
+					size:    info.Size(),
+				}
+			}
+
+			return nil
+		})
+	}
+
+	return ret
+}
+
+// diffPollFiles returns the paths of the files that have been added,
+// modified or removed between the polls.
+func diffPollFiles(previous map[string]pollEntry, current map[string]pollEntry) []string {
+	var ret []string
+	for p, e := range current {
+		if old, found := previous[p]; !found || !old.modTime.Equal(e.modTime) || old.size != e.size {
+			ret = append(ret, p)
+		}
+	}
+
+	for p := range previous {
+		if _, found := current[p]; !found {
+			ret = append(ret, p)
+		}
+	}
+
+	sort.Strings(ret)
+	return ret
+}
+
+func (w *Watcher) pollLoop(files map[string]pollEntry) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			current := w.pollFiles(files)
+			for _, p := range diffPollFiles(files, current) {
+				w.debouncer.add(p)
+			}
+			files = current
+		}
+	}
+}
+
+// filterNestedPaths removes the paths that are within another of the
+// provided directory paths.
+func filterNestedPaths(paths []string) []string {
+	sorted := make([]string, len(paths))
+	copy(sorted, paths)
+	sort.Strings(sorted)
+
+	var ret []string
+	for _, p := range sorted {
+		nested := false
+		for _, dir := range ret {
+			if utils.IsPathInDir(dir, p) {
+				nested = true
+				break
+			}
+		}
+
+		if !nested {
+			ret = append(ret, p)
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,111 @@
+package manager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func TestChangeDebouncer(t *testing.T) {
+	flushed := make(chan []string, 1)
+	d := newChangeDebouncer(50*time.Millisecond, func(paths []string) {
+		flushed <- paths
+	})
+
+	d.add("b")
+	d.add("a")
+	d.add("b")
+
+	select {
+	case paths := <-flushed:
+		assert.Equal(t, []string{"a", "b"}, paths)
+	case <-time.After(time.Second):
+		t.Fatal("paths not flushed")
+	}
+
+	// paths added after stop are ignored
+	d.add("c")
+	d.stop()
+	d.add("d")
+
+	select {
+	case paths := <-flushed:
+		t.Errorf("unexpected flush: %v", paths)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWatcherPollFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-watcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name string) string {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	existing := writeFile("existing.mp4")
+	removed := writeFile("removed.mp4")
+	writeFile("ignored.txt")
+	writeFile(filepath.Join("excluded", "excluded.mp4"))
+
+	filter := &scanFilter{
+		vidExt:          []string{"mp4"},
+		excludeVidRegex: generateRegexps([]string{"/excluded/"}),
+	}
+	stash := &models.StashConfig{
+		Path:         dir,
+		ExcludeImage: true,
+	}
+
+	w := NewWatcher([]*models.StashConfig{stash}, filter, true, time.Minute, time.Minute, nil)
+
+	previous := w.pollFiles(nil)
+	assert.Len(t, previous, 2)
+	assert.Contains(t, previous, existing)
+	assert.Contains(t, previous, removed)
+
+	added := writeFile(filepath.Join("sub", "added.mp4"))
+	if err := os.Remove(removed); err != nil {
+		t.Fatal(err)
+	}
+
+	current := w.pollFiles(previous)
+	assert.Equal(t, []string{removed, added}, diffPollFiles(previous, current))
+
+	// files are not removed while the stash path is unavailable
+	moved := dir + "-moved"
+	if err := os.Rename(dir, moved); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Rename(moved, dir)
+
+	assert.Equal(t, current, w.pollFiles(current))
+}
+
+func TestFilterNestedPaths(t *testing.T) {
+	sep := string(filepath.Separator)
+	dir := sep + "stash"
+	sub := dir + sep + "sub"
+	file := sub + sep + "file.mp4"
+	other := dir + sep + "sub2" + sep + "file.mp4"
+
+	assert.Equal(t, []string{dir}, filterNestedPaths([]string{file, dir, sub}))
+	assert.Equal(t, []string{sub, other}, filterNestedPaths([]string{other, file, sub}))
+	assert.Len(t, filterNestedPaths(nil), 0)
+}
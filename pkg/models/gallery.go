@@ -8,6 +8,7 @@ type GalleryReader interface {
 	FindByPath(path string) (*Gallery, error)
 	FindBySceneID(sceneID int) ([]*Gallery, error)
 	FindByImageID(imageID int) ([]*Gallery, error)
+	FindChildren(id int) ([]*Gallery, error)
 	Count() (int, error)
 	All() ([]*Gallery, error)
 	Query(galleryFilter *GalleryFilterType, findFilter *FindFilterType) ([]*Gallery, int, error)
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type ImageReader interface {
 	Find(id int) (*Image, error)
 	FindMany(ids []int) ([]*Image, error)
@@ -7,6 +9,7 @@ type ImageReader interface {
 	FindByGalleryID(galleryID int) ([]*Image, error)
 	CountByGalleryID(galleryID int) (int, error)
 	FindByPath(path string) (*Image, error)
+	FindDuplicates(distance int) ([][]*Image, error)
 	// FindByPerformerID(performerID int) ([]*Image, error)
 	// CountByPerformerID(performerID int) (int, error)
 	// FindByStudioID(studioID int) ([]*Image, error)
@@ -21,6 +24,8 @@ type ImageReader interface {
 	GetGalleryIDs(imageID int) ([]int, error)
 	GetTagIDs(imageID int) ([]int, error)
 	GetPerformerIDs(imageID int) ([]int, error)
+	GetODates(imageID int) ([]time.Time, error)
+	GetKeywords(imageID int) ([]string, error)
 }
 
 type ImageWriter interface {
@@ -34,6 +39,8 @@ type ImageWriter interface {
 	UpdateGalleries(imageID int, galleryIDs []int) error
 	UpdatePerformers(imageID int, performerIDs []int) error
 	UpdateTags(imageID int, tagIDs []int) error
+	UpdateODates(imageID int, dates []time.Time) error
+	UpdateKeywords(imageID int, keywords []string) error
 }
 
 type ImageReaderWriter interface {
@@ -231,6 +231,29 @@ func (_m *GalleryReaderWriter) FindBySceneID(sceneID int) ([]*models.Gallery, er
 	return r0, r1
 }
 
+// FindChildren provides a mock function with given fields: id
+func (_m *GalleryReaderWriter) FindChildren(id int) ([]*models.Gallery, error) {
+	ret := _m.Called(id)
+
+	var r0 []*models.Gallery
+	if rf, ok := ret.Get(0).(func(int) []*models.Gallery); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Gallery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindMany provides a mock function with given fields: ids
 func (_m *GalleryReaderWriter) FindMany(ids []int) ([]*models.Gallery, error) {
 	ret := _m.Called(ids)
@@ -5,6 +5,8 @@ package mocks
 import (
 	models "github.com/stashapp/stash/pkg/models"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // ImageReaderWriter is an autogenerated mock type for the ImageReaderWriter type
@@ -227,6 +229,29 @@ func (_m *ImageReaderWriter) FindByPath(path string) (*models.Image, error) {
 	return r0, r1
 }
 
+// FindDuplicates provides a mock function with given fields: distance
+func (_m *ImageReaderWriter) FindDuplicates(distance int) ([][]*models.Image, error) {
+	ret := _m.Called(distance)
+
+	var r0 [][]*models.Image
+	if rf, ok := ret.Get(0).(func(int) [][]*models.Image); ok {
+		r0 = rf(distance)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([][]*models.Image)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(distance)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindMany provides a mock function with given fields: ids
 func (_m *ImageReaderWriter) FindMany(ids []int) ([]*models.Image, error) {
 	ret := _m.Called(ids)
@@ -273,6 +298,52 @@ func (_m *ImageReaderWriter) GetGalleryIDs(imageID int) ([]int, error) {
 	return r0, r1
 }
 
+// GetKeywords provides a mock function with given fields: imageID
+func (_m *ImageReaderWriter) GetKeywords(imageID int) ([]string, error) {
+	ret := _m.Called(imageID)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(int) []string); ok {
+		r0 = rf(imageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(imageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetODates provides a mock function with given fields: imageID
+func (_m *ImageReaderWriter) GetODates(imageID int) ([]time.Time, error) {
+	ret := _m.Called(imageID)
+
+	var r0 []time.Time
+	if rf, ok := ret.Get(0).(func(int) []time.Time); ok {
+		r0 = rf(imageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]time.Time)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(imageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPerformerIDs provides a mock function with given fields: imageID
 func (_m *ImageReaderWriter) GetPerformerIDs(imageID int) ([]int, error) {
 	ret := _m.Called(imageID)
@@ -493,6 +564,34 @@ func (_m *ImageReaderWriter) UpdateGalleries(imageID int, galleryIDs []int) erro
 	return r0
 }
 
+// UpdateKeywords provides a mock function with given fields: imageID, keywords
+func (_m *ImageReaderWriter) UpdateKeywords(imageID int, keywords []string) error {
+	ret := _m.Called(imageID, keywords)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, []string) error); ok {
+		r0 = rf(imageID, keywords)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateODates provides a mock function with given fields: imageID, dates
+func (_m *ImageReaderWriter) UpdateODates(imageID int, dates []time.Time) error {
+	ret := _m.Called(imageID, dates)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, []time.Time) error); ok {
+		r0 = rf(imageID, dates)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdatePerformers provides a mock function with given fields: imageID, performerIDs
 func (_m *ImageReaderWriter) UpdatePerformers(imageID int, performerIDs []int) error {
 	ret := _m.Called(imageID, performerIDs)
@@ -5,6 +5,8 @@ package mocks
 import (
 	models "github.com/stashapp/stash/pkg/models"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // SceneReaderWriter is an autogenerated mock type for the SceneReaderWriter type
@@ -205,6 +207,29 @@ func (_m *SceneReaderWriter) Create(newScene models.Scene) (*models.Scene, error
 	return r0, r1
 }
 
+// CreateFile provides a mock function with given fields: newFile
+func (_m *SceneReaderWriter) CreateFile(newFile models.SceneFile) (*models.SceneFile, error) {
+	ret := _m.Called(newFile)
+
+	var r0 *models.SceneFile
+	if rf, ok := ret.Get(0).(func(models.SceneFile) *models.SceneFile); ok {
+		r0 = rf(newFile)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SceneFile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.SceneFile) error); ok {
+		r1 = rf(newFile)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DecrementOCounter provides a mock function with given fields: id
 func (_m *SceneReaderWriter) DecrementOCounter(id int) (int, error) {
 	ret := _m.Called(id)
@@ -254,6 +279,20 @@ func (_m *SceneReaderWriter) DestroyCover(sceneID int) error {
 	return r0
 }
 
+// DestroyFile provides a mock function with given fields: id
+func (_m *SceneReaderWriter) DestroyFile(id int) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Find provides a mock function with given fields: id
 func (_m *SceneReaderWriter) Find(id int) (*models.Scene, error) {
 	ret := _m.Called(id)
@@ -438,6 +477,98 @@ func (_m *SceneReaderWriter) FindDuplicates(distance int) ([][]*models.Scene, er
 	return r0, r1
 }
 
+// FindFile provides a mock function with given fields: id
+func (_m *SceneReaderWriter) FindFile(id int) (*models.SceneFile, error) {
+	ret := _m.Called(id)
+
+	var r0 *models.SceneFile
+	if rf, ok := ret.Get(0).(func(int) *models.SceneFile); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SceneFile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindFileByChecksum provides a mock function with given fields: checksum
+func (_m *SceneReaderWriter) FindFileByChecksum(checksum string) (*models.SceneFile, error) {
+	ret := _m.Called(checksum)
+
+	var r0 *models.SceneFile
+	if rf, ok := ret.Get(0).(func(string) *models.SceneFile); ok {
+		r0 = rf(checksum)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SceneFile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(checksum)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindFileByOSHash provides a mock function with given fields: oshash
+func (_m *SceneReaderWriter) FindFileByOSHash(oshash string) (*models.SceneFile, error) {
+	ret := _m.Called(oshash)
+
+	var r0 *models.SceneFile
+	if rf, ok := ret.Get(0).(func(string) *models.SceneFile); ok {
+		r0 = rf(oshash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SceneFile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(oshash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindFileByPath provides a mock function with given fields: path
+func (_m *SceneReaderWriter) FindFileByPath(path string) (*models.SceneFile, error) {
+	ret := _m.Called(path)
+
+	var r0 *models.SceneFile
+	if rf, ok := ret.Get(0).(func(string) *models.SceneFile); ok {
+		r0 = rf(path)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SceneFile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(path)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindMany provides a mock function with given fields: ids
 func (_m *SceneReaderWriter) FindMany(ids []int) ([]*models.Scene, error) {
 	ret := _m.Called(ids)
@@ -461,6 +592,75 @@ func (_m *SceneReaderWriter) FindMany(ids []int) ([]*models.Scene, error) {
 	return r0, r1
 }
 
+// FindOverlaps provides a mock function with given fields: distance, minOverlap
+func (_m *SceneReaderWriter) FindOverlaps(distance int, minOverlap float64) ([]*models.SceneOverlap, error) {
+	ret := _m.Called(distance, minOverlap)
+
+	var r0 []*models.SceneOverlap
+	if rf, ok := ret.Get(0).(func(int, float64) []*models.SceneOverlap); ok {
+		r0 = rf(distance, minOverlap)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SceneOverlap)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, float64) error); ok {
+		r1 = rf(distance, minOverlap)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAudioTracks provides a mock function with given fields: sceneID
+func (_m *SceneReaderWriter) GetAudioTracks(sceneID int) ([]*models.SceneAudioTrack, error) {
+	ret := _m.Called(sceneID)
+
+	var r0 []*models.SceneAudioTrack
+	if rf, ok := ret.Get(0).(func(int) []*models.SceneAudioTrack); ok {
+		r0 = rf(sceneID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SceneAudioTrack)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(sceneID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCaptions provides a mock function with given fields: sceneID
+func (_m *SceneReaderWriter) GetCaptions(sceneID int) ([]*models.SceneCaption, error) {
+	ret := _m.Called(sceneID)
+
+	var r0 []*models.SceneCaption
+	if rf, ok := ret.Get(0).(func(int) []*models.SceneCaption); ok {
+		r0 = rf(sceneID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SceneCaption)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(sceneID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCover provides a mock function with given fields: sceneID
 func (_m *SceneReaderWriter) GetCover(sceneID int) ([]byte, error) {
 	ret := _m.Called(sceneID)
@@ -484,6 +684,52 @@ func (_m *SceneReaderWriter) GetCover(sceneID int) ([]byte, error) {
 	return r0, r1
 }
 
+// GetCuts provides a mock function with given fields: sceneID
+func (_m *SceneReaderWriter) GetCuts(sceneID int) ([]float64, error) {
+	ret := _m.Called(sceneID)
+
+	var r0 []float64
+	if rf, ok := ret.Get(0).(func(int) []float64); ok {
+		r0 = rf(sceneID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]float64)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(sceneID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFiles provides a mock function with given fields: sceneID
+func (_m *SceneReaderWriter) GetFiles(sceneID int) ([]*models.SceneFile, error) {
+	ret := _m.Called(sceneID)
+
+	var r0 []*models.SceneFile
+	if rf, ok := ret.Get(0).(func(int) []*models.SceneFile); ok {
+		r0 = rf(sceneID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SceneFile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(sceneID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetGalleryIDs provides a mock function with given fields: sceneID
 func (_m *SceneReaderWriter) GetGalleryIDs(sceneID int) ([]int, error) {
 	ret := _m.Called(sceneID)
@@ -530,6 +776,29 @@ func (_m *SceneReaderWriter) GetMovies(sceneID int) ([]models.MoviesScenes, erro
 	return r0, r1
 }
 
+// GetODates provides a mock function with given fields: sceneID
+func (_m *SceneReaderWriter) GetODates(sceneID int) ([]time.Time, error) {
+	ret := _m.Called(sceneID)
+
+	var r0 []time.Time
+	if rf, ok := ret.Get(0).(func(int) []time.Time); ok {
+		r0 = rf(sceneID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]time.Time)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(sceneID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPerformerIDs provides a mock function with given fields: sceneID
 func (_m *SceneReaderWriter) GetPerformerIDs(sceneID int) ([]int, error) {
 	ret := _m.Called(sceneID)
@@ -553,6 +822,52 @@ func (_m *SceneReaderWriter) GetPerformerIDs(sceneID int) ([]int, error) {
 	return r0, r1
 }
 
+// GetPhashFrames provides a mock function with given fields: sceneID
+func (_m *SceneReaderWriter) GetPhashFrames(sceneID int) ([]*models.ScenePhashFrame, error) {
+	ret := _m.Called(sceneID)
+
+	var r0 []*models.ScenePhashFrame
+	if rf, ok := ret.Get(0).(func(int) []*models.ScenePhashFrame); ok {
+		r0 = rf(sceneID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ScenePhashFrame)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(sceneID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPlayDates provides a mock function with given fields: sceneID
+func (_m *SceneReaderWriter) GetPlayDates(sceneID int) ([]time.Time, error) {
+	ret := _m.Called(sceneID)
+
+	var r0 []time.Time
+	if rf, ok := ret.Get(0).(func(int) []time.Time); ok {
+		r0 = rf(sceneID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]time.Time)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(sceneID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetStashIDs provides a mock function with given fields: sceneID
 func (_m *SceneReaderWriter) GetStashIDs(sceneID int) ([]*models.StashID, error) {
 	ret := _m.Called(sceneID)
@@ -620,6 +935,27 @@ func (_m *SceneReaderWriter) IncrementOCounter(id int) (int, error) {
 	return r0, r1
 }
 
+// IncrementPlayCount provides a mock function with given fields: id
+func (_m *SceneReaderWriter) IncrementPlayCount(id int) (int, error) {
+	ret := _m.Called(id)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(int) int); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Query provides a mock function with given fields: sceneFilter, findFilter
 func (_m *SceneReaderWriter) Query(sceneFilter *models.SceneFilterType, findFilter *models.FindFilterType) ([]*models.Scene, int, error) {
 	ret := _m.Called(sceneFilter, findFilter)
@@ -671,6 +1007,34 @@ func (_m *SceneReaderWriter) ResetOCounter(id int) (int, error) {
 	return r0, r1
 }
 
+// SaveActivity provides a mock function with given fields: id, resumeTime, playDuration
+func (_m *SceneReaderWriter) SaveActivity(id int, resumeTime *float64, playDuration *float64) error {
+	ret := _m.Called(id, resumeTime, playDuration)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, *float64, *float64) error); ok {
+		r0 = rf(id, resumeTime, playDuration)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetPrimaryFile provides a mock function with given fields: sceneID, fileID
+func (_m *SceneReaderWriter) SetPrimaryFile(sceneID int, fileID int) error {
+	ret := _m.Called(sceneID, fileID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, int) error); ok {
+		r0 = rf(sceneID, fileID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Size provides a mock function with given fields:
 func (_m *SceneReaderWriter) Size() (float64, error) {
 	ret := _m.Called()
@@ -715,6 +1079,34 @@ func (_m *SceneReaderWriter) Update(updatedScene models.ScenePartial) (*models.S
 	return r0, r1
 }
 
+// UpdateAudioTracks provides a mock function with given fields: sceneID, tracks
+func (_m *SceneReaderWriter) UpdateAudioTracks(sceneID int, tracks []models.SceneAudioTrack) error {
+	ret := _m.Called(sceneID, tracks)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, []models.SceneAudioTrack) error); ok {
+		r0 = rf(sceneID, tracks)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCaptions provides a mock function with given fields: sceneID, captions
+func (_m *SceneReaderWriter) UpdateCaptions(sceneID int, captions []models.SceneCaption) error {
+	ret := _m.Called(sceneID, captions)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, []models.SceneCaption) error); ok {
+		r0 = rf(sceneID, captions)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateCover provides a mock function with given fields: sceneID, cover
 func (_m *SceneReaderWriter) UpdateCover(sceneID int, cover []byte) error {
 	ret := _m.Called(sceneID, cover)
@@ -729,6 +1121,43 @@ func (_m *SceneReaderWriter) UpdateCover(sceneID int, cover []byte) error {
 	return r0
 }
 
+// UpdateCuts provides a mock function with given fields: sceneID, cuts
+func (_m *SceneReaderWriter) UpdateCuts(sceneID int, cuts []float64) error {
+	ret := _m.Called(sceneID, cuts)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, []float64) error); ok {
+		r0 = rf(sceneID, cuts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateFile provides a mock function with given fields: updatedFile
+func (_m *SceneReaderWriter) UpdateFile(updatedFile models.SceneFile) (*models.SceneFile, error) {
+	ret := _m.Called(updatedFile)
+
+	var r0 *models.SceneFile
+	if rf, ok := ret.Get(0).(func(models.SceneFile) *models.SceneFile); ok {
+		r0 = rf(updatedFile)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SceneFile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.SceneFile) error); ok {
+		r1 = rf(updatedFile)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateFileModTime provides a mock function with given fields: id, modTime
 func (_m *SceneReaderWriter) UpdateFileModTime(id int, modTime models.NullSQLiteTimestamp) error {
 	ret := _m.Called(id, modTime)
@@ -794,6 +1223,20 @@ func (_m *SceneReaderWriter) UpdateMovies(sceneID int, movies []models.MoviesSce
 	return r0
 }
 
+// UpdateODates provides a mock function with given fields: sceneID, dates
+func (_m *SceneReaderWriter) UpdateODates(sceneID int, dates []time.Time) error {
+	ret := _m.Called(sceneID, dates)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, []time.Time) error); ok {
+		r0 = rf(sceneID, dates)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdatePerformers provides a mock function with given fields: sceneID, performerIDs
 func (_m *SceneReaderWriter) UpdatePerformers(sceneID int, performerIDs []int) error {
 	ret := _m.Called(sceneID, performerIDs)
@@ -808,6 +1251,34 @@ func (_m *SceneReaderWriter) UpdatePerformers(sceneID int, performerIDs []int) e
 	return r0
 }
 
+// UpdatePhashFrames provides a mock function with given fields: sceneID, frames
+func (_m *SceneReaderWriter) UpdatePhashFrames(sceneID int, frames []models.ScenePhashFrame) error {
+	ret := _m.Called(sceneID, frames)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, []models.ScenePhashFrame) error); ok {
+		r0 = rf(sceneID, frames)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePlayDates provides a mock function with given fields: sceneID, dates
+func (_m *SceneReaderWriter) UpdatePlayDates(sceneID int, dates []time.Time) error {
+	ret := _m.Called(sceneID, dates)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, []time.Time) error); ok {
+		r0 = rf(sceneID, dates)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateStashIDs provides a mock function with given fields: sceneID, stashIDs
 func (_m *SceneReaderWriter) UpdateStashIDs(sceneID int, stashIDs []models.StashID) error {
 	ret := _m.Called(sceneID, stashIDs)
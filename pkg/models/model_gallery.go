@@ -17,6 +17,7 @@ type Gallery struct {
 	Rating      sql.NullInt64       `db:"rating" json:"rating"`
 	Organized   bool                `db:"organized" json:"organized"`
 	StudioID    sql.NullInt64       `db:"studio_id,omitempty" json:"studio_id"`
+	ParentID    sql.NullInt64       `db:"parent_id,omitempty" json:"parent_id"`
 	FileModTime NullSQLiteTimestamp `db:"file_mod_time" json:"file_mod_time"`
 	CreatedAt   SQLiteTimestamp     `db:"created_at" json:"created_at"`
 	UpdatedAt   SQLiteTimestamp     `db:"updated_at" json:"updated_at"`
@@ -35,6 +36,7 @@ type GalleryPartial struct {
 	Rating      *sql.NullInt64       `db:"rating" json:"rating"`
 	Organized   *bool                `db:"organized" json:"organized"`
 	StudioID    *sql.NullInt64       `db:"studio_id,omitempty" json:"studio_id"`
+	ParentID    *sql.NullInt64       `db:"parent_id,omitempty" json:"parent_id"`
 	FileModTime *NullSQLiteTimestamp `db:"file_mod_time" json:"file_mod_time"`
 	CreatedAt   *SQLiteTimestamp     `db:"created_at" json:"created_at"`
 	UpdatedAt   *SQLiteTimestamp     `db:"updated_at" json:"updated_at"`
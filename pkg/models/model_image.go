@@ -17,10 +17,30 @@ type Image struct {
 	Size        sql.NullInt64       `db:"size" json:"size"`
 	Width       sql.NullInt64       `db:"width" json:"width"`
 	Height      sql.NullInt64       `db:"height" json:"height"`
+	Clip        bool                `db:"clip" json:"clip"`
+	Duration    sql.NullFloat64     `db:"duration" json:"duration"`
+	FrameCount  sql.NullInt64       `db:"frame_count" json:"frame_count"`
 	StudioID    sql.NullInt64       `db:"studio_id,omitempty" json:"studio_id"`
+	Date        SQLiteDate          `db:"date" json:"date"`
+	Phash       sql.NullInt64       `db:"phash,omitempty" json:"phash"`
 	FileModTime NullSQLiteTimestamp `db:"file_mod_time" json:"file_mod_time"`
 	CreatedAt   SQLiteTimestamp     `db:"created_at" json:"created_at"`
 	UpdatedAt   SQLiteTimestamp     `db:"updated_at" json:"updated_at"`
+
+	// metadata embedded in the file
+	CapturedAt     NullSQLiteTimestamp `db:"captured_at" json:"captured_at"`
+	CameraMake     sql.NullString      `db:"camera_make" json:"camera_make"`
+	CameraModel    sql.NullString      `db:"camera_model" json:"camera_model"`
+	Lens           sql.NullString      `db:"lens" json:"lens"`
+	ExposureTime   sql.NullFloat64     `db:"exposure_time" json:"exposure_time"`
+	FNumber        sql.NullFloat64     `db:"f_number" json:"f_number"`
+	ISO            sql.NullInt64       `db:"iso" json:"iso"`
+	FocalLength    sql.NullFloat64     `db:"focal_length" json:"focal_length"`
+	GPSLatitude    sql.NullFloat64     `db:"gps_latitude" json:"gps_latitude"`
+	GPSLongitude   sql.NullFloat64     `db:"gps_longitude" json:"gps_longitude"`
+	Orientation    sql.NullInt64       `db:"orientation" json:"orientation"`
+	Creator        sql.NullString      `db:"creator" json:"creator"`
+	MetadataRating sql.NullInt64       `db:"metadata_rating" json:"metadata_rating"`
 }
 
 // ImagePartial represents part of a Image object. It is used to update
@@ -35,10 +55,29 @@ type ImagePartial struct {
 	Size        *sql.NullInt64       `db:"size" json:"size"`
 	Width       *sql.NullInt64       `db:"width" json:"width"`
 	Height      *sql.NullInt64       `db:"height" json:"height"`
+	Clip        *bool                `db:"clip" json:"clip"`
+	Duration    *sql.NullFloat64     `db:"duration" json:"duration"`
+	FrameCount  *sql.NullInt64       `db:"frame_count" json:"frame_count"`
 	StudioID    *sql.NullInt64       `db:"studio_id,omitempty" json:"studio_id"`
+	Date        *SQLiteDate          `db:"date" json:"date"`
+	Phash       *sql.NullInt64       `db:"phash,omitempty" json:"phash"`
 	FileModTime *NullSQLiteTimestamp `db:"file_mod_time" json:"file_mod_time"`
 	CreatedAt   *SQLiteTimestamp     `db:"created_at" json:"created_at"`
 	UpdatedAt   *SQLiteTimestamp     `db:"updated_at" json:"updated_at"`
+
+	CapturedAt     *NullSQLiteTimestamp `db:"captured_at" json:"captured_at"`
+	CameraMake     *sql.NullString      `db:"camera_make" json:"camera_make"`
+	CameraModel    *sql.NullString      `db:"camera_model" json:"camera_model"`
+	Lens           *sql.NullString      `db:"lens" json:"lens"`
+	ExposureTime   *sql.NullFloat64     `db:"exposure_time" json:"exposure_time"`
+	FNumber        *sql.NullFloat64     `db:"f_number" json:"f_number"`
+	ISO            *sql.NullInt64       `db:"iso" json:"iso"`
+	FocalLength    *sql.NullFloat64     `db:"focal_length" json:"focal_length"`
+	GPSLatitude    *sql.NullFloat64     `db:"gps_latitude" json:"gps_latitude"`
+	GPSLongitude   *sql.NullFloat64     `db:"gps_longitude" json:"gps_longitude"`
+	Orientation    *sql.NullInt64       `db:"orientation" json:"orientation"`
+	Creator        *sql.NullString      `db:"creator" json:"creator"`
+	MetadataRating *sql.NullInt64       `db:"metadata_rating" json:"metadata_rating"`
 }
 
 // GetTitle returns the title of the image. If the Title field is empty,
@@ -53,9 +92,11 @@ func (s Image) GetTitle() string {
 
 // ImageFileType represents the file metadata for an image.
 type ImageFileType struct {
-	Size   *int `graphql:"size" json:"size"`
-	Width  *int `graphql:"width" json:"width"`
-	Height *int `graphql:"height" json:"height"`
+	Size       *int     `graphql:"size" json:"size"`
+	Width      *int     `graphql:"width" json:"width"`
+	Height     *int     `graphql:"height" json:"height"`
+	Duration   *float64 `graphql:"duration" json:"duration"`
+	FrameCount *int     `graphql:"frame_count" json:"frame_count"`
 }
 
 type Images []*Image
@@ -7,63 +7,84 @@ import (
 
 // Scene stores the metadata for a single video scene.
 type Scene struct {
-	ID          int                 `db:"id" json:"id"`
-	Checksum    sql.NullString      `db:"checksum" json:"checksum"`
-	OSHash      sql.NullString      `db:"oshash" json:"oshash"`
-	Path        string              `db:"path" json:"path"`
-	Title       sql.NullString      `db:"title" json:"title"`
-	Details     sql.NullString      `db:"details" json:"details"`
-	URL         sql.NullString      `db:"url" json:"url"`
-	Date        SQLiteDate          `db:"date" json:"date"`
-	Rating      sql.NullInt64       `db:"rating" json:"rating"`
-	Organized   bool                `db:"organized" json:"organized"`
-	OCounter    int                 `db:"o_counter" json:"o_counter"`
-	Size        sql.NullString      `db:"size" json:"size"`
-	Duration    sql.NullFloat64     `db:"duration" json:"duration"`
-	VideoCodec  sql.NullString      `db:"video_codec" json:"video_codec"`
-	Format      sql.NullString      `db:"format" json:"format_name"`
-	AudioCodec  sql.NullString      `db:"audio_codec" json:"audio_codec"`
-	Width       sql.NullInt64       `db:"width" json:"width"`
-	Height      sql.NullInt64       `db:"height" json:"height"`
-	Framerate   sql.NullFloat64     `db:"framerate" json:"framerate"`
-	Bitrate     sql.NullInt64       `db:"bitrate" json:"bitrate"`
-	StudioID    sql.NullInt64       `db:"studio_id,omitempty" json:"studio_id"`
-	FileModTime NullSQLiteTimestamp `db:"file_mod_time" json:"file_mod_time"`
-	Phash       sql.NullInt64       `db:"phash,omitempty" json:"phash"`
-	CreatedAt   SQLiteTimestamp     `db:"created_at" json:"created_at"`
-	UpdatedAt   SQLiteTimestamp     `db:"updated_at" json:"updated_at"`
-	Interactive bool                `db:"interactive" json:"interactive"`
+	ID           int                 `db:"id" json:"id"`
+	Checksum     sql.NullString      `db:"checksum" json:"checksum"`
+	OSHash       sql.NullString      `db:"oshash" json:"oshash"`
+	Path         string              `db:"path" json:"path"`
+	Title        sql.NullString      `db:"title" json:"title"`
+	Details      sql.NullString      `db:"details" json:"details"`
+	URL          sql.NullString      `db:"url" json:"url"`
+	Date         SQLiteDate          `db:"date" json:"date"`
+	Rating       sql.NullInt64       `db:"rating" json:"rating"`
+	Organized    bool                `db:"organized" json:"organized"`
+	OCounter     int                 `db:"o_counter" json:"o_counter"`
+	Size         sql.NullString      `db:"size" json:"size"`
+	Duration     sql.NullFloat64     `db:"duration" json:"duration"`
+	VideoCodec   sql.NullString      `db:"video_codec" json:"video_codec"`
+	Format       sql.NullString      `db:"format" json:"format_name"`
+	AudioCodec   sql.NullString      `db:"audio_codec" json:"audio_codec"`
+	Width        sql.NullInt64       `db:"width" json:"width"`
+	Height       sql.NullInt64       `db:"height" json:"height"`
+	Framerate    sql.NullFloat64     `db:"framerate" json:"framerate"`
+	Bitrate      sql.NullInt64       `db:"bitrate" json:"bitrate"`
+	StudioID     sql.NullInt64       `db:"studio_id,omitempty" json:"studio_id"`
+	FileModTime  NullSQLiteTimestamp `db:"file_mod_time" json:"file_mod_time"`
+	Phash        sql.NullInt64       `db:"phash,omitempty" json:"phash"`
+	CreatedAt    SQLiteTimestamp     `db:"created_at" json:"created_at"`
+	UpdatedAt    SQLiteTimestamp     `db:"updated_at" json:"updated_at"`
+	Interactive  bool                `db:"interactive" json:"interactive"`
+	ResumeTime   float64             `db:"resume_time" json:"resume_time"`
+	PlayDuration float64             `db:"play_duration" json:"play_duration"`
+	PlayCount    int                 `db:"play_count" json:"play_count"`
+	LastPlayedAt NullSQLiteTimestamp `db:"last_played_at" json:"last_played_at"`
+	IntroEnd     sql.NullFloat64     `db:"intro_end,omitempty" json:"intro_end"`
+	OutroStart   sql.NullFloat64     `db:"outro_start,omitempty" json:"outro_start"`
+	// CaptionsModTime is the modification time of the folder of the scene
+	// file when the sidecar captions were last read.
+	CaptionsModTime NullSQLiteTimestamp `db:"captions_mod_time" json:"captions_mod_time"`
+	// AudioTracksProbed is true if the audio tracks of the scene file have
+	// been read, so that files without audio streams are not probed again.
+	AudioTracksProbed bool `db:"audio_tracks_probed" json:"audio_tracks_probed"`
+	// ChaptersImported is true if the chapters of the scene file have been
+	// imported as scene markers, so that markers deleted by the user are not
+	// created again.
+	ChaptersImported bool `db:"chapters_imported" json:"chapters_imported"`
 }
 
 // ScenePartial represents part of a Scene object. It is used to update
 // the database entry. Only non-nil fields will be updated.
 type ScenePartial struct {
-	ID          int                  `db:"id" json:"id"`
-	Checksum    *sql.NullString      `db:"checksum" json:"checksum"`
-	OSHash      *sql.NullString      `db:"oshash" json:"oshash"`
-	Path        *string              `db:"path" json:"path"`
-	Title       *sql.NullString      `db:"title" json:"title"`
-	Details     *sql.NullString      `db:"details" json:"details"`
-	URL         *sql.NullString      `db:"url" json:"url"`
-	Date        *SQLiteDate          `db:"date" json:"date"`
-	Rating      *sql.NullInt64       `db:"rating" json:"rating"`
-	Organized   *bool                `db:"organized" json:"organized"`
-	Size        *sql.NullString      `db:"size" json:"size"`
-	Duration    *sql.NullFloat64     `db:"duration" json:"duration"`
-	VideoCodec  *sql.NullString      `db:"video_codec" json:"video_codec"`
-	Format      *sql.NullString      `db:"format" json:"format_name"`
-	AudioCodec  *sql.NullString      `db:"audio_codec" json:"audio_codec"`
-	Width       *sql.NullInt64       `db:"width" json:"width"`
-	Height      *sql.NullInt64       `db:"height" json:"height"`
-	Framerate   *sql.NullFloat64     `db:"framerate" json:"framerate"`
-	Bitrate     *sql.NullInt64       `db:"bitrate" json:"bitrate"`
-	StudioID    *sql.NullInt64       `db:"studio_id,omitempty" json:"studio_id"`
-	MovieID     *sql.NullInt64       `db:"movie_id,omitempty" json:"movie_id"`
-	FileModTime *NullSQLiteTimestamp `db:"file_mod_time" json:"file_mod_time"`
-	Phash       *sql.NullInt64       `db:"phash,omitempty" json:"phash"`
-	CreatedAt   *SQLiteTimestamp     `db:"created_at" json:"created_at"`
-	UpdatedAt   *SQLiteTimestamp     `db:"updated_at" json:"updated_at"`
-	Interactive *bool                `db:"interactive" json:"interactive"`
+	ID                int                  `db:"id" json:"id"`
+	Checksum          *sql.NullString      `db:"checksum" json:"checksum"`
+	OSHash            *sql.NullString      `db:"oshash" json:"oshash"`
+	Path              *string              `db:"path" json:"path"`
+	Title             *sql.NullString      `db:"title" json:"title"`
+	Details           *sql.NullString      `db:"details" json:"details"`
+	URL               *sql.NullString      `db:"url" json:"url"`
+	Date              *SQLiteDate          `db:"date" json:"date"`
+	Rating            *sql.NullInt64       `db:"rating" json:"rating"`
+	Organized         *bool                `db:"organized" json:"organized"`
+	Size              *sql.NullString      `db:"size" json:"size"`
+	Duration          *sql.NullFloat64     `db:"duration" json:"duration"`
+	VideoCodec        *sql.NullString      `db:"video_codec" json:"video_codec"`
+	Format            *sql.NullString      `db:"format" json:"format_name"`
+	AudioCodec        *sql.NullString      `db:"audio_codec" json:"audio_codec"`
+	Width             *sql.NullInt64       `db:"width" json:"width"`
+	Height            *sql.NullInt64       `db:"height" json:"height"`
+	Framerate         *sql.NullFloat64     `db:"framerate" json:"framerate"`
+	Bitrate           *sql.NullInt64       `db:"bitrate" json:"bitrate"`
+	StudioID          *sql.NullInt64       `db:"studio_id,omitempty" json:"studio_id"`
+	MovieID           *sql.NullInt64       `db:"movie_id,omitempty" json:"movie_id"`
+	FileModTime       *NullSQLiteTimestamp `db:"file_mod_time" json:"file_mod_time"`
+	Phash             *sql.NullInt64       `db:"phash,omitempty" json:"phash"`
+	CreatedAt         *SQLiteTimestamp     `db:"created_at" json:"created_at"`
+	UpdatedAt         *SQLiteTimestamp     `db:"updated_at" json:"updated_at"`
+	Interactive       *bool                `db:"interactive" json:"interactive"`
+	IntroEnd          *sql.NullFloat64     `db:"intro_end,omitempty" json:"intro_end"`
+	OutroStart        *sql.NullFloat64     `db:"outro_start,omitempty" json:"outro_start"`
+	CaptionsModTime   *NullSQLiteTimestamp `db:"captions_mod_time" json:"captions_mod_time"`
+	AudioTracksProbed *bool                `db:"audio_tracks_probed" json:"audio_tracks_probed"`
+	ChaptersImported  *bool                `db:"chapters_imported" json:"chapters_imported"`
 }
 
 // GetTitle returns the title of the scene. If the Title field is empty,
@@ -96,16 +117,37 @@ func (s Scene) GetMinResolution() int64 {
 	return s.Height.Int64
 }
 
+// WithFile returns a copy of the scene with the primary file details
+// replaced by those of the provided file.
+func (s Scene) WithFile(f SceneFile) *Scene {
+	s.Path = f.Path
+	s.Checksum = f.Checksum
+	s.OSHash = f.OSHash
+	s.Phash = f.Phash
+	s.Size = f.Size
+	s.Duration = f.Duration
+	s.VideoCodec = f.VideoCodec
+	s.AudioCodec = f.AudioCodec
+	s.Format = f.Format
+	s.Width = f.Width
+	s.Height = f.Height
+	s.Framerate = f.Framerate
+	s.Bitrate = f.Bitrate
+	s.FileModTime = f.FileModTime
+	return &s
+}
+
 // SceneFileType represents the file metadata for a scene.
 type SceneFileType struct {
-	Size       *string  `graphql:"size" json:"size"`
-	Duration   *float64 `graphql:"duration" json:"duration"`
-	VideoCodec *string  `graphql:"video_codec" json:"video_codec"`
-	AudioCodec *string  `graphql:"audio_codec" json:"audio_codec"`
-	Width      *int     `graphql:"width" json:"width"`
-	Height     *int     `graphql:"height" json:"height"`
-	Framerate  *float64 `graphql:"framerate" json:"framerate"`
-	Bitrate    *int     `graphql:"bitrate" json:"bitrate"`
+	Size        *string       `graphql:"size" json:"size"`
+	Duration    *float64      `graphql:"duration" json:"duration"`
+	VideoCodec  *string       `graphql:"video_codec" json:"video_codec"`
+	AudioCodec  *string       `graphql:"audio_codec" json:"audio_codec"`
+	AudioTracks []*AudioTrack `graphql:"audio_tracks" json:"audio_tracks"`
+	Width       *int          `graphql:"width" json:"width"`
+	Height      *int          `graphql:"height" json:"height"`
+	Framerate   *float64      `graphql:"framerate" json:"framerate"`
+	Bitrate     *int          `graphql:"bitrate" json:"bitrate"`
 }
 
 type Scenes []*Scene
@@ -0,0 +1,20 @@
+package models
+
+// SceneAudioTrack stores the details of an audio stream of a scene file.
+type SceneAudioTrack struct {
+	// StreamIndex is the index of the stream in the scene file.
+	StreamIndex  int    `db:"stream_index" json:"stream_index"`
+	LanguageCode string `db:"language_code" json:"language_code"`
+	Codec        string `db:"codec" json:"codec"`
+	Channels     int    `db:"channels" json:"channels"`
+}
+
+type SceneAudioTracks []*SceneAudioTrack
+
+func (t *SceneAudioTracks) Append(o interface{}) {
+	*t = append(*t, o.(*SceneAudioTrack))
+}
+
+func (t *SceneAudioTracks) New() interface{} {
+	return &SceneAudioTrack{}
+}
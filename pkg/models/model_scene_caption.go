@@ -0,0 +1,48 @@
+package models
+
+import (
+	"database/sql"
+	"path/filepath"
+)
+
+// UnknownLanguage is the language code used for captions and audio tracks
+// where the language could not be determined.
+const UnknownLanguage = "und"
+
+// SceneCaption stores the details of a caption track of a scene. Captions are
+// either sidecar files stored next to the scene file, or subtitle streams
+// embedded in the scene file.
+type SceneCaption struct {
+	LanguageCode string `db:"language_code" json:"language_code"`
+	// Filename is the base name of the sidecar file. Empty for embedded
+	// captions.
+	Filename string `db:"filename" json:"filename"`
+	// CaptionType is the extension of the sidecar file, or the codec of the
+	// embedded stream.
+	CaptionType string `db:"caption_type" json:"caption_type"`
+	// StreamIndex is the index of the embedded stream. Null for sidecar
+	// captions.
+	StreamIndex sql.NullInt64 `db:"stream_index" json:"stream_index"`
+}
+
+// IsEmbedded returns true if the caption is a subtitle stream embedded in
+// the scene file.
+func (c SceneCaption) IsEmbedded() bool {
+	return c.StreamIndex.Valid
+}
+
+// Path returns the path of the sidecar caption file, based on the path of
+// the scene file.
+func (c SceneCaption) Path(scenePath string) string {
+	return filepath.Join(filepath.Dir(scenePath), c.Filename)
+}
+
+type SceneCaptions []*SceneCaption
+
+func (c *SceneCaptions) Append(o interface{}) {
+	*c = append(*c, o.(*SceneCaption))
+}
+
+func (c *SceneCaptions) New() interface{} {
+	return &SceneCaption{}
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"database/sql"
+)
+
+// SceneFile stores the details of a video file belonging to a scene, other
+// than the primary file. The primary file details are stored on the Scene.
+type SceneFile struct {
+	ID          int                 `db:"id" json:"id"`
+	SceneID     int                 `db:"scene_id" json:"scene_id"`
+	Path        string              `db:"path" json:"path"`
+	Checksum    sql.NullString      `db:"checksum" json:"checksum"`
+	OSHash      sql.NullString      `db:"oshash" json:"oshash"`
+	Phash       sql.NullInt64       `db:"phash,omitempty" json:"phash"`
+	Size        sql.NullString      `db:"size" json:"size"`
+	Duration    sql.NullFloat64     `db:"duration" json:"duration"`
+	VideoCodec  sql.NullString      `db:"video_codec" json:"video_codec"`
+	AudioCodec  sql.NullString      `db:"audio_codec" json:"audio_codec"`
+	Format      sql.NullString      `db:"format" json:"format_name"`
+	Width       sql.NullInt64       `db:"width" json:"width"`
+	Height      sql.NullInt64       `db:"height" json:"height"`
+	Framerate   sql.NullFloat64     `db:"framerate" json:"framerate"`
+	Bitrate     sql.NullInt64       `db:"bitrate" json:"bitrate"`
+	FileModTime NullSQLiteTimestamp `db:"file_mod_time" json:"file_mod_time"`
+	CreatedAt   SQLiteTimestamp     `db:"created_at" json:"created_at"`
+	UpdatedAt   SQLiteTimestamp     `db:"updated_at" json:"updated_at"`
+}
+
+// NewSceneFileFromScene returns a SceneFile populated with the primary file
+// details of the provided scene.
+func NewSceneFileFromScene(s Scene) SceneFile {
+	return SceneFile{
+		SceneID:     s.ID,
+		Path:        s.Path,
+		Checksum:    s.Checksum,
+		OSHash:      s.OSHash,
+		Phash:       s.Phash,
+		Size:        s.Size,
+		Duration:    s.Duration,
+		VideoCodec:  s.VideoCodec,
+		AudioCodec:  s.AudioCodec,
+		Format:      s.Format,
+		Width:       s.Width,
+		Height:      s.Height,
+		Framerate:   s.Framerate,
+		Bitrate:     s.Bitrate,
+		FileModTime: s.FileModTime,
+	}
+}
+
+// GetHash returns the hash of the file, based on the hash algorithm provided.
+func (f SceneFile) GetHash(hashAlgorithm HashAlgorithm) string {
+	if hashAlgorithm == HashAlgorithmMd5 {
+		return f.Checksum.String
+	} else if hashAlgorithm == HashAlgorithmOshash {
+		return f.OSHash.String
+	}
+
+	panic("unknown hash algorithm")
+}
+
+// ScenePartial returns a ScenePartial that sets the primary file details of
+// the scene with the provided id to the details of this file.
+func (f SceneFile) ScenePartial(sceneID int) ScenePartial {
+	return ScenePartial{
+		ID:          sceneID,
+		Path:        &f.Path,
+		Checksum:    &f.Checksum,
+		OSHash:      &f.OSHash,
+		Phash:       &f.Phash,
+		Size:        &f.Size,
+		Duration:    &f.Duration,
+		VideoCodec:  &f.VideoCodec,
+		AudioCodec:  &f.AudioCodec,
+		Format:      &f.Format,
+		Width:       &f.Width,
+		Height:      &f.Height,
+		Framerate:   &f.Framerate,
+		Bitrate:     &f.Bitrate,
+		FileModTime: &f.FileModTime,
+	}
+}
+
+type SceneFiles []*SceneFile
+
+func (f *SceneFiles) Append(o interface{}) {
+	*f = append(*f, o.(*SceneFile))
+}
+
+func (f *SceneFiles) New() interface{} {
+	return &SceneFile{}
+}
@@ -8,12 +8,35 @@ type SceneMarker struct {
 	ID           int             `db:"id" json:"id"`
 	Title        string          `db:"title" json:"title"`
 	Seconds      float64         `db:"seconds" json:"seconds"`
+	EndSeconds   sql.NullFloat64 `db:"end_seconds" json:"end_seconds"`
 	PrimaryTagID int             `db:"primary_tag_id" json:"primary_tag_id"`
 	SceneID      sql.NullInt64   `db:"scene_id,omitempty" json:"scene_id"`
 	CreatedAt    SQLiteTimestamp `db:"created_at" json:"created_at"`
 	UpdatedAt    SQLiteTimestamp `db:"updated_at" json:"updated_at"`
 }
 
+// GetDuration returns the duration of the marker in seconds. Returns nil if
+// the marker has no end time.
+func (m SceneMarker) GetDuration() *float64 {
+	if !m.EndSeconds.Valid {
+		return nil
+	}
+
+	ret := m.EndSeconds.Float64 - m.Seconds
+	return &ret
+}
+
+// GetEndSeconds returns the end time of the marker in whole seconds, or 0 if
+// the marker has no end time. The generated files of markers are keyed on
+// their start and end time in whole seconds.
+func (m SceneMarker) GetEndSeconds() int {
+	if !m.EndSeconds.Valid {
+		return 0
+	}
+
+	return int(m.EndSeconds.Float64)
+}
+
 type SceneMarkers []*SceneMarker
 
 func (m *SceneMarkers) Append(o interface{}) {
@@ -0,0 +1,20 @@
+package models
+
+// ScenePhashFrame is the perceptual hash of a frame of the primary file of a
+// scene. The frames of a scene form a sequence, used to find scenes that
+// partially match other scenes.
+type ScenePhashFrame struct {
+	// Seconds is the time of the frame in the file.
+	Seconds float64 `db:"seconds" json:"seconds"`
+	Phash   int64   `db:"phash" json:"phash"`
+}
+
+type ScenePhashFrames []*ScenePhashFrame
+
+func (f *ScenePhashFrames) Append(o interface{}) {
+	*f = append(*f, o.(*ScenePhashFrame))
+}
+
+func (f *ScenePhashFrames) New() interface{} {
+	return &ScenePhashFrame{}
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type SceneReader interface {
 	Find(id int) (*Scene, error)
 	FindMany(ids []int) ([]*Scene, error)
@@ -9,6 +11,7 @@ type SceneReader interface {
 	FindByPerformerID(performerID int) ([]*Scene, error)
 	FindByGalleryID(performerID int) ([]*Scene, error)
 	FindDuplicates(distance int) ([][]*Scene, error)
+	FindOverlaps(distance int, minOverlap float64) ([]*SceneOverlap, error)
 	CountByPerformerID(performerID int) (int, error)
 	// FindByStudioID(studioID int) ([]*Scene, error)
 	FindByMovieID(movieID int) ([]*Scene, error)
@@ -29,6 +32,17 @@ type SceneReader interface {
 	GetGalleryIDs(sceneID int) ([]int, error)
 	GetPerformerIDs(sceneID int) ([]int, error)
 	GetStashIDs(sceneID int) ([]*StashID, error)
+	GetODates(sceneID int) ([]time.Time, error)
+	GetPlayDates(sceneID int) ([]time.Time, error)
+	GetFiles(sceneID int) ([]*SceneFile, error)
+	FindFile(id int) (*SceneFile, error)
+	FindFileByPath(path string) (*SceneFile, error)
+	FindFileByChecksum(checksum string) (*SceneFile, error)
+	FindFileByOSHash(oshash string) (*SceneFile, error)
+	GetCaptions(sceneID int) ([]*SceneCaption, error)
+	GetAudioTracks(sceneID int) ([]*SceneAudioTrack, error)
+	GetCuts(sceneID int) ([]float64, error)
+	GetPhashFrames(sceneID int) ([]*ScenePhashFrame, error)
 }
 
 type SceneWriter interface {
@@ -38,6 +52,8 @@ type SceneWriter interface {
 	IncrementOCounter(id int) (int, error)
 	DecrementOCounter(id int) (int, error)
 	ResetOCounter(id int) (int, error)
+	SaveActivity(id int, resumeTime *float64, playDuration *float64) error
+	IncrementPlayCount(id int) (int, error)
 	UpdateFileModTime(id int, modTime NullSQLiteTimestamp) error
 	Destroy(id int) error
 	UpdateCover(sceneID int, cover []byte) error
@@ -47,6 +63,16 @@ type SceneWriter interface {
 	UpdateGalleries(sceneID int, galleryIDs []int) error
 	UpdateMovies(sceneID int, movies []MoviesScenes) error
 	UpdateStashIDs(sceneID int, stashIDs []StashID) error
+	UpdateODates(sceneID int, dates []time.Time) error
+	UpdatePlayDates(sceneID int, dates []time.Time) error
+	CreateFile(newFile SceneFile) (*SceneFile, error)
+	UpdateFile(updatedFile SceneFile) (*SceneFile, error)
+	DestroyFile(id int) error
+	SetPrimaryFile(sceneID int, fileID int) error
+	UpdateCaptions(sceneID int, captions []SceneCaption) error
+	UpdateAudioTracks(sceneID int, tracks []SceneAudioTrack) error
+	UpdateCuts(sceneID int, cuts []float64) error
+	UpdatePhashFrames(sceneID int, frames []ScenePhashFrame) error
 }
 
 type SceneReaderWriter interface {
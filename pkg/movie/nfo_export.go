@@ -0,0 +1,57 @@
+package movie
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/nfo"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// nfoIDType is the type of the unique ID element holding the movie ID.
+const nfoIDType = "stash-movie"
+
+// ToNFO converts a Movie into its Kodi movie NFO equivalent.
+func ToNFO(studioReader models.StudioReader, movie *models.Movie) (*nfo.Video, error) {
+	ret := nfo.NewMovie()
+	ret.UniqueIDs = []nfo.UniqueID{
+		{
+			Type:    nfoIDType,
+			Default: true,
+			Value:   strconv.Itoa(movie.ID),
+		},
+	}
+
+	if movie.Name.Valid {
+		ret.Title = movie.Name.String
+	}
+	if movie.Synopsis.Valid {
+		ret.Plot = movie.Synopsis.String
+	}
+	if movie.Date.Valid {
+		ret.SetDate(utils.GetYMDFromDatabaseDate(movie.Date.String))
+	}
+	if movie.Rating.Valid {
+		ret.SetRating(int(movie.Rating.Int64))
+	}
+	if movie.Duration.Valid {
+		ret.SetRuntime(float64(movie.Duration.Int64))
+	}
+	if movie.Director.Valid && movie.Director.String != "" {
+		ret.Directors = []string{movie.Director.String}
+	}
+
+	if movie.StudioID.Valid {
+		studio, err := studioReader.Find(int(movie.StudioID.Int64))
+		if err != nil {
+			return nil, fmt.Errorf("error getting movie studio: %s", err.Error())
+		}
+
+		if studio != nil {
+			ret.Studios = []string{studio.Name.String}
+		}
+	}
+
+	return ret, nil
+}
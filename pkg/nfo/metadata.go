@@ -0,0 +1,215 @@
+package nfo
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metadata is the scene metadata read from the NFO files of a video file.
+type Metadata struct {
+	Title   string
+	Details string
+	// Date is in the format YYYY-MM-DD. Empty if not set or invalid.
+	Date string
+	// Rating is between 1 and 5. Zero if not set.
+	Rating     int
+	Studio     string
+	Performers []Actor
+	Tags       []string
+	// Movie is the name of the set of a movie, or the title of the show of
+	// an episode.
+	Movie string
+	// SceneIndex is the episode number of an episode. Zero if not set.
+	SceneIndex int
+}
+
+// Load reads the metadata from the NFO files of the video file. The genres,
+// tags and studio of a tvshow.nfo file in the same or parent directory
+// apply to the video as well. Returns nil if the video file has no NFO
+// file.
+func Load(videoPath string) (*Metadata, error) {
+	video, err := findVideo(videoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var show *TVShow
+	if video == nil || video.IsEpisode() {
+		show, err = findTVShow(videoPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if video == nil && show == nil {
+		return nil, nil
+	}
+
+	ret := &Metadata{}
+	if video != nil {
+		ret = video.toMetadata()
+	}
+
+	if show != nil {
+		ret.addShow(show)
+	}
+
+	return ret, nil
+}
+
+func (v Video) toMetadata() *Metadata {
+	ret := &Metadata{
+		Title:   strings.TrimSpace(v.Title),
+		Details: strings.TrimSpace(v.Plot),
+		Rating:  v.getRating(),
+		Tags:    appendUnique(nil, v.Genres, v.Tags),
+	}
+
+	if ret.Details == "" {
+		ret.Details = strings.TrimSpace(v.Outline)
+	}
+
+	for _, d := range []string{v.Premiered, v.Aired} {
+		if date := parseDate(d); date != "" {
+			ret.Date = date
+			break
+		}
+	}
+
+	if len(v.Studios) > 0 {
+		ret.Studio = strings.TrimSpace(v.Studios[0])
+	}
+
+	for _, a := range v.Actors {
+		a.Name = strings.TrimSpace(a.Name)
+		if a.Name != "" {
+			a.Thumb = strings.TrimSpace(a.Thumb)
+			ret.Performers = append(ret.Performers, a)
+		}
+	}
+
+	if v.IsEpisode() {
+		ret.Movie = strings.TrimSpace(v.ShowTitle)
+		ret.SceneIndex, _ = strconv.Atoi(strings.TrimSpace(v.Episode))
+	} else if v.Set != nil {
+		ret.Movie = v.Set.GetName()
+	}
+
+	return ret
+}
+
+func (m *Metadata) addShow(show *TVShow) {
+	if m.Movie == "" {
+		m.Movie = strings.TrimSpace(show.Title)
+	}
+
+	if m.Studio == "" && len(show.Studios) > 0 {
+		m.Studio = strings.TrimSpace(show.Studios[0])
+	}
+
+	m.Tags = appendUnique(m.Tags, show.Genres, show.Tags)
+}
+
+// getRating returns the rating converted to a 1-5 rating. The user rating is
+// preferred over the default rating of the ratings element, which is
+// preferred over the rating element. All are out of 10 unless a maximum is
+// specified.
+func (v Video) getRating() int {
+	if r := convertRating(v.UserRating, ""); r != 0 {
+		return r
+	}
+
+	for _, r := range v.Ratings {
+		if r.Default || len(v.Ratings) == 1 {
+			if ret := convertRating(r.Value, r.Max); ret != 0 {
+				return ret
+			}
+		}
+	}
+
+	return convertRating(v.Rating, "")
+}
+
+func convertRating(value string, max string) int {
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || v <= 0 {
+		return 0
+	}
+
+	m, err := strconv.ParseFloat(strings.TrimSpace(max), 64)
+	if err != nil || m <= 0 {
+		m = defaultRatingMax
+	}
+
+	ret := int(math.Round(v / m * 5))
+	if ret < 1 {
+		ret = 1
+	}
+	if ret > 5 {
+		ret = 5
+	}
+
+	return ret
+}
+
+func parseDate(s string) string {
+	s = strings.TrimSpace(s)
+	if _, err := time.Parse("2006-01-02", s); err != nil {
+		return ""
+	}
+
+	return s
+}
+
+// appendUnique appends the trimmed, non-empty values that are not already
+// present, ignoring case.
+func appendUnique(dest []string, values ...[]string) []string {
+	for _, vs := range values {
+		for _, v := range vs {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+
+			found := false
+			for _, d := range dest {
+				if strings.EqualFold(d, v) {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				dest = append(dest, v)
+			}
+		}
+	}
+
+	return dest
+}
+
+// SetRating sets the user rating of the document from a rating between 1
+// and 5. A rating of zero is not set.
+func (v *Video) SetRating(rating int) {
+	if rating > 0 {
+		v.UserRating = strconv.Itoa(rating * 2)
+	}
+}
+
+// SetDate sets the premiered date and year of the document from a date in
+// the format YYYY-MM-DD. Invalid dates are not set.
+func (v *Video) SetDate(date string) {
+	if date = parseDate(date); date != "" {
+		v.Premiered = date
+		v.Year = date[:4]
+	}
+}
+
+// SetRuntime sets the runtime of the document from a duration in seconds.
+func (v *Video) SetRuntime(seconds float64) {
+	if minutes := int(math.Round(seconds / 60)); minutes > 0 {
+		v.Runtime = strconv.Itoa(minutes)
+	}
+}
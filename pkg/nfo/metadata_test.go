@@ -0,0 +1,233 @@
+package nfo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const movieNFO = `<?xml version="1.0" encoding="UTF-8" standalone="yes" ?>
+<movie>
+    <title>Movie Title</title>
+    <outline>Outline</outline>
+    <plot>Plot</plot>
+    <userrating>0</userrating>
+    <ratings>
+        <rating name="imdb" max="10" default="true">
+            <value>7.4</value>
+        </rating>
+        <rating name="themoviedb" max="100">
+            <value>20</value>
+        </rating>
+    </ratings>
+    <genre>Genre</genre>
+    <genre>genre</genre>
+    <tag>Tag</tag>
+    <set>
+        <name>Set Name</name>
+    </set>
+    <premiered>2010-05-01</premiered>
+    <year>2010</year>
+    <studio>Studio</studio>
+    <studio>Other Studio</studio>
+    <actor>
+        <name>Actor</name>
+        <role>Role</role>
+        <thumb>http://example.com/actor.jpg</thumb>
+    </actor>
+    <actor>
+        <name> </name>
+    </actor>
+</movie>
+https://www.themoviedb.org/movie/1
+`
+
+const episodeNFO = `<episodedetails>
+    <title>Episode Title</title>
+    <showtitle>Show Title</showtitle>
+    <episode>3</episode>
+    <rating>10</rating>
+    <aired>2011-02-03</aired>
+    <tag>Tag</tag>
+</episodedetails>`
+
+const tvShowNFO = `<tvshow>
+    <title>Show</title>
+    <studio>Show Studio</studio>
+    <genre>Show Genre</genre>
+    <tag>tag</tag>
+</tvshow>`
+
+func TestParseVideo(t *testing.T) {
+	v, err := ParseVideo(strings.NewReader(movieNFO))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.False(t, v.IsEpisode())
+	assert.Equal(t, &Metadata{
+		Title:   "Movie Title",
+		Details: "Plot",
+		Date:    "2010-05-01",
+		Rating:  4,
+		Studio:  "Studio",
+		Performers: []Actor{
+			{
+				Name:  "Actor",
+				Role:  "Role",
+				Thumb: "http://example.com/actor.jpg",
+			},
+		},
+		Tags:  []string{"Genre", "Tag"},
+		Movie: "Set Name",
+	}, v.toMetadata())
+
+	// older versions of Kodi write the set name as text
+	v, err = ParseVideo(strings.NewReader("<movie><set>Set</set></movie>"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Set", v.toMetadata().Movie)
+
+	_, err = ParseVideo(strings.NewReader("https://www.themoviedb.org/movie/1"))
+	assert.NotNil(t, err)
+
+	_, err = ParseVideo(strings.NewReader(tvShowNFO))
+	assert.NotNil(t, err)
+}
+
+func TestConvertRating(t *testing.T) {
+	tests := []struct {
+		value string
+		max   string
+		want  int
+	}{
+		{"", "", 0},
+		{"invalid", "", 0},
+		{"0", "", 0},
+		{"0.1", "", 1},
+		{"5", "", 3},
+		{"10", "", 5},
+		{"11", "", 5},
+		{"60", "100", 3},
+		{"4", "5", 4},
+		{"4", "invalid", 2},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, convertRating(tt.value, tt.max), "value %q max %q", tt.value, tt.max)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stash-nfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name string, content string) string {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	// no NFO files
+	m, err := Load(filepath.Join(dir, "video.mp4"))
+	assert.Nil(t, err)
+	assert.Nil(t, m)
+
+	// movie.nfo in the movie directory
+	writeFile(filepath.Join("movie", "movie.nfo"), movieNFO)
+	m, err = Load(filepath.Join(dir, "movie", "video.mp4"))
+	assert.Nil(t, err)
+	if assert.NotNil(t, m) {
+		assert.Equal(t, "Movie Title", m.Title)
+	}
+
+	// episode with tvshow.nfo in the parent directory
+	writeFile("tvshow.nfo", tvShowNFO)
+	writeFile(filepath.Join("season 1", "episode.nfo"), episodeNFO)
+	m, err = Load(filepath.Join(dir, "season 1", "episode.mkv"))
+	assert.Nil(t, err)
+	assert.Equal(t, &Metadata{
+		Title:      "Episode Title",
+		Date:       "2011-02-03",
+		Rating:     5,
+		Studio:     "Show Studio",
+		Tags:       []string{"Tag", "Show Genre"},
+		Movie:      "Show Title",
+		SceneIndex: 3,
+	}, m)
+
+	// tvshow.nfo only
+	m, err = Load(filepath.Join(dir, "other.mkv"))
+	assert.Nil(t, err)
+	assert.Equal(t, &Metadata{
+		Studio: "Show Studio",
+		Tags:   []string{"Show Genre", "tag"},
+		Movie:  "Show",
+	}, m)
+
+	// invalid NFO
+	writeFile("invalid.nfo", "<movie><title>")
+	_, err = Load(filepath.Join(dir, "invalid.mp4"))
+	assert.NotNil(t, err)
+}
+
+func TestMarshal(t *testing.T) {
+	order := 0
+	v := NewMovie()
+	v.Title = "Title"
+	v.Plot = "Plot"
+	v.SetDate("2010-05-01")
+	v.SetRating(3)
+	v.SetRuntime(5400)
+	v.Studios = []string{"Studio"}
+	v.Tags = []string{"Tag"}
+	v.Set = &Set{Name: "Set"}
+	v.Actors = []Actor{{Name: "Actor", Order: &order}}
+
+	data, err := Marshal(v)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, "2010", v.Year)
+	assert.Equal(t, "90", v.Runtime)
+	assert.True(t, strings.HasPrefix(string(data), "<?xml"))
+
+	// written documents are read back as the same metadata
+	parsed, err := ParseVideo(strings.NewReader(string(data)))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, &Metadata{
+		Title:      "Title",
+		Details:    "Plot",
+		Date:       "2010-05-01",
+		Rating:     3,
+		Studio:     "Studio",
+		Performers: []Actor{{Name: "Actor", Order: &order}},
+		Tags:       []string{"Tag"},
+		Movie:      "Set",
+	}, parsed.toMetadata())
+}
+
+func TestArtworkPath(t *testing.T) {
+	jpg := []byte{0xff, 0xd8, 0xff, 0xe0}
+	png := []byte("\x89PNG\x0D\x0A\x1A\x0A")
+
+	videoPath := filepath.Join("dir", "video.mp4")
+	assert.Equal(t, filepath.Join("dir", "video-poster.jpg"), ArtworkPath(videoPath, Poster, jpg))
+	assert.Equal(t, filepath.Join("dir", "video-fanart.png"), ArtworkPath(videoPath, Fanart, png))
+	assert.Equal(t, filepath.Join("dir", "poster.jpg"), FolderArtworkPath("dir", Poster, jpg))
+}
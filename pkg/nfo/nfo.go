@@ -0,0 +1,291 @@
+// Package nfo reads and writes the XML sidecar metadata files used by Kodi
+// and Jellyfin.
+package nfo
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	movieRoot        = "movie"
+	episodeRoot      = "episodedetails"
+	tvShowRoot       = "tvshow"
+	movieFilename    = "movie.nfo"
+	tvShowFilename   = "tvshow.nfo"
+	defaultRatingMax = 10
+)
+
+// Artwork kinds, used in the names of artwork files.
+const (
+	Poster = "poster"
+	Fanart = "fanart"
+)
+
+// ErrUnsupported is returned when the NFO file does not contain a supported
+// document. Kodi NFO files may contain only a scraper URL.
+var ErrUnsupported = errors.New("unsupported NFO document")
+
+// Actor is an actor element of a movie, episode or show.
+type Actor struct {
+	Name  string `xml:"name"`
+	Role  string `xml:"role,omitempty"`
+	Order *int   `xml:"order"`
+	Thumb string `xml:"thumb,omitempty"`
+}
+
+// Rating is a rating element of the ratings element.
+type Rating struct {
+	Name    string `xml:"name,attr,omitempty"`
+	Max     string `xml:"max,attr,omitempty"`
+	Default bool   `xml:"default,attr,omitempty"`
+	Value   string `xml:"value"`
+}
+
+// UniqueID is an identifier of the video in an external source.
+type UniqueID struct {
+	Type    string `xml:"type,attr"`
+	Default bool   `xml:"default,attr,omitempty"`
+	Value   string `xml:",chardata"`
+}
+
+// Set is the set element of a movie. Older versions of Kodi write the name
+// of the set as the element text.
+type Set struct {
+	Name string `xml:"name,omitempty"`
+	Text string `xml:",chardata"`
+}
+
+// GetName returns the name of the set.
+func (s Set) GetName() string {
+	if s.Name != "" {
+		return strings.TrimSpace(s.Name)
+	}
+	return strings.TrimSpace(s.Text)
+}
+
+// Thumb is an artwork element.
+type Thumb struct {
+	Aspect string `xml:"aspect,attr,omitempty"`
+	URL    string `xml:",chardata"`
+}
+
+// Video is a movie or episodedetails document.
+type Video struct {
+	XMLName    xml.Name
+	Title      string     `xml:"title"`
+	ShowTitle  string     `xml:"showtitle,omitempty"`
+	Season     string     `xml:"season,omitempty"`
+	Episode    string     `xml:"episode,omitempty"`
+	Rating     string     `xml:"rating,omitempty"`
+	UserRating string     `xml:"userrating,omitempty"`
+	Ratings    []Rating   `xml:"ratings>rating"`
+	Outline    string     `xml:"outline,omitempty"`
+	Plot       string     `xml:"plot,omitempty"`
+	Runtime    string     `xml:"runtime,omitempty"`
+	Thumbs     []Thumb    `xml:"thumb"`
+	Fanart     []Thumb    `xml:"fanart>thumb"`
+	UniqueIDs  []UniqueID `xml:"uniqueid"`
+	Genres     []string   `xml:"genre"`
+	Tags       []string   `xml:"tag"`
+	Set        *Set       `xml:"set"`
+	Premiered  string     `xml:"premiered,omitempty"`
+	Aired      string     `xml:"aired,omitempty"`
+	Year       string     `xml:"year,omitempty"`
+	Studios    []string   `xml:"studio"`
+	Directors  []string   `xml:"director"`
+	Actors     []Actor    `xml:"actor"`
+}
+
+// NewMovie returns a new movie document.
+func NewMovie() *Video {
+	return &Video{
+		XMLName: xml.Name{Local: movieRoot},
+	}
+}
+
+// IsEpisode returns true if the document is an episodedetails document.
+func (v Video) IsEpisode() bool {
+	return v.XMLName.Local == episodeRoot
+}
+
+// TVShow is a tvshow document. Only the fields that apply to the episodes of
+// the show are read.
+type TVShow struct {
+	XMLName xml.Name `xml:"tvshow"`
+	Title   string   `xml:"title"`
+	Genres  []string `xml:"genre"`
+	Tags    []string `xml:"tag"`
+	Studios []string `xml:"studio"`
+}
+
+// decodeFirst decodes the first element of the document into v, ignoring
+// anything that follows it, such as a scraper URL.
+func decodeFirst(r io.Reader, v interface{}) (string, error) {
+	d := xml.NewDecoder(r)
+	// NFO files are frequently written with non-UTF-8 declarations
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return "", ErrUnsupported
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if start, ok := t.(xml.StartElement); ok {
+			return start.Name.Local, d.DecodeElement(v, &start)
+		}
+	}
+}
+
+// ParseVideo parses a movie or episodedetails document.
+func ParseVideo(r io.Reader) (*Video, error) {
+	ret := &Video{}
+	root, err := decodeFirst(r, ret)
+	if err != nil {
+		return nil, err
+	}
+
+	if root != movieRoot && root != episodeRoot {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, root)
+	}
+
+	return ret, nil
+}
+
+// ParseTVShow parses a tvshow document.
+func ParseTVShow(r io.Reader) (*TVShow, error) {
+	ret := &TVShow{}
+	root, err := decodeFirst(r, ret)
+	if err != nil {
+		return nil, err
+	}
+
+	if root != tvShowRoot {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, root)
+	}
+
+	return ret, nil
+}
+
+// Marshal returns the indented XML encoding of the document, including the
+// XML declaration.
+func Marshal(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	ret := append([]byte(xml.Header), data...)
+	return append(ret, '\n'), nil
+}
+
+// VideoPath returns the path of the NFO file for the video file, which has
+// the same name as the video file with the nfo extension.
+func VideoPath(videoPath string) string {
+	ext := filepath.Ext(videoPath)
+	return strings.TrimSuffix(videoPath, ext) + ".nfo"
+}
+
+// MoviePath returns the path of the movie.nfo file in the directory, which
+// applies to all video files in the directory.
+func MoviePath(dir string) string {
+	return filepath.Join(dir, movieFilename)
+}
+
+// ArtworkPath returns the path of the artwork file of the kind for the video
+// file. The extension is that of the image data.
+func ArtworkPath(videoPath string, kind string, data []byte) string {
+	ext := filepath.Ext(videoPath)
+	return strings.TrimSuffix(videoPath, ext) + "-" + kind + imageExtension(data)
+}
+
+// FolderArtworkPath returns the path of the artwork file of the kind for the
+// directory. The extension is that of the image data.
+func FolderArtworkPath(dir string, kind string, data []byte) string {
+	return filepath.Join(dir, kind+imageExtension(data))
+}
+
+func imageExtension(data []byte) string {
+	switch http.DetectContentType(data) {
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	}
+
+	return ".jpg"
+}
+
+func readFile(path string, parse func(r io.Reader) error) (bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	if err := parse(f); err != nil {
+		return false, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	return true, nil
+}
+
+// findVideo returns the NFO document of the video file. The NFO file with the
+// same name as the video file is used if present, otherwise movie.nfo in the
+// same directory. Returns nil if neither exists.
+func findVideo(videoPath string) (*Video, error) {
+	var ret *Video
+	parse := func(r io.Reader) error {
+		var err error
+		ret, err = ParseVideo(r)
+		return err
+	}
+
+	for _, p := range []string{VideoPath(videoPath), MoviePath(filepath.Dir(videoPath))} {
+		found, err := readFile(p, parse)
+		if err != nil || found {
+			return ret, err
+		}
+	}
+
+	return nil, nil
+}
+
+// findTVShow returns the tvshow document in the directory of the video file
+// or its parent directory, which is where it is found when episodes are
+// organised in season directories. Returns nil if neither exists.
+func findTVShow(videoPath string) (*TVShow, error) {
+	var ret *TVShow
+	parse := func(r io.Reader) error {
+		var err error
+		ret, err = ParseTVShow(r)
+		return err
+	}
+
+	dir := filepath.Dir(videoPath)
+	for _, d := range []string{dir, filepath.Dir(dir)} {
+		found, err := readFile(filepath.Join(d, tvShowFilename), parse)
+		if err != nil || found {
+			return ret, err
+		}
+	}
+
+	return nil, nil
+}
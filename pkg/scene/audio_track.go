@@ -0,0 +1,64 @@
+package scene
+
+import (
+	"strings"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// GetAudioTracks returns the audio tracks for the audio streams of the
+// provided video file.
+func GetAudioTracks(videoFile *ffmpeg.VideoFile) []models.SceneAudioTrack {
+	var ret []models.SceneAudioTrack
+	for _, s := range videoFile.GetAudioStreams() {
+		lang := strings.ToLower(s.Tags.Language)
+		if lang == "" {
+			lang = models.UnknownLanguage
+		}
+
+		ret = append(ret, models.SceneAudioTrack{
+			StreamIndex:  s.Index,
+			LanguageCode: lang,
+			Codec:        s.CodecName,
+			Channels:     s.Channels,
+		})
+	}
+
+	return ret
+}
+
+// UpdateAudioTracks sets the audio tracks of the scene. Returns true if the
+// audio tracks of the scene were changed.
+func UpdateAudioTracks(qb models.SceneReaderWriter, sceneID int, tracks []models.SceneAudioTrack) (bool, error) {
+	existing, err := qb.GetAudioTracks(sceneID)
+	if err != nil {
+		return false, err
+	}
+
+	if audioTracksEqual(existing, tracks) {
+		return false, nil
+	}
+
+	if err := qb.UpdateAudioTracks(sceneID, tracks); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func audioTracksEqual(existing []*models.SceneAudioTrack, tracks []models.SceneAudioTrack) bool {
+	if len(existing) != len(tracks) {
+		return false
+	}
+
+	// existing tracks are ordered by stream index, as are the tracks of the
+	// file
+	for i, t := range tracks {
+		if *existing[i] != t {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,52 @@
+package scene
+
+import (
+	"testing"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAudioTracks(t *testing.T) {
+	videoFile := &ffmpeg.VideoFile{}
+	videoFile.JSON.Streams = []ffmpeg.FFProbeStream{
+		{Index: 0, CodecType: "video", CodecName: "h264"},
+		{Index: 1, CodecType: "audio", CodecName: "aac", Channels: 2},
+		{Index: 2, CodecType: "audio", CodecName: "ac3", Channels: 6},
+	}
+	videoFile.JSON.Streams[1].Tags.Language = "ENG"
+
+	want := []models.SceneAudioTrack{
+		{StreamIndex: 1, LanguageCode: "eng", Codec: "aac", Channels: 2},
+		{StreamIndex: 2, LanguageCode: models.UnknownLanguage, Codec: "ac3", Channels: 6},
+	}
+
+	assert.Equal(t, want, GetAudioTracks(videoFile))
+}
+
+func TestUpdateAudioTracks(t *testing.T) {
+	const (
+		sceneID          = 1
+		unchangedSceneID = 2
+	)
+
+	track := models.SceneAudioTrack{StreamIndex: 1, LanguageCode: "eng", Codec: "aac", Channels: 2}
+	otherTrack := models.SceneAudioTrack{StreamIndex: 2, LanguageCode: "fre", Codec: "aac", Channels: 2}
+
+	mockSceneReader := &mocks.SceneReaderWriter{}
+	mockSceneReader.On("GetAudioTracks", sceneID).Return([]*models.SceneAudioTrack{&track}, nil).Once()
+	mockSceneReader.On("UpdateAudioTracks", sceneID, []models.SceneAudioTrack{track, otherTrack}).Return(nil).Once()
+	mockSceneReader.On("GetAudioTracks", unchangedSceneID).Return([]*models.SceneAudioTrack{&track}, nil).Once()
+
+	changed, err := UpdateAudioTracks(mockSceneReader, sceneID, []models.SceneAudioTrack{track, otherTrack})
+	assert.Nil(t, err)
+	assert.True(t, changed)
+
+	changed, err = UpdateAudioTracks(mockSceneReader, unchangedSceneID, []models.SceneAudioTrack{track})
+	assert.Nil(t, err)
+	assert.False(t, changed)
+
+	mockSceneReader.AssertExpectations(t)
+}
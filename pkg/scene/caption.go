@@ -0,0 +1,356 @@
+package scene
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+const (
+	CaptionTypeVTT = "vtt"
+	CaptionTypeSRT = "srt"
+	CaptionTypeASS = "ass"
+)
+
+// CaptionExtensions are the extensions of the supported sidecar caption
+// files.
+var CaptionExtensions = []string{CaptionTypeVTT, CaptionTypeSRT, CaptionTypeASS}
+
+var captionLanguageRE = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,4})?$`)
+
+// GetSidecarCaptions returns the caption files stored next to the scene file
+// at the provided path. Caption files must have the same base name as the
+// scene file, optionally followed by a language code. For example, the
+// captions of movie.mp4 may be movie.srt or movie.en.srt.
+func GetSidecarCaptions(scenePath string) ([]models.SceneCaption, error) {
+	dir := filepath.Dir(scenePath)
+	base := strings.TrimSuffix(filepath.Base(scenePath), filepath.Ext(scenePath))
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []models.SceneCaption
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+
+		c := getSidecarCaption(base, f.Name())
+		if c != nil {
+			ret = append(ret, *c)
+		}
+	}
+
+	return ret, nil
+}
+
+func getSidecarCaption(sceneBase string, filename string) *models.SceneCaption {
+	ext := filepath.Ext(filename)
+	captionType := strings.ToLower(strings.TrimPrefix(ext, "."))
+	if !utils.StrInclude(CaptionExtensions, captionType) {
+		return nil
+	}
+
+	name := strings.TrimSuffix(filename, ext)
+	if !strings.HasPrefix(name, sceneBase) {
+		return nil
+	}
+
+	lang := models.UnknownLanguage
+	suffix := strings.TrimPrefix(name, sceneBase)
+	if suffix != "" {
+		if !strings.HasPrefix(suffix, ".") || !captionLanguageRE.MatchString(suffix[1:]) {
+			return nil
+		}
+		lang = strings.ToLower(suffix[1:])
+	}
+
+	return &models.SceneCaption{
+		LanguageCode: lang,
+		Filename:     filename,
+		CaptionType:  captionType,
+	}
+}
+
+// GetEmbeddedCaptions returns the captions for the text-based subtitle
+// streams of the provided video file.
+func GetEmbeddedCaptions(videoFile *ffmpeg.VideoFile) []models.SceneCaption {
+	var ret []models.SceneCaption
+	for _, s := range videoFile.GetSubtitleStreams() {
+		lang := strings.ToLower(s.Tags.Language)
+		if lang == "" {
+			lang = models.UnknownLanguage
+		}
+
+		ret = append(ret, models.SceneCaption{
+			LanguageCode: lang,
+			CaptionType:  s.CodecName,
+			StreamIndex:  sql.NullInt64{Int64: int64(s.Index), Valid: true},
+		})
+	}
+
+	return ret
+}
+
+// UpdateCaptions sets the captions of the scene. If keepEmbedded is true,
+// then the existing embedded captions of the scene are retained. Returns
+// true if the captions of the scene were changed.
+func UpdateCaptions(qb models.SceneReaderWriter, sceneID int, captions []models.SceneCaption, keepEmbedded bool) (bool, error) {
+	existing, err := qb.GetCaptions(sceneID)
+	if err != nil {
+		return false, err
+	}
+
+	if keepEmbedded {
+		for _, c := range existing {
+			if c.IsEmbedded() {
+				captions = append(captions, *c)
+			}
+		}
+	}
+
+	if captionsEqual(existing, captions) {
+		return false, nil
+	}
+
+	if err := qb.UpdateCaptions(sceneID, captions); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func captionsEqual(existing []*models.SceneCaption, captions []models.SceneCaption) bool {
+	if len(existing) != len(captions) {
+		return false
+	}
+
+	for _, c := range captions {
+		found := false
+		for _, e := range existing {
+			if *e == c {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+type captionCue struct {
+	start float64
+	end   float64
+	text  string
+}
+
+// CaptionToVTT converts caption data of the provided type to WebVTT.
+func CaptionToVTT(r io.Reader, captionType string) ([]byte, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// strip the byte order mark and normalise line endings
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+
+	var cues []captionCue
+	switch strings.ToLower(captionType) {
+	case CaptionTypeVTT:
+		return data, nil
+	case CaptionTypeSRT:
+		cues, err = parseSRT(data)
+	case CaptionTypeASS, "ssa":
+		cues, err = parseASS(data)
+	default:
+		return nil, fmt.Errorf("unsupported caption type: %s", captionType)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("WEBVTT\n\n")
+	for i, c := range cues {
+		fmt.Fprintf(&buf, "%d\n%s --> %s\n%s\n\n", i+1, utils.GetVTTTime(c.start), utils.GetVTTTime(c.end), c.text)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// parseCaptionTime parses timestamps of the form [hh:]mm:ss[.,]fff
+func parseCaptionTime(s string) (float64, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp: %s", s)
+	}
+
+	seconds, err := strconv.ParseFloat(strings.Replace(parts[len(parts)-1], ",", ".", 1), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp: %s", s)
+	}
+
+	multiplier := 60.0
+	for i := len(parts) - 2; i >= 0; i-- {
+		v, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp: %s", s)
+		}
+		seconds += float64(v) * multiplier
+		multiplier *= 60
+	}
+
+	return seconds, nil
+}
+
+func parseSRT(data []byte) ([]captionCue, error) {
+	var ret []captionCue
+	var current *captionCue
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t")
+
+		if strings.Contains(line, "-->") {
+			times := strings.SplitN(line, "-->", 2)
+			start, err := parseCaptionTime(times[0])
+			if err != nil {
+				return nil, err
+			}
+
+			// the end time may be followed by position information
+			endFields := strings.Fields(times[1])
+			if len(endFields) == 0 {
+				return nil, fmt.Errorf("invalid timestamp line: %s", line)
+			}
+			end, err := parseCaptionTime(endFields[0])
+			if err != nil {
+				return nil, err
+			}
+
+			current = &captionCue{start: start, end: end}
+			continue
+		}
+
+		if current == nil {
+			// cue number or blank line between cues
+			continue
+		}
+
+		if line == "" {
+			ret = append(ret, *current)
+			current = nil
+			continue
+		}
+
+		if current.text != "" {
+			current.text += "\n"
+		}
+		current.text += line
+	}
+
+	if current != nil {
+		ret = append(ret, *current)
+	}
+
+	return ret, scanner.Err()
+}
+
+var assOverrideRE = regexp.MustCompile(`\{[^}]*\}`)
+
+func parseASS(data []byte) ([]captionCue, error) {
+	var ret []captionCue
+
+	inEvents := false
+	// default field order of the Events section
+	format := []string{"layer", "start", "end", "style", "name", "marginl", "marginr", "marginv", "effect", "text"}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if strings.HasPrefix(line, "[") {
+			inEvents = strings.EqualFold(line, "[Events]")
+			continue
+		}
+
+		if !inEvents {
+			continue
+		}
+
+		if strings.HasPrefix(line, "Format:") {
+			format = nil
+			for _, f := range strings.Split(strings.TrimPrefix(line, "Format:"), ",") {
+				format = append(format, strings.ToLower(strings.TrimSpace(f)))
+			}
+			continue
+		}
+
+		if !strings.HasPrefix(line, "Dialogue:") {
+			continue
+		}
+
+		// the text field is last and may contain commas
+		fields := strings.SplitN(strings.TrimPrefix(line, "Dialogue:"), ",", len(format))
+		if len(fields) != len(format) {
+			return nil, fmt.Errorf("invalid dialogue line: %s", line)
+		}
+
+		var c captionCue
+		for i, name := range format {
+			var err error
+			switch name {
+			case "start":
+				c.start, err = parseCaptionTime(fields[i])
+			case "end":
+				c.end, err = parseCaptionTime(fields[i])
+			case "text":
+				c.text = assTextToVTT(fields[i])
+			}
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if c.text != "" {
+			ret = append(ret, c)
+		}
+	}
+
+	return ret, scanner.Err()
+}
+
+func assTextToVTT(text string) string {
+	text = assOverrideRE.ReplaceAllString(text, "")
+
+	// escape characters that have meaning in WebVTT
+	text = strings.ReplaceAll(text, "&", "&amp;")
+	text = strings.ReplaceAll(text, "<", "&lt;")
+	text = strings.ReplaceAll(text, ">", "&gt;")
+
+	text = strings.ReplaceAll(text, `\N`, "\n")
+	text = strings.ReplaceAll(text, `\n`, "\n")
+	text = strings.ReplaceAll(text, `\h`, " ")
+
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,134 @@
+package scene
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSidecarCaption(t *testing.T) {
+	const base = "movie"
+
+	tests := []struct {
+		filename string
+		want     *models.SceneCaption
+	}{
+		{"movie.srt", &models.SceneCaption{LanguageCode: models.UnknownLanguage, Filename: "movie.srt", CaptionType: CaptionTypeSRT}},
+		{"movie.en.vtt", &models.SceneCaption{LanguageCode: "en", Filename: "movie.en.vtt", CaptionType: CaptionTypeVTT}},
+		{"movie.pt-BR.ASS", &models.SceneCaption{LanguageCode: "pt-br", Filename: "movie.pt-BR.ASS", CaptionType: CaptionTypeASS}},
+		{"movie.mp4", nil},
+		{"movie2.srt", nil},
+		{"movie.forced.srt", nil},
+		{"other.en.srt", nil},
+	}
+
+	for _, tt := range tests {
+		got := getSidecarCaption(base, tt.filename)
+		assert.Equal(t, tt.want, got, tt.filename)
+	}
+}
+
+const srtInput = "\xef\xbb\xbf1\r\n" +
+	"00:00:01,000 --> 00:00:02,500\r\n" +
+	"Hello\r\n" +
+	"<i>world</i>\r\n" +
+	"\r\n" +
+	"2\r\n" +
+	"00:01:03,250 --> 00:01:04,000 X1:10 X2:20 Y1:30 Y2:40\r\n" +
+	"Second\r\n"
+
+const srtOutput = `WEBVTT
+
+1
+00:00:01.000 --> 00:00:02.500
+Hello
+<i>world</i>
+
+2
+00:01:03.250 --> 00:01:04.000
+Second
+
+`
+
+const assInput = `[Script Info]
+Title: test
+
+[V4+ Styles]
+Format: Name, Fontname
+Style: Default,Arial
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Comment: 0,0:00:00.00,0:00:01.00,Default,,0,0,0,,ignored
+Dialogue: 0,0:00:01.50,0:00:03.00,Default,,0,0,0,,{\i1}Hello{\i0}, world\NA & B
+Dialogue: 0,1:02:03.04,1:02:04.00,Default,,0,0,0,,<Second>
+`
+
+const assOutput = `WEBVTT
+
+1
+00:00:01.500 --> 00:00:03.000
+Hello, world
+A &amp; B
+
+2
+01:02:03.040 --> 01:02:04.000
+&lt;Second&gt;
+
+`
+
+func TestCaptionToVTT(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		captionType string
+		want        string
+		wantErr     bool
+	}{
+		{"srt", srtInput, CaptionTypeSRT, srtOutput, false},
+		{"ass", assInput, CaptionTypeASS, assOutput, false},
+		{"vtt", srtOutput, CaptionTypeVTT, srtOutput, false},
+		{"invalid srt time", "1\n00:00:aa,000 --> 00:00:01,000\ntext\n", CaptionTypeSRT, "", true},
+		{"unsupported", "", "sub", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := CaptionToVTT(strings.NewReader(tt.input), tt.captionType)
+		if tt.wantErr {
+			assert.NotNil(t, err, tt.name)
+			continue
+		}
+
+		assert.Nil(t, err, tt.name)
+		assert.Equal(t, tt.want, string(got), tt.name)
+	}
+}
+
+func TestUpdateCaptions(t *testing.T) {
+	const (
+		sceneID          = 1
+		unchangedSceneID = 2
+	)
+
+	sidecar := models.SceneCaption{LanguageCode: "en", Filename: "movie.en.srt", CaptionType: CaptionTypeSRT}
+	embedded := models.SceneCaption{LanguageCode: "fr", CaptionType: "subrip", StreamIndex: sql.NullInt64{Int64: 2, Valid: true}}
+
+	mockSceneReader := &mocks.SceneReaderWriter{}
+	mockSceneReader.On("GetCaptions", sceneID).Return([]*models.SceneCaption{&embedded}, nil).Once()
+	mockSceneReader.On("UpdateCaptions", sceneID, []models.SceneCaption{sidecar, embedded}).Return(nil).Once()
+	mockSceneReader.On("GetCaptions", unchangedSceneID).Return([]*models.SceneCaption{&embedded, &sidecar}, nil).Once()
+
+	changed, err := UpdateCaptions(mockSceneReader, sceneID, []models.SceneCaption{sidecar}, true)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+
+	changed, err = UpdateCaptions(mockSceneReader, unchangedSceneID, []models.SceneCaption{sidecar}, true)
+	assert.Nil(t, err)
+	assert.False(t, changed)
+
+	mockSceneReader.AssertExpectations(t)
+}
@@ -0,0 +1,141 @@
+package scene
+
+import (
+	"database/sql"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/tag"
+)
+
+// ChapterImporter creates scene markers from the chapters embedded in the
+// file of a scene.
+type ChapterImporter struct {
+	MarkerWriter models.SceneMarkerReaderWriter
+	TagWriter    models.TagReaderWriter
+
+	// TagMappings maps chapter titles to the names of the primary tags of
+	// the created markers.
+	TagMappings []*models.ChapterTagMapping
+	// DefaultTag is the name of the primary tag of markers created from
+	// chapters without a tag mapping.
+	DefaultTag string
+
+	tagIDs map[string]int
+}
+
+// Import creates a marker for each chapter that does not have a marker of the
+// scene at the same time, so that importing the chapters of a scene more than
+// once does not create duplicate markers. Tags that do not exist are created.
+// Returns the number of created markers.
+func (i *ChapterImporter) Import(sceneID int, chapters []ffmpeg.Chapter) (int, error) {
+	if len(chapters) == 0 {
+		return 0, nil
+	}
+
+	existing, err := i.MarkerWriter.FindBySceneID(sceneID)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for n, c := range chapters {
+		if hasMarkerAt(existing, c.Start) {
+			continue
+		}
+
+		// titles written by muxers are often padded with whitespace
+		title := strings.TrimSpace(c.Title)
+		tagID, err := i.getTagID(i.tagName(title))
+		if err != nil {
+			return created, err
+		}
+
+		if title == "" {
+			title = "Chapter " + strconv.Itoa(n+1)
+		}
+
+		currentTime := time.Now()
+		marker, err := i.MarkerWriter.Create(models.SceneMarker{
+			Title:        title,
+			Seconds:      c.Start,
+			EndSeconds:   sql.NullFloat64{Float64: c.End, Valid: c.End > c.Start},
+			PrimaryTagID: tagID,
+			SceneID:      sql.NullInt64{Int64: int64(sceneID), Valid: true},
+			CreatedAt:    models.SQLiteTimestamp{Timestamp: currentTime},
+			UpdatedAt:    models.SQLiteTimestamp{Timestamp: currentTime},
+		})
+		if err != nil {
+			return created, err
+		}
+
+		existing = append(existing, marker)
+		created++
+	}
+
+	return created, nil
+}
+
+// tagName returns the name of the primary tag of the marker created from the
+// chapter with the provided title. Leading and trailing whitespace is ignored.
+func (i *ChapterImporter) tagName(title string) string {
+	title = strings.TrimSpace(title)
+	for _, m := range i.TagMappings {
+		if strings.EqualFold(strings.TrimSpace(m.Chapter), title) {
+			return m.Tag
+		}
+	}
+
+	return i.DefaultTag
+}
+
+func (i *ChapterImporter) getTagID(name string) (int, error) {
+	if id, found := i.tagIDs[name]; found {
+		return id, nil
+	}
+
+	t, err := tag.ByName(i.TagWriter, name)
+	if err != nil {
+		return 0, err
+	}
+
+	if t == nil {
+		t, err = tag.ByAlias(i.TagWriter, name)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if t == nil {
+		t, err = i.TagWriter.Create(*models.NewTag(name))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if i.tagIDs == nil {
+		i.tagIDs = make(map[string]int)
+	}
+	i.tagIDs[name] = t.ID
+
+	return t.ID, nil
+}
+
+// chapterTimeTolerance is the maximum difference in seconds between the start
+// of a chapter and an existing marker for the marker to be considered to be
+// created from the chapter.
+const chapterTimeTolerance = 0.01
+
+func hasMarkerAt(markers []*models.SceneMarker, seconds float64) bool {
+	for _, m := range markers {
+		if math.Abs(m.Seconds-seconds) <= chapterTimeTolerance {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,130 @@
+package scene
+
+import (
+	"testing"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	chapterSceneID      = 1
+	mappedChapterTagID  = 2
+	defaultChapterTagID = 3
+	existingMarkerID    = 4
+)
+
+const (
+	mappedChapterTag  = "Intro"
+	defaultChapterTag = "Chapter"
+)
+
+func chapterTagFilter(name string) interface{} {
+	return mock.MatchedBy(func(f *models.TagFilterType) bool {
+		return f != nil && f.Name != nil && f.Name.Value == name
+	})
+}
+
+func chapterTagAliasFilter(alias string) interface{} {
+	return mock.MatchedBy(func(f *models.TagFilterType) bool {
+		return f != nil && f.Aliases != nil && f.Aliases.Value == alias
+	})
+}
+
+func chapterMarker(title string, seconds float64, tagID int) interface{} {
+	return mock.MatchedBy(func(m models.SceneMarker) bool {
+		return m.Title == title && m.Seconds == seconds && m.EndSeconds.Float64 == seconds+60 && m.PrimaryTagID == tagID && m.SceneID.Int64 == chapterSceneID
+	})
+}
+
+func TestChapterImporterImport(t *testing.T) {
+	mockMarkerReaderWriter := &mocks.SceneMarkerReaderWriter{}
+	mockTagReaderWriter := &mocks.TagReaderWriter{}
+
+	chapters := []ffmpeg.Chapter{
+		{Title: " opening ", Start: 0, End: 60},
+		// already has a marker
+		{Title: "Existing", Start: 60, End: 120},
+		{Title: "  ", Start: 120, End: 180},
+		{Title: "Other", Start: 180, End: 240},
+	}
+
+	mockMarkerReaderWriter.On("FindBySceneID", chapterSceneID).Return([]*models.SceneMarker{
+		{ID: existingMarkerID, Seconds: 60.001},
+	}, nil).Once()
+
+	// the mapped tag exists, and the default tag is created once
+	mockTagReaderWriter.On("Query", chapterTagFilter(mappedChapterTag), mock.Anything).Return([]*models.Tag{{ID: mappedChapterTagID}}, 1, nil).Once()
+	mockTagReaderWriter.On("Query", chapterTagFilter(defaultChapterTag), mock.Anything).Return(nil, 0, nil).Once()
+	mockTagReaderWriter.On("Query", chapterTagAliasFilter(defaultChapterTag), mock.Anything).Return(nil, 0, nil).Once()
+	mockTagReaderWriter.On("Create", mock.MatchedBy(func(t models.Tag) bool {
+		return t.Name == defaultChapterTag
+	})).Return(&models.Tag{ID: defaultChapterTagID}, nil).Once()
+
+	mockMarkerReaderWriter.On("Create", chapterMarker("opening", 0, mappedChapterTagID)).Return(&models.SceneMarker{Seconds: 0}, nil).Once()
+	mockMarkerReaderWriter.On("Create", chapterMarker("Chapter 3", 120, defaultChapterTagID)).Return(&models.SceneMarker{Seconds: 120}, nil).Once()
+	mockMarkerReaderWriter.On("Create", chapterMarker("Other", 180, defaultChapterTagID)).Return(&models.SceneMarker{Seconds: 180}, nil).Once()
+
+	importer := ChapterImporter{
+		MarkerWriter: mockMarkerReaderWriter,
+		TagWriter:    mockTagReaderWriter,
+		TagMappings: []*models.ChapterTagMapping{
+			{Chapter: "Opening", Tag: mappedChapterTag},
+		},
+		DefaultTag: defaultChapterTag,
+	}
+
+	created, err := importer.Import(chapterSceneID, chapters)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, created)
+
+	mockMarkerReaderWriter.AssertExpectations(t)
+	mockTagReaderWriter.AssertExpectations(t)
+}
+
+func TestChapterImporterTagName(t *testing.T) {
+	importer := ChapterImporter{
+		TagMappings: []*models.ChapterTagMapping{
+			{Chapter: "Opening", Tag: mappedChapterTag},
+			{Chapter: " Credits ", Tag: "Outro"},
+		},
+		DefaultTag: defaultChapterTag,
+	}
+
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Opening", mappedChapterTag},
+		{"opening", mappedChapterTag},
+		// padded titles written by muxers
+		{" Opening ", mappedChapterTag},
+		{"\tOPENING\n", mappedChapterTag},
+		{"Credits", "Outro"},
+		{"  credits", "Outro"},
+		{"Opening scene", defaultChapterTag},
+		{"", defaultChapterTag},
+		{"   ", defaultChapterTag},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, importer.tagName(tt.title), tt.title)
+	}
+}
+
+func TestChapterImporterNoChapters(t *testing.T) {
+	mockMarkerReaderWriter := &mocks.SceneMarkerReaderWriter{}
+
+	importer := ChapterImporter{
+		MarkerWriter: mockMarkerReaderWriter,
+	}
+
+	created, err := importer.Import(chapterSceneID, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, created)
+
+	mockMarkerReaderWriter.AssertExpectations(t)
+}
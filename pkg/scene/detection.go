@@ -0,0 +1,89 @@
+package scene
+
+import (
+	"math"
+	"sort"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+)
+
+const (
+	// maxIntroDuration is the maximum duration in seconds of the intro of a
+	// scene. Black segments after this are not considered to end the intro.
+	maxIntroDuration = 180
+	// maxOutroDuration is the maximum duration in seconds of the outro of a
+	// scene.
+	maxOutroDuration = 300
+	// maxIntroOutroProportion is the maximum proportion of the duration of
+	// a scene taken by its intro or outro.
+	maxIntroOutroProportion = 0.25
+	// minCutInterval is the minimum interval in seconds between cuts.
+	// Cuts closer to the previous cut are ignored.
+	minCutInterval = 1
+)
+
+// Detection is the result of scene detection on the file of a scene.
+type Detection struct {
+	// IntroEnd is the time in seconds that the intro of the scene ends. It
+	// is 0 if no intro was detected.
+	IntroEnd float64
+	// OutroStart is the time in seconds that the outro of the scene
+	// starts. It is the duration of the scene if no outro was detected.
+	OutroStart float64
+	// Cuts are the times in seconds that shots start, in ascending order.
+	Cuts []float64
+}
+
+// GetDetection determines the intro, outro and cuts of a scene with the
+// provided duration from the black segments and cuts detected in its file.
+//
+// The intro ends at the end of the last black segment near the start of the
+// scene, and the outro starts at the start of the first black segment near
+// the end of the scene. Cuts within black segments, such as at fades, are
+// ignored.
+func GetDetection(duration float64, result ffmpeg.SceneDetectionResult) Detection {
+	ret := Detection{
+		OutroStart: duration,
+	}
+
+	introLimit := math.Min(maxIntroDuration, duration*maxIntroOutroProportion)
+	outroLimit := duration - math.Min(maxOutroDuration, duration*maxIntroOutroProportion)
+
+	for _, b := range result.BlackSegments {
+		if b.End <= introLimit && b.End > ret.IntroEnd {
+			ret.IntroEnd = b.End
+		}
+
+		if b.Start >= outroLimit && b.Start < ret.OutroStart {
+			ret.OutroStart = b.Start
+		}
+	}
+
+	cuts := append([]float64{}, result.Cuts...)
+	sort.Float64s(cuts)
+
+	last := math.Inf(-1)
+	for _, c := range cuts {
+		if c < 0 || c > duration || c-last < minCutInterval || inBlackSegment(result.BlackSegments, c) {
+			continue
+		}
+
+		ret.Cuts = append(ret.Cuts, math.Round(c*100)/100)
+		last = c
+	}
+
+	ret.IntroEnd = math.Round(ret.IntroEnd*100) / 100
+	ret.OutroStart = math.Round(ret.OutroStart*100) / 100
+
+	return ret
+}
+
+func inBlackSegment(segments []ffmpeg.BlackSegment, t float64) bool {
+	for _, b := range segments {
+		if t >= b.Start && t <= b.End {
+			return true
+		}
+	}
+
+	return false
+}
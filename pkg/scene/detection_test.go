@@ -0,0 +1,89 @@
+package scene
+
+import (
+	"testing"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDetection(t *testing.T) {
+	const duration = 1200
+
+	tests := []struct {
+		name   string
+		result ffmpeg.SceneDetectionResult
+		want   Detection
+	}{
+		{
+			"none",
+			ffmpeg.SceneDetectionResult{},
+			Detection{
+				OutroStart: duration,
+			},
+		},
+		{
+			"intro and outro",
+			ffmpeg.SceneDetectionResult{
+				BlackSegments: []ffmpeg.BlackSegment{
+					{Start: 0, End: 1.5},
+					{Start: 10, End: 12.004},
+					// after the intro limit
+					{Start: 400, End: 402},
+					{Start: 1100, End: 1102},
+					{Start: 1150, End: 1200},
+				},
+			},
+			Detection{
+				IntroEnd:   12,
+				OutroStart: 1100,
+			},
+		},
+		{
+			"cuts",
+			ffmpeg.SceneDetectionResult{
+				BlackSegments: []ffmpeg.BlackSegment{
+					{Start: 500, End: 502},
+				},
+				Cuts: []float64{
+					300,
+					50.123,
+					// within a second of the previous cut
+					50.5,
+					// within a black segment
+					501,
+					// after the end of the scene
+					1300,
+				},
+			},
+			Detection{
+				OutroStart: duration,
+				Cuts:       []float64{50.12, 300},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GetDetection(duration, tt.result))
+		})
+	}
+}
+
+func TestGetDetectionShortScene(t *testing.T) {
+	// the intro and outro are limited to a proportion of the duration
+	result := ffmpeg.SceneDetectionResult{
+		BlackSegments: []ffmpeg.BlackSegment{
+			{Start: 20, End: 30},
+			{Start: 60, End: 61},
+		},
+	}
+
+	got := GetDetection(100, result)
+	assert.Equal(t, float64(0), got.IntroEnd)
+	assert.Equal(t, float64(100), got.OutroStart)
+
+	got = GetDetection(200, result)
+	assert.Equal(t, float64(30), got.IntroEnd)
+	assert.Equal(t, float64(200), got.OutroStart)
+}
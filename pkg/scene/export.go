@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"time"
 
 	"github.com/stashapp/stash/pkg/manager/jsonschema"
 	"github.com/stashapp/stash/pkg/models"
@@ -49,6 +50,13 @@ func ToBasicJSON(reader models.SceneReader, scene *models.Scene) (*jsonschema.Sc
 
 	newSceneJSON.Organized = scene.Organized
 	newSceneJSON.OCounter = scene.OCounter
+	newSceneJSON.ResumeTime = scene.ResumeTime
+	newSceneJSON.PlayDuration = scene.PlayDuration
+	newSceneJSON.PlayCount = scene.PlayCount
+
+	if scene.LastPlayedAt.Valid {
+		newSceneJSON.LastPlayedAt = &models.JSONTime{Time: scene.LastPlayedAt.Timestamp}
+	}
 
 	if scene.Details.Valid {
 		newSceneJSON.Details = scene.Details.String
@@ -65,9 +73,32 @@ func ToBasicJSON(reader models.SceneReader, scene *models.Scene) (*jsonschema.Sc
 		newSceneJSON.Cover = utils.GetBase64StringFromData(cover)
 	}
 
+	oDates, err := reader.GetODates(scene.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting scene o-counter history: %s", err.Error())
+	}
+
+	newSceneJSON.OHistory = getJSONTimes(oDates)
+
+	playDates, err := reader.GetPlayDates(scene.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting scene play history: %s", err.Error())
+	}
+
+	newSceneJSON.PlayHistory = getJSONTimes(playDates)
+
 	return &newSceneJSON, nil
 }
 
+func getJSONTimes(times []time.Time) []models.JSONTime {
+	var ret []models.JSONTime
+	for _, t := range times {
+		ret = append(ret, models.JSONTime{Time: t})
+	}
+
+	return ret
+}
+
 func getSceneFileJSON(scene *models.Scene) *jsonschema.SceneFile {
 	ret := &jsonschema.SceneFile{}
 
@@ -259,6 +290,10 @@ func GetSceneMarkersJSON(markerReader models.SceneMarkerReader, tagReader models
 			UpdatedAt:  models.JSONTime{Time: sceneMarker.UpdatedAt.Timestamp},
 		}
 
+		if sceneMarker.EndSeconds.Valid {
+			sceneMarkerJSON.EndSeconds = getDecimalString(sceneMarker.EndSeconds.Float64)
+		}
+
 		results = append(results, sceneMarkerJSON)
 	}
 
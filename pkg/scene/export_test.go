@@ -48,6 +48,9 @@ const (
 	date         = "2001-01-01"
 	rating       = 5
 	ocounter     = 2
+	resumeTime   = 4.56
+	playDuration = 7.89
+	playCount    = 3
 	organized    = true
 	details      = "details"
 	size         = "size"
@@ -89,6 +92,8 @@ const image = "aW1hZ2VCeXRlcw=="
 
 var createTime time.Time = time.Date(2001, 01, 01, 0, 0, 0, 0, time.UTC)
 var updateTime time.Time = time.Date(2002, 01, 01, 0, 0, 0, 0, time.UTC)
+var lastPlayedTime time.Time = time.Date(2003, 01, 01, 0, 0, 0, 0, time.UTC)
+var oTime time.Time = time.Date(2004, 01, 01, 0, 0, 0, 0, time.UTC)
 
 func createFullScene(id int) models.Scene {
 	return models.Scene{
@@ -127,6 +132,13 @@ func createFullScene(id int) models.Scene {
 		UpdatedAt: models.SQLiteTimestamp{
 			Timestamp: updateTime,
 		},
+		ResumeTime:   resumeTime,
+		PlayDuration: playDuration,
+		PlayCount:    playCount,
+		LastPlayedAt: models.NullSQLiteTimestamp{
+			Timestamp: lastPlayedTime,
+			Valid:     true,
+		},
 	}
 }
 
@@ -171,7 +183,23 @@ func createFullJSONScene(image string) *jsonschema.Scene {
 		UpdatedAt: models.JSONTime{
 			Time: updateTime,
 		},
-		Cover: image,
+		Cover:        image,
+		ResumeTime:   resumeTime,
+		PlayDuration: playDuration,
+		PlayCount:    playCount,
+		LastPlayedAt: &models.JSONTime{
+			Time: lastPlayedTime,
+		},
+		OHistory: []models.JSONTime{
+			{
+				Time: oTime,
+			},
+		},
+		PlayHistory: []models.JSONTime{
+			{
+				Time: lastPlayedTime,
+			},
+		},
 	}
 }
 
@@ -220,6 +248,11 @@ func TestToJSON(t *testing.T) {
 	mockSceneReader.On("GetCover", noImageID).Return(nil, nil).Once()
 	mockSceneReader.On("GetCover", errImageID).Return(nil, imageErr).Once()
 
+	mockSceneReader.On("GetODates", sceneID).Return([]time.Time{oTime}, nil).Once()
+	mockSceneReader.On("GetODates", noImageID).Return(nil, nil).Once()
+	mockSceneReader.On("GetPlayDates", sceneID).Return([]time.Time{lastPlayedTime}, nil).Once()
+	mockSceneReader.On("GetPlayDates", noImageID).Return(nil, nil).Once()
+
 	for i, s := range scenarios {
 		scene := s.input
 		json, err := ToBasicJSON(mockSceneReader, &scene)
@@ -484,8 +517,12 @@ const (
 	markerSeconds1 = 1.0
 	markerSeconds2 = 2.3
 
+	markerEndSeconds2 = 12.5
+
 	markerSeconds1Str = "1.0"
 	markerSeconds2Str = "2.3"
+
+	markerEndSeconds2Str = "12.5"
 )
 
 type sceneMarkersTestScenario struct {
@@ -517,6 +554,7 @@ var getSceneMarkersJSONScenarios = []sceneMarkersTestScenario{
 				Title:      markerTitle2,
 				PrimaryTag: validTagName2,
 				Seconds:    markerSeconds2Str,
+				EndSeconds: markerEndSeconds2Str,
 				Tags: []string{
 					validTagName2,
 				},
@@ -570,6 +608,10 @@ var validMarkers = []*models.SceneMarker{
 		Title:        markerTitle2,
 		PrimaryTagID: validTagID2,
 		Seconds:      markerSeconds2,
+		EndSeconds: sql.NullFloat64{
+			Float64: markerEndSeconds2,
+			Valid:   true,
+		},
 		CreatedAt: models.SQLiteTimestamp{
 			Timestamp: createTime,
 		},
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/stashapp/stash/pkg/manager/jsonschema"
 	"github.com/stashapp/stash/pkg/models"
@@ -30,6 +31,8 @@ type Importer struct {
 	movies         []models.MoviesScenes
 	tags           []*models.Tag
 	coverImageData []byte
+	oDates         []time.Time
+	playDates      []time.Time
 }
 
 func (i *Importer) PreImport() error {
@@ -96,6 +99,20 @@ func (i *Importer) sceneJSONToScene(sceneJSON jsonschema.Scene) models.Scene {
 
 	newScene.Organized = sceneJSON.Organized
 	newScene.OCounter = sceneJSON.OCounter
+	newScene.ResumeTime = sceneJSON.ResumeTime
+	newScene.PlayDuration = sceneJSON.PlayDuration
+	newScene.PlayCount = sceneJSON.PlayCount
+
+	if sceneJSON.LastPlayedAt != nil && !sceneJSON.LastPlayedAt.IsZero() {
+		newScene.LastPlayedAt = models.NullSQLiteTimestamp{Timestamp: sceneJSON.LastPlayedAt.GetTime(), Valid: true}
+	}
+	for _, t := range sceneJSON.OHistory {
+		i.oDates = append(i.oDates, t.GetTime())
+	}
+	for _, t := range sceneJSON.PlayHistory {
+		i.playDates = append(i.playDates, t.GetTime())
+	}
+
 	newScene.CreatedAt = models.SQLiteTimestamp{Timestamp: sceneJSON.CreatedAt.GetTime()}
 	newScene.UpdatedAt = models.SQLiteTimestamp{Timestamp: sceneJSON.UpdatedAt.GetTime()}
 
@@ -386,6 +403,18 @@ func (i *Importer) PostImport(id int) error {
 		}
 	}
 
+	if len(i.oDates) > 0 {
+		if err := i.ReaderWriter.UpdateODates(id, i.oDates); err != nil {
+			return fmt.Errorf("failed to set o-counter history: %s", err.Error())
+		}
+	}
+
+	if len(i.playDates) > 0 {
+		if err := i.ReaderWriter.UpdatePlayDates(id, i.playDates); err != nil {
+			return fmt.Errorf("failed to set play history: %s", err.Error())
+		}
+	}
+
 	return nil
 }
 
@@ -3,6 +3,7 @@ package scene
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stashapp/stash/pkg/manager/jsonschema"
 	"github.com/stashapp/stash/pkg/models"
@@ -48,6 +49,8 @@ const (
 
 	errPerformersID = 200
 	errGalleriesID  = 201
+	errODatesID     = 202
+	errPlayDatesID  = 203
 
 	missingChecksum = "missingChecksum"
 	missingOSHash   = "missingOSHash"
@@ -623,6 +626,35 @@ func TestImporterPostImportUpdateTags(t *testing.T) {
 	sceneReaderWriter.AssertExpectations(t)
 }
 
+func TestImporterPostImportUpdateHistory(t *testing.T) {
+	sceneReaderWriter := &mocks.SceneReaderWriter{}
+
+	i := Importer{
+		ReaderWriter: sceneReaderWriter,
+		oDates:       []time.Time{oTime},
+		playDates:    []time.Time{lastPlayedTime},
+	}
+
+	updateErr := errors.New("Update history error")
+
+	sceneReaderWriter.On("UpdateODates", sceneID, []time.Time{oTime}).Return(nil).Once()
+	sceneReaderWriter.On("UpdatePlayDates", sceneID, []time.Time{lastPlayedTime}).Return(nil).Once()
+	sceneReaderWriter.On("UpdateODates", errODatesID, mock.AnythingOfType("[]time.Time")).Return(updateErr).Once()
+	sceneReaderWriter.On("UpdateODates", errPlayDatesID, mock.AnythingOfType("[]time.Time")).Return(nil).Once()
+	sceneReaderWriter.On("UpdatePlayDates", errPlayDatesID, mock.AnythingOfType("[]time.Time")).Return(updateErr).Once()
+
+	err := i.PostImport(sceneID)
+	assert.Nil(t, err)
+
+	err = i.PostImport(errODatesID)
+	assert.NotNil(t, err)
+
+	err = i.PostImport(errPlayDatesID)
+	assert.NotNil(t, err)
+
+	sceneReaderWriter.AssertExpectations(t)
+}
+
 func TestImporterFindExistingID(t *testing.T) {
 	readerWriter := &mocks.SceneReaderWriter{}
 
@@ -30,6 +30,10 @@ func (i *MarkerImporter) PreImport() error {
 		UpdatedAt: models.SQLiteTimestamp{Timestamp: i.Input.UpdatedAt.GetTime()},
 	}
 
+	if endSeconds, err := strconv.ParseFloat(i.Input.EndSeconds, 64); err == nil && endSeconds > seconds {
+		i.marker.EndSeconds = sql.NullFloat64{Float64: endSeconds, Valid: true}
+	}
+
 	if err := i.populateTags(); err != nil {
 		return err
 	}
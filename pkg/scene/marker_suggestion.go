@@ -0,0 +1,125 @@
+package scene
+
+import (
+	"math"
+	"sort"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// DefaultSuggestedCuts is the default maximum number of cuts suggested as
+// markers.
+const DefaultSuggestedCuts = 5
+
+// minSuggestionDistance is the minimum distance in seconds between a
+// suggested marker and an existing marker or another suggestion.
+const minSuggestionDistance = 5
+
+type shot struct {
+	start  float64
+	length float64
+}
+
+// SuggestMarkers returns the markers suggested from the scene detection
+// results of the scene. The end of the intro and the outro are suggested if
+// they were detected, along with up to limit cuts. The cuts starting the
+// longest shots outside of the intro and outro are suggested, since these
+// are most likely to start a new part of the scene.
+//
+// Suggestions near existing markers are excluded. Returns nil if scene
+// detection has not been run on the scene. Suggestions are ordered by time.
+func SuggestMarkers(s *models.Scene, cuts []float64, markers []*models.SceneMarker, limit int) []*models.SceneMarkerSuggestion {
+	if !s.IntroEnd.Valid {
+		return nil
+	}
+
+	duration := s.Duration.Float64
+	introEnd := s.IntroEnd.Float64
+	outroStart := duration
+	if s.OutroStart.Valid {
+		outroStart = s.OutroStart.Float64
+	}
+
+	var ret []*models.SceneMarkerSuggestion
+	isNear := func(t float64) bool {
+		for _, m := range markers {
+			if math.Abs(m.Seconds-t) < minSuggestionDistance {
+				return true
+			}
+		}
+
+		for _, s := range ret {
+			if math.Abs(s.Seconds-t) < minSuggestionDistance {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if introEnd > 0 && !isNear(introEnd) {
+		ret = append(ret, &models.SceneMarkerSuggestion{
+			Type:    models.SceneMarkerSuggestionTypeIntroEnd,
+			Seconds: introEnd,
+		})
+	}
+
+	if outroStart < duration && !isNear(outroStart) {
+		end := duration
+		ret = append(ret, &models.SceneMarkerSuggestion{
+			Type:       models.SceneMarkerSuggestionTypeOutro,
+			Seconds:    outroStart,
+			EndSeconds: &end,
+		})
+	}
+
+	suggested := 0
+	for _, shot := range getShots(cuts, introEnd, outroStart) {
+		if suggested >= limit {
+			break
+		}
+
+		if isNear(shot.start) {
+			continue
+		}
+
+		ret = append(ret, &models.SceneMarkerSuggestion{
+			Type:    models.SceneMarkerSuggestionTypeCut,
+			Seconds: shot.start,
+		})
+		suggested++
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Seconds < ret[j].Seconds
+	})
+
+	return ret
+}
+
+// getShots returns the shots started by the cuts between start and end,
+// longest first. Each shot ends at the next cut, or at end.
+func getShots(cuts []float64, start float64, end float64) []shot {
+	sorted := append([]float64{}, cuts...)
+	sort.Float64s(sorted)
+
+	var ret []shot
+	for i, c := range sorted {
+		if c <= start || c >= end {
+			continue
+		}
+
+		next := end
+		if i+1 < len(sorted) && sorted[i+1] < end {
+			next = sorted[i+1]
+		}
+
+		ret = append(ret, shot{start: c, length: next - c})
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].length > ret[j].length
+	})
+
+	return ret
+}
@@ -0,0 +1,132 @@
+package scene
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestMarkers(t *testing.T) {
+	const duration = 1200
+
+	detected := func(introEnd, outroStart float64) *models.Scene {
+		return &models.Scene{
+			Duration:   sql.NullFloat64{Float64: duration, Valid: true},
+			IntroEnd:   sql.NullFloat64{Float64: introEnd, Valid: true},
+			OutroStart: sql.NullFloat64{Float64: outroStart, Valid: true},
+		}
+	}
+
+	suggestion := func(t models.SceneMarkerSuggestionType, seconds float64) *models.SceneMarkerSuggestion {
+		return &models.SceneMarkerSuggestion{
+			Type:    t,
+			Seconds: seconds,
+		}
+	}
+
+	outroEnd := float64(duration)
+	outro := func(seconds float64) *models.SceneMarkerSuggestion {
+		return &models.SceneMarkerSuggestion{
+			Type:       models.SceneMarkerSuggestionTypeOutro,
+			Seconds:    seconds,
+			EndSeconds: &outroEnd,
+		}
+	}
+
+	cuts := []float64{600, 100, 300, 900, 1150}
+
+	tests := []struct {
+		name    string
+		scene   *models.Scene
+		cuts    []float64
+		markers []*models.SceneMarker
+		limit   int
+		want    []*models.SceneMarkerSuggestion
+	}{
+		{
+			"not detected",
+			&models.Scene{
+				Duration: sql.NullFloat64{Float64: duration, Valid: true},
+			},
+			cuts,
+			nil,
+			DefaultSuggestedCuts,
+			nil,
+		},
+		{
+			"no intro or outro",
+			detected(0, duration),
+			cuts,
+			nil,
+			DefaultSuggestedCuts,
+			[]*models.SceneMarkerSuggestion{
+				suggestion(models.SceneMarkerSuggestionTypeCut, 100),
+				suggestion(models.SceneMarkerSuggestionTypeCut, 300),
+				suggestion(models.SceneMarkerSuggestionTypeCut, 600),
+				suggestion(models.SceneMarkerSuggestionTypeCut, 900),
+				suggestion(models.SceneMarkerSuggestionTypeCut, 1150),
+			},
+		},
+		{
+			"longest shots",
+			detected(0, duration),
+			cuts,
+			nil,
+			2,
+			[]*models.SceneMarkerSuggestion{
+				// 300 to 600 and 600 to 900 are the longest, 300 is earlier
+				suggestion(models.SceneMarkerSuggestionTypeCut, 300),
+				suggestion(models.SceneMarkerSuggestionTypeCut, 600),
+			},
+		},
+		{
+			"intro and outro",
+			detected(120, 1100),
+			cuts,
+			nil,
+			DefaultSuggestedCuts,
+			[]*models.SceneMarkerSuggestion{
+				suggestion(models.SceneMarkerSuggestionTypeIntroEnd, 120),
+				suggestion(models.SceneMarkerSuggestionTypeCut, 300),
+				suggestion(models.SceneMarkerSuggestionTypeCut, 600),
+				suggestion(models.SceneMarkerSuggestionTypeCut, 900),
+				outro(1100),
+			},
+		},
+		{
+			"near markers and suggestions",
+			detected(98, duration),
+			cuts,
+			[]*models.SceneMarker{
+				{Seconds: 602},
+			},
+			DefaultSuggestedCuts,
+			[]*models.SceneMarkerSuggestion{
+				suggestion(models.SceneMarkerSuggestionTypeIntroEnd, 98),
+				suggestion(models.SceneMarkerSuggestionTypeCut, 300),
+				suggestion(models.SceneMarkerSuggestionTypeCut, 900),
+				suggestion(models.SceneMarkerSuggestionTypeCut, 1150),
+			},
+		},
+		{
+			"zero limit",
+			detected(120, 1100),
+			cuts,
+			nil,
+			0,
+			[]*models.SceneMarkerSuggestion{
+				suggestion(models.SceneMarkerSuggestionTypeIntroEnd, 120),
+				outro(1100),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SuggestMarkers(tt.scene, tt.cuts, tt.markers, tt.limit)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
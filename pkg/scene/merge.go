@@ -0,0 +1,265 @@
+package scene
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// Merge merges the relationships and counters of the source scenes into the
+// destination scene. Markers of the source scenes are moved to the
+// destination. If moveFiles is true, then the files of the source scenes are
+// added to the destination as additional files. The source scenes are not
+// destroyed.
+func Merge(qb models.SceneReaderWriter, mqb models.SceneMarkerReaderWriter, source []*models.Scene, destination *models.Scene, moveFiles bool) error {
+	performerIDs, err := qb.GetPerformerIDs(destination.ID)
+	if err != nil {
+		return err
+	}
+
+	tagIDs, err := qb.GetTagIDs(destination.ID)
+	if err != nil {
+		return err
+	}
+
+	galleryIDs, err := qb.GetGalleryIDs(destination.ID)
+	if err != nil {
+		return err
+	}
+
+	movies, err := qb.GetMovies(destination.ID)
+	if err != nil {
+		return err
+	}
+
+	stashIDs, err := getStashIDs(qb, destination.ID)
+	if err != nil {
+		return err
+	}
+
+	oDates, err := qb.GetODates(destination.ID)
+	if err != nil {
+		return err
+	}
+
+	playDates, err := qb.GetPlayDates(destination.ID)
+	if err != nil {
+		return err
+	}
+
+	cover, err := qb.GetCover(destination.ID)
+	if err != nil {
+		return err
+	}
+	coverChanged := false
+
+	updated := *destination
+	for _, s := range source {
+		if s.ID == destination.ID {
+			return errors.New("cannot merge where source == destination")
+		}
+
+		if err := mergeRelationships(qb, s.ID, &performerIDs, &tagIDs, &galleryIDs, &movies, &stashIDs); err != nil {
+			return err
+		}
+
+		sourceODates, err := qb.GetODates(s.ID)
+		if err != nil {
+			return err
+		}
+		oDates = append(oDates, sourceODates...)
+
+		sourcePlayDates, err := qb.GetPlayDates(s.ID)
+		if err != nil {
+			return err
+		}
+		playDates = append(playDates, sourcePlayDates...)
+
+		updated.OCounter += s.OCounter
+		updated.PlayCount += s.PlayCount
+		updated.PlayDuration += s.PlayDuration
+
+		// keep the destination cover, using the first source cover if the
+		// destination does not have one
+		if len(cover) == 0 {
+			cover, err = qb.GetCover(s.ID)
+			if err != nil {
+				return err
+			}
+			coverChanged = len(cover) > 0
+		}
+
+		if err := moveMarkers(mqb, s.ID, destination.ID); err != nil {
+			return err
+		}
+
+		if moveFiles {
+			if err := moveSceneFiles(qb, s, destination.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	updated.UpdatedAt = models.SQLiteTimestamp{Timestamp: time.Now()}
+	if _, err := qb.UpdateFull(updated); err != nil {
+		return err
+	}
+
+	if err := qb.UpdatePerformers(destination.ID, performerIDs); err != nil {
+		return err
+	}
+
+	if err := qb.UpdateTags(destination.ID, tagIDs); err != nil {
+		return err
+	}
+
+	if err := qb.UpdateGalleries(destination.ID, galleryIDs); err != nil {
+		return err
+	}
+
+	if err := qb.UpdateMovies(destination.ID, movies); err != nil {
+		return err
+	}
+
+	if err := qb.UpdateStashIDs(destination.ID, stashIDs); err != nil {
+		return err
+	}
+
+	if err := qb.UpdateODates(destination.ID, oDates); err != nil {
+		return err
+	}
+
+	if err := qb.UpdatePlayDates(destination.ID, playDates); err != nil {
+		return err
+	}
+
+	if coverChanged {
+		if err := qb.UpdateCover(destination.ID, cover); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func getStashIDs(qb models.SceneReader, sceneID int) ([]models.StashID, error) {
+	stashIDs, err := qb.GetStashIDs(sceneID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []models.StashID
+	for _, stashID := range stashIDs {
+		ret = append(ret, *stashID)
+	}
+
+	return ret, nil
+}
+
+func mergeRelationships(qb models.SceneReader, sourceID int, performerIDs, tagIDs, galleryIDs *[]int, movies *[]models.MoviesScenes, stashIDs *[]models.StashID) error {
+	ids, err := qb.GetPerformerIDs(sourceID)
+	if err != nil {
+		return err
+	}
+	*performerIDs = utils.IntAppendUniques(*performerIDs, ids)
+
+	ids, err = qb.GetTagIDs(sourceID)
+	if err != nil {
+		return err
+	}
+	*tagIDs = utils.IntAppendUniques(*tagIDs, ids)
+
+	ids, err = qb.GetGalleryIDs(sourceID)
+	if err != nil {
+		return err
+	}
+	*galleryIDs = utils.IntAppendUniques(*galleryIDs, ids)
+
+	sourceMovies, err := qb.GetMovies(sourceID)
+	if err != nil {
+		return err
+	}
+	for _, m := range sourceMovies {
+		if !hasMovie(*movies, m.MovieID) {
+			*movies = append(*movies, m)
+		}
+	}
+
+	sourceStashIDs, err := getStashIDs(qb, sourceID)
+	if err != nil {
+		return err
+	}
+	for _, s := range sourceStashIDs {
+		if !hasStashID(*stashIDs, s) {
+			*stashIDs = append(*stashIDs, s)
+		}
+	}
+
+	return nil
+}
+
+func hasMovie(movies []models.MoviesScenes, movieID int) bool {
+	for _, m := range movies {
+		if m.MovieID == movieID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasStashID(stashIDs []models.StashID, stashID models.StashID) bool {
+	for _, s := range stashIDs {
+		if s.Endpoint == stashID.Endpoint && s.StashID == stashID.StashID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func moveMarkers(mqb models.SceneMarkerReaderWriter, sourceID int, destinationID int) error {
+	markers, err := mqb.FindBySceneID(sourceID)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range markers {
+		m.SceneID = sql.NullInt64{Int64: int64(destinationID), Valid: true}
+		m.UpdatedAt = models.SQLiteTimestamp{Timestamp: time.Now()}
+		if _, err := mqb.Update(*m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveSceneFiles adds the primary file of the source scene as an additional
+// file of the destination scene, and moves the additional files of the
+// source scene to the destination.
+func moveSceneFiles(qb models.SceneReaderWriter, source *models.Scene, destinationID int) error {
+	files, err := qb.GetFiles(source.ID)
+	if err != nil {
+		return err
+	}
+
+	now := models.SQLiteTimestamp{Timestamp: time.Now()}
+	for _, f := range files {
+		f.SceneID = destinationID
+		f.UpdatedAt = now
+		if _, err := qb.UpdateFile(*f); err != nil {
+			return err
+		}
+	}
+
+	primary := models.NewSceneFileFromScene(*source)
+	primary.SceneID = destinationID
+	primary.CreatedAt = now
+	primary.UpdatedAt = now
+	_, err = qb.CreateFile(primary)
+	return err
+}
@@ -0,0 +1,169 @@
+package scene
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	mergeDestID   = 1
+	mergeSourceID = 2
+
+	mergeMarkerID = 3
+
+	mergeMovieID     = 4
+	mergeOtherMovie  = 5
+	mergePerformerID = 6
+	mergeTagID       = 7
+	mergeGalleryID   = 8
+
+	mergeSourcePath = "sourcePath"
+)
+
+var (
+	mergeDestCover   = []byte("destCover")
+	mergeSourceCover = []byte("sourceCover")
+	mergeODate       = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+	mergePlayDate    = time.Date(2002, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func mergeDestScene() *models.Scene {
+	return &models.Scene{
+		ID:        mergeDestID,
+		OCounter:  1,
+		PlayCount: 2,
+	}
+}
+
+func mergeSourceScene() *models.Scene {
+	return &models.Scene{
+		ID:        mergeSourceID,
+		Path:      mergeSourcePath,
+		OCounter:  3,
+		PlayCount: 4,
+	}
+}
+
+func mockMergeReads(mockSceneReader *mocks.SceneReaderWriter, destCover []byte) {
+	mockSceneReader.On("GetPerformerIDs", mergeDestID).Return([]int{mergePerformerID}, nil).Once()
+	mockSceneReader.On("GetTagIDs", mergeDestID).Return(nil, nil).Once()
+	mockSceneReader.On("GetGalleryIDs", mergeDestID).Return(nil, nil).Once()
+	mockSceneReader.On("GetMovies", mergeDestID).Return([]models.MoviesScenes{
+		{MovieID: mergeMovieID, SceneID: mergeDestID},
+	}, nil).Once()
+	mockSceneReader.On("GetStashIDs", mergeDestID).Return([]*models.StashID{
+		{Endpoint: "endpoint", StashID: "destStashID"},
+	}, nil).Once()
+	mockSceneReader.On("GetODates", mergeDestID).Return(nil, nil).Once()
+	mockSceneReader.On("GetPlayDates", mergeDestID).Return(nil, nil).Once()
+	mockSceneReader.On("GetCover", mergeDestID).Return(destCover, nil).Once()
+
+	mockSceneReader.On("GetPerformerIDs", mergeSourceID).Return([]int{mergePerformerID}, nil).Once()
+	mockSceneReader.On("GetTagIDs", mergeSourceID).Return([]int{mergeTagID}, nil).Once()
+	mockSceneReader.On("GetGalleryIDs", mergeSourceID).Return([]int{mergeGalleryID}, nil).Once()
+	mockSceneReader.On("GetMovies", mergeSourceID).Return([]models.MoviesScenes{
+		{MovieID: mergeMovieID, SceneID: mergeSourceID},
+		{MovieID: mergeOtherMovie, SceneID: mergeSourceID},
+	}, nil).Once()
+	mockSceneReader.On("GetStashIDs", mergeSourceID).Return([]*models.StashID{
+		{Endpoint: "endpoint", StashID: "destStashID"},
+		{Endpoint: "endpoint", StashID: "sourceStashID"},
+	}, nil).Once()
+	mockSceneReader.On("GetODates", mergeSourceID).Return([]time.Time{mergeODate}, nil).Once()
+	mockSceneReader.On("GetPlayDates", mergeSourceID).Return([]time.Time{mergePlayDate}, nil).Once()
+}
+
+func TestMerge(t *testing.T) {
+	mockSceneReader := &mocks.SceneReaderWriter{}
+	mockMarkerReader := &mocks.SceneMarkerReaderWriter{}
+
+	mockMergeReads(mockSceneReader, nil)
+	mockSceneReader.On("GetCover", mergeSourceID).Return(mergeSourceCover, nil).Once()
+
+	mockMarkerReader.On("FindBySceneID", mergeSourceID).Return([]*models.SceneMarker{
+		{ID: mergeMarkerID, SceneID: sql.NullInt64{Int64: mergeSourceID, Valid: true}},
+	}, nil).Once()
+	mockMarkerReader.On("Update", mock.MatchedBy(func(m models.SceneMarker) bool {
+		return m.ID == mergeMarkerID && m.SceneID.Int64 == mergeDestID
+	})).Return(nil, nil).Once()
+
+	mockSceneReader.On("GetFiles", mergeSourceID).Return([]*models.SceneFile{
+		{ID: 1, SceneID: mergeSourceID},
+	}, nil).Once()
+	mockSceneReader.On("UpdateFile", mock.MatchedBy(func(f models.SceneFile) bool {
+		return f.ID == 1 && f.SceneID == mergeDestID
+	})).Return(nil, nil).Once()
+	mockSceneReader.On("CreateFile", mock.MatchedBy(func(f models.SceneFile) bool {
+		return f.Path == mergeSourcePath && f.SceneID == mergeDestID
+	})).Return(nil, nil).Once()
+
+	mockSceneReader.On("UpdateFull", mock.MatchedBy(func(s models.Scene) bool {
+		return s.ID == mergeDestID && s.OCounter == 4 && s.PlayCount == 6
+	})).Return(nil, nil).Once()
+	mockSceneReader.On("UpdatePerformers", mergeDestID, []int{mergePerformerID}).Return(nil).Once()
+	mockSceneReader.On("UpdateTags", mergeDestID, []int{mergeTagID}).Return(nil).Once()
+	mockSceneReader.On("UpdateGalleries", mergeDestID, []int{mergeGalleryID}).Return(nil).Once()
+	mockSceneReader.On("UpdateMovies", mergeDestID, []models.MoviesScenes{
+		{MovieID: mergeMovieID, SceneID: mergeDestID},
+		{MovieID: mergeOtherMovie, SceneID: mergeSourceID},
+	}).Return(nil).Once()
+	mockSceneReader.On("UpdateStashIDs", mergeDestID, []models.StashID{
+		{Endpoint: "endpoint", StashID: "destStashID"},
+		{Endpoint: "endpoint", StashID: "sourceStashID"},
+	}).Return(nil).Once()
+	mockSceneReader.On("UpdateODates", mergeDestID, []time.Time{mergeODate}).Return(nil).Once()
+	mockSceneReader.On("UpdatePlayDates", mergeDestID, []time.Time{mergePlayDate}).Return(nil).Once()
+	mockSceneReader.On("UpdateCover", mergeDestID, mergeSourceCover).Return(nil).Once()
+
+	err := Merge(mockSceneReader, mockMarkerReader, []*models.Scene{mergeSourceScene()}, mergeDestScene(), true)
+	assert.Nil(t, err)
+
+	mockSceneReader.AssertExpectations(t)
+	mockMarkerReader.AssertExpectations(t)
+}
+
+func TestMergeKeepCover(t *testing.T) {
+	mockSceneReader := &mocks.SceneReaderWriter{}
+	mockMarkerReader := &mocks.SceneMarkerReaderWriter{}
+
+	mockMergeReads(mockSceneReader, mergeDestCover)
+	mockMarkerReader.On("FindBySceneID", mergeSourceID).Return(nil, nil).Once()
+
+	mockSceneReader.On("UpdateFull", mock.Anything).Return(nil, nil).Once()
+	mockSceneReader.On("UpdatePerformers", mergeDestID, mock.Anything).Return(nil).Once()
+	mockSceneReader.On("UpdateTags", mergeDestID, mock.Anything).Return(nil).Once()
+	mockSceneReader.On("UpdateGalleries", mergeDestID, mock.Anything).Return(nil).Once()
+	mockSceneReader.On("UpdateMovies", mergeDestID, mock.Anything).Return(nil).Once()
+	mockSceneReader.On("UpdateStashIDs", mergeDestID, mock.Anything).Return(nil).Once()
+	mockSceneReader.On("UpdateODates", mergeDestID, mock.Anything).Return(nil).Once()
+	mockSceneReader.On("UpdatePlayDates", mergeDestID, mock.Anything).Return(nil).Once()
+
+	// files are not moved, and the destination cover is not replaced
+	err := Merge(mockSceneReader, mockMarkerReader, []*models.Scene{mergeSourceScene()}, mergeDestScene(), false)
+	assert.Nil(t, err)
+
+	mockSceneReader.AssertExpectations(t)
+	mockMarkerReader.AssertExpectations(t)
+}
+
+func TestMergeErrors(t *testing.T) {
+	mockSceneReader := &mocks.SceneReaderWriter{}
+	mockMarkerReader := &mocks.SceneMarkerReaderWriter{}
+
+	// source == destination
+	mockMergeReads(mockSceneReader, nil)
+	err := Merge(mockSceneReader, mockMarkerReader, []*models.Scene{mergeDestScene()}, mergeDestScene(), false)
+	assert.NotNil(t, err)
+
+	mockSceneReader = &mocks.SceneReaderWriter{}
+	mockSceneReader.On("GetPerformerIDs", mergeDestID).Return(nil, errors.New("GetPerformerIDs error")).Once()
+	err = Merge(mockSceneReader, mockMarkerReader, []*models.Scene{mergeSourceScene()}, mergeDestScene(), false)
+	assert.NotNil(t, err)
+}
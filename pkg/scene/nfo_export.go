@@ -0,0 +1,106 @@
+package scene
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/nfo"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// nfoIDType is the type of the unique ID element holding the scene ID.
+const nfoIDType = "stash"
+
+// ToNFO converts a scene into its Kodi movie NFO equivalent. The first movie
+// of the scene is written as the set of the NFO.
+func ToNFO(reader models.SceneReader, studioReader models.StudioReader, movieReader models.MovieReader, performerReader models.PerformerReader, tagReader models.TagReader, scene *models.Scene) (*nfo.Video, error) {
+	ret := nfo.NewMovie()
+	ret.Title = scene.GetTitle()
+	ret.UniqueIDs = []nfo.UniqueID{
+		{
+			Type:    nfoIDType,
+			Default: true,
+			Value:   strconv.Itoa(scene.ID),
+		},
+	}
+
+	if scene.Details.Valid {
+		ret.Plot = scene.Details.String
+	}
+	if scene.Date.Valid {
+		ret.SetDate(utils.GetYMDFromDatabaseDate(scene.Date.String))
+	}
+	if scene.Rating.Valid {
+		ret.SetRating(int(scene.Rating.Int64))
+	}
+	if scene.Duration.Valid {
+		ret.SetRuntime(scene.Duration.Float64)
+	}
+
+	studio, err := GetStudioName(studioReader, scene)
+	if err != nil {
+		return nil, fmt.Errorf("error getting scene studio: %s", err.Error())
+	}
+	if studio != "" {
+		ret.Studios = []string{studio}
+	}
+
+	ret.Tags, err = GetTagNames(tagReader, scene)
+	if err != nil {
+		return nil, err
+	}
+
+	performers, err := performerReader.FindBySceneID(scene.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting scene performers: %s", err.Error())
+	}
+
+	for i, p := range performers {
+		if !p.Name.Valid {
+			continue
+		}
+
+		order := i
+		ret.Actors = append(ret.Actors, nfo.Actor{
+			Name:  p.Name.String,
+			Order: &order,
+		})
+	}
+
+	movies, err := GetSceneMoviesJSON(movieReader, reader, scene)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(movies) > 0 {
+		ret.Set = &nfo.Set{
+			Name: movies[0].MovieName,
+		}
+	}
+
+	return ret, nil
+}
+
+// GetNFOPoster returns the image to use as the poster of the scene, which is
+// the front image of the first movie of the scene, or the scene cover if the
+// movie has no front image.
+func GetNFOPoster(reader models.SceneReader, movieReader models.MovieReader, scene *models.Scene) ([]byte, error) {
+	movies, err := reader.GetMovies(scene.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting scene movies: %s", err.Error())
+	}
+
+	if len(movies) > 0 {
+		frontImage, err := movieReader.GetFrontImage(movies[0].MovieID)
+		if err != nil {
+			return nil, fmt.Errorf("error getting movie front image: %s", err.Error())
+		}
+
+		if len(frontImage) > 0 {
+			return frontImage, nil
+		}
+	}
+
+	return reader.GetCover(scene.ID)
+}
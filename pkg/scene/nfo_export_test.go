@@ -0,0 +1,111 @@
+package scene
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stashapp/stash/pkg/nfo"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	nfoPerformerName = "nfoPerformerName"
+	nfoStudioName    = "nfoStudioName"
+	nfoTagName       = "nfoTagName"
+	nfoMovieID       = 20
+	nfoMovieName     = "nfoMovieName"
+)
+
+func TestToNFO(t *testing.T) {
+	mockSceneReader := &mocks.SceneReaderWriter{}
+	mockStudioReader := &mocks.StudioReaderWriter{}
+	mockMovieReader := &mocks.MovieReaderWriter{}
+	mockPerformerReader := &mocks.PerformerReaderWriter{}
+	mockTagReader := &mocks.TagReaderWriter{}
+
+	s := models.Scene{
+		ID:       sceneID,
+		Title:    sql.NullString{String: title, Valid: true},
+		Details:  sql.NullString{String: details, Valid: true},
+		Date:     models.SQLiteDate{String: date, Valid: true},
+		Rating:   sql.NullInt64{Int64: rating, Valid: true},
+		Duration: sql.NullFloat64{Float64: 150, Valid: true},
+		StudioID: sql.NullInt64{Int64: studioID, Valid: true},
+	}
+
+	mockStudioReader.On("Find", studioID).Return(&models.Studio{
+		Name: sql.NullString{String: nfoStudioName, Valid: true},
+	}, nil).Once()
+	mockTagReader.On("FindBySceneID", sceneID).Return([]*models.Tag{
+		{Name: nfoTagName},
+	}, nil).Once()
+	mockPerformerReader.On("FindBySceneID", sceneID).Return([]*models.Performer{
+		{Name: sql.NullString{String: nfoPerformerName, Valid: true}},
+	}, nil).Once()
+	mockSceneReader.On("GetMovies", sceneID).Return([]models.MoviesScenes{
+		{MovieID: nfoMovieID},
+	}, nil).Once()
+	mockMovieReader.On("Find", nfoMovieID).Return(&models.Movie{
+		Name: sql.NullString{String: nfoMovieName, Valid: true},
+	}, nil).Once()
+
+	order := 0
+	expected := nfo.NewMovie()
+	expected.Title = title
+	expected.Plot = details
+	expected.Premiered = date
+	expected.Year = "2001"
+	expected.UserRating = "10"
+	expected.Runtime = "3"
+	expected.UniqueIDs = []nfo.UniqueID{{Type: nfoIDType, Default: true, Value: "1"}}
+	expected.Studios = []string{nfoStudioName}
+	expected.Tags = []string{nfoTagName}
+	expected.Actors = []nfo.Actor{{Name: nfoPerformerName, Order: &order}}
+	expected.Set = &nfo.Set{Name: nfoMovieName}
+
+	v, err := ToNFO(mockSceneReader, mockStudioReader, mockMovieReader, mockPerformerReader, mockTagReader, &s)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, v)
+
+	// errors are returned
+	mockStudioReader.On("Find", errStudioID).Return(nil, errors.New("error getting studio")).Once()
+	s.StudioID = sql.NullInt64{Int64: errStudioID, Valid: true}
+	_, err = ToNFO(mockSceneReader, mockStudioReader, mockMovieReader, mockPerformerReader, mockTagReader, &s)
+	assert.NotNil(t, err)
+
+	mockSceneReader.AssertExpectations(t)
+	mockStudioReader.AssertExpectations(t)
+	mockMovieReader.AssertExpectations(t)
+	mockPerformerReader.AssertExpectations(t)
+	mockTagReader.AssertExpectations(t)
+}
+
+func TestGetNFOPoster(t *testing.T) {
+	mockSceneReader := &mocks.SceneReaderWriter{}
+	mockMovieReader := &mocks.MovieReaderWriter{}
+
+	s := models.Scene{ID: sceneID}
+	noMovieScene := models.Scene{ID: noMoviesID}
+	frontImage := []byte("frontImage")
+
+	mockSceneReader.On("GetMovies", sceneID).Return([]models.MoviesScenes{
+		{MovieID: nfoMovieID},
+	}, nil).Once()
+	mockSceneReader.On("GetMovies", noMoviesID).Return(nil, nil).Once()
+	mockSceneReader.On("GetCover", noMoviesID).Return(imageBytes, nil).Once()
+	mockMovieReader.On("GetFrontImage", nfoMovieID).Return(frontImage, nil).Once()
+
+	poster, err := GetNFOPoster(mockSceneReader, mockMovieReader, &s)
+	assert.Nil(t, err)
+	assert.Equal(t, frontImage, poster)
+
+	poster, err = GetNFOPoster(mockSceneReader, mockMovieReader, &noMovieScene)
+	assert.Nil(t, err)
+	assert.Equal(t, imageBytes, poster)
+
+	mockSceneReader.AssertExpectations(t)
+	mockMovieReader.AssertExpectations(t)
+}
@@ -0,0 +1,240 @@
+package scene
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/nfo"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// NFOImporter applies the metadata read from the NFO files of a scene file
+// to a new scene. Missing studios, performers, movies and tags are created or
+// ignored according to MissingRefBehaviour.
+type NFOImporter struct {
+	ReaderWriter        models.SceneReaderWriter
+	StudioWriter        models.StudioReaderWriter
+	PerformerWriter     models.PerformerReaderWriter
+	MovieWriter         models.MovieReaderWriter
+	TagWriter           models.TagReaderWriter
+	Input               nfo.Metadata
+	MissingRefBehaviour models.ImportMissingRefEnum
+
+	// PerformerImages maps the IDs of the performers created by the import
+	// to the URLs of their thumbnails. The images are not downloaded by the
+	// importer so that this can be done outside of the transaction.
+	PerformerImages map[int]string
+
+	studioID   sql.NullInt64
+	performers []*models.Performer
+	movie      *models.MoviesScenes
+	tags       []*models.Tag
+}
+
+// PreImport finds or creates the referenced objects, and sets the fields of
+// the scene from the metadata. The scene is not modified if an error is
+// returned, but objects created before the error are not removed, so the
+// transaction should be rolled back.
+func (i *NFOImporter) PreImport(s *models.Scene) error {
+	if err := i.populateStudio(); err != nil {
+		return err
+	}
+
+	if err := i.populatePerformers(); err != nil {
+		return err
+	}
+
+	if err := i.populateMovie(); err != nil {
+		return err
+	}
+
+	if len(i.Input.Tags) > 0 {
+		tags, err := importTags(i.TagWriter, i.Input.Tags, i.MissingRefBehaviour)
+		if err != nil {
+			return err
+		}
+		i.tags = tags
+	}
+
+	if i.Input.Title != "" {
+		s.Title = sql.NullString{String: i.Input.Title, Valid: true}
+	}
+	if i.Input.Details != "" {
+		s.Details = sql.NullString{String: i.Input.Details, Valid: true}
+	}
+	if i.Input.Date != "" {
+		s.Date = models.SQLiteDate{String: i.Input.Date, Valid: true}
+	}
+	if i.Input.Rating != 0 {
+		s.Rating = sql.NullInt64{Int64: int64(i.Input.Rating), Valid: true}
+	}
+	if i.studioID.Valid {
+		s.StudioID = i.studioID
+	}
+
+	return nil
+}
+
+func (i *NFOImporter) populateStudio() error {
+	name := i.Input.Studio
+	if name == "" {
+		return nil
+	}
+
+	studio, err := i.StudioWriter.FindByName(name, false)
+	if err != nil {
+		return fmt.Errorf("error finding studio by name: %s", err.Error())
+	}
+
+	if studio == nil {
+		switch i.MissingRefBehaviour {
+		case models.ImportMissingRefEnumFail:
+			return fmt.Errorf("scene studio '%s' not found", name)
+		case models.ImportMissingRefEnumCreate:
+			studio, err = i.StudioWriter.Create(*models.NewStudio(name))
+			if err != nil {
+				return fmt.Errorf("error creating scene studio: %s", err.Error())
+			}
+		default:
+			return nil
+		}
+	}
+
+	i.studioID = sql.NullInt64{Int64: int64(studio.ID), Valid: true}
+	return nil
+}
+
+func (i *NFOImporter) populatePerformers() error {
+	if len(i.Input.Performers) == 0 {
+		return nil
+	}
+
+	var names []string
+	for _, a := range i.Input.Performers {
+		names = append(names, a.Name)
+	}
+
+	performers, err := i.PerformerWriter.FindByNames(names, false)
+	if err != nil {
+		return err
+	}
+
+	var pluckedNames []string
+	for _, performer := range performers {
+		if performer.Name.Valid {
+			pluckedNames = append(pluckedNames, performer.Name.String)
+		}
+	}
+
+	var missing []nfo.Actor
+	var missingNames []string
+	for _, a := range i.Input.Performers {
+		if !utils.StrInclude(pluckedNames, a.Name) && !utils.StrInclude(missingNames, a.Name) {
+			missing = append(missing, a)
+			missingNames = append(missingNames, a.Name)
+		}
+	}
+
+	if len(missing) > 0 {
+		if i.MissingRefBehaviour == models.ImportMissingRefEnumFail {
+			return fmt.Errorf("scene performers [%s] not found", strings.Join(missingNames, ", "))
+		}
+
+		if i.MissingRefBehaviour == models.ImportMissingRefEnumCreate {
+			for _, a := range missing {
+				created, err := i.PerformerWriter.Create(*models.NewPerformer(a.Name))
+				if err != nil {
+					return fmt.Errorf("error creating scene performers: %s", err.Error())
+				}
+
+				if a.Thumb != "" {
+					if i.PerformerImages == nil {
+						i.PerformerImages = make(map[int]string)
+					}
+					i.PerformerImages[created.ID] = a.Thumb
+				}
+
+				performers = append(performers, created)
+			}
+		}
+
+		// ignore if MissingRefBehaviour set to Ignore
+	}
+
+	i.performers = performers
+	return nil
+}
+
+func (i *NFOImporter) populateMovie() error {
+	name := i.Input.Movie
+	if name == "" {
+		return nil
+	}
+
+	movie, err := i.MovieWriter.FindByName(name, false)
+	if err != nil {
+		return fmt.Errorf("error finding scene movie: %s", err.Error())
+	}
+
+	if movie == nil {
+		switch i.MissingRefBehaviour {
+		case models.ImportMissingRefEnumFail:
+			return fmt.Errorf("scene movie [%s] not found", name)
+		case models.ImportMissingRefEnumCreate:
+			movie, err = i.MovieWriter.Create(*models.NewMovie(name))
+			if err != nil {
+				return fmt.Errorf("error creating scene movie: %s", err.Error())
+			}
+		default:
+			return nil
+		}
+	}
+
+	i.movie = &models.MoviesScenes{
+		MovieID: movie.ID,
+	}
+
+	if i.Input.SceneIndex != 0 {
+		i.movie.SceneIndex = sql.NullInt64{
+			Int64: int64(i.Input.SceneIndex),
+			Valid: true,
+		}
+	}
+
+	return nil
+}
+
+// PostImport sets the performers, movie and tags of the created scene.
+func (i *NFOImporter) PostImport(id int) error {
+	if len(i.performers) > 0 {
+		var performerIDs []int
+		for _, performer := range i.performers {
+			performerIDs = append(performerIDs, performer.ID)
+		}
+
+		if err := i.ReaderWriter.UpdatePerformers(id, performerIDs); err != nil {
+			return fmt.Errorf("failed to associate performers: %s", err.Error())
+		}
+	}
+
+	if i.movie != nil {
+		if err := i.ReaderWriter.UpdateMovies(id, []models.MoviesScenes{*i.movie}); err != nil {
+			return fmt.Errorf("failed to associate movies: %s", err.Error())
+		}
+	}
+
+	if len(i.tags) > 0 {
+		var tagIDs []int
+		for _, t := range i.tags {
+			tagIDs = append(tagIDs, t.ID)
+		}
+
+		if err := i.ReaderWriter.UpdateTags(id, tagIDs); err != nil {
+			return fmt.Errorf("failed to associate tags: %s", err.Error())
+		}
+	}
+
+	return nil
+}
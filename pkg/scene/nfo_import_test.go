@@ -0,0 +1,225 @@
+package scene
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stashapp/stash/pkg/nfo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	nfoTitle     = "nfoTitle"
+	nfoDetails   = "nfoDetails"
+	nfoDate      = "2001-02-03"
+	nfoRating    = 4
+	nfoThumb     = "http://example.com/thumb.jpg"
+	createdID    = 106
+	nfoSceneIdx  = 2
+	nfoCreateErr = "nfoCreateErr"
+)
+
+func TestNFOImporterPreImport(t *testing.T) {
+	studioReaderWriter := &mocks.StudioReaderWriter{}
+	performerReaderWriter := &mocks.PerformerReaderWriter{}
+	movieReaderWriter := &mocks.MovieReaderWriter{}
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	i := NFOImporter{
+		StudioWriter:    studioReaderWriter,
+		PerformerWriter: performerReaderWriter,
+		MovieWriter:     movieReaderWriter,
+		TagWriter:       tagReaderWriter,
+		Input: nfo.Metadata{
+			Title:   nfoTitle,
+			Details: nfoDetails,
+			Date:    nfoDate,
+			Rating:  nfoRating,
+			Studio:  existingStudioName,
+			Performers: []nfo.Actor{
+				{Name: existingPerformerName, Thumb: nfoThumb},
+			},
+			Movie:      existingMovieName,
+			SceneIndex: nfoSceneIdx,
+			Tags:       []string{existingTagName},
+		},
+		MissingRefBehaviour: models.ImportMissingRefEnumFail,
+	}
+
+	studioReaderWriter.On("FindByName", existingStudioName, false).Return(&models.Studio{
+		ID: existingStudioID,
+	}, nil).Once()
+	performerReaderWriter.On("FindByNames", []string{existingPerformerName}, false).Return([]*models.Performer{
+		{
+			ID:   existingPerformerID,
+			Name: sql.NullString{String: existingPerformerName, Valid: true},
+		},
+	}, nil).Once()
+	movieReaderWriter.On("FindByName", existingMovieName, false).Return(&models.Movie{
+		ID: existingMovieID,
+	}, nil).Once()
+	tagReaderWriter.On("FindByNames", []string{existingTagName}, false).Return([]*models.Tag{
+		{
+			ID:   existingTagID,
+			Name: existingTagName,
+		},
+	}, nil).Once()
+
+	s := models.Scene{}
+	err := i.PreImport(&s)
+	assert.Nil(t, err)
+	assert.Equal(t, models.Scene{
+		Title:    sql.NullString{String: nfoTitle, Valid: true},
+		Details:  sql.NullString{String: nfoDetails, Valid: true},
+		Date:     models.SQLiteDate{String: nfoDate, Valid: true},
+		Rating:   sql.NullInt64{Int64: nfoRating, Valid: true},
+		StudioID: sql.NullInt64{Int64: existingStudioID, Valid: true},
+	}, s)
+	assert.Equal(t, existingPerformerID, i.performers[0].ID)
+	assert.Equal(t, existingMovieID, i.movie.MovieID)
+	assert.Equal(t, int64(nfoSceneIdx), i.movie.SceneIndex.Int64)
+	assert.Equal(t, existingTagID, i.tags[0].ID)
+
+	// images are only set for created performers
+	assert.Len(t, i.PerformerImages, 0)
+
+	studioReaderWriter.AssertExpectations(t)
+	performerReaderWriter.AssertExpectations(t)
+	movieReaderWriter.AssertExpectations(t)
+	tagReaderWriter.AssertExpectations(t)
+}
+
+func TestNFOImporterPreImportWithMissing(t *testing.T) {
+	studioReaderWriter := &mocks.StudioReaderWriter{}
+	performerReaderWriter := &mocks.PerformerReaderWriter{}
+	movieReaderWriter := &mocks.MovieReaderWriter{}
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	newImporter := func(missingRefBehaviour models.ImportMissingRefEnum) *NFOImporter {
+		return &NFOImporter{
+			StudioWriter:    studioReaderWriter,
+			PerformerWriter: performerReaderWriter,
+			MovieWriter:     movieReaderWriter,
+			TagWriter:       tagReaderWriter,
+			Input: nfo.Metadata{
+				Title:  nfoTitle,
+				Studio: missingStudioName,
+				Performers: []nfo.Actor{
+					{Name: missingPerformerName, Thumb: nfoThumb},
+				},
+				Movie: missingMovieName,
+				Tags:  []string{missingTagName},
+			},
+			MissingRefBehaviour: missingRefBehaviour,
+		}
+	}
+
+	studioReaderWriter.On("FindByName", missingStudioName, false).Return(nil, nil)
+	studioReaderWriter.On("Create", mock.AnythingOfType("models.Studio")).Return(&models.Studio{
+		ID: createdID,
+	}, nil).Once()
+	performerReaderWriter.On("FindByNames", []string{missingPerformerName}, false).Return(nil, nil).Twice()
+	performerReaderWriter.On("Create", mock.AnythingOfType("models.Performer")).Return(&models.Performer{
+		ID: createdID,
+	}, nil).Once()
+	movieReaderWriter.On("FindByName", missingMovieName, false).Return(nil, nil).Twice()
+	movieReaderWriter.On("Create", mock.AnythingOfType("models.Movie")).Return(&models.Movie{
+		ID: createdID,
+	}, nil).Once()
+	tagReaderWriter.On("FindByNames", []string{missingTagName}, false).Return(nil, nil).Twice()
+	tagReaderWriter.On("Create", mock.AnythingOfType("models.Tag")).Return(&models.Tag{
+		ID: createdID,
+	}, nil).Once()
+
+	// the scene is not modified on failure
+	s := models.Scene{}
+	err := newImporter(models.ImportMissingRefEnumFail).PreImport(&s)
+	assert.NotNil(t, err)
+	assert.Equal(t, models.Scene{}, s)
+
+	i := newImporter(models.ImportMissingRefEnumIgnore)
+	err = i.PreImport(&s)
+	assert.Nil(t, err)
+	assert.Equal(t, nfoTitle, s.Title.String)
+	assert.False(t, s.StudioID.Valid)
+	assert.Len(t, i.performers, 0)
+	assert.Nil(t, i.movie)
+	assert.Len(t, i.tags, 0)
+
+	i = newImporter(models.ImportMissingRefEnumCreate)
+	err = i.PreImport(&s)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(createdID), s.StudioID.Int64)
+	assert.Equal(t, createdID, i.performers[0].ID)
+	assert.Equal(t, createdID, i.movie.MovieID)
+	assert.False(t, i.movie.SceneIndex.Valid)
+	assert.Equal(t, createdID, i.tags[0].ID)
+	assert.Equal(t, map[int]string{createdID: nfoThumb}, i.PerformerImages)
+
+	studioReaderWriter.AssertExpectations(t)
+	performerReaderWriter.AssertExpectations(t)
+	movieReaderWriter.AssertExpectations(t)
+	tagReaderWriter.AssertExpectations(t)
+}
+
+func TestNFOImporterPreImportWithMissingCreateErr(t *testing.T) {
+	studioReaderWriter := &mocks.StudioReaderWriter{}
+
+	i := NFOImporter{
+		StudioWriter: studioReaderWriter,
+		Input: nfo.Metadata{
+			Studio: missingStudioName,
+		},
+		MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+	}
+
+	studioReaderWriter.On("FindByName", missingStudioName, false).Return(nil, nil).Once()
+	studioReaderWriter.On("Create", mock.AnythingOfType("models.Studio")).Return(nil, errors.New(nfoCreateErr))
+
+	s := models.Scene{}
+	err := i.PreImport(&s)
+	assert.NotNil(t, err)
+}
+
+func TestNFOImporterPostImport(t *testing.T) {
+	readerWriter := &mocks.SceneReaderWriter{}
+
+	i := NFOImporter{
+		ReaderWriter: readerWriter,
+		performers: []*models.Performer{
+			{ID: existingPerformerID},
+		},
+		movie: &models.MoviesScenes{
+			MovieID: existingMovieID,
+		},
+		tags: []*models.Tag{
+			{ID: existingTagID},
+		},
+	}
+
+	updateErr := errors.New("UpdatePerformers error")
+
+	readerWriter.On("UpdatePerformers", sceneID, []int{existingPerformerID}).Return(nil).Once()
+	readerWriter.On("UpdatePerformers", errPerformersID, []int{existingPerformerID}).Return(updateErr).Once()
+	readerWriter.On("UpdateMovies", sceneID, []models.MoviesScenes{{MovieID: existingMovieID}}).Return(nil).Once()
+	readerWriter.On("UpdateTags", sceneID, []int{existingTagID}).Return(nil).Once()
+
+	err := i.PostImport(sceneID)
+	assert.Nil(t, err)
+
+	err = i.PostImport(errPerformersID)
+	assert.NotNil(t, err)
+
+	// nothing is updated without references
+	i = NFOImporter{
+		ReaderWriter: readerWriter,
+	}
+	err = i.PostImport(sceneID)
+	assert.Nil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/utils"
@@ -379,6 +381,135 @@ func intCriterionHandler(c *models.IntCriterionInput, column string) criterionHa
 	}
 }
 
+func timestampCriterionHandler(c *models.TimestampCriterionInput, column string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if c != nil {
+			clause, args, err := getTimestampCriterionClause(column, *c)
+			if err != nil {
+				f.setError(err)
+				return
+			}
+
+			f.addWhere(clause, args...)
+		}
+	}
+}
+
+// historyCriterionHandler filters on a table of timestamped events belonging
+// to the primary table. A row matches if any of its events match the
+// criterion. IS_NULL matches rows with no events, and the negated modifiers
+// match rows where none of the events match.
+func historyCriterionHandler(c *models.TimestampCriterionInput, primaryTable, historyTable, primaryFK, column string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if c == nil {
+			return
+		}
+
+		existsClause := "EXISTS"
+		criterion := *c
+		switch criterion.Modifier {
+		case models.CriterionModifierIsNull:
+			existsClause = "NOT EXISTS"
+		case models.CriterionModifierNotEquals:
+			existsClause = "NOT EXISTS"
+			criterion.Modifier = models.CriterionModifierEquals
+		case models.CriterionModifierNotBetween:
+			existsClause = "NOT EXISTS"
+			criterion.Modifier = models.CriterionModifierBetween
+		}
+
+		subQuery := fmt.Sprintf("SELECT 1 FROM %s WHERE %[1]s.%s = %s.id", historyTable, primaryFK, primaryTable)
+		var args []interface{}
+		if criterion.Modifier != models.CriterionModifierIsNull && criterion.Modifier != models.CriterionModifierNotNull {
+			var clause string
+			var err error
+			clause, args, err = getTimestampCriterionClause(historyTable+"."+column, criterion)
+			if err != nil {
+				f.setError(err)
+				return
+			}
+			subQuery += " AND " + clause
+		}
+
+		f.addWhere(fmt.Sprintf("%s (%s)", existsClause, subQuery), args...)
+	}
+}
+
+func getTimestampCriterionClause(column string, c models.TimestampCriterionInput) (string, []interface{}, error) {
+	switch c.Modifier {
+	case models.CriterionModifierIsNull, models.CriterionModifierNotNull:
+		clause, _ := getSimpleCriterionClause(c.Modifier, "")
+		return column + " " + clause, nil, nil
+	}
+
+	value, err := parseTimestampCriterionValue(c.Value)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch c.Modifier {
+	case models.CriterionModifierEquals:
+		return fmt.Sprintf("date(%s) = date(?)", column), []interface{}{value}, nil
+	case models.CriterionModifierNotEquals:
+		return fmt.Sprintf("(%[1]s IS NULL OR date(%[1]s) != date(?))", column), []interface{}{value}, nil
+	case models.CriterionModifierBetween, models.CriterionModifierNotBetween:
+		if c.Value2 == nil {
+			return "", nil, fmt.Errorf("value2 is required for %s modifier", c.Modifier.String())
+		}
+
+		value2, err := parseTimestampCriterionValue(*c.Value2)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if c.Modifier == models.CriterionModifierBetween {
+			return fmt.Sprintf("datetime(%s) BETWEEN datetime(?) AND datetime(?)", column), []interface{}{value, value2}, nil
+		}
+		return fmt.Sprintf("(%[1]s IS NULL OR datetime(%[1]s) NOT BETWEEN datetime(?) AND datetime(?))", column), []interface{}{value, value2}, nil
+	default:
+		clause, _ := getSimpleCriterionClause(c.Modifier, "datetime(?)")
+		return fmt.Sprintf("datetime(%s) %s", column, clause), []interface{}{value}, nil
+	}
+}
+
+var relativeTimestampRE = regexp.MustCompile(`^([+-]?\d+)([hdwmy])$`)
+
+// parseTimestampCriterionValue parses an absolute date or timestamp, or a
+// time relative to now such as -30d. Supported units are h (hours), d (days),
+// w (weeks), m (months) and y (years). The returned value is formatted as
+// RFC3339 in UTC.
+func parseTimestampCriterionValue(v string) (string, error) {
+	if m := relativeTimestampRE.FindStringSubmatch(v); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return "", err
+		}
+
+		t := time.Now()
+		switch m[2] {
+		case "h":
+			t = t.Add(time.Duration(n) * time.Hour)
+		case "d":
+			t = t.AddDate(0, 0, n)
+		case "w":
+			t = t.AddDate(0, 0, n*7)
+		case "m":
+			t = t.AddDate(0, n, 0)
+		case "y":
+			t = t.AddDate(n, 0, 0)
+		}
+
+		return t.UTC().Format(time.RFC3339), nil
+	}
+
+	t, err := utils.ParseDateStringAsTime(v)
+	if err != nil {
+		return "", err
+	}
+
+	return t.UTC().Format(time.RFC3339), nil
+}
+
 func boolCriterionHandler(c *bool, column string) criterionHandlerFunc {
 	return func(f *filterBuilder) {
 		if c != nil {
@@ -548,7 +679,7 @@ func addHierarchicalWithClause(f *filterBuilder, value []string, derivedTable, t
 		depthCondition = fmt.Sprintf("WHERE depth < %d", depth)
 	}
 
-	withClause := utils.StrFormat(`RECURSIVE {derivedTable} AS (
+	withClause := utils.StrFormat(`{derivedTable} AS (
 SELECT id as id, id as child_id, 0 as depth FROM {table} 
 WHERE id in {inBinding} 
 UNION SELECT p.id, c.id, depth + 1 FROM {table} as c 
@@ -143,6 +143,12 @@ func (qb *galleryQueryBuilder) FindByImageID(imageID int) ([]*models.Gallery, er
 	return qb.queryGalleries(query, args)
 }
 
+func (qb *galleryQueryBuilder) FindChildren(id int) ([]*models.Gallery, error) {
+	query := selectAll(galleryTable) + `WHERE galleries.parent_id = ?`
+	args := []interface{}{id}
+	return qb.queryGalleries(query, args)
+}
+
 func (qb *galleryQueryBuilder) CountByImageID(imageID int) (int, error) {
 	query := `SELECT image_id FROM galleries_images
 	WHERE image_id = ?
@@ -206,6 +212,7 @@ func (qb *galleryQueryBuilder) makeFilter(galleryFilter *models.GalleryFilterTyp
 	query.handleCriterion(stringCriterionHandler(galleryFilter.Title, "galleries.title"))
 	query.handleCriterion(stringCriterionHandler(galleryFilter.Details, "galleries.details"))
 	query.handleCriterion(stringCriterionHandler(galleryFilter.Checksum, "galleries.checksum"))
+	query.handleCriterion(boolCriterionHandler(galleryFilter.IsArchive, "galleries.zip"))
 	query.handleCriterion(boolCriterionHandler(galleryFilter.IsZip, "galleries.zip"))
 	query.handleCriterion(stringCriterionHandler(galleryFilter.Path, "galleries.path"))
 	query.handleCriterion(intCriterionHandler(galleryFilter.Rating, "galleries.rating"))
@@ -217,6 +224,7 @@ func (qb *galleryQueryBuilder) makeFilter(galleryFilter *models.GalleryFilterTyp
 	query.handleCriterion(galleryPerformersCriterionHandler(qb, galleryFilter.Performers))
 	query.handleCriterion(galleryPerformerCountCriterionHandler(qb, galleryFilter.PerformerCount))
 	query.handleCriterion(galleryStudioCriterionHandler(qb, galleryFilter.Studios))
+	query.handleCriterion(galleryParentsCriterionHandler(qb, galleryFilter.Galleries))
 	query.handleCriterion(galleryPerformerTagsCriterionHandler(qb, galleryFilter.PerformerTags))
 	query.handleCriterion(galleryAverageResolutionCriterionHandler(qb, galleryFilter.AverageResolution))
 	query.handleCriterion(galleryImageCountCriterionHandler(qb, galleryFilter.ImageCount))
@@ -397,6 +405,18 @@ func galleryStudioCriterionHandler(qb *galleryQueryBuilder, studios *models.Hier
 	return h.handler(studios)
 }
 
+func galleryParentsCriterionHandler(qb *galleryQueryBuilder, galleries *models.HierarchicalMultiCriterionInput) criterionHandlerFunc {
+	h := hierarchicalMultiCriterionHandlerBuilder{
+		primaryTable: galleryTable,
+		foreignTable: galleryTable,
+		foreignFK:    "id",
+		derivedTable: "parent_galleries",
+		parentFK:     "parent_id",
+	}
+
+	return h.handler(galleries)
+}
+
 func galleryPerformerTagsCriterionHandler(qb *galleryQueryBuilder, performerTagsFilter *models.MultiCriterionInput) criterionHandlerFunc {
 	return func(f *filterBuilder) {
 		if performerTagsFilter != nil && len(performerTagsFilter.Value) > 0 {
@@ -115,6 +115,31 @@ func TestGalleryFindBySceneID(t *testing.T) {
 	})
 }
 
+func TestGalleryFindChildren(t *testing.T) {
+	withTxn(func(r models.Repository) error {
+		gqb := r.Gallery()
+
+		galleries, err := gqb.FindChildren(galleryIDs[galleryIdxWithGrandChild])
+
+		if err != nil {
+			t.Errorf("Error finding children: %s", err.Error())
+		}
+
+		assert.Len(t, galleries, 1)
+		assert.Equal(t, galleryIDs[galleryIdxWithParentAndChild], galleries[0].ID)
+
+		galleries, err = gqb.FindChildren(galleryIDs[galleryIdxWithGrandParent])
+
+		if err != nil {
+			t.Errorf("Error finding children: %s", err.Error())
+		}
+
+		assert.Len(t, galleries, 0)
+
+		return nil
+	})
+}
+
 func TestGalleryQueryQ(t *testing.T) {
 	withTxn(func(r models.Repository) error {
 		const galleryIdx = 0
@@ -772,6 +797,87 @@ func TestGalleryQueryStudioDepth(t *testing.T) {
 	})
 }
 
+func TestGalleryQueryGalleriesDepth(t *testing.T) {
+	withTxn(func(r models.Repository) error {
+		sqb := r.Gallery()
+		galleriesCriterion := models.HierarchicalMultiCriterionInput{
+			Value: []string{
+				strconv.Itoa(galleryIDs[galleryIdxWithGrandChild]),
+			},
+			Modifier: models.CriterionModifierIncludes,
+			Depth:    0,
+		}
+
+		galleryFilter := models.GalleryFilterType{
+			Galleries: &galleriesCriterion,
+		}
+
+		galleries := queryGallery(t, sqb, &galleryFilter, nil)
+		assert.Len(t, galleries, 1)
+		assert.Equal(t, galleryIDs[galleryIdxWithGrandChild], galleries[0].ID)
+
+		galleriesCriterion.Depth = 1
+		galleries = queryGallery(t, sqb, &galleryFilter, nil)
+		assert.Len(t, galleries, 2)
+
+		galleriesCriterion.Depth = -1
+		galleries = queryGallery(t, sqb, &galleryFilter, nil)
+		assert.Len(t, galleries, 3)
+
+		galleriesCriterion.Value = []string{strconv.Itoa(galleryIDs[galleryIdxWithParentAndChild])}
+		galleries = queryGallery(t, sqb, &galleryFilter, nil)
+		assert.Len(t, galleries, 2)
+
+		galleriesCriterion = models.HierarchicalMultiCriterionInput{
+			Value: []string{
+				strconv.Itoa(galleryIDs[galleryIdxWithGrandChild]),
+			},
+			Modifier: models.CriterionModifierExcludes,
+			Depth:    1,
+		}
+
+		q := getGalleryStringValue(galleryIdxWithGrandParent, pathField)
+		findFilter := models.FindFilterType{
+			Q: &q,
+		}
+
+		galleries = queryGallery(t, sqb, &galleryFilter, &findFilter)
+		assert.Len(t, galleries, 1)
+
+		galleriesCriterion.Depth = 2
+		galleries = queryGallery(t, sqb, &galleryFilter, &findFilter)
+		assert.Len(t, galleries, 0)
+
+		return nil
+	})
+}
+
+func TestGalleryQueryGalleriesAndStudios(t *testing.T) {
+	withTxn(func(r models.Repository) error {
+		sqb := r.Gallery()
+
+		// both criteria use recursive with clauses
+		galleryFilter := models.GalleryFilterType{
+			Galleries: &models.HierarchicalMultiCriterionInput{
+				Value:    []string{strconv.Itoa(galleryIDs[galleryIdxWithGrandChild])},
+				Modifier: models.CriterionModifierExcludes,
+				Depth:    -1,
+			},
+			Studios: &models.HierarchicalMultiCriterionInput{
+				Value:    []string{strconv.Itoa(studioIDs[studioIdxWithGrandChild])},
+				Modifier: models.CriterionModifierIncludes,
+				Depth:    -1,
+			},
+		}
+
+		galleries := queryGallery(t, sqb, &galleryFilter, nil)
+		assert.Len(t, galleries, 1)
+		assert.Equal(t, galleryIDs[galleryIdxWithGrandChildStudio], galleries[0].ID)
+
+		return nil
+	})
+}
+
 func TestGalleryQueryPerformerTags(t *testing.T) {
 	withTxn(func(r models.Repository) error {
 		sqb := r.Gallery()
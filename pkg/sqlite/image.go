@@ -3,14 +3,22 @@ package sqlite
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
 )
 
 const imageTable = "images"
 const imageIDColumn = "image_id"
 const performersImagesTable = "performers_images"
 const imagesTagsTable = "images_tags"
+const imagesODatesTable = "images_o_dates"
+const imagesKeywordsTable = "images_keywords"
+const imageKeywordColumn = "keyword"
 
 var imagesForGalleryQuery = selectAll(imageTable) + `
 LEFT JOIN galleries_images as galleries_join on galleries_join.image_id = images.id
@@ -24,6 +32,18 @@ WHERE gallery_id = ?
 GROUP BY image_id
 `
 
+var findExactDuplicateImagesQuery = `
+SELECT GROUP_CONCAT(id) as ids
+FROM images
+WHERE phash IS NOT NULL
+GROUP BY phash
+HAVING COUNT(id) > 1;
+`
+
+var findAllImagePhashesQuery = `
+SELECT id, phash FROM images WHERE phash IS NOT NULL
+`
+
 type imageQueryBuilder struct {
 	repository
 }
@@ -74,6 +94,10 @@ func (qb *imageQueryBuilder) IncrementOCounter(id int) (int, error) {
 		return 0, err
 	}
 
+	if _, err := qb.oDatesRepository().insert(id, time.Now()); err != nil {
+		return 0, err
+	}
+
 	image, err := qb.find(id)
 	if err != nil {
 		return 0, err
@@ -91,6 +115,10 @@ func (qb *imageQueryBuilder) DecrementOCounter(id int) (int, error) {
 		return 0, err
 	}
 
+	if err := qb.oDatesRepository().destroyLatest(id); err != nil {
+		return 0, err
+	}
+
 	image, err := qb.find(id)
 	if err != nil {
 		return 0, err
@@ -108,6 +136,10 @@ func (qb *imageQueryBuilder) ResetOCounter(id int) (int, error) {
 		return 0, err
 	}
 
+	if err := qb.oDatesRepository().destroy([]int{id}); err != nil {
+		return 0, err
+	}
+
 	image, err := qb.find(id)
 	if err != nil {
 		return 0, err
@@ -165,6 +197,54 @@ func (qb *imageQueryBuilder) FindByPath(path string) (*models.Image, error) {
 	return qb.queryImage(query, args)
 }
 
+func (qb *imageQueryBuilder) FindDuplicates(distance int) ([][]*models.Image, error) {
+	var dupeIds [][]int
+	if distance == 0 {
+		var ids []string
+		if err := qb.tx.Select(&ids, findExactDuplicateImagesQuery); err != nil {
+			return nil, err
+		}
+
+		for _, id := range ids {
+			strIds := strings.Split(id, ",")
+			var imageIds []int
+			for _, strId := range strIds {
+				if intId, err := strconv.Atoi(strId); err == nil {
+					imageIds = append(imageIds, intId)
+				}
+			}
+			dupeIds = append(dupeIds, imageIds)
+		}
+	} else {
+		var hashes []*utils.Phash
+
+		if err := qb.queryFunc(findAllImagePhashesQuery, nil, func(rows *sqlx.Rows) error {
+			phash := utils.Phash{
+				Bucket: -1,
+			}
+			if err := rows.StructScan(&phash); err != nil {
+				return err
+			}
+
+			hashes = append(hashes, &phash)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+
+		dupeIds = utils.FindDuplicates(hashes, distance)
+	}
+
+	var duplicates [][]*models.Image
+	for _, imageIds := range dupeIds {
+		if images, err := qb.FindMany(imageIds); err == nil {
+			duplicates = append(duplicates, images)
+		}
+	}
+
+	return duplicates, nil
+}
+
 func (qb *imageQueryBuilder) FindByGalleryID(galleryID int) ([]*models.Image, error) {
 	args := []interface{}{galleryID}
 	return qb.queryImages(imagesForGalleryQuery+qb.getImageSort(nil), args)
@@ -236,9 +316,20 @@ func (qb *imageQueryBuilder) makeFilter(imageFilter *models.ImageFilterType) *fi
 	query.handleCriterion(stringCriterionHandler(imageFilter.Path, "images.path"))
 	query.handleCriterion(intCriterionHandler(imageFilter.Rating, "images.rating"))
 	query.handleCriterion(intCriterionHandler(imageFilter.OCounter, "images.o_counter"))
+	query.handleCriterion(historyCriterionHandler(imageFilter.OHistory, imageTable, imagesODatesTable, imageIDColumn, "o_date"))
 	query.handleCriterion(boolCriterionHandler(imageFilter.Organized, "images.organized"))
+	query.handleCriterion(boolCriterionHandler(imageFilter.Clip, "images.clip"))
 	query.handleCriterion(resolutionCriterionHandler(imageFilter.Resolution, "images.height", "images.width"))
 	query.handleCriterion(imageIsMissingCriterionHandler(qb, imageFilter.IsMissing))
+	query.handleCriterion(timestampCriterionHandler(imageFilter.Date, "images.date"))
+	query.handleCriterion(timestampCriterionHandler(imageFilter.CapturedAt, "images.captured_at"))
+	query.handleCriterion(stringCriterionHandler(imageFilter.CameraMake, "images.camera_make"))
+	query.handleCriterion(stringCriterionHandler(imageFilter.CameraModel, "images.camera_model"))
+	query.handleCriterion(stringCriterionHandler(imageFilter.Lens, "images.lens"))
+	query.handleCriterion(intCriterionHandler(imageFilter.Iso, "images.iso"))
+	query.handleCriterion(stringCriterionHandler(imageFilter.Creator, "images.creator"))
+	query.handleCriterion(imageHasGPSCriterionHandler(imageFilter.HasGps))
+	query.handleCriterion(imageKeywordsCriterionHandler(qb, imageFilter.Keywords))
 
 	query.handleCriterion(imageTagsCriterionHandler(qb, imageFilter.Tags))
 	query.handleCriterion(imageTagCountCriterionHandler(qb, imageFilter.TagCount))
@@ -337,6 +428,30 @@ func imageIsMissingCriterionHandler(qb *imageQueryBuilder, isMissing *string) cr
 	}
 }
 
+func imageHasGPSCriterionHandler(hasGPS *bool) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if hasGPS != nil {
+			if *hasGPS {
+				f.addWhere("images.gps_latitude IS NOT NULL AND images.gps_longitude IS NOT NULL")
+			} else {
+				f.addWhere("(images.gps_latitude IS NULL OR images.gps_longitude IS NULL)")
+			}
+		}
+	}
+}
+
+func imageKeywordsCriterionHandler(qb *imageQueryBuilder, keywords *models.StringCriterionInput) criterionHandlerFunc {
+	h := stringListCriterionHandlerBuilder{
+		joinTable:    imagesKeywordsTable,
+		stringColumn: imageKeywordColumn,
+		addJoinTable: func(f *filterBuilder) {
+			qb.keywordsRepository().join(f, "", "images.id")
+		},
+	}
+
+	return h.handler(keywords)
+}
+
 func (qb *imageQueryBuilder) getMultiCriterionHandlerBuilder(foreignTable, joinTable, foreignFK string, addJoinsFunc func(f *filterBuilder)) multiCriterionHandlerBuilder {
 	return multiCriterionHandlerBuilder{
 		primaryTable: imageTable,
@@ -544,3 +659,41 @@ func (qb *imageQueryBuilder) UpdateTags(imageID int, tagIDs []int) error {
 	// Delete the existing joins and then create new ones
 	return qb.tagsRepository().replace(imageID, tagIDs)
 }
+
+func (qb *imageQueryBuilder) oDatesRepository() *timestampRepository {
+	return &timestampRepository{
+		repository: repository{
+			tx:        qb.tx,
+			tableName: imagesODatesTable,
+			idColumn:  imageIDColumn,
+		},
+		timestampColumn: "o_date",
+	}
+}
+
+func (qb *imageQueryBuilder) GetODates(imageID int) ([]time.Time, error) {
+	return qb.oDatesRepository().get(imageID)
+}
+
+func (qb *imageQueryBuilder) UpdateODates(imageID int, dates []time.Time) error {
+	return qb.oDatesRepository().replace(imageID, dates)
+}
+
+func (qb *imageQueryBuilder) keywordsRepository() *stringRepository {
+	return &stringRepository{
+		repository: repository{
+			tx:        qb.tx,
+			tableName: imagesKeywordsTable,
+			idColumn:  imageIDColumn,
+		},
+		stringColumn: imageKeywordColumn,
+	}
+}
+
+func (qb *imageQueryBuilder) GetKeywords(imageID int) ([]string, error) {
+	return qb.keywordsRepository().get(imageID)
+}
+
+func (qb *imageQueryBuilder) UpdateKeywords(imageID int, keywords []string) error {
+	return qb.keywordsRepository().replace(imageID, keywords)
+}
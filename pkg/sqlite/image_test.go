@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -1114,10 +1115,172 @@ func TestImageQueryPagination(t *testing.T) {
 	})
 }
 
+func TestImageQueryFileMetadata(t *testing.T) {
+	withRollbackTxn(func(r models.Repository) error {
+		sqb := r.Image()
+		imageID := imageIDs[imageIdxWithStudio]
+
+		capturedAt := time.Date(2019, 5, 4, 13, 14, 15, 0, time.UTC)
+		if _, err := sqb.Update(models.ImagePartial{
+			ID:           imageID,
+			Date:         &models.SQLiteDate{String: "2019-05-04", Valid: true},
+			CapturedAt:   &models.NullSQLiteTimestamp{Timestamp: capturedAt, Valid: true},
+			CameraMake:   &sql.NullString{String: "Canon", Valid: true},
+			CameraModel:  &sql.NullString{String: "Canon EOS 5D", Valid: true},
+			ISO:          &sql.NullInt64{Int64: 400, Valid: true},
+			GPSLatitude:  &sql.NullFloat64{Float64: 51.5, Valid: true},
+			GPSLongitude: &sql.NullFloat64{Float64: -0.1, Valid: true},
+		}); err != nil {
+			t.Errorf("Error updating image: %s", err.Error())
+			return nil
+		}
+
+		keywords := []string{"beach", "sunset"}
+		if err := sqb.UpdateKeywords(imageID, keywords); err != nil {
+			t.Errorf("Error updating keywords: %s", err.Error())
+			return nil
+		}
+
+		got, err := sqb.GetKeywords(imageID)
+		if err != nil {
+			t.Errorf("Error getting keywords: %s", err.Error())
+		}
+		assert.Equal(t, keywords, got)
+
+		hasGPS := true
+		filters := []models.ImageFilterType{
+			{
+				Date: &models.TimestampCriterionInput{
+					Value:    "2019-05-04",
+					Modifier: models.CriterionModifierEquals,
+				},
+			},
+			{
+				CapturedAt: &models.TimestampCriterionInput{
+					Value:    "2019-05-01",
+					Modifier: models.CriterionModifierGreaterThan,
+				},
+			},
+			{
+				CameraMake: &models.StringCriterionInput{
+					Value:    "canon",
+					Modifier: models.CriterionModifierEquals,
+				},
+			},
+			{
+				CameraModel: &models.StringCriterionInput{
+					Value:    "5D",
+					Modifier: models.CriterionModifierIncludes,
+				},
+			},
+			{
+				Iso: &models.IntCriterionInput{
+					Value:    200,
+					Modifier: models.CriterionModifierGreaterThan,
+				},
+			},
+			{
+				HasGps: &hasGPS,
+			},
+			{
+				Keywords: &models.StringCriterionInput{
+					Value:    "sunset",
+					Modifier: models.CriterionModifierEquals,
+				},
+			},
+		}
+
+		for _, f := range filters {
+			filter := f
+			images := queryImages(t, sqb, &filter, nil)
+			if assert.Len(t, images, 1) {
+				assert.Equal(t, imageID, images[0].ID)
+			}
+		}
+
+		hasGPS = false
+		images := queryImages(t, sqb, &models.ImageFilterType{
+			HasGps: &hasGPS,
+		}, nil)
+		assert.Len(t, images, totalImages-1)
+
+		images = queryImages(t, sqb, &models.ImageFilterType{
+			Keywords: &models.StringCriterionInput{
+				Value:    "mountain",
+				Modifier: models.CriterionModifierEquals,
+			},
+		}, nil)
+		assert.Len(t, images, 0)
+
+		return nil
+	})
+}
+
 // TODO Update
 // TODO IncrementOCounter
 // TODO DecrementOCounter
 // TODO ResetOCounter
+func TestImageFindDuplicates(t *testing.T) {
+	withRollbackTxn(func(r models.Repository) error {
+		sqb := r.Image()
+
+		const hash int64 = 0x0f0f0f0f0f0f0f0f
+		phashes := map[int]int64{
+			imageIdxWithGallery:  hash,
+			imageIdx1WithGallery: hash,
+			// 3 bits different
+			imageIdxWithTwoGalleries: hash ^ 0x7,
+			// all bits different
+			imageIdxWithTag: ^hash,
+		}
+
+		for idx, phash := range phashes {
+			if _, err := sqb.Update(models.ImagePartial{
+				ID:    imageIDs[idx],
+				Phash: &sql.NullInt64{Int64: phash, Valid: true},
+			}); err != nil {
+				t.Errorf("Error updating image: %s", err.Error())
+				return nil
+			}
+		}
+
+		getIDs := func(images []*models.Image) []int {
+			var ret []int
+			for _, i := range images {
+				ret = append(ret, i.ID)
+			}
+			return ret
+		}
+
+		duplicates, err := sqb.FindDuplicates(0)
+		if err != nil {
+			t.Errorf("Error finding duplicates: %s", err.Error())
+		}
+
+		if assert.Len(t, duplicates, 1) {
+			assert.ElementsMatch(t, []int{
+				imageIDs[imageIdxWithGallery],
+				imageIDs[imageIdx1WithGallery],
+			}, getIDs(duplicates[0]))
+		}
+
+		duplicates, err = sqb.FindDuplicates(4)
+		if err != nil {
+			t.Errorf("Error finding duplicates: %s", err.Error())
+		}
+
+		if assert.Len(t, duplicates, 1) {
+			assert.ElementsMatch(t, []int{
+				imageIDs[imageIdxWithGallery],
+				imageIDs[imageIdx1WithGallery],
+				imageIDs[imageIdxWithTwoGalleries],
+			}, getIDs(duplicates[0]))
+		}
+
+		return nil
+	})
+}
+
 // TODO Destroy
 // TODO FindByChecksum
 // TODO Count
@@ -45,7 +45,9 @@ func (qb queryBuilder) executeCount() (int, error) {
 
 	withClause := ""
 	if len(qb.withClauses) > 0 {
-		withClause = "WITH " + strings.Join(qb.withClauses, ", ") + " "
+		// recursive clauses may only be used if the RECURSIVE keyword
+		// follows WITH, and it is permitted for non-recursive clauses
+		withClause = "WITH RECURSIVE " + strings.Join(qb.withClauses, ", ") + " "
 	}
 
 	body = qb.repository.buildQueryBody(body, qb.whereClauses, qb.havingClauses)
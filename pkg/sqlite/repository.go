@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 
@@ -251,7 +252,9 @@ func (r *repository) executeFindQuery(body string, args []interface{}, sortAndPa
 
 	withClause := ""
 	if len(withClauses) > 0 {
-		withClause = "WITH " + strings.Join(withClauses, ", ") + " "
+		// recursive clauses may only be used if the RECURSIVE keyword
+		// follows WITH, and it is permitted for non-recursive clauses
+		withClause = "WITH RECURSIVE " + strings.Join(withClauses, ", ") + " "
 	}
 
 	countQuery := withClause + r.buildCountQuery(body)
@@ -387,6 +390,113 @@ func (r *stringRepository) replace(id int, newStrings []string) error {
 	return nil
 }
 
+type timestampRepository struct {
+	repository
+	timestampColumn string
+}
+
+func (r *timestampRepository) get(id int) ([]time.Time, error) {
+	query := fmt.Sprintf("SELECT %s from %s WHERE %s = ? ORDER BY %[1]s ASC", r.timestampColumn, r.tableName, r.idColumn)
+	var ret []time.Time
+	err := r.queryFunc(query, []interface{}{id}, func(rows *sqlx.Rows) error {
+		var out models.SQLiteTimestamp
+		if err := rows.Scan(&out); err != nil {
+			return err
+		}
+
+		ret = append(ret, out.Timestamp)
+		return nil
+	})
+	return ret, err
+}
+
+func (r *timestampRepository) insert(id int, t time.Time) (sql.Result, error) {
+	stmt := fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES (?, ?)", r.tableName, r.idColumn, r.timestampColumn)
+	return r.tx.Exec(stmt, id, models.SQLiteTimestamp{Timestamp: t})
+}
+
+// destroyLatest removes the most recent timestamp for the provided id.
+func (r *timestampRepository) destroyLatest(id int) error {
+	stmt := fmt.Sprintf("DELETE FROM %[1]s WHERE rowid = (SELECT rowid FROM %[1]s WHERE %[2]s = ? ORDER BY %[3]s DESC LIMIT 1)", r.tableName, r.idColumn, r.timestampColumn)
+	_, err := r.tx.Exec(stmt, id)
+	return err
+}
+
+func (r *timestampRepository) replace(id int, newTimes []time.Time) error {
+	if err := r.destroy([]int{id}); err != nil {
+		return err
+	}
+
+	for _, t := range newTimes {
+		if _, err := r.insert(id, t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type floatRepository struct {
+	repository
+	valueColumn string
+}
+
+func (r *floatRepository) get(id int) ([]float64, error) {
+	query := fmt.Sprintf("SELECT %s from %s WHERE %s = ? ORDER BY %[1]s ASC", r.valueColumn, r.tableName, r.idColumn)
+	var ret []float64
+	err := r.queryFunc(query, []interface{}{id}, func(rows *sqlx.Rows) error {
+		var out float64
+		if err := rows.Scan(&out); err != nil {
+			return err
+		}
+
+		ret = append(ret, out)
+		return nil
+	})
+	return ret, err
+}
+
+func (r *floatRepository) replace(id int, values []float64) error {
+	if err := r.destroy([]int{id}); err != nil {
+		return err
+	}
+
+	stmt := fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES (?, ?)", r.tableName, r.idColumn, r.valueColumn)
+	for _, v := range values {
+		if _, err := r.tx.Exec(stmt, id, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type phashFrameRepository struct {
+	repository
+}
+
+func (r *phashFrameRepository) get(id int) ([]*models.ScenePhashFrame, error) {
+	query := fmt.Sprintf("SELECT seconds, phash from %s WHERE %s = ? ORDER BY seconds ASC", r.tableName, r.idColumn)
+	var ret models.ScenePhashFrames
+	err := r.query(query, []interface{}{id}, &ret)
+	return []*models.ScenePhashFrame(ret), err
+}
+
+func (r *phashFrameRepository) replace(id int, frames []models.ScenePhashFrame) error {
+	if err := r.destroy([]int{id}); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s, seconds, phash) VALUES (?, ?, ?)", r.tableName, r.idColumn)
+	for _, f := range frames {
+		_, err := r.tx.Exec(query, id, f.Seconds, f.Phash)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type stashIDRepository struct {
 	repository
 }
@@ -423,6 +533,58 @@ func (r *stashIDRepository) replace(id int, newIDs []models.StashID) error {
 	return nil
 }
 
+type captionRepository struct {
+	repository
+}
+
+func (r *captionRepository) get(id int) ([]*models.SceneCaption, error) {
+	query := fmt.Sprintf("SELECT language_code, filename, caption_type, stream_index from %s WHERE %s = ? ORDER BY stream_index ASC, filename ASC", r.tableName, r.idColumn)
+	var ret models.SceneCaptions
+	err := r.query(query, []interface{}{id}, &ret)
+	return []*models.SceneCaption(ret), err
+}
+
+func (r *captionRepository) replace(id int, captions []models.SceneCaption) error {
+	if err := r.destroy([]int{id}); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s, language_code, filename, caption_type, stream_index) VALUES (?, ?, ?, ?, ?)", r.tableName, r.idColumn)
+	for _, c := range captions {
+		_, err := r.tx.Exec(query, id, c.LanguageCode, c.Filename, c.CaptionType, c.StreamIndex)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type audioTrackRepository struct {
+	repository
+}
+
+func (r *audioTrackRepository) get(id int) ([]*models.SceneAudioTrack, error) {
+	query := fmt.Sprintf("SELECT stream_index, language_code, codec, channels from %s WHERE %s = ? ORDER BY stream_index ASC", r.tableName, r.idColumn)
+	var ret models.SceneAudioTracks
+	err := r.query(query, []interface{}{id}, &ret)
+	return []*models.SceneAudioTrack(ret), err
+}
+
+func (r *audioTrackRepository) replace(id int, tracks []models.SceneAudioTrack) error {
+	if err := r.destroy([]int{id}); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s, stream_index, language_code, codec, channels) VALUES (?, ?, ?, ?, ?)", r.tableName, r.idColumn)
+	for _, t := range tracks {
+		_, err := r.tx.Exec(query, id, t.StreamIndex, t.LanguageCode, t.Codec, t.Channels)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func listKeys(i interface{}, addPrefix bool) string {
 	var query []string
 	v := reflect.ValueOf(i)
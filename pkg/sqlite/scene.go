@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/stashapp/stash/pkg/models"
@@ -17,6 +18,13 @@ const performersScenesTable = "performers_scenes"
 const scenesTagsTable = "scenes_tags"
 const scenesGalleriesTable = "scenes_galleries"
 const moviesScenesTable = "movies_scenes"
+const scenesODatesTable = "scenes_o_dates"
+const scenesPlayDatesTable = "scenes_play_dates"
+const sceneFilesTable = "scene_files"
+const sceneCaptionsTable = "scene_captions"
+const sceneAudioTracksTable = "scene_audio_tracks"
+const sceneCutsTable = "scene_cuts"
+const scenePhashFramesTable = "scene_phash_frames"
 
 var scenesForPerformerQuery = selectAll(sceneTable) + `
 LEFT JOIN performers_scenes as performers_join on performers_join.scene_id = scenes.id
@@ -63,18 +71,25 @@ SELECT id FROM scenes
 WHERE scenes.oshash is null
 `
 
+// phashes of the primary and additional files of all scenes
+var scenePhashesQuery = `
+SELECT id, phash FROM scenes WHERE phash IS NOT NULL
+UNION ALL
+SELECT scene_id as id, phash FROM scene_files WHERE phash IS NOT NULL
+`
+
 var findExactDuplicateQuery = `
-SELECT GROUP_CONCAT(id) as ids
-FROM scenes
-WHERE phash IS NOT NULL
+SELECT GROUP_CONCAT(DISTINCT id) as ids
+FROM (` + scenePhashesQuery + `)
 GROUP BY phash
-HAVING COUNT(*) > 1;
+HAVING COUNT(DISTINCT id) > 1;
 `
 
-var findAllPhashesQuery = `
-SELECT id, phash
-FROM scenes
-WHERE phash IS NOT NULL
+var findAllPhashesQuery = scenePhashesQuery
+
+var findAllPhashFramesQuery = `
+SELECT scene_id, seconds, phash FROM ` + scenePhashFramesTable + `
+ORDER BY scene_id ASC, seconds ASC
 `
 
 type sceneQueryBuilder struct {
@@ -133,6 +148,10 @@ func (qb *sceneQueryBuilder) IncrementOCounter(id int) (int, error) {
 		return 0, err
 	}
 
+	if _, err := qb.oDatesRepository().insert(id, time.Now()); err != nil {
+		return 0, err
+	}
+
 	scene, err := qb.find(id)
 	if err != nil {
 		return 0, err
@@ -150,6 +169,10 @@ func (qb *sceneQueryBuilder) DecrementOCounter(id int) (int, error) {
 		return 0, err
 	}
 
+	if err := qb.oDatesRepository().destroyLatest(id); err != nil {
+		return 0, err
+	}
+
 	scene, err := qb.find(id)
 	if err != nil {
 		return 0, err
@@ -167,6 +190,10 @@ func (qb *sceneQueryBuilder) ResetOCounter(id int) (int, error) {
 		return 0, err
 	}
 
+	if err := qb.oDatesRepository().destroy([]int{id}); err != nil {
+		return 0, err
+	}
+
 	scene, err := qb.find(id)
 	if err != nil {
 		return 0, err
@@ -175,6 +202,65 @@ func (qb *sceneQueryBuilder) ResetOCounter(id int) (int, error) {
 	return scene.OCounter, nil
 }
 
+// SaveActivity sets the resume time of the scene, if provided, and adds the
+// provided duration to the total play duration of the scene.
+func (qb *sceneQueryBuilder) SaveActivity(id int, resumeTime *float64, playDuration *float64) error {
+	exists, err := qb.exists(id)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("scene with id %d not found", id)
+	}
+
+	if resumeTime != nil {
+		if _, err := qb.tx.Exec(
+			`UPDATE scenes SET resume_time = ? WHERE scenes.id = ?`,
+			*resumeTime, id,
+		); err != nil {
+			return err
+		}
+	}
+
+	if playDuration != nil && *playDuration > 0 {
+		if _, err := qb.tx.Exec(
+			`UPDATE scenes SET play_duration = play_duration + ? WHERE scenes.id = ?`,
+			*playDuration, id,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (qb *sceneQueryBuilder) IncrementPlayCount(id int) (int, error) {
+	now := time.Now()
+	_, err := qb.tx.Exec(
+		`UPDATE scenes SET play_count = play_count + 1, last_played_at = ? WHERE scenes.id = ?`,
+		models.SQLiteTimestamp{Timestamp: now}, id,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	scene, err := qb.find(id)
+	if err != nil {
+		return 0, err
+	}
+
+	if scene == nil {
+		return 0, fmt.Errorf("scene with id %d not found", id)
+	}
+
+	if _, err := qb.playDatesRepository().insert(id, now); err != nil {
+		return 0, err
+	}
+
+	return scene.PlayCount, nil
+}
+
 func (qb *sceneQueryBuilder) Destroy(id int) error {
 	// delete all related table rows
 	// TODO - this should be handled by a delete cascade
@@ -376,6 +462,12 @@ func (qb *sceneQueryBuilder) makeFilter(sceneFilter *models.SceneFilterType) *fi
 	}))
 
 	query.handleCriterion(boolCriterionHandler(sceneFilter.Interactive, "scenes.interactive"))
+	query.handleCriterion(durationCriterionHandler(sceneFilter.ResumeTime, "scenes.resume_time"))
+	query.handleCriterion(durationCriterionHandler(sceneFilter.PlayDuration, "scenes.play_duration"))
+	query.handleCriterion(intCriterionHandler(sceneFilter.PlayCount, "scenes.play_count"))
+	query.handleCriterion(timestampCriterionHandler(sceneFilter.LastPlayedAt, "scenes.last_played_at"))
+	query.handleCriterion(historyCriterionHandler(sceneFilter.OHistory, sceneTable, scenesODatesTable, sceneIDColumn, "o_date"))
+	query.handleCriterion(historyCriterionHandler(sceneFilter.PlayHistory, sceneTable, scenesPlayDatesTable, sceneIDColumn, "play_date"))
 
 	query.handleCriterion(sceneTagsCriterionHandler(qb, sceneFilter.Tags))
 	query.handleCriterion(sceneTagCountCriterionHandler(qb, sceneFilter.TagCount))
@@ -683,6 +775,9 @@ func (qb *sceneQueryBuilder) setSceneSort(query *queryBuilder, findFilter *model
 		query.sortAndPagination += getCountSort(sceneTable, scenesTagsTable, sceneIDColumn, direction)
 	case "performer_count":
 		query.sortAndPagination += getCountSort(sceneTable, performersScenesTable, sceneIDColumn, direction)
+	case "play_count":
+		// getSort treats the _count suffix as a relation count, so sort on the column explicitly
+		query.sortAndPagination += fmt.Sprintf(" ORDER BY scenes.play_count %s", getSortDirection(direction))
 	default:
 		query.sortAndPagination += getSort(sort, direction, "scenes")
 	}
@@ -847,6 +942,241 @@ func (qb *sceneQueryBuilder) UpdateStashIDs(sceneID int, stashIDs []models.Stash
 	return qb.stashIDRepository().replace(sceneID, stashIDs)
 }
 
+func (qb *sceneQueryBuilder) oDatesRepository() *timestampRepository {
+	return &timestampRepository{
+		repository: repository{
+			tx:        qb.tx,
+			tableName: scenesODatesTable,
+			idColumn:  sceneIDColumn,
+		},
+		timestampColumn: "o_date",
+	}
+}
+
+func (qb *sceneQueryBuilder) GetODates(sceneID int) ([]time.Time, error) {
+	return qb.oDatesRepository().get(sceneID)
+}
+
+func (qb *sceneQueryBuilder) UpdateODates(sceneID int, dates []time.Time) error {
+	return qb.oDatesRepository().replace(sceneID, dates)
+}
+
+func (qb *sceneQueryBuilder) playDatesRepository() *timestampRepository {
+	return &timestampRepository{
+		repository: repository{
+			tx:        qb.tx,
+			tableName: scenesPlayDatesTable,
+			idColumn:  sceneIDColumn,
+		},
+		timestampColumn: "play_date",
+	}
+}
+
+func (qb *sceneQueryBuilder) GetPlayDates(sceneID int) ([]time.Time, error) {
+	return qb.playDatesRepository().get(sceneID)
+}
+
+func (qb *sceneQueryBuilder) UpdatePlayDates(sceneID int, dates []time.Time) error {
+	return qb.playDatesRepository().replace(sceneID, dates)
+}
+
+func (qb *sceneQueryBuilder) captionsRepository() *captionRepository {
+	return &captionRepository{
+		repository{
+			tx:        qb.tx,
+			tableName: sceneCaptionsTable,
+			idColumn:  sceneIDColumn,
+		},
+	}
+}
+
+func (qb *sceneQueryBuilder) GetCaptions(sceneID int) ([]*models.SceneCaption, error) {
+	return qb.captionsRepository().get(sceneID)
+}
+
+func (qb *sceneQueryBuilder) UpdateCaptions(sceneID int, captions []models.SceneCaption) error {
+	return qb.captionsRepository().replace(sceneID, captions)
+}
+
+func (qb *sceneQueryBuilder) audioTracksRepository() *audioTrackRepository {
+	return &audioTrackRepository{
+		repository{
+			tx:        qb.tx,
+			tableName: sceneAudioTracksTable,
+			idColumn:  sceneIDColumn,
+		},
+	}
+}
+
+func (qb *sceneQueryBuilder) GetAudioTracks(sceneID int) ([]*models.SceneAudioTrack, error) {
+	return qb.audioTracksRepository().get(sceneID)
+}
+
+func (qb *sceneQueryBuilder) UpdateAudioTracks(sceneID int, tracks []models.SceneAudioTrack) error {
+	return qb.audioTracksRepository().replace(sceneID, tracks)
+}
+
+func (qb *sceneQueryBuilder) cutsRepository() *floatRepository {
+	return &floatRepository{
+		repository: repository{
+			tx:        qb.tx,
+			tableName: sceneCutsTable,
+			idColumn:  sceneIDColumn,
+		},
+		valueColumn: "seconds",
+	}
+}
+
+func (qb *sceneQueryBuilder) GetCuts(sceneID int) ([]float64, error) {
+	return qb.cutsRepository().get(sceneID)
+}
+
+func (qb *sceneQueryBuilder) UpdateCuts(sceneID int, cuts []float64) error {
+	return qb.cutsRepository().replace(sceneID, cuts)
+}
+
+func (qb *sceneQueryBuilder) phashFramesRepository() *phashFrameRepository {
+	return &phashFrameRepository{
+		repository{
+			tx:        qb.tx,
+			tableName: scenePhashFramesTable,
+			idColumn:  sceneIDColumn,
+		},
+	}
+}
+
+func (qb *sceneQueryBuilder) GetPhashFrames(sceneID int) ([]*models.ScenePhashFrame, error) {
+	return qb.phashFramesRepository().get(sceneID)
+}
+
+func (qb *sceneQueryBuilder) UpdatePhashFrames(sceneID int, frames []models.ScenePhashFrame) error {
+	return qb.phashFramesRepository().replace(sceneID, frames)
+}
+
+func (qb *sceneQueryBuilder) filesRepository() *repository {
+	return &repository{
+		tx:        qb.tx,
+		tableName: sceneFilesTable,
+		idColumn:  idColumn,
+	}
+}
+
+func (qb *sceneQueryBuilder) GetFiles(sceneID int) ([]*models.SceneFile, error) {
+	query := selectAll(sceneFilesTable) + "WHERE scene_id = ? ORDER BY path ASC"
+	return qb.querySceneFiles(query, []interface{}{sceneID})
+}
+
+func (qb *sceneQueryBuilder) FindFile(id int) (*models.SceneFile, error) {
+	query := selectAll(sceneFilesTable) + "WHERE id = ? LIMIT 1"
+	return qb.querySceneFile(query, []interface{}{id})
+}
+
+func (qb *sceneQueryBuilder) FindFileByPath(path string) (*models.SceneFile, error) {
+	query := selectAll(sceneFilesTable) + "WHERE path = ? LIMIT 1"
+	return qb.querySceneFile(query, []interface{}{path})
+}
+
+func (qb *sceneQueryBuilder) FindFileByChecksum(checksum string) (*models.SceneFile, error) {
+	query := selectAll(sceneFilesTable) + "WHERE checksum = ? LIMIT 1"
+	return qb.querySceneFile(query, []interface{}{checksum})
+}
+
+func (qb *sceneQueryBuilder) FindFileByOSHash(oshash string) (*models.SceneFile, error) {
+	query := selectAll(sceneFilesTable) + "WHERE oshash = ? LIMIT 1"
+	return qb.querySceneFile(query, []interface{}{oshash})
+}
+
+func (qb *sceneQueryBuilder) CreateFile(newFile models.SceneFile) (*models.SceneFile, error) {
+	var ret models.SceneFile
+	if err := qb.filesRepository().insertObject(newFile, &ret); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+func (qb *sceneQueryBuilder) UpdateFile(updatedFile models.SceneFile) (*models.SceneFile, error) {
+	const partial = false
+	if err := qb.filesRepository().update(updatedFile.ID, updatedFile, partial); err != nil {
+		return nil, err
+	}
+
+	return qb.FindFile(updatedFile.ID)
+}
+
+func (qb *sceneQueryBuilder) DestroyFile(id int) error {
+	return qb.filesRepository().destroyExisting([]int{id})
+}
+
+// SetPrimaryFile makes the file with the provided id the primary file of the
+// scene. The details of the previous primary file are stored against the
+// provided file id.
+func (qb *sceneQueryBuilder) SetPrimaryFile(sceneID int, fileID int) error {
+	scene, err := qb.find(sceneID)
+	if err != nil {
+		return err
+	}
+	if scene == nil {
+		return fmt.Errorf("scene with id %d not found", sceneID)
+	}
+
+	file, err := qb.FindFile(fileID)
+	if err != nil {
+		return err
+	}
+	if file == nil || file.SceneID != sceneID {
+		return fmt.Errorf("file with id %d does not belong to scene %d", fileID, sceneID)
+	}
+
+	now := models.SQLiteTimestamp{Timestamp: time.Now()}
+	oldPrimary := models.NewSceneFileFromScene(*scene)
+	oldPrimary.ID = file.ID
+	oldPrimary.CreatedAt = file.CreatedAt
+	oldPrimary.UpdatedAt = now
+
+	if _, err := qb.UpdateFile(oldPrimary); err != nil {
+		return err
+	}
+
+	scenePartial := file.ScenePartial(sceneID)
+	scenePartial.UpdatedAt = &now
+	// read the sidecar captions of the new primary file on the next scan
+	scenePartial.CaptionsModTime = &models.NullSQLiteTimestamp{}
+	// probe the audio tracks of the new primary file on the next scan
+	audioTracksProbed := false
+	scenePartial.AudioTracksProbed = &audioTracksProbed
+	// scene detection must be run on the new primary file
+	scenePartial.IntroEnd = &sql.NullFloat64{}
+	scenePartial.OutroStart = &sql.NullFloat64{}
+	if _, err := qb.Update(scenePartial); err != nil {
+		return err
+	}
+
+	if err := qb.UpdateCuts(sceneID, nil); err != nil {
+		return err
+	}
+
+	// the phash frames are of the previous primary file
+	return qb.phashFramesRepository().destroy([]int{sceneID})
+}
+
+func (qb *sceneQueryBuilder) querySceneFile(query string, args []interface{}) (*models.SceneFile, error) {
+	results, err := qb.querySceneFiles(query, args)
+	if err != nil || len(results) < 1 {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+func (qb *sceneQueryBuilder) querySceneFiles(query string, args []interface{}) ([]*models.SceneFile, error) {
+	var ret models.SceneFiles
+	if err := qb.query(query, args, &ret); err != nil {
+		return nil, err
+	}
+
+	return []*models.SceneFile(ret), nil
+}
+
 func (qb *sceneQueryBuilder) FindDuplicates(distance int) ([][]*models.Scene, error) {
 	var dupeIds [][]int
 	if distance == 0 {
@@ -887,6 +1217,12 @@ func (qb *sceneQueryBuilder) FindDuplicates(distance int) ([][]*models.Scene, er
 
 	var duplicates [][]*models.Scene
 	for _, sceneIds := range dupeIds {
+		// a scene may be present more than once if its files are similar
+		sceneIds = utils.IntAppendUniques(nil, sceneIds)
+		if len(sceneIds) < 2 {
+			continue
+		}
+
 		if scenes, err := qb.FindMany(sceneIds); err == nil {
 			duplicates = append(duplicates, scenes)
 		}
@@ -894,3 +1230,54 @@ func (qb *sceneQueryBuilder) FindDuplicates(distance int) ([][]*models.Scene, er
 
 	return duplicates, nil
 }
+
+// FindOverlaps returns the pairs of scenes where at least minOverlap of the
+// phash frames of the shorter scene match the frames of the other scene, where
+// frames match if the distance between their phashes is at most distance.
+func (qb *sceneQueryBuilder) FindOverlaps(distance int, minOverlap float64) ([]*models.SceneOverlap, error) {
+	var sequences []*utils.PhashSequence
+	if err := qb.queryFunc(findAllPhashFramesQuery, nil, func(rows *sqlx.Rows) error {
+		var sceneID int
+		var frame models.ScenePhashFrame
+		if err := rows.Scan(&sceneID, &frame.Seconds, &frame.Phash); err != nil {
+			return err
+		}
+
+		if len(sequences) == 0 || sequences[len(sequences)-1].SceneID != sceneID {
+			sequences = append(sequences, &utils.PhashSequence{SceneID: sceneID})
+		}
+
+		seq := sequences[len(sequences)-1]
+		seq.Seconds = append(seq.Seconds, frame.Seconds)
+		seq.Hashes = append(seq.Hashes, frame.Phash)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var ret []*models.SceneOverlap
+	for _, o := range utils.FindPhashOverlaps(sequences, distance, minOverlap) {
+		scene, err := qb.find(o.SceneID)
+		if err != nil {
+			return nil, err
+		}
+
+		other, err := qb.find(o.OtherSceneID)
+		if err != nil {
+			return nil, err
+		}
+
+		if scene == nil || other == nil {
+			continue
+		}
+
+		ret = append(ret, &models.SceneOverlap{
+			Scene:   scene,
+			Other:   other,
+			Offset:  o.Offset,
+			Overlap: o.Overlap,
+		})
+	}
+
+	return ret, nil
+}
@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -1622,6 +1623,470 @@ func TestSceneQueryQTrim(t *testing.T) {
 	}
 }
 
+func TestSceneSaveActivityIncrementPlayCount(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Scene()
+
+		// create scene to test against
+		const name = "TestSceneSaveActivityIncrementPlayCount"
+		scene := models.Scene{
+			Path:     name,
+			Checksum: sql.NullString{String: utils.MD5FromString(name), Valid: true},
+		}
+		created, err := qb.Create(scene)
+		if err != nil {
+			return fmt.Errorf("Error creating scene: %s", err.Error())
+		}
+
+		resumeTime := 12.5
+		playDuration := 30.0
+		if err := qb.SaveActivity(created.ID, &resumeTime, &playDuration); err != nil {
+			return fmt.Errorf("Error saving activity: %s", err.Error())
+		}
+
+		// resume time should be left alone if not provided
+		if err := qb.SaveActivity(created.ID, nil, &playDuration); err != nil {
+			return fmt.Errorf("Error saving activity: %s", err.Error())
+		}
+
+		playCount, err := qb.IncrementPlayCount(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error incrementing play count: %s", err.Error())
+		}
+		assert.Equal(t, 1, playCount)
+
+		updated, err := qb.Find(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error finding scene: %s", err.Error())
+		}
+
+		assert.Equal(t, resumeTime, updated.ResumeTime)
+		assert.Equal(t, playDuration*2, updated.PlayDuration)
+		assert.Equal(t, 1, updated.PlayCount)
+		assert.True(t, updated.LastPlayedAt.Valid)
+
+		if err := qb.SaveActivity(0, &resumeTime, nil); err == nil {
+			return fmt.Errorf("Expected error saving activity for invalid scene")
+		}
+
+		pathCriterion := models.StringCriterionInput{
+			Value:    name,
+			Modifier: models.CriterionModifierEquals,
+		}
+
+		sceneFilter := models.SceneFilterType{
+			Path: &pathCriterion,
+			PlayCount: &models.IntCriterionInput{
+				Value:    0,
+				Modifier: models.CriterionModifierGreaterThan,
+			},
+			LastPlayedAt: &models.TimestampCriterionInput{
+				Value:    "2000-01-01",
+				Modifier: models.CriterionModifierGreaterThan,
+			},
+		}
+
+		scenes := queryScene(t, qb, &sceneFilter, nil)
+		assert.Len(t, scenes, 1)
+
+		sceneFilter.LastPlayedAt.Modifier = models.CriterionModifierLessThan
+		scenes = queryScene(t, qb, &sceneFilter, nil)
+		assert.Len(t, scenes, 0)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestSceneOHistoryPlayHistory(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Scene()
+
+		// create scene to test against
+		const name = "TestSceneOHistoryPlayHistory"
+		scene := models.Scene{
+			Path:     name,
+			Checksum: sql.NullString{String: utils.MD5FromString(name), Valid: true},
+		}
+		created, err := qb.Create(scene)
+		if err != nil {
+			return fmt.Errorf("Error creating scene: %s", err.Error())
+		}
+
+		pathCriterion := models.StringCriterionInput{
+			Value:    name,
+			Modifier: models.CriterionModifierEquals,
+		}
+
+		// no history yet
+		sceneFilter := models.SceneFilterType{
+			Path: &pathCriterion,
+			OHistory: &models.TimestampCriterionInput{
+				Modifier: models.CriterionModifierIsNull,
+			},
+		}
+		scenes := queryScene(t, qb, &sceneFilter, nil)
+		assert.Len(t, scenes, 1)
+
+		for i := 0; i < 2; i++ {
+			if _, err := qb.IncrementOCounter(created.ID); err != nil {
+				return fmt.Errorf("Error incrementing o-counter: %s", err.Error())
+			}
+		}
+
+		if _, err := qb.DecrementOCounter(created.ID); err != nil {
+			return fmt.Errorf("Error decrementing o-counter: %s", err.Error())
+		}
+
+		if _, err := qb.IncrementPlayCount(created.ID); err != nil {
+			return fmt.Errorf("Error incrementing play count: %s", err.Error())
+		}
+
+		oDates, err := qb.GetODates(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting o dates: %s", err.Error())
+		}
+		assert.Len(t, oDates, 1)
+
+		playDates, err := qb.GetPlayDates(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting play dates: %s", err.Error())
+		}
+		assert.Len(t, playDates, 1)
+
+		sceneFilter.OHistory = &models.TimestampCriterionInput{
+			Value:    "-30d",
+			Modifier: models.CriterionModifierGreaterThan,
+		}
+		sceneFilter.PlayHistory = &models.TimestampCriterionInput{
+			Value:    "-1d",
+			Value2:   &[]string{"+1d"}[0],
+			Modifier: models.CriterionModifierBetween,
+		}
+		scenes = queryScene(t, qb, &sceneFilter, nil)
+		assert.Len(t, scenes, 1)
+
+		sceneFilter.PlayHistory.Modifier = models.CriterionModifierNotBetween
+		scenes = queryScene(t, qb, &sceneFilter, nil)
+		assert.Len(t, scenes, 0)
+
+		// replace the history with explicit dates
+		oldDate := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+		if err := qb.UpdatePlayDates(created.ID, []time.Time{oldDate}); err != nil {
+			return fmt.Errorf("Error updating play dates: %s", err.Error())
+		}
+
+		sceneFilter.PlayHistory = &models.TimestampCriterionInput{
+			Value:    "2001-01-01",
+			Modifier: models.CriterionModifierEquals,
+		}
+		scenes = queryScene(t, qb, &sceneFilter, nil)
+		assert.Len(t, scenes, 1)
+
+		if _, err := qb.ResetOCounter(created.ID); err != nil {
+			return fmt.Errorf("Error resetting o-counter: %s", err.Error())
+		}
+
+		oDates, err = qb.GetODates(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting o dates: %s", err.Error())
+		}
+		assert.Len(t, oDates, 0)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestSceneCaptions(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Scene()
+
+		sceneID := sceneIDs[sceneIdxWithGallery]
+		captions := []models.SceneCaption{
+			{
+				LanguageCode: "en",
+				Filename:     "scene.en.srt",
+				CaptionType:  "srt",
+			},
+			{
+				LanguageCode: "fr",
+				CaptionType:  "subrip",
+				StreamIndex:  sql.NullInt64{Int64: 2, Valid: true},
+			},
+		}
+
+		if err := qb.UpdateCaptions(sceneID, captions); err != nil {
+			return fmt.Errorf("Error updating scene captions: %s", err.Error())
+		}
+
+		found, err := qb.GetCaptions(sceneID)
+		if err != nil {
+			return fmt.Errorf("Error getting scene captions: %s", err.Error())
+		}
+
+		// sidecar captions are returned first
+		assert.Len(t, found, 2)
+		assert.Equal(t, captions[0], *found[0])
+		assert.Equal(t, captions[1], *found[1])
+
+		// reset
+		return qb.UpdateCaptions(sceneID, nil)
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestSceneAudioTracks(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Scene()
+
+		sceneID := sceneIDs[sceneIdxWithGallery]
+		tracks := []models.SceneAudioTrack{
+			{
+				StreamIndex:  2,
+				LanguageCode: "fr",
+				Codec:        "ac3",
+				Channels:     6,
+			},
+			{
+				StreamIndex:  1,
+				LanguageCode: "en",
+				Codec:        "aac",
+				Channels:     2,
+			},
+		}
+
+		if err := qb.UpdateAudioTracks(sceneID, tracks); err != nil {
+			return fmt.Errorf("Error updating scene audio tracks: %s", err.Error())
+		}
+
+		found, err := qb.GetAudioTracks(sceneID)
+		if err != nil {
+			return fmt.Errorf("Error getting scene audio tracks: %s", err.Error())
+		}
+
+		// tracks are returned in stream order
+		assert.Len(t, found, 2)
+		assert.Equal(t, tracks[1], *found[0])
+		assert.Equal(t, tracks[0], *found[1])
+
+		// reset
+		return qb.UpdateAudioTracks(sceneID, nil)
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestSceneCuts(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Scene()
+
+		sceneID := sceneIDs[sceneIdxWithGallery]
+		cuts := []float64{120.5, 10.25}
+
+		if err := qb.UpdateCuts(sceneID, cuts); err != nil {
+			return fmt.Errorf("Error updating scene cuts: %s", err.Error())
+		}
+
+		found, err := qb.GetCuts(sceneID)
+		if err != nil {
+			return fmt.Errorf("Error getting scene cuts: %s", err.Error())
+		}
+
+		// cuts are returned in ascending order
+		assert.Equal(t, []float64{10.25, 120.5}, found)
+
+		// reset
+		return qb.UpdateCuts(sceneID, nil)
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestScenePhashFrames(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Scene()
+
+		sceneID := sceneIDs[sceneIdxWithGallery]
+		frames := []models.ScenePhashFrame{
+			{Seconds: 5, Phash: 5678},
+			{Seconds: 0, Phash: 1234},
+		}
+
+		if err := qb.UpdatePhashFrames(sceneID, frames); err != nil {
+			return fmt.Errorf("Error updating scene phash frames: %s", err.Error())
+		}
+
+		found, err := qb.GetPhashFrames(sceneID)
+		if err != nil {
+			return fmt.Errorf("Error getting scene phash frames: %s", err.Error())
+		}
+
+		// frames are returned in ascending order
+		assert.Equal(t, []*models.ScenePhashFrame{&frames[1], &frames[0]}, found)
+
+		// reset
+		return qb.UpdatePhashFrames(sceneID, nil)
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestSceneFindOverlaps(t *testing.T) {
+	const interval = 5
+
+	phash := func(i int) int64 {
+		return int64(uint64(i+1) * 0x9E3779B97F4A7C15)
+	}
+
+	makeFrames := func(start int, end int) []models.ScenePhashFrame {
+		var ret []models.ScenePhashFrame
+		for i := start; i < end; i++ {
+			ret = append(ret, models.ScenePhashFrame{
+				Seconds: float64((i - start) * interval),
+				Phash:   phash(i),
+			})
+		}
+		return ret
+	}
+
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Scene()
+
+		sceneID := sceneIDs[sceneIdxWithGallery]
+		otherSceneID := sceneIDs[sceneIdxWithMovie]
+
+		if err := qb.UpdatePhashFrames(sceneID, makeFrames(0, 20)); err != nil {
+			return err
+		}
+		// the other scene is part of the scene
+		if err := qb.UpdatePhashFrames(otherSceneID, makeFrames(4, 14)); err != nil {
+			return err
+		}
+
+		overlaps, err := qb.FindOverlaps(0, 0.5)
+		if err != nil {
+			return fmt.Errorf("Error finding overlaps: %s", err.Error())
+		}
+
+		assert.Len(t, overlaps, 1)
+		if len(overlaps) == 1 {
+			o := overlaps[0]
+			assert.Equal(t, sceneID, o.Scene.ID)
+			assert.Equal(t, otherSceneID, o.Other.ID)
+			assert.Equal(t, float64(20), o.Offset)
+			assert.Equal(t, float64(1), o.Overlap)
+		}
+
+		// reset
+		if err := qb.UpdatePhashFrames(sceneID, nil); err != nil {
+			return err
+		}
+		return qb.UpdatePhashFrames(otherSceneID, nil)
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestSceneFiles(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Scene()
+
+		// create scene to test against
+		const name = "TestSceneFiles"
+		scene := models.Scene{
+			Path:       name,
+			Checksum:   sql.NullString{String: utils.MD5FromString(name), Valid: true},
+			Phash:      sql.NullInt64{Int64: 1234, Valid: true},
+			IntroEnd:   sql.NullFloat64{Float64: 10, Valid: true},
+			OutroStart: sql.NullFloat64{Float64: 100, Valid: true},
+		}
+		created, err := qb.Create(scene)
+		if err != nil {
+			return fmt.Errorf("Error creating scene: %s", err.Error())
+		}
+
+		if err := qb.UpdateCuts(created.ID, []float64{20, 30}); err != nil {
+			return fmt.Errorf("Error updating scene cuts: %s", err.Error())
+		}
+
+		const filePath = "TestSceneFiles additional"
+		fileChecksum := utils.MD5FromString(filePath)
+		file, err := qb.CreateFile(models.SceneFile{
+			SceneID:  created.ID,
+			Path:     filePath,
+			Checksum: sql.NullString{String: fileChecksum, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("Error creating scene file: %s", err.Error())
+		}
+
+		found, err := qb.FindFileByPath(filePath)
+		if err != nil {
+			return fmt.Errorf("Error finding scene file: %s", err.Error())
+		}
+		assert.Equal(t, file.ID, found.ID)
+
+		found, err = qb.FindFileByChecksum(fileChecksum)
+		if err != nil {
+			return fmt.Errorf("Error finding scene file: %s", err.Error())
+		}
+		assert.Equal(t, file.ID, found.ID)
+
+		if err := qb.SetPrimaryFile(created.ID, file.ID); err != nil {
+			return fmt.Errorf("Error setting primary file: %s", err.Error())
+		}
+
+		updated, err := qb.Find(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error finding scene: %s", err.Error())
+		}
+		assert.Equal(t, filePath, updated.Path)
+		assert.Equal(t, fileChecksum, updated.Checksum.String)
+		assert.False(t, updated.Phash.Valid)
+
+		// scene detection results are of the previous primary file
+		assert.False(t, updated.IntroEnd.Valid)
+		assert.False(t, updated.OutroStart.Valid)
+		cuts, err := qb.GetCuts(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting scene cuts: %s", err.Error())
+		}
+		assert.Len(t, cuts, 0)
+
+		files, err := qb.GetFiles(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting scene files: %s", err.Error())
+		}
+		assert.Len(t, files, 1)
+		assert.Equal(t, file.ID, files[0].ID)
+		assert.Equal(t, name, files[0].Path)
+		assert.Equal(t, int64(1234), files[0].Phash.Int64)
+
+		// setting a file belonging to another scene should fail
+		if err := qb.SetPrimaryFile(sceneIDs[sceneIdxWithGallery], file.ID); err == nil {
+			return fmt.Errorf("Expected error setting primary file of another scene")
+		}
+
+		if err := qb.DestroyFile(file.ID); err != nil {
+			return fmt.Errorf("Error destroying scene file: %s", err.Error())
+		}
+
+		files, err = qb.GetFiles(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting scene files: %s", err.Error())
+		}
+		assert.Len(t, files, 0)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
 // TODO Update
 // TODO IncrementOCounter
 // TODO DecrementOCounter
@@ -129,6 +129,9 @@ const (
 	galleryIdxWithPerformerTwoTags
 	galleryIdxWithStudioPerformer
 	galleryIdxWithGrandChildStudio
+	galleryIdxWithGrandChild
+	galleryIdxWithParentAndChild
+	galleryIdxWithGrandParent
 	// new indexes above
 	lastGalleryIdx
 
@@ -337,6 +340,13 @@ var (
 	}
 )
 
+var (
+	galleryParentLinks = [][2]int{
+		{galleryIdxWithGrandChild, galleryIdxWithParentAndChild},
+		{galleryIdxWithParentAndChild, galleryIdxWithGrandParent},
+	}
+)
+
 var (
 	performerTagLinks = [][2]int{
 		{performerIdxWithTag, tagIdxWithPerformer},
@@ -499,6 +509,10 @@ func populateDB() error {
 			return fmt.Errorf("error linking gallery studios: %s", err.Error())
 		}
 
+		if err := linkGalleriesParent(r.Gallery()); err != nil {
+			return fmt.Errorf("error linking galleries parent: %s", err.Error())
+		}
+
 		if err := createMarker(r.SceneMarker(), sceneIdxWithMarker, tagIdxWithPrimaryMarker, []int{tagIdxWithMarker}); err != nil {
 			return fmt.Errorf("error creating scene marker: %s", err.Error())
 		}
@@ -1224,6 +1238,18 @@ func linkStudiosParent(qb models.StudioWriter) error {
 	})
 }
 
+func linkGalleriesParent(qb models.GalleryWriter) error {
+	return doLinks(galleryParentLinks, func(parentIndex, childIndex int) error {
+		gallery := models.GalleryPartial{
+			ID:       galleryIDs[childIndex],
+			ParentID: &sql.NullInt64{Int64: int64(galleryIDs[parentIndex]), Valid: true},
+		}
+		_, err := qb.UpdatePartial(gallery)
+
+		return err
+	})
+}
+
 func addTagImage(qb models.TagWriter, tagIndex int) error {
 	return qb.UpdateImage(tagIDs[tagIndex], models.DefaultTagImage)
 }
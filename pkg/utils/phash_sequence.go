@@ -0,0 +1,263 @@
+package utils
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// minPhashBits is the minimum number of set bits in the phash of a frame for
+// the frame to be used to find overlaps. Frames without detail, such as black
+// frames, have phashes with few set bits and match frames of any scene.
+const minPhashBits = 8
+
+// maxPhashBands is the maximum number of bands that phashes are split into to
+// find candidate matching frames. Narrower bands match too many frames.
+const maxPhashBands = 4
+
+// maxPhashBucketSize is the maximum number of frames sharing a band for the
+// band to be used to find candidate matching frames. Bands shared by more
+// frames are too common to identify matching frames, and comparing each pair
+// of their frames is too slow.
+const maxPhashBucketSize = 256
+
+// minOverlapFrames is the minimum number of matching frames of an overlap, so
+// that single frames matching by chance are ignored.
+const minOverlapFrames = 2
+
+// PhashSequence is the sequence of phashes of frames of a scene, taken at a
+// fixed interval.
+type PhashSequence struct {
+	SceneID int
+	// Seconds is the time of each frame, in seconds.
+	Seconds []float64
+	Hashes  []int64
+}
+
+// PhashOverlap is a pair of scenes where part of one scene matches the other.
+type PhashOverlap struct {
+	SceneID      int
+	OtherSceneID int
+	// Offset is the time in the scene that matches the start of the other
+	// scene, in seconds. It is negative if the other scene starts before the
+	// scene.
+	Offset float64
+	// Overlap is the fraction of the frames of the other scene that match
+	// the scene.
+	Overlap float64
+}
+
+type phashFrameRef struct {
+	sequence int
+	frame    int
+}
+
+type phashBandKey struct {
+	band  int
+	value uint64
+}
+
+type phashOverlapPair struct {
+	sequence      int
+	otherSequence int
+}
+
+// FindPhashOverlaps returns the pairs of sequences where at least minOverlap
+// of the frames of the shorter sequence match frames of the longer sequence
+// at the same offset, where frames match if the distance between their phashes
+// is at most distance.
+//
+// Sequences must be taken at the same interval. The other scene of each
+// overlap is the scene of the shorter sequence.
+//
+// Candidate offsets are found by indexing bands of the phashes. Any two
+// phashes within distance share at least one of distance+1 bands, so for
+// distances greater than maxPhashBands-1 some matching frames may not be
+// indexed, but the overlap is calculated from all frames at the best offset.
+// Only the first frame of a run of identical phashes, such as a still shot, is
+// indexed, and bands shared by more than maxPhashBucketSize frames are
+// ignored.
+func FindPhashOverlaps(sequences []*PhashSequence, distance int, minOverlap float64) []*PhashOverlap {
+	bandCount := distance + 1
+	if bandCount > maxPhashBands {
+		bandCount = maxPhashBands
+	}
+
+	index := make(map[phashBandKey][]phashFrameRef)
+	for s, seq := range sequences {
+		for f, h := range seq.Hashes {
+			if !isDetailedPhash(h) || (f > 0 && h == seq.Hashes[f-1]) {
+				continue
+			}
+
+			for b := 0; b < bandCount; b++ {
+				key := phashBandKey{band: b, value: phashBand(h, b, bandCount)}
+				index[key] = append(index[key], phashFrameRef{sequence: s, frame: f})
+			}
+		}
+	}
+
+	// number of matching frames of each pair of sequences at each offset
+	votes := make(map[phashOverlapPair]map[int]int)
+	for key, refs := range index {
+		if len(refs) > maxPhashBucketSize {
+			continue
+		}
+
+		for i, a := range refs {
+			for _, o := range refs[i+1:] {
+				if a.sequence == o.sequence {
+					continue
+				}
+
+				ha := sequences[a.sequence].Hashes[a.frame]
+				ho := sequences[o.sequence].Hashes[o.frame]
+
+				// only count each pair of frames in the first band they share
+				if phashDistance(ha, ho) > distance || sharesEarlierBand(ha, ho, key.band, bandCount) {
+					continue
+				}
+
+				pair := phashOverlapPair{sequence: a.sequence, otherSequence: o.sequence}
+				offset := a.frame - o.frame
+				if a.sequence > o.sequence {
+					pair = phashOverlapPair{sequence: o.sequence, otherSequence: a.sequence}
+					offset = -offset
+				}
+
+				if votes[pair] == nil {
+					votes[pair] = make(map[int]int)
+				}
+				votes[pair][offset]++
+			}
+		}
+	}
+
+	var ret []*PhashOverlap
+	for pair, offsets := range votes {
+		seq := sequences[pair.sequence]
+		other := sequences[pair.otherSequence]
+
+		bestOffset, bestVotes := 0, 0
+		for offset, n := range offsets {
+			if n > bestVotes || (n == bestVotes && offset < bestOffset) {
+				bestOffset, bestVotes = offset, n
+			}
+		}
+		if bestVotes < minOverlapFrames {
+			continue
+		}
+		bestVotes = matchingPhashCount(seq, other, bestOffset, distance)
+
+		// report the overlap relative to the longer sequence
+		if detailedPhashCount(other) > detailedPhashCount(seq) {
+			seq, other = other, seq
+			bestOffset = -bestOffset
+		}
+
+		frames := detailedPhashCount(other)
+		if frames == 0 {
+			continue
+		}
+
+		overlap := float64(bestVotes) / float64(frames)
+		if overlap < minOverlap {
+			continue
+		}
+
+		ret = append(ret, &PhashOverlap{
+			SceneID:      seq.SceneID,
+			OtherSceneID: other.SceneID,
+			Offset:       frameOffsetSeconds(seq, other, bestOffset),
+			Overlap:      overlap,
+		})
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].SceneID != ret[j].SceneID {
+			return ret[i].SceneID < ret[j].SceneID
+		}
+		return ret[i].OtherSceneID < ret[j].OtherSceneID
+	})
+
+	return ret
+}
+
+// matchingPhashCount returns the number of frames of other that match seq,
+// where frame i of seq is compared to frame i-offset of other.
+func matchingPhashCount(seq *PhashSequence, other *PhashSequence, offset int, distance int) int {
+	ret := 0
+	for i, h := range seq.Hashes {
+		j := i - offset
+		if j < 0 || j >= len(other.Hashes) {
+			continue
+		}
+
+		if isDetailedPhash(h) && isDetailedPhash(other.Hashes[j]) && phashDistance(h, other.Hashes[j]) <= distance {
+			ret++
+		}
+	}
+
+	return ret
+}
+
+func phashDistance(a int64, b int64) int {
+	return bits.OnesCount64(uint64(a) ^ uint64(b))
+}
+
+func isDetailedPhash(h int64) bool {
+	return bits.OnesCount64(uint64(h)) >= minPhashBits
+}
+
+func detailedPhashCount(seq *PhashSequence) int {
+	ret := 0
+	for _, h := range seq.Hashes {
+		if isDetailedPhash(h) {
+			ret++
+		}
+	}
+
+	return ret
+}
+
+// phashBand returns the bits of band b of the hash, where the hash is split
+// into bandCount bands.
+func phashBand(h int64, b int, bandCount int) uint64 {
+	start := b * 64 / bandCount
+	end := (b + 1) * 64 / bandCount
+	width := uint(end - start)
+
+	v := uint64(h) >> uint(start)
+	if width < 64 {
+		v &= (1 << width) - 1
+	}
+	return v
+}
+
+func sharesEarlierBand(a int64, b int64, band int, bandCount int) bool {
+	for i := 0; i < band; i++ {
+		if phashBand(a, i, bandCount) == phashBand(b, i, bandCount) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// frameOffsetSeconds returns the time in seq that matches the start of other,
+// where frame i of seq matches frame i-offset of other.
+func frameOffsetSeconds(seq *PhashSequence, other *PhashSequence, offset int) float64 {
+	interval := 0.0
+	if len(seq.Seconds) > 1 {
+		interval = seq.Seconds[1] - seq.Seconds[0]
+	}
+
+	start := 0.0
+	if len(seq.Seconds) > 0 {
+		start = seq.Seconds[0]
+	}
+	if len(other.Seconds) > 0 {
+		start -= other.Seconds[0]
+	}
+
+	return start + float64(offset)*interval
+}
@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPhashInterval = 5
+
+func testPhash(i int) int64 {
+	return int64(uint64(i+1) * 0x9E3779B97F4A7C15)
+}
+
+func testPhashSequence(sceneID int, hashes []int64) *PhashSequence {
+	ret := &PhashSequence{
+		SceneID: sceneID,
+		Hashes:  hashes,
+	}
+	for i := range hashes {
+		ret.Seconds = append(ret.Seconds, float64(i*testPhashInterval))
+	}
+	return ret
+}
+
+func testPhashRange(start int, end int) []int64 {
+	var ret []int64
+	for i := start; i < end; i++ {
+		ret = append(ret, testPhash(i))
+	}
+	return ret
+}
+
+func TestFindPhashOverlaps(t *testing.T) {
+	const (
+		sceneID = iota + 1
+		trimmedSceneID
+		unrelatedSceneID
+		blackSceneID
+	)
+
+	trimmed := testPhashRange(5, 15)
+	// small differences from re-encoding
+	trimmed[2] ^= 1
+	trimmed[7] ^= 1 << 40
+
+	sequences := []*PhashSequence{
+		testPhashSequence(trimmedSceneID, trimmed),
+		testPhashSequence(sceneID, testPhashRange(0, 20)),
+		testPhashSequence(unrelatedSceneID, testPhashRange(100, 110)),
+		testPhashSequence(blackSceneID, make([]int64, 10)),
+	}
+
+	got := FindPhashOverlaps(sequences, 4, 0.5)
+	assert.Equal(t, []*PhashOverlap{
+		{
+			SceneID:      sceneID,
+			OtherSceneID: trimmedSceneID,
+			Offset:       25,
+			Overlap:      1,
+		},
+	}, got)
+
+	// the distance is less than the differences
+	got = FindPhashOverlaps(sequences, 0, 0.5)
+	assert.Equal(t, []*PhashOverlap{
+		{
+			SceneID:      sceneID,
+			OtherSceneID: trimmedSceneID,
+			Offset:       25,
+			Overlap:      0.8,
+		},
+	}, got)
+
+	got = FindPhashOverlaps(sequences, 0, 0.9)
+	assert.Len(t, got, 0)
+}
+
+func TestFindPhashOverlapsPartial(t *testing.T) {
+	// the end of one scene is the start of the other
+	sequences := []*PhashSequence{
+		testPhashSequence(1, testPhashRange(0, 20)),
+		testPhashSequence(2, testPhashRange(16, 26)),
+	}
+
+	got := FindPhashOverlaps(sequences, 0, 0.4)
+	assert.Equal(t, []*PhashOverlap{
+		{
+			SceneID:      1,
+			OtherSceneID: 2,
+			Offset:       80,
+			Overlap:      0.4,
+		},
+	}, got)
+
+	got = FindPhashOverlaps(sequences, 0, 0.5)
+	assert.Len(t, got, 0)
+}
+
+func testPhashRun(h int64, n int) []int64 {
+	var ret []int64
+	for i := 0; i < n; i++ {
+		ret = append(ret, h)
+	}
+	return ret
+}
+
+func TestFindPhashOverlapsStillShots(t *testing.T) {
+	still := testPhash(1000)
+
+	// unrelated scenes with the same still shot at the end
+	sequences := []*PhashSequence{
+		testPhashSequence(1, append(testPhashRange(0, 10), testPhashRun(still, 10)...)),
+		testPhashSequence(2, append(testPhashRange(100, 110), testPhashRun(still, 10)...)),
+	}
+
+	got := FindPhashOverlaps(sequences, 0, 0.5)
+	assert.Len(t, got, 0)
+
+	// the still shot is part of the overlap of matching scenes
+	sequences = []*PhashSequence{
+		testPhashSequence(1, append(testPhashRange(0, 10), testPhashRun(still, 10)...)),
+		testPhashSequence(2, append(testPhashRange(5, 10), testPhashRun(still, 5)...)),
+	}
+
+	got = FindPhashOverlaps(sequences, 0, 0.5)
+	assert.Equal(t, []*PhashOverlap{
+		{
+			SceneID:      1,
+			OtherSceneID: 2,
+			Offset:       25,
+			Overlap:      1,
+		},
+	}, got)
+}
+
+func TestFindPhashOverlapsCommonFrames(t *testing.T) {
+	// frames shared by too many scenes, such as a studio logo, are ignored
+	var sequences []*PhashSequence
+	for i := 0; i <= maxPhashBucketSize; i++ {
+		hashes := append(testPhashRange(0, 2), testPhash(100+i))
+		sequences = append(sequences, testPhashSequence(i+1, hashes))
+	}
+
+	got := FindPhashOverlaps(sequences, 0, 0.5)
+	assert.Len(t, got, 0)
+
+	// scenes that match are still found
+	sequences = append(sequences,
+		testPhashSequence(1000, append(testPhashRange(0, 2), testPhashRange(500, 510)...)),
+		testPhashSequence(1001, testPhashRange(502, 510)),
+	)
+
+	got = FindPhashOverlaps(sequences, 0, 0.5)
+	assert.Equal(t, []*PhashOverlap{
+		{
+			SceneID:      1000,
+			OtherSceneID: 1001,
+			Offset:       20,
+			Overlap:      1,
+		},
+	}, got)
+}